@@ -0,0 +1,140 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// AlertingConfigParameters are the configurable alerting settings. Settings
+// that are not set are left as they are.
+type AlertingConfigParameters struct {
+	// WebhookURLSecretRef references the Slack webhook URL alerts are
+	// posted to. It is sent to the proxy as an environment variable, so it
+	// isn't stored in plain text in the spec.
+	WebhookURLSecretRef xpv1.SecretKeySelector `json:"webhookUrlSecretRef"`
+
+	// AlertTypes are the types of alerts that are posted, e.g.
+	// budget_alerts, llm_exceptions or llm_too_slow. All alert types are
+	// posted if unset.
+	// +optional
+	AlertTypes []string `json:"alertTypes,omitempty"`
+
+	// BudgetAlertThresholds are the fractions of a budget, e.g. 0.5 and
+	// 0.8, at which a budget alert is posted.
+	// +optional
+	BudgetAlertThresholds []float64 `json:"budgetAlertThresholds,omitempty"`
+
+	// AlertingThreshold is how many seconds a request may take before an
+	// alert that it is slow or hanging is posted.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	AlertingThreshold *int64 `json:"alertingThreshold,omitempty"`
+}
+
+// AlertingConfigObservation are the observed alerting settings.
+type AlertingConfigObservation struct {
+	// Enabled is true if the proxy posts alerts to Slack.
+	Enabled bool `json:"enabled,omitempty"`
+
+	// AlertTypes are the types of alerts that are posted.
+	AlertTypes []string `json:"alertTypes,omitempty"`
+
+	// BudgetAlertThresholds are the fractions of a budget at which a budget
+	// alert is posted.
+	BudgetAlertThresholds []float64 `json:"budgetAlertThresholds,omitempty"`
+
+	// AlertingThreshold is how many seconds a request may take before an
+	// alert that it is slow or hanging is posted.
+	AlertingThreshold *int64 `json:"alertingThreshold,omitempty"`
+
+	// LastSyncTime is when the AlertingConfig last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// An AlertingConfigSpec defines the desired state of an AlertingConfig.
+type AlertingConfigSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       AlertingConfigParameters `json:"forProvider"`
+}
+
+// An AlertingConfigStatus represents the observed state of an
+// AlertingConfig.
+type AlertingConfigStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          AlertingConfigObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An AlertingConfig manages the Slack alerting of a LiteLLM proxy, e.g. on
+// budget events. The alerting settings are global, so only the oldest
+// AlertingConfig of a ProviderConfig is reconciled; any other fails to sync.
+// Deleting an AlertingConfig disables alerting and removes the settings it
+// set.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ENABLED",type="boolean",JSONPath=".status.atProvider.enabled"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type AlertingConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   AlertingConfigSpec   `json:"spec"`
+	Status AlertingConfigStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the AlertingConfig last synced with LiteLLM.
+func (mg *AlertingConfig) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the AlertingConfig last synced with LiteLLM.
+func (mg *AlertingConfig) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// AlertingConfigList contains a list of AlertingConfig
+type AlertingConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []AlertingConfig `json:"items"`
+}
+
+// AlertingConfig type metadata.
+var (
+	AlertingConfigKind             = reflect.TypeOf(AlertingConfig{}).Name()
+	AlertingConfigGroupKind        = schema.GroupKind{Group: Group, Kind: AlertingConfigKind}.String()
+	AlertingConfigKindAPIVersion   = AlertingConfigKind + "." + SchemeGroupVersion.String()
+	AlertingConfigGroupVersionKind = SchemeGroupVersion.WithKind(AlertingConfigKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&AlertingConfig{}, &AlertingConfigList{})
+}
@@ -0,0 +1,161 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// BudgetParameters are the configurable fields of a Budget. The LiteLLM
+// budget_id is the external name of the Budget.
+type BudgetParameters struct {
+	// MaxBudget is the maximum spend in USD.
+	// +optional
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// SoftBudget is the spend in USD at which alerts are sent.
+	// +optional
+	SoftBudget *float64 `json:"softBudget,omitempty"`
+
+	// BudgetDuration is how often the budget resets, e.g. 30d.
+	// +optional
+	BudgetDuration string `json:"budgetDuration,omitempty"`
+
+	// TPMLimit is the maximum number of tokens per minute.
+	// +optional
+	TPMLimit *int64 `json:"tpmLimit,omitempty"`
+
+	// RPMLimit is the maximum number of requests per minute.
+	// +optional
+	RPMLimit *int64 `json:"rpmLimit,omitempty"`
+
+	// MaxParallelRequests is the maximum number of concurrent requests.
+	// +optional
+	MaxParallelRequests *int64 `json:"maxParallelRequests,omitempty"`
+
+	// ModelMaxBudget is the maximum spend in USD per model.
+	// +optional
+	ModelMaxBudget map[string]float64 `json:"modelMaxBudget,omitempty"`
+}
+
+// BudgetObservation are the observable fields of a Budget.
+type BudgetObservation struct {
+	// BudgetID is the LiteLLM id of the budget.
+	BudgetID string `json:"budgetId,omitempty"`
+
+	// BudgetResetAt is when the budget is next reset.
+	BudgetResetAt *metav1.Time `json:"budgetResetAt,omitempty"`
+
+	// CreatedAt is when the budget was created.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is when the budget was last updated.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+
+	// MaxBudget is the effective maximum spend in USD.
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// SoftBudget is the effective spend in USD at which alerts are sent.
+	SoftBudget *float64 `json:"softBudget,omitempty"`
+
+	// BudgetDuration is the effective budget reset period.
+	BudgetDuration string `json:"budgetDuration,omitempty"`
+
+	// TPMLimit is the effective maximum number of tokens per minute.
+	TPMLimit *int64 `json:"tpmLimit,omitempty"`
+
+	// RPMLimit is the effective maximum number of requests per minute.
+	RPMLimit *int64 `json:"rpmLimit,omitempty"`
+
+	// MaxParallelRequests is the effective maximum number of concurrent
+	// requests.
+	MaxParallelRequests *int64 `json:"maxParallelRequests,omitempty"`
+
+	// LastSyncTime is when the Budget last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A BudgetSpec defines the desired state of a Budget.
+type BudgetSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       BudgetParameters `json:"forProvider"`
+}
+
+// A BudgetStatus represents the observed state of a Budget.
+type BudgetStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          BudgetObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Budget is a LiteLLM budget that can be shared by customers and teams.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="MAX-BUDGET",type="number",JSONPath=".status.atProvider.maxBudget"
+// +kubebuilder:printcolumn:name="DURATION",type="string",JSONPath=".status.atProvider.budgetDuration"
+// +kubebuilder:printcolumn:name="RESET-AT",type="date",JSONPath=".status.atProvider.budgetResetAt",priority=1
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Budget struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   BudgetSpec   `json:"spec"`
+	Status BudgetStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Budget last synced with LiteLLM.
+func (mg *Budget) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Budget last synced with LiteLLM.
+func (mg *Budget) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// BudgetList contains a list of Budget
+type BudgetList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Budget `json:"items"`
+}
+
+// Budget type metadata.
+var (
+	BudgetKind             = reflect.TypeOf(Budget{}).Name()
+	BudgetGroupKind        = schema.GroupKind{Group: Group, Kind: BudgetKind}.String()
+	BudgetKindAPIVersion   = BudgetKind + "." + SchemeGroupVersion.String()
+	BudgetGroupVersionKind = SchemeGroupVersion.WithKind(BudgetKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Budget{}, &BudgetList{})
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// BudgetID extracts the budget_id of a Budget. The external name of a Budget
+// defaults to its name before it is created, so nothing is extracted until
+// the Budget is ready. This keeps referencing resources from being sent a
+// budget_id that doesn't exist yet.
+func BudgetID() reference.ExtractValueFn {
+	return func(mg resource.Managed) string {
+		if mg.GetCondition(xpv1.TypeReady).Status != corev1.ConditionTrue {
+			return ""
+		}
+		return meta.GetExternalName(mg)
+	}
+}
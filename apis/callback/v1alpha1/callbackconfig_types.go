@@ -0,0 +1,152 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// An EnvVar is an environment variable a callback reads its settings from,
+// e.g. LANGFUSE_SECRET_KEY.
+// +kubebuilder:validation:XValidation:rule="has(self.value) != has(self.secretKeyRef)",message="exactly one of value and secretKeyRef must be set"
+type EnvVar struct {
+	// Name of the environment variable.
+	Name string `json:"name"`
+
+	// Value of the environment variable.
+	// +optional
+	Value *string `json:"value,omitempty"`
+
+	// SecretKeyRef references the value of the environment variable, so
+	// that tokens aren't stored in plain text in the spec.
+	// +optional
+	SecretKeyRef *xpv1.SecretKeySelector `json:"secretKeyRef,omitempty"`
+}
+
+// CallbackConfigParameters are the logging callbacks a CallbackConfig
+// manages. Callbacks that are not listed, e.g. those of another
+// CallbackConfig or of the proxy config file, are left as they are.
+// +kubebuilder:validation:XValidation:rule="(has(self.successCallbacks) && size(self.successCallbacks) > 0) || (has(self.failureCallbacks) && size(self.failureCallbacks) > 0)",message="at least one of successCallbacks and failureCallbacks must be set"
+type CallbackConfigParameters struct {
+	// SuccessCallbacks are called for successful requests, e.g. langfuse
+	// or datadog.
+	// +optional
+	SuccessCallbacks []string `json:"successCallbacks,omitempty"`
+
+	// FailureCallbacks are called for failed requests.
+	// +optional
+	FailureCallbacks []string `json:"failureCallbacks,omitempty"`
+
+	// Env are the environment variables of the callbacks.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	Env []EnvVar `json:"env,omitempty"`
+}
+
+// CallbackConfigObservation are the observed logging callbacks of a
+// CallbackConfig.
+type CallbackConfigObservation struct {
+	// SuccessCallbacks are the callbacks of the CallbackConfig that are
+	// called for successful requests.
+	SuccessCallbacks []string `json:"successCallbacks,omitempty"`
+
+	// FailureCallbacks are the callbacks of the CallbackConfig that are
+	// called for failed requests.
+	FailureCallbacks []string `json:"failureCallbacks,omitempty"`
+
+	// AppliedCallbacks are the callbacks the CallbackConfig last applied,
+	// so that callbacks that were removed from its spec are removed from
+	// the proxy too.
+	// +optional
+	AppliedCallbacks []string `json:"appliedCallbacks,omitempty"`
+
+	// LastSyncTime is when the CallbackConfig last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A CallbackConfigSpec defines the desired state of a CallbackConfig.
+type CallbackConfigSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       CallbackConfigParameters `json:"forProvider"`
+}
+
+// A CallbackConfigStatus represents the observed state of a CallbackConfig.
+type CallbackConfigStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          CallbackConfigObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A CallbackConfig manages logging callbacks of a LiteLLM proxy, e.g.
+// Langfuse or Datadog. Several CallbackConfigs may manage different
+// callbacks of the same proxy. Deleting a CallbackConfig removes its
+// callbacks, unless another CallbackConfig lists them too.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="SUCCESS",type="string",JSONPath=".status.atProvider.successCallbacks"
+// +kubebuilder:printcolumn:name="FAILURE",type="string",JSONPath=".status.atProvider.failureCallbacks"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type CallbackConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CallbackConfigSpec   `json:"spec"`
+	Status CallbackConfigStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the CallbackConfig last synced with LiteLLM.
+func (mg *CallbackConfig) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the CallbackConfig last synced with LiteLLM.
+func (mg *CallbackConfig) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// CallbackConfigList contains a list of CallbackConfig
+type CallbackConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []CallbackConfig `json:"items"`
+}
+
+// CallbackConfig type metadata.
+var (
+	CallbackConfigKind             = reflect.TypeOf(CallbackConfig{}).Name()
+	CallbackConfigGroupKind        = schema.GroupKind{Group: Group, Kind: CallbackConfigKind}.String()
+	CallbackConfigKindAPIVersion   = CallbackConfigKind + "." + SchemeGroupVersion.String()
+	CallbackConfigGroupVersionKind = SchemeGroupVersion.WithKind(CallbackConfigKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&CallbackConfig{}, &CallbackConfigList{})
+}
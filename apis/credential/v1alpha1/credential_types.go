@@ -0,0 +1,132 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// CredentialParameters are the configurable fields of a Credential. The
+// LiteLLM credential_name is the external name of the Credential.
+type CredentialParameters struct {
+	// CredentialName is the name models reference the credential by.
+	// Defaults to the external name of the Credential.
+	// +optional
+	CredentialName string `json:"credentialName,omitempty"`
+
+	// CredentialInfo is free-form information about the credential, e.g.
+	// the provider it is for.
+	// +optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	CredentialInfo *runtime.RawExtension `json:"credentialInfo,omitempty"`
+
+	// CredentialValuesSecretRef references a secret whose keys and values
+	// are all sent as credential values, e.g. api_key and api_base.
+	// +optional
+	CredentialValuesSecretRef *xpv1.SecretReference `json:"credentialValuesSecretRef,omitempty"`
+
+	// CredentialValues are credential values read from secrets, keyed by
+	// value name. They take precedence over the values of
+	// CredentialValuesSecretRef.
+	// +optional
+	CredentialValues map[string]xpv1.SecretKeySelector `json:"credentialValues,omitempty"`
+}
+
+// CredentialObservation are the observable fields of a Credential.
+type CredentialObservation struct {
+	// CredentialName is the name of the credential in LiteLLM.
+	CredentialName string `json:"credentialName,omitempty"`
+
+	// SecretVersions are the resource versions of the secrets whose values
+	// were last sent to the proxy, keyed by value name, or by namespace/name
+	// for CredentialValuesSecretRef.
+	SecretVersions map[string]string `json:"secretVersions,omitempty"`
+
+	// LastSyncTime is when the Credential last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A CredentialSpec defines the desired state of a Credential.
+type CredentialSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       CredentialParameters `json:"forProvider"`
+}
+
+// A CredentialStatus represents the observed state of a Credential.
+type CredentialStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          CredentialObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Credential is a reusable LiteLLM credential that models reference by
+// name instead of carrying their own provider credentials.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Credential struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CredentialSpec   `json:"spec"`
+	Status CredentialStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Credential last synced with LiteLLM.
+func (mg *Credential) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Credential last synced with LiteLLM.
+func (mg *Credential) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// CredentialList contains a list of Credential
+type CredentialList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Credential `json:"items"`
+}
+
+// Credential type metadata.
+var (
+	CredentialKind             = reflect.TypeOf(Credential{}).Name()
+	CredentialGroupKind        = schema.GroupKind{Group: Group, Kind: CredentialKind}.String()
+	CredentialKindAPIVersion   = CredentialKind + "." + SchemeGroupVersion.String()
+	CredentialGroupVersionKind = SchemeGroupVersion.WithKind(CredentialKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Credential{}, &CredentialList{})
+}
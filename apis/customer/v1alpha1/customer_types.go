@@ -0,0 +1,160 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// CustomerParameters are the configurable fields of a Customer. The LiteLLM
+// end-user id is the external name of the Customer.
+type CustomerParameters struct {
+	// UserID is the end-user id of the customer, i.e. the user field sent
+	// with requests to the proxy. Defaults to the external name.
+	// +optional
+	UserID string `json:"userId,omitempty"`
+
+	// Alias is a human readable name for the customer.
+	// +optional
+	Alias string `json:"alias,omitempty"`
+
+	// MaxBudget is the maximum spend of the customer in USD.
+	// +optional
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// BudgetID is the id of a LiteLLM budget that applies to the customer.
+	// +crossplane:generate:reference:type=github.com/crossplane/provider-litellm/apis/budget/v1alpha1.Budget
+	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-litellm/apis/budget/v1alpha1.BudgetID()
+	// +optional
+	BudgetID string `json:"budgetId,omitempty"`
+
+	// BudgetIDRef references a Budget to set BudgetID.
+	// +optional
+	BudgetIDRef *xpv1.Reference `json:"budgetIdRef,omitempty"`
+
+	// BudgetIDSelector selects a Budget to set BudgetID.
+	// +optional
+	BudgetIDSelector *xpv1.Selector `json:"budgetIdSelector,omitempty"`
+
+	// AllowedModelRegion restricts the customer to models deployed in the
+	// supplied region.
+	// +kubebuilder:validation:Enum=eu;us
+	// +optional
+	AllowedModelRegion string `json:"allowedModelRegion,omitempty"`
+
+	// DefaultModel is used for requests of the customer that don't specify
+	// a model.
+	// +optional
+	DefaultModel string `json:"defaultModel,omitempty"`
+
+	// Blocked customers can't make requests to the proxy. Changing it blocks
+	// or unblocks the customer.
+	// +optional
+	Blocked bool `json:"blocked,omitempty"`
+}
+
+// CustomerObservation are the observable fields of a Customer.
+type CustomerObservation struct {
+	// UserID is the end-user id of the customer.
+	UserID string `json:"userId,omitempty"`
+
+	// Blocked is whether the customer is blocked.
+	Blocked bool `json:"blocked,omitempty"`
+
+	// Spend is the current spend of the customer in USD.
+	Spend float64 `json:"spend,omitempty"`
+
+	// MaxBudget is the maximum spend of the customer in USD.
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// BudgetResetAt is when the spend of the customer is next reset.
+	BudgetResetAt *metav1.Time `json:"budgetResetAt,omitempty"`
+
+	// LastSyncTime is when the Customer last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A CustomerSpec defines the desired state of a Customer.
+type CustomerSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       CustomerParameters `json:"forProvider"`
+}
+
+// A CustomerStatus represents the observed state of a Customer.
+type CustomerStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          CustomerObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Customer is a LiteLLM end-user, i.e. a customer of an application built
+// on the proxy whose spend is tracked separately.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="BLOCKED",type="boolean",JSONPath=".status.atProvider.blocked"
+// +kubebuilder:printcolumn:name="SPEND",type="number",JSONPath=".status.atProvider.spend"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Customer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   CustomerSpec   `json:"spec"`
+	Status CustomerStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Customer last synced with LiteLLM.
+func (mg *Customer) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Customer last synced with LiteLLM.
+func (mg *Customer) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// CustomerList contains a list of Customer
+type CustomerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Customer `json:"items"`
+}
+
+// Customer type metadata.
+var (
+	CustomerKind             = reflect.TypeOf(Customer{}).Name()
+	CustomerGroupKind        = schema.GroupKind{Group: Group, Kind: CustomerKind}.String()
+	CustomerKindAPIVersion   = CustomerKind + "." + SchemeGroupVersion.String()
+	CustomerGroupVersionKind = SchemeGroupVersion.WithKind(CustomerKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Customer{}, &CustomerList{})
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// GuardrailLiteLLMParams configure the provider of a guardrail.
+type GuardrailLiteLLMParams struct {
+	// Guardrail is the guardrail provider, e.g. presidio, aporia,
+	// bedrock or lakera_prompt_injection.
+	Guardrail string `json:"guardrail"`
+
+	// Mode is when the guardrail runs: before the call, after it, or in
+	// parallel with it.
+	// +kubebuilder:validation:Enum=pre_call;post_call;during_call
+	Mode string `json:"mode"`
+
+	// DefaultOn runs the guardrail on every request, rather than only on
+	// requests that ask for it.
+	// +optional
+	DefaultOn *bool `json:"defaultOn,omitempty"`
+
+	// APIBase is the base URL of the guardrail provider.
+	// +optional
+	APIBase string `json:"apiBase,omitempty"`
+
+	// APIKeySecretRef references the API key of the guardrail provider. The
+	// key is sent to the proxy again whenever the secret changes.
+	// +optional
+	APIKeySecretRef *xpv1.SecretKeySelector `json:"apiKeySecretRef,omitempty"`
+
+	// AWSAccessKeyIDSecretRef references the AWS access key id of a Bedrock
+	// guardrail.
+	// +optional
+	AWSAccessKeyIDSecretRef *xpv1.SecretKeySelector `json:"awsAccessKeyIdSecretRef,omitempty"`
+
+	// AWSSecretAccessKeySecretRef references the AWS secret access key of a
+	// Bedrock guardrail.
+	// +optional
+	AWSSecretAccessKeySecretRef *xpv1.SecretKeySelector `json:"awsSecretAccessKeySecretRef,omitempty"`
+
+	// ExtraSecretParams are further litellm_params read from secrets, keyed
+	// by param name, e.g. aws_session_token. The fields above take
+	// precedence. Like the API key, they are sent to the proxy again
+	// whenever their secrets change.
+	// +optional
+	ExtraSecretParams map[string]xpv1.SecretKeySelector `json:"extraSecretParams,omitempty"`
+
+	// ExtraParams are further provider-specific litellm_params, e.g.
+	// guardrailIdentifier for Bedrock. The fields above take precedence.
+	// +optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	ExtraParams *runtime.RawExtension `json:"extraParams,omitempty"`
+}
+
+// GuardrailParameters are the configurable fields of a Guardrail. The
+// LiteLLM guardrail_id is the external name of the Guardrail.
+type GuardrailParameters struct {
+	// GuardrailName is the name requests use to ask for the guardrail.
+	GuardrailName string `json:"guardrailName"`
+
+	// LiteLLMParams configure the provider of the guardrail.
+	LiteLLMParams GuardrailLiteLLMParams `json:"litellmParams"`
+
+	// GuardrailInfo is free-form information about the guardrail, e.g. a
+	// description.
+	// +optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	GuardrailInfo *runtime.RawExtension `json:"guardrailInfo,omitempty"`
+}
+
+// GuardrailObservation are the observable fields of a Guardrail.
+type GuardrailObservation struct {
+	// GuardrailID is the LiteLLM id of the guardrail.
+	GuardrailID string `json:"guardrailId,omitempty"`
+
+	// CreatedAt is when the guardrail was created.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is when the guardrail was last updated.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+
+	// SecretVersions are the resource versions of the secrets that were
+	// last sent to the proxy, keyed by param name.
+	SecretVersions map[string]string `json:"secretVersions,omitempty"`
+
+	// LastSyncTime is when the Guardrail last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A GuardrailSpec defines the desired state of a Guardrail.
+type GuardrailSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       GuardrailParameters `json:"forProvider"`
+}
+
+// A GuardrailStatus represents the observed state of a Guardrail.
+type GuardrailStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          GuardrailObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Guardrail is a guardrail of a LiteLLM proxy, such as PII masking or
+// prompt injection detection.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="GUARDRAIL-NAME",type="string",JSONPath=".spec.forProvider.guardrailName"
+// +kubebuilder:printcolumn:name="PROVIDER",type="string",JSONPath=".spec.forProvider.litellmParams.guardrail"
+// +kubebuilder:printcolumn:name="MODE",type="string",JSONPath=".spec.forProvider.litellmParams.mode"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Guardrail struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   GuardrailSpec   `json:"spec"`
+	Status GuardrailStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Guardrail last synced with LiteLLM.
+func (mg *Guardrail) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Guardrail last synced with LiteLLM.
+func (mg *Guardrail) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// GuardrailList contains a list of Guardrail
+type GuardrailList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Guardrail `json:"items"`
+}
+
+// Guardrail type metadata.
+var (
+	GuardrailKind             = reflect.TypeOf(Guardrail{}).Name()
+	GuardrailGroupKind        = schema.GroupKind{Group: Group, Kind: GuardrailKind}.String()
+	GuardrailKindAPIVersion   = GuardrailKind + "." + SchemeGroupVersion.String()
+	GuardrailGroupVersionKind = SchemeGroupVersion.WithKind(GuardrailKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Guardrail{}, &GuardrailList{})
+}
@@ -20,12 +20,49 @@ import (
 	"reflect"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
+// Supported budget enforcement modes.
+const (
+	BudgetEnforcementEnforce  = "enforce"
+	BudgetEnforcementAdvisory = "advisory"
+)
+
+// LoggingConfig configures the logging callbacks of a key, e.g. langfuse.
+// A callback listed for both successes and failures is called for every
+// request.
+type LoggingConfig struct {
+	// SuccessCallbacks are called for successful requests.
+	// +optional
+	SuccessCallbacks []string `json:"success_callbacks,omitempty"`
+
+	// FailureCallbacks are called for failed requests.
+	// +optional
+	FailureCallbacks []string `json:"failure_callbacks,omitempty"`
+}
+
+// ObjectPermission grants access to vector stores and MCP servers. Lists
+// that are not set are not managed.
+type ObjectPermission struct {
+	// VectorStores are the ids of the vector stores that may be used.
+	// +optional
+	VectorStores []string `json:"vector_stores,omitempty"`
+
+	// MCPServers are the ids of the MCP servers that may be used.
+	// +optional
+	MCPServers []string `json:"mcp_servers,omitempty"`
+
+	// MCPAccessGroups are the MCP access groups whose servers may be used.
+	// +optional
+	MCPAccessGroups []string `json:"mcp_access_groups,omitempty"`
+}
+
 // KeyParameters are the configurable fields of a Key.
+// +kubebuilder:validation:XValidation:rule="!(has(self.budget_duration) && has(self.budget_reset_at))",message="budget_duration and budget_reset_at are mutually exclusive"
 type KeyParameters struct {
 	Duration       string            `json:"duration,omitempty"`
 	KeyAlias       string            `json:"key_alias,omitempty"`
@@ -36,14 +73,187 @@ type KeyParameters struct {
 	MaxBudget      float64           `json:"max_budget,omitempty"`
 	BudgetDuration string            `json:"budget_duration,omitempty"`
 	Metadata       map[string]string `json:"metadata,omitempty"`
+
+	// KeyNamePrefix is prepended to the name of the Key to form the alias
+	// of the key when KeyAlias is not set. An explicit KeyAlias is used
+	// as is.
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`
+	// +kubebuilder:validation:MaxLength=64
+	// +optional
+	KeyNamePrefix string `json:"key_name_prefix,omitempty"`
+
+	// MetadataJSON is metadata whose values are arbitrary JSON, e.g. nested
+	// logging configuration. It is merged with Metadata, whose values take
+	// precedence.
+	// +optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	MetadataJSON *runtime.RawExtension `json:"metadata_json,omitempty"`
+
+	// Logging configures the logging callbacks of the key. It is sent as
+	// the logging metadata of the key and replaces any logging set in
+	// MetadataJSON.
+	// +optional
+	Logging *LoggingConfig `json:"logging,omitempty"`
+
+	// BudgetEnforcement is whether LiteLLM blocks the key once its spend
+	// exceeds MaxBudget (enforce), or only alerts (advisory). An advisory
+	// MaxBudget is sent to LiteLLM as the soft budget of the key.
+	// +kubebuilder:validation:Enum=enforce;advisory
+	// +kubebuilder:default=enforce
+	// +optional
+	BudgetEnforcement string `json:"budget_enforcement,omitempty"`
+
+	// ModelMaxBudget is the maximum spend in USD per model. Removing a model
+	// resets its budget.
+	ModelMaxBudget map[string]float64 `json:"model_max_budget,omitempty"`
+
+	// RotationPeriod is how often the key is regenerated. The alias and
+	// settings of the key are kept, and the new key is published to the
+	// connection secret. Rotation is disabled if unset or zero.
+	// +optional
+	RotationPeriod *metav1.Duration `json:"rotation_period,omitempty"`
+
+	// BudgetResetAtRFC3339 is a fixed time, in RFC 3339 format, at which the
+	// spend of the key is reset once. It can't be combined with the
+	// recurring BudgetDuration.
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	BudgetResetAtRFC3339 string `json:"budget_reset_at,omitempty"`
+
+	// ExpiresAtRFC3339 is a fixed time, in RFC 3339 format, at which the key
+	// expires. It is sent to LiteLLM as the duration until then, so it can't
+	// be combined with Duration.
+	// +kubebuilder:validation:Format=date-time
+	// +optional
+	ExpiresAtRFC3339 string `json:"expires_at,omitempty"`
+
+	// SpendAlertThreshold is the spend in USD above which the key is
+	// blocked. Clearing or raising the threshold unblocks the key again.
+	// +optional
+	SpendAlertThreshold *float64 `json:"spend_alert_threshold,omitempty"`
+
+	// Guardrails are the names of the guardrails that run on requests made
+	// with the key.
+	// +optional
+	Guardrails []string `json:"guardrails,omitempty"`
+
+	// GuardrailRefs reference Guardrails to set Guardrails.
+	// +optional
+	GuardrailRefs []xpv1.Reference `json:"guardrail_refs,omitempty"`
+
+	// GuardrailSelector selects Guardrails to set Guardrails.
+	// +optional
+	GuardrailSelector *xpv1.Selector `json:"guardrail_selector,omitempty"`
+
+	// ModelRefs reference Models whose model names are added to Models.
+	// +optional
+	ModelRefs []xpv1.Reference `json:"model_refs,omitempty"`
+
+	// ModelSelector selects Models whose model names are added to Models.
+	// +optional
+	ModelSelector *xpv1.Selector `json:"model_selector,omitempty"`
+
+	// ResolvedModels are the model names of the Models referenced by
+	// ModelRefs or selected by ModelSelector. They are set by the provider
+	// whenever references are resolved, and are granted to the key in
+	// addition to Models.
+	// +optional
+	ResolvedModels []string `json:"resolved_models,omitempty"`
+
+	// ObjectPermission grants the key access to vector stores and MCP
+	// servers.
+	// +optional
+	ObjectPermission *ObjectPermission `json:"object_permission,omitempty"`
+
+	// TPMLimit is the maximum number of tokens per minute of the key. A key
+	// without one is only bounded by the TPM limit of its team, if any.
+	// +optional
+	TPMLimit *int64 `json:"tpm_limit,omitempty"`
+
+	// RPMLimit is the maximum number of requests per minute of the key. A
+	// key without one is only bounded by the RPM limit of its team, if any.
+	// +optional
+	RPMLimit *int64 `json:"rpm_limit,omitempty"`
+
+	// ModelRPMLimit is the maximum number of requests per minute of the key
+	// per model. Removing a model resets its limit.
+	// +optional
+	ModelRPMLimit map[string]int64 `json:"model_rpm_limit,omitempty"`
+
+	// ModelTPMLimit is the maximum number of tokens per minute of the key
+	// per model. Removing a model resets its limit.
+	// +optional
+	ModelTPMLimit map[string]int64 `json:"model_tpm_limit,omitempty"`
 }
 
 // KeyObservation are the observable fields of a Key.
 type KeyObservation struct {
-	Key     string      `json:"key,omitempty"`
+	// TokenID is the hashed token LiteLLM identifies the key by. It is the
+	// external name of the Key; the key itself is only ever written to the
+	// connection secret.
+	TokenID string `json:"token_id,omitempty"`
+
 	Expires metav1.Time `json:"expires,omitempty"`
 	UserID  string      `json:"user_id,omitempty"`
 	Status  string      `json:"status,omitempty"` // e.g., "generated"
+
+	ModelMaxBudget map[string]float64 `json:"model_max_budget,omitempty"`
+
+	// LastRotatedAt is when the key was last regenerated by its rotation
+	// period.
+	LastRotatedAt *metav1.Time `json:"last_rotated_at,omitempty"`
+
+	// Spend is the current spend of the key in USD.
+	Spend float64 `json:"spend,omitempty"`
+
+	// Blocked is whether the key is blocked.
+	Blocked bool `json:"blocked,omitempty"`
+
+	// ExpiresIn is the time left until the key expires, e.g. 3d. It is
+	// updated whenever the key is observed.
+	ExpiresIn string `json:"expires_in,omitempty"`
+
+	// KeyAlias is the alias of the key, including any KeyNamePrefix.
+	KeyAlias string `json:"key_alias,omitempty"`
+
+	// Duration is the duration the current expiry of the key was computed
+	// from. Changing the duration in the spec pushes a new expiry.
+	Duration string `json:"duration,omitempty"`
+
+	// TPMLimit is the effective maximum number of tokens per minute of the
+	// key. A limit set on the key takes precedence over that of its team,
+	// which applies to keys without their own.
+	TPMLimit *int64 `json:"tpm_limit,omitempty"`
+
+	// RPMLimit is the effective maximum number of requests per minute of
+	// the key. A limit set on the key takes precedence over that of its
+	// team, which applies to keys without their own.
+	RPMLimit *int64 `json:"rpm_limit,omitempty"`
+
+	// ModelRPMLimit is the maximum number of requests per minute of the key
+	// per model.
+	ModelRPMLimit map[string]int64 `json:"model_rpm_limit,omitempty"`
+
+	// ModelTPMLimit is the maximum number of tokens per minute of the key
+	// per model.
+	ModelTPMLimit map[string]int64 `json:"model_tpm_limit,omitempty"`
+
+	// UnpublishedKey is a generated key that couldn't be written to the
+	// connection secret yet. It is only set if the ProviderConfig retains
+	// unpublished keys, and is cleared once the key is published.
+	UnpublishedKey string `json:"unpublished_key,omitempty"`
+
+	// CreatedBy is who LiteLLM recorded as having created the key.
+	CreatedBy string `json:"created_by,omitempty"`
+
+	// UpdatedBy is who LiteLLM recorded as having last updated the key.
+	UpdatedBy string `json:"updated_by,omitempty"`
+
+	// LastSyncTime is when the Key last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"last_sync_time,omitempty"`
 }
 
 // A KeySpec defines the desired state of a Key.
@@ -64,6 +274,8 @@ type KeyStatus struct {
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="EXPIRES-IN",type="string",JSONPath=".status.atProvider.expires_in"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.last_sync_time"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
@@ -75,6 +287,16 @@ type Key struct {
 	Status KeyStatus `json:"status,omitempty"`
 }
 
+// GetLastSyncTime returns when the Key last synced with LiteLLM.
+func (mg *Key) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Key last synced with LiteLLM.
+func (mg *Key) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
 // +kubebuilder:object:root=true
 
 // KeyList contains a list of Key
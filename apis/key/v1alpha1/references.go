@@ -0,0 +1,93 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+
+	guardrailv1alpha1 "github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1"
+	modelv1alpha1 "github.com/crossplane/provider-litellm/apis/model/v1alpha1"
+)
+
+// ResolveReferences of this Key. Referenced Guardrails replace Guardrails,
+// like generated resolvers do, while referenced Models are recorded in
+// ResolvedModels, so that a key can be granted models by name and by
+// reference at once, and models are revoked again when their references are
+// removed. The resolvers of Key are written by hand for that reason.
+func (mg *Key) ResolveReferences(ctx context.Context, c client.Reader) error {
+	r := reference.NewAPIResolver(c, mg)
+
+	mrsp, err := r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		CurrentValues: mg.Spec.ForProvider.Guardrails,
+		Extract:       guardrailv1alpha1.GuardrailName(),
+		References:    mg.Spec.ForProvider.GuardrailRefs,
+		Selector:      mg.Spec.ForProvider.GuardrailSelector,
+		To: reference.To{
+			List:    &guardrailv1alpha1.GuardrailList{},
+			Managed: &guardrailv1alpha1.Guardrail{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.Guardrails")
+	}
+	mg.Spec.ForProvider.Guardrails = mrsp.ResolvedValues
+	mg.Spec.ForProvider.GuardrailRefs = mrsp.ResolvedReferences
+
+	if len(mg.Spec.ForProvider.ModelRefs) == 0 && mg.Spec.ForProvider.ModelSelector == nil {
+		mg.Spec.ForProvider.ResolvedModels = nil
+		return nil
+	}
+	mrsp, err = r.ResolveMultiple(ctx, reference.MultiResolutionRequest{
+		Extract:    modelv1alpha1.ModelName(),
+		References: mg.Spec.ForProvider.ModelRefs,
+		Selector:   mg.Spec.ForProvider.ModelSelector,
+		To: reference.To{
+			List:    &modelv1alpha1.ModelList{},
+			Managed: &modelv1alpha1.Model{},
+		},
+	})
+	if err != nil {
+		return errors.Wrap(err, "mg.Spec.ForProvider.ResolvedModels")
+	}
+	mg.Spec.ForProvider.ResolvedModels = mrsp.ResolvedValues
+	mg.Spec.ForProvider.ModelRefs = mrsp.ResolvedReferences
+
+	return nil
+}
+
+// AllModels returns Models followed by the ResolvedModels that aren't among
+// them, i.e. all models the key is granted. It is nil if neither is set.
+func (p KeyParameters) AllModels() []string {
+	// Never append to Models in place, which would write to the spec.
+	models := p.Models[:len(p.Models):len(p.Models)]
+	seen := make(map[string]bool, len(models))
+	for _, m := range models {
+		seen[m] = true
+	}
+	for _, m := range p.ResolvedModels {
+		if !seen[m] {
+			models = append(models, m)
+			seen[m] = true
+		}
+	}
+	return models
+}
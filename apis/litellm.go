@@ -20,7 +20,22 @@ package apis
 import (
 	"k8s.io/apimachinery/pkg/runtime"
 
+	alertingv1alpha1 "github.com/crossplane/provider-litellm/apis/alerting/v1alpha1"
+	budgetv1alpha1 "github.com/crossplane/provider-litellm/apis/budget/v1alpha1"
+	callbackv1alpha1 "github.com/crossplane/provider-litellm/apis/callback/v1alpha1"
+	credentialv1alpha1 "github.com/crossplane/provider-litellm/apis/credential/v1alpha1"
+	customerv1alpha1 "github.com/crossplane/provider-litellm/apis/customer/v1alpha1"
+	guardrailv1alpha1 "github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1"
 	keyv1alpha1 "github.com/crossplane/provider-litellm/apis/key/v1alpha1"
+	mcpserverv1alpha1 "github.com/crossplane/provider-litellm/apis/mcpserver/v1alpha1"
+	modelv1alpha1 "github.com/crossplane/provider-litellm/apis/model/v1alpha1"
+	organizationv1alpha1 "github.com/crossplane/provider-litellm/apis/organization/v1alpha1"
+	rawv1alpha1 "github.com/crossplane/provider-litellm/apis/raw/v1alpha1"
+	routerv1alpha1 "github.com/crossplane/provider-litellm/apis/router/v1alpha1"
+	spendreportv1alpha1 "github.com/crossplane/provider-litellm/apis/spendreport/v1alpha1"
+	tagv1alpha1 "github.com/crossplane/provider-litellm/apis/tag/v1alpha1"
+	teamv1alpha1 "github.com/crossplane/provider-litellm/apis/team/v1alpha1"
+	userv1alpha1 "github.com/crossplane/provider-litellm/apis/user/v1alpha1"
 	litellmv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
 )
 
@@ -28,7 +43,22 @@ func init() {
 	// Register the types with the Scheme so the components can map objects to GroupVersionKinds and back
 	AddToSchemes = append(AddToSchemes,
 		litellmv1alpha1.SchemeBuilder.AddToScheme,
+		alertingv1alpha1.SchemeBuilder.AddToScheme,
+		budgetv1alpha1.SchemeBuilder.AddToScheme,
+		callbackv1alpha1.SchemeBuilder.AddToScheme,
+		credentialv1alpha1.SchemeBuilder.AddToScheme,
+		customerv1alpha1.SchemeBuilder.AddToScheme,
+		guardrailv1alpha1.SchemeBuilder.AddToScheme,
 		keyv1alpha1.SchemeBuilder.AddToScheme,
+		mcpserverv1alpha1.SchemeBuilder.AddToScheme,
+		modelv1alpha1.SchemeBuilder.AddToScheme,
+		organizationv1alpha1.SchemeBuilder.AddToScheme,
+		rawv1alpha1.SchemeBuilder.AddToScheme,
+		routerv1alpha1.SchemeBuilder.AddToScheme,
+		spendreportv1alpha1.SchemeBuilder.AddToScheme,
+		tagv1alpha1.SchemeBuilder.AddToScheme,
+		teamv1alpha1.SchemeBuilder.AddToScheme,
+		userv1alpha1.SchemeBuilder.AddToScheme,
 	)
 }
 
@@ -0,0 +1,166 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Transports LiteLLM may connect to an MCP server with.
+const (
+	TransportSSE  = "sse"
+	TransportHTTP = "http"
+)
+
+// MCPServerParameters are the configurable fields of an MCPServer. The
+// LiteLLM server_id is the external name of the MCPServer.
+type MCPServerParameters struct {
+	// ServerName is the name the tools of the server are prefixed with.
+	ServerName string `json:"serverName"`
+
+	// Alias is a friendlier name of the server, used instead of its name
+	// to prefix its tools.
+	// +optional
+	Alias string `json:"alias,omitempty"`
+
+	// Description of the server.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// URL of the server, e.g. https://mcp.example.com/mcp.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url"`
+
+	// TransportType is how LiteLLM connects to the server: with server
+	// sent events, or streamable HTTP.
+	// +kubebuilder:validation:Enum=sse;http
+	// +kubebuilder:default=sse
+	// +optional
+	TransportType string `json:"transportType,omitempty"`
+
+	// AuthType is how LiteLLM authenticates to the server.
+	// +kubebuilder:validation:Enum=none;api_key;bearer_token;basic;authorization
+	// +optional
+	AuthType string `json:"authType,omitempty"`
+
+	// AuthValueSecretRef references the token LiteLLM authenticates to the
+	// server with. The token is sent to the proxy again whenever the secret
+	// changes.
+	// +optional
+	AuthValueSecretRef *xpv1.SecretKeySelector `json:"authValueSecretRef,omitempty"`
+
+	// MCPAccessGroups are the access groups the server belongs to. Keys and
+	// teams may be granted access to all servers of an access group.
+	// +optional
+	MCPAccessGroups []string `json:"mcpAccessGroups,omitempty"`
+}
+
+// MCPServerObservation are the observable fields of an MCPServer.
+type MCPServerObservation struct {
+	// ServerID is the LiteLLM id of the server.
+	ServerID string `json:"serverId,omitempty"`
+
+	// URL is the URL of the server in LiteLLM.
+	URL string `json:"url,omitempty"`
+
+	// TransportType is the transport of the server in LiteLLM.
+	TransportType string `json:"transportType,omitempty"`
+
+	// CreatedAt is when the server was created.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is when the server was last updated.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+
+	// SecretVersion is the resource version of the secret of the auth
+	// value that was last sent to the proxy.
+	SecretVersion string `json:"secretVersion,omitempty"`
+
+	// LastSyncTime is when the MCPServer last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// An MCPServerSpec defines the desired state of an MCPServer.
+type MCPServerSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       MCPServerParameters `json:"forProvider"`
+}
+
+// An MCPServerStatus represents the observed state of an MCPServer.
+type MCPServerStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          MCPServerObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An MCPServer is a Model Context Protocol server whose tools a LiteLLM proxy
+// offers to its clients.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="SERVER-NAME",type="string",JSONPath=".spec.forProvider.serverName"
+// +kubebuilder:printcolumn:name="TRANSPORT",type="string",JSONPath=".spec.forProvider.transportType"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type MCPServer struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   MCPServerSpec   `json:"spec"`
+	Status MCPServerStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the MCPServer last synced with LiteLLM.
+func (mg *MCPServer) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the MCPServer last synced with LiteLLM.
+func (mg *MCPServer) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// MCPServerList contains a list of MCPServer
+type MCPServerList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []MCPServer `json:"items"`
+}
+
+// MCPServer type metadata.
+var (
+	MCPServerKind             = reflect.TypeOf(MCPServer{}).Name()
+	MCPServerGroupKind        = schema.GroupKind{Group: Group, Kind: MCPServerKind}.String()
+	MCPServerKindAPIVersion   = MCPServerKind + "." + SchemeGroupVersion.String()
+	MCPServerGroupVersionKind = SchemeGroupVersion.WithKind(MCPServerKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&MCPServer{}, &MCPServerList{})
+}
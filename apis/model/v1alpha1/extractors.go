@@ -0,0 +1,39 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reference"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// ModelName extracts the public model name of a Model, which is what keys
+// and teams are granted access to. Nothing is extracted until the Model is
+// ready, so that keys aren't sent the name of a model LiteLLM doesn't serve
+// yet.
+func ModelName() reference.ExtractValueFn {
+	return func(mg resource.Managed) string {
+		m, ok := mg.(*Model)
+		if !ok || m.GetCondition(xpv1.TypeReady).Status != corev1.ConditionTrue {
+			return ""
+		}
+		return m.Spec.ForProvider.ModelName
+	}
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// LiteLLMParams configure how the proxy calls a model deployment.
+type LiteLLMParams struct {
+	// Model is the provider and model of the deployment, e.g.
+	// azure/gpt-4o or openai/gpt-4o-mini.
+	Model string `json:"model"`
+
+	// APIBase is the base URL of the provider.
+	// +optional
+	APIBase string `json:"apiBase,omitempty"`
+
+	// APIVersion is the API version of the provider, e.g. for Azure.
+	// +optional
+	APIVersion string `json:"apiVersion,omitempty"`
+
+	// APIKeySecretRef references the API key of the provider. The key is
+	// sent to the proxy again whenever the secret changes.
+	// +optional
+	APIKeySecretRef *xpv1.SecretKeySelector `json:"apiKeySecretRef,omitempty"`
+
+	// VertexCredentialsSecretRef references the JSON service account
+	// credentials of a Vertex AI deployment.
+	// +optional
+	VertexCredentialsSecretRef *xpv1.SecretKeySelector `json:"vertexCredentialsSecretRef,omitempty"`
+
+	// AWSAccessKeyIDSecretRef references the AWS access key id of a Bedrock
+	// deployment.
+	// +optional
+	AWSAccessKeyIDSecretRef *xpv1.SecretKeySelector `json:"awsAccessKeyIdSecretRef,omitempty"`
+
+	// AWSSecretAccessKeySecretRef references the AWS secret access key of a
+	// Bedrock deployment.
+	// +optional
+	AWSSecretAccessKeySecretRef *xpv1.SecretKeySelector `json:"awsSecretAccessKeySecretRef,omitempty"`
+
+	// ExtraSecretParams are further litellm_params read from secrets, keyed
+	// by param name, e.g. azure_ad_token. The fields above take precedence.
+	// Like the API key, they are sent to the proxy again whenever their
+	// secrets change.
+	// +optional
+	ExtraSecretParams map[string]xpv1.SecretKeySelector `json:"extraSecretParams,omitempty"`
+
+	// LiteLLMCredentialName is the name of a LiteLLM credential the
+	// deployment uses instead of its own provider credentials.
+	// +crossplane:generate:reference:type=github.com/crossplane/provider-litellm/apis/credential/v1alpha1.Credential
+	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-litellm/apis/credential/v1alpha1.CredentialName()
+	// +crossplane:generate:reference:refFieldName=LiteLLMCredentialNameRef
+	// +crossplane:generate:reference:selectorFieldName=LiteLLMCredentialNameSelector
+	// +optional
+	LiteLLMCredentialName string `json:"litellmCredentialName,omitempty"`
+
+	// LiteLLMCredentialNameRef references a Credential to set
+	// LiteLLMCredentialName.
+	// +optional
+	LiteLLMCredentialNameRef *xpv1.Reference `json:"litellmCredentialNameRef,omitempty"`
+
+	// LiteLLMCredentialNameSelector selects a Credential to set
+	// LiteLLMCredentialName.
+	// +optional
+	LiteLLMCredentialNameSelector *xpv1.Selector `json:"litellmCredentialNameSelector,omitempty"`
+
+	// Timeout is the timeout of a request to the deployment in seconds.
+	// +optional
+	Timeout *int64 `json:"timeout,omitempty"`
+
+	// RPM is the maximum number of requests per minute of the deployment.
+	// +optional
+	RPM *int64 `json:"rpm,omitempty"`
+
+	// TPM is the maximum number of tokens per minute of the deployment.
+	// +optional
+	TPM *int64 `json:"tpm,omitempty"`
+
+	// MaxRetries is how often a failed request to the deployment is retried.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	MaxRetries *int64 `json:"maxRetries,omitempty"`
+
+	// CooldownTime is how long, in seconds, a failing deployment is taken
+	// out of the load balancing. Zero disables the cooldown; if unset the
+	// router default is used.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CooldownTime *int64 `json:"cooldownTime,omitempty"`
+
+	// ExtraParams are further litellm_params, e.g. aws_region_name. The
+	// fields above take precedence.
+	// +optional
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	ExtraParams *runtime.RawExtension `json:"extraParams,omitempty"`
+}
+
+// ModelInfo describes a model deployment.
+type ModelInfo struct {
+	// Mode is the kind of requests the deployment serves.
+	// +kubebuilder:validation:Enum=chat;completion;embedding;image_generation;audio_transcription;audio_speech;rerank;moderation
+	// +optional
+	Mode string `json:"mode,omitempty"`
+
+	// BaseModel is the model the deployment is based on, used to look up
+	// its cost, e.g. for Azure deployments with custom names.
+	// +optional
+	BaseModel string `json:"baseModel,omitempty"`
+
+	// InputCostPerToken overrides the cost of an input token in USD.
+	// +optional
+	InputCostPerToken *float64 `json:"inputCostPerToken,omitempty"`
+
+	// OutputCostPerToken overrides the cost of an output token in USD.
+	// +optional
+	OutputCostPerToken *float64 `json:"outputCostPerToken,omitempty"`
+
+	// AccessGroups the deployment belongs to. Keys and teams can be granted
+	// access to a whole access group.
+	// +optional
+	AccessGroups []string `json:"accessGroups,omitempty"`
+}
+
+// ModelParameters are the configurable fields of a Model. The LiteLLM model
+// id is the external name of the Model.
+type ModelParameters struct {
+	// ModelName is the public name of the model, i.e. the model group
+	// clients request.
+	ModelName string `json:"modelName"`
+
+	// LiteLLMParams configure how the proxy calls the deployment.
+	LiteLLMParams LiteLLMParams `json:"litellmParams"`
+
+	// ModelInfo describes the deployment.
+	// +optional
+	ModelInfo *ModelInfo `json:"modelInfo,omitempty"`
+
+	// HealthCheck the deployment whenever it is observed. This makes a live
+	// request to the upstream provider.
+	// +optional
+	HealthCheck bool `json:"healthCheck,omitempty"`
+}
+
+// ModelObservation are the observable fields of a Model.
+type ModelObservation struct {
+	// ModelID is the LiteLLM id of the deployment.
+	ModelID string `json:"modelId,omitempty"`
+
+	// DBModel is whether the deployment is stored in the database, rather
+	// than in the config file of the proxy.
+	DBModel bool `json:"dbModel,omitempty"`
+
+	// APIKeySecretVersion is the resource version of the API key secret
+	// that was last sent to the proxy.
+	APIKeySecretVersion string `json:"apiKeySecretVersion,omitempty"`
+
+	// SecretVersions are the resource versions of the secrets of the other
+	// credentials that were last sent to the proxy, keyed by param name.
+	SecretVersions map[string]string `json:"secretVersions,omitempty"`
+
+	// Healthy is whether the last health check of the deployment passed.
+	// It is only set if health checks are enabled.
+	Healthy *bool `json:"healthy,omitempty"`
+
+	// HealthError is the error of the last failed health check.
+	HealthError string `json:"healthError,omitempty"`
+
+	// LastSyncTime is when the Model last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A ModelSpec defines the desired state of a Model.
+type ModelSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ModelParameters `json:"forProvider"`
+}
+
+// A ModelStatus represents the observed state of a Model.
+type ModelStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ModelObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Model is a model deployment of a LiteLLM proxy.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="MODEL-NAME",type="string",JSONPath=".spec.forProvider.modelName"
+// +kubebuilder:printcolumn:name="MODEL",type="string",JSONPath=".spec.forProvider.litellmParams.model"
+// +kubebuilder:printcolumn:name="HEALTHY",type="string",JSONPath=".status.atProvider.healthy"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Model struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelSpec   `json:"spec"`
+	Status ModelStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Model last synced with LiteLLM.
+func (mg *Model) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Model last synced with LiteLLM.
+func (mg *Model) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// ModelList contains a list of Model
+type ModelList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Model `json:"items"`
+}
+
+// Model type metadata.
+var (
+	ModelKind             = reflect.TypeOf(Model{}).Name()
+	ModelGroupKind        = schema.GroupKind{Group: Group, Kind: ModelKind}.String()
+	ModelKindAPIVersion   = ModelKind + "." + SchemeGroupVersion.String()
+	ModelGroupVersionKind = SchemeGroupVersion.WithKind(ModelKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Model{}, &ModelList{})
+}
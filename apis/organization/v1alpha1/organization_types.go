@@ -0,0 +1,167 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// OrganizationParameters are the configurable fields of an Organization. The
+// LiteLLM organization_id is the external name of the Organization.
+type OrganizationParameters struct {
+	// OrganizationAlias is a human readable name for the organization.
+	OrganizationAlias string `json:"organizationAlias"`
+
+	// Models the organization is allowed to call.
+	// +optional
+	Models []string `json:"models,omitempty"`
+
+	// MaxBudget is the maximum spend of the organization in USD.
+	// +optional
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// BudgetDuration is how often the budget resets, e.g. 30d.
+	// +optional
+	BudgetDuration string `json:"budgetDuration,omitempty"`
+
+	// TPMLimit is the maximum number of tokens per minute of the
+	// organization.
+	// +optional
+	TPMLimit *int64 `json:"tpmLimit,omitempty"`
+
+	// RPMLimit is the maximum number of requests per minute of the
+	// organization.
+	// +optional
+	RPMLimit *int64 `json:"rpmLimit,omitempty"`
+
+	// Metadata attached to the organization.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// BudgetID is the id of a LiteLLM budget that applies to the
+	// organization.
+	// +crossplane:generate:reference:type=github.com/crossplane/provider-litellm/apis/budget/v1alpha1.Budget
+	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-litellm/apis/budget/v1alpha1.BudgetID()
+	// +optional
+	BudgetID string `json:"budgetId,omitempty"`
+
+	// BudgetIDRef references a Budget to set BudgetID.
+	// +optional
+	BudgetIDRef *xpv1.Reference `json:"budgetIdRef,omitempty"`
+
+	// BudgetIDSelector selects a Budget to set BudgetID.
+	// +optional
+	BudgetIDSelector *xpv1.Selector `json:"budgetIdSelector,omitempty"`
+}
+
+// OrganizationObservation are the observable fields of an Organization.
+type OrganizationObservation struct {
+	// OrganizationID is the LiteLLM id of the organization.
+	OrganizationID string `json:"organizationId,omitempty"`
+
+	// BudgetID is the id of the budget of the organization.
+	BudgetID string `json:"budgetId,omitempty"`
+
+	// Spend is the current spend of the organization in USD.
+	Spend float64 `json:"spend,omitempty"`
+
+	// MaxBudget is the maximum spend of the organization in USD.
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// TeamIDs are the ids of the teams in the organization.
+	TeamIDs []string `json:"teamIds,omitempty"`
+
+	// MemberCount is the number of members of the organization.
+	MemberCount int `json:"memberCount,omitempty"`
+
+	// LastSyncTime is when the Organization last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// An OrganizationSpec defines the desired state of an Organization.
+type OrganizationSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       OrganizationParameters `json:"forProvider"`
+}
+
+// An OrganizationStatus represents the observed state of an Organization.
+type OrganizationStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          OrganizationObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An Organization is a LiteLLM organization that groups teams, e.g. by
+// business unit.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="ALIAS",type="string",JSONPath=".spec.forProvider.organizationAlias"
+// +kubebuilder:printcolumn:name="SPEND",type="number",JSONPath=".status.atProvider.spend"
+// +kubebuilder:printcolumn:name="MAX-BUDGET",type="number",JSONPath=".status.atProvider.maxBudget"
+// +kubebuilder:printcolumn:name="TEAMS",type="string",JSONPath=".status.atProvider.teamIds"
+// +kubebuilder:printcolumn:name="MEMBERS",type="integer",JSONPath=".status.atProvider.memberCount",priority=1
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Organization struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OrganizationSpec   `json:"spec"`
+	Status OrganizationStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Organization last synced with LiteLLM.
+func (mg *Organization) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Organization last synced with LiteLLM.
+func (mg *Organization) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// OrganizationList contains a list of Organization
+type OrganizationList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Organization `json:"items"`
+}
+
+// Organization type metadata.
+var (
+	OrganizationKind             = reflect.TypeOf(Organization{}).Name()
+	OrganizationGroupKind        = schema.GroupKind{Group: Group, Kind: OrganizationKind}.String()
+	OrganizationKindAPIVersion   = OrganizationKind + "." + SchemeGroupVersion.String()
+	OrganizationGroupVersionKind = SchemeGroupVersion.WithKind(OrganizationKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Organization{}, &OrganizationList{})
+}
@@ -0,0 +1,148 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// OrganizationMemberParameters are the configurable fields of an
+// OrganizationMember. The external name of an OrganizationMember is
+// <organization_id>:<user_id>.
+type OrganizationMemberParameters struct {
+	// OrganizationID is the id of the organization.
+	// +crossplane:generate:reference:type=Organization
+	// +crossplane:generate:reference:extractor=OrganizationID()
+	// +optional
+	OrganizationID string `json:"organizationId,omitempty"`
+
+	// OrganizationIDRef references an Organization to set OrganizationID.
+	// +optional
+	OrganizationIDRef *xpv1.Reference `json:"organizationIdRef,omitempty"`
+
+	// OrganizationIDSelector selects an Organization to set OrganizationID.
+	// +optional
+	OrganizationIDSelector *xpv1.Selector `json:"organizationIdSelector,omitempty"`
+
+	// UserID is the id of the user. Either UserID or UserEmail is required.
+	// +crossplane:generate:reference:type=github.com/crossplane/provider-litellm/apis/user/v1alpha1.User
+	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-litellm/apis/user/v1alpha1.UserID()
+	// +optional
+	UserID string `json:"userId,omitempty"`
+
+	// UserIDRef references a User to set UserID.
+	// +optional
+	UserIDRef *xpv1.Reference `json:"userIdRef,omitempty"`
+
+	// UserIDSelector selects a User to set UserID.
+	// +optional
+	UserIDSelector *xpv1.Selector `json:"userIdSelector,omitempty"`
+
+	// UserEmail is the email address of the user. It is used to find the
+	// user if UserID is not set.
+	// +optional
+	UserEmail string `json:"userEmail,omitempty"`
+
+	// Role of the user in the organization.
+	// +kubebuilder:validation:Enum=org_admin;internal_user;internal_user_viewer
+	Role string `json:"role"`
+}
+
+// OrganizationMemberObservation are the observable fields of an
+// OrganizationMember.
+type OrganizationMemberObservation struct {
+	// UserID is the id of the user.
+	UserID string `json:"userId,omitempty"`
+
+	// Role is the role of the user in the organization.
+	Role string `json:"role,omitempty"`
+
+	// LastSyncTime is when the OrganizationMember last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// An OrganizationMemberSpec defines the desired state of an
+// OrganizationMember.
+type OrganizationMemberSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       OrganizationMemberParameters `json:"forProvider"`
+}
+
+// An OrganizationMemberStatus represents the observed state of an
+// OrganizationMember.
+type OrganizationMemberStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          OrganizationMemberObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// An OrganizationMember is the membership of a LiteLLM user in an
+// organization.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="ROLE",type="string",JSONPath=".status.atProvider.role"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type OrganizationMember struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OrganizationMemberSpec   `json:"spec"`
+	Status OrganizationMemberStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the OrganizationMember last synced with LiteLLM.
+func (mg *OrganizationMember) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the OrganizationMember last synced with LiteLLM.
+func (mg *OrganizationMember) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// OrganizationMemberList contains a list of OrganizationMember
+type OrganizationMemberList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OrganizationMember `json:"items"`
+}
+
+// OrganizationMember type metadata.
+var (
+	OrganizationMemberKind             = reflect.TypeOf(OrganizationMember{}).Name()
+	OrganizationMemberGroupKind        = schema.GroupKind{Group: Group, Kind: OrganizationMemberKind}.String()
+	OrganizationMemberKindAPIVersion   = OrganizationMemberKind + "." + SchemeGroupVersion.String()
+	OrganizationMemberGroupVersionKind = SchemeGroupVersion.WithKind(OrganizationMemberKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&OrganizationMember{}, &OrganizationMemberList{})
+}
@@ -0,0 +1,145 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// A Request is an HTTP request to the LiteLLM proxy. The placeholder {id} in
+// its path is replaced with the external name of the RawRequest.
+type Request struct {
+	// Method of the request.
+	// +kubebuilder:validation:Enum=GET;POST;PUT;PATCH;DELETE
+	// +kubebuilder:default=POST
+	// +optional
+	Method string `json:"method,omitempty"`
+
+	// Path of the request, e.g. /tag/new. It may include a query string.
+	// +kubebuilder:validation:Pattern=`^/`
+	Path string `json:"path"`
+
+	// Body of the request, sent as JSON.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	// +optional
+	Body *runtime.RawExtension `json:"body,omitempty"`
+}
+
+// RawRequestParameters are the configurable fields of a RawRequest.
+type RawRequestParameters struct {
+	// Create is the request that creates the external resource.
+	Create Request `json:"create"`
+
+	// IDJSONPath extracts the id of the external resource from the response
+	// to the create request, e.g. {.tag_id}. The id becomes the external
+	// name of the RawRequest.
+	IDJSONPath string `json:"idJsonPath"`
+
+	// ObservePath is polled with GET to check that the external resource
+	// exists, e.g. /tag/info?names={id}. A 404 response means it doesn't.
+	ObservePath string `json:"observePath"`
+
+	// Delete is the request that deletes the external resource. The
+	// external resource is left as it is if no delete request is set.
+	// +optional
+	Delete *Request `json:"delete,omitempty"`
+}
+
+// RawRequestObservation are the observable fields of a RawRequest.
+type RawRequestObservation struct {
+	// Response is the last response to the observe request.
+	// +kubebuilder:validation:Type=object
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Response *runtime.RawExtension `json:"response,omitempty"`
+
+	// LastSyncTime is when the RawRequest last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A RawRequestSpec defines the desired state of a RawRequest.
+type RawRequestSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       RawRequestParameters `json:"forProvider"`
+}
+
+// A RawRequestStatus represents the observed state of a RawRequest.
+type RawRequestStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          RawRequestObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A RawRequest is an EXPERIMENTAL escape hatch that manages a LiteLLM resource
+// for which there is no typed resource yet, using plain HTTP requests. It is
+// created and deleted, but never updated: changes to its spec are not applied
+// to the external resource. Prefer a typed resource wherever one exists.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="PATH",type="string",JSONPath=".spec.forProvider.create.path"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type RawRequest struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RawRequestSpec   `json:"spec"`
+	Status RawRequestStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the RawRequest last synced with LiteLLM.
+func (mg *RawRequest) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the RawRequest last synced with LiteLLM.
+func (mg *RawRequest) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// RawRequestList contains a list of RawRequest
+type RawRequestList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RawRequest `json:"items"`
+}
+
+// RawRequest type metadata.
+var (
+	RawRequestKind             = reflect.TypeOf(RawRequest{}).Name()
+	RawRequestGroupKind        = schema.GroupKind{Group: Group, Kind: RawRequestKind}.String()
+	RawRequestKindAPIVersion   = RawRequestKind + "." + SchemeGroupVersion.String()
+	RawRequestGroupVersionKind = SchemeGroupVersion.WithKind(RawRequestKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&RawRequest{}, &RawRequestList{})
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// ModelAliasParameters are the configurable fields of a ModelAlias.
+type ModelAliasParameters struct {
+	// Alias is the model group clients request, e.g. gpt-4.
+	// +kubebuilder:validation:XValidation:rule="self == oldSelf",message="alias is immutable"
+	Alias string `json:"alias"`
+
+	// Model is the model group requests for the alias are routed to, e.g.
+	// gpt-4o.
+	Model string `json:"model"`
+
+	// Hidden hides the alias from the /models endpoint.
+	// +optional
+	Hidden bool `json:"hidden,omitempty"`
+}
+
+// ModelAliasObservation are the observable fields of a ModelAlias.
+type ModelAliasObservation struct {
+	// Model is the model group the alias currently routes to.
+	Model string `json:"model,omitempty"`
+
+	// LastSyncTime is when the ModelAlias last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A ModelAliasSpec defines the desired state of a ModelAlias.
+type ModelAliasSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       ModelAliasParameters `json:"forProvider"`
+}
+
+// A ModelAliasStatus represents the observed state of a ModelAlias.
+type ModelAliasStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          ModelAliasObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A ModelAlias is an entry of the model_group_alias router setting of a
+// LiteLLM proxy. Each ModelAlias manages only its own alias, so aliases that
+// are managed by other ModelAliases, or not by Crossplane at all, are kept.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="ALIAS",type="string",JSONPath=".spec.forProvider.alias"
+// +kubebuilder:printcolumn:name="MODEL",type="string",JSONPath=".status.atProvider.model"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type ModelAlias struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   ModelAliasSpec   `json:"spec"`
+	Status ModelAliasStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the ModelAlias last synced with LiteLLM.
+func (mg *ModelAlias) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the ModelAlias last synced with LiteLLM.
+func (mg *ModelAlias) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// ModelAliasList contains a list of ModelAlias
+type ModelAliasList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []ModelAlias `json:"items"`
+}
+
+// ModelAlias type metadata.
+var (
+	ModelAliasKind             = reflect.TypeOf(ModelAlias{}).Name()
+	ModelAliasGroupKind        = schema.GroupKind{Group: Group, Kind: ModelAliasKind}.String()
+	ModelAliasKindAPIVersion   = ModelAliasKind + "." + SchemeGroupVersion.String()
+	ModelAliasGroupVersionKind = SchemeGroupVersion.WithKind(ModelAliasKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&ModelAlias{}, &ModelAliasList{})
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// RouterConfigParameters are the configurable router settings. Settings that
+// are not set are left as they are.
+type RouterConfigParameters struct {
+	// Fallbacks maps a model group to the model groups that are tried, in
+	// order, when it fails.
+	// +optional
+	Fallbacks map[string][]string `json:"fallbacks,omitempty"`
+
+	// NumRetries is how often a failed request is retried.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	NumRetries *int `json:"numRetries,omitempty"`
+
+	// TimeoutSeconds is the timeout of a request to a model.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	TimeoutSeconds *int `json:"timeoutSeconds,omitempty"`
+
+	// RoutingStrategy selects how requests are spread across the
+	// deployments of a model group.
+	// +kubebuilder:validation:Enum=simple-shuffle;least-busy;usage-based-routing;usage-based-routing-v2;latency-based-routing;cost-based-routing
+	// +optional
+	RoutingStrategy string `json:"routingStrategy,omitempty"`
+
+	// AllowedFails is how many requests to a deployment may fail within a
+	// minute before it is cooled down.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	AllowedFails *int `json:"allowedFails,omitempty"`
+
+	// CooldownSeconds is how long a failing deployment receives no
+	// requests.
+	// +kubebuilder:validation:Minimum=0
+	// +optional
+	CooldownSeconds *int `json:"cooldownSeconds,omitempty"`
+}
+
+// RouterConfigObservation are the observed router settings.
+type RouterConfigObservation struct {
+	// Fallbacks maps a model group to its fallback model groups.
+	Fallbacks map[string][]string `json:"fallbacks,omitempty"`
+
+	// NumRetries is how often a failed request is retried.
+	NumRetries *int `json:"numRetries,omitempty"`
+
+	// TimeoutSeconds is the timeout of a request to a model.
+	TimeoutSeconds *int `json:"timeoutSeconds,omitempty"`
+
+	// RoutingStrategy is how requests are spread across deployments.
+	RoutingStrategy string `json:"routingStrategy,omitempty"`
+
+	// AllowedFails is how many requests to a deployment may fail within a
+	// minute before it is cooled down.
+	AllowedFails *int `json:"allowedFails,omitempty"`
+
+	// CooldownSeconds is how long a failing deployment receives no
+	// requests.
+	CooldownSeconds *int `json:"cooldownSeconds,omitempty"`
+
+	// LastSyncTime is when the RouterConfig last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A RouterConfigSpec defines the desired state of a RouterConfig.
+type RouterConfigSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       RouterConfigParameters `json:"forProvider"`
+}
+
+// A RouterConfigStatus represents the observed state of a RouterConfig.
+type RouterConfigStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          RouterConfigObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A RouterConfig manages the router settings of a LiteLLM proxy. The router
+// settings are global, so only the oldest RouterConfig of a ProviderConfig
+// is reconciled; any other fails to sync. Deleting a RouterConfig leaves the
+// router settings as they are.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="STRATEGY",type="string",JSONPath=".status.atProvider.routingStrategy"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type RouterConfig struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   RouterConfigSpec   `json:"spec"`
+	Status RouterConfigStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the RouterConfig last synced with LiteLLM.
+func (mg *RouterConfig) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the RouterConfig last synced with LiteLLM.
+func (mg *RouterConfig) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// RouterConfigList contains a list of RouterConfig
+type RouterConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []RouterConfig `json:"items"`
+}
+
+// RouterConfig type metadata.
+var (
+	RouterConfigKind             = reflect.TypeOf(RouterConfig{}).Name()
+	RouterConfigGroupKind        = schema.GroupKind{Group: Group, Kind: RouterConfigKind}.String()
+	RouterConfigKindAPIVersion   = RouterConfigKind + "." + SchemeGroupVersion.String()
+	RouterConfigGroupVersionKind = SchemeGroupVersion.WithKind(RouterConfigKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&RouterConfig{}, &RouterConfigList{})
+}
@@ -0,0 +1,170 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Supported spend groupings.
+const (
+	GroupByTeam   = "team"
+	GroupByAPIKey = "api_key"
+)
+
+// SpendReportParameters are the configurable fields of a SpendReport.
+type SpendReportParameters struct {
+	// GroupBy selects whether spend is aggregated per team or per key.
+	// +kubebuilder:validation:Enum=team;api_key
+	// +kubebuilder:default=team
+	// +optional
+	GroupBy string `json:"groupBy,omitempty"`
+
+	// Window is how far back from now spend is aggregated, e.g. 24h for the
+	// last day or 168h for the last week.
+	// +kubebuilder:default="24h"
+	// +optional
+	Window *metav1.Duration `json:"window,omitempty"`
+
+	// Periods is the number of most recent days whose spend is also
+	// reported day by day, newest first. Daily spend isn't reported if
+	// unset.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=31
+	// +optional
+	Periods *int `json:"periods,omitempty"`
+
+	// RefreshInterval is how often the report is refreshed. Defaults to the
+	// poll interval of the provider.
+	// +optional
+	RefreshInterval *metav1.Duration `json:"refreshInterval,omitempty"`
+}
+
+// SpendEntry is the aggregated spend of a single team or key.
+type SpendEntry struct {
+	// Name of the team or key the spend is attributed to.
+	Name string `json:"name"`
+
+	// Spend in USD over the report window.
+	Spend float64 `json:"spend"`
+}
+
+// SpendPeriod is the spend of a single day.
+type SpendPeriod struct {
+	// Date of the day, e.g. 2024-05-01.
+	Date string `json:"date"`
+
+	// TotalSpend is the sum of all entries in USD.
+	TotalSpend float64 `json:"totalSpend,omitempty"`
+
+	// Entries contains the spend per team or key.
+	Entries []SpendEntry `json:"entries,omitempty"`
+}
+
+// SpendReportObservation are the observable fields of a SpendReport.
+type SpendReportObservation struct {
+	// StartDate is the first day included in the report.
+	StartDate string `json:"startDate,omitempty"`
+
+	// EndDate is the last day included in the report.
+	EndDate string `json:"endDate,omitempty"`
+
+	// TotalSpend is the sum of all entries in USD.
+	TotalSpend float64 `json:"totalSpend,omitempty"`
+
+	// Entries contains the spend per team or key.
+	Entries []SpendEntry `json:"entries,omitempty"`
+
+	// Periods contains the spend of the most recent days, newest first.
+	Periods []SpendPeriod `json:"periods,omitempty"`
+
+	// LastRefreshTime is when the report was last refreshed.
+	LastRefreshTime *metav1.Time `json:"lastRefreshTime,omitempty"`
+
+	// LastSyncTime is when the SpendReport last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A SpendReportSpec defines the desired state of a SpendReport.
+type SpendReportSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       SpendReportParameters `json:"forProvider,omitempty"`
+}
+
+// A SpendReportStatus represents the observed state of a SpendReport.
+type SpendReportStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          SpendReportObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A SpendReport is a read-only view of LiteLLM spend aggregated per team or
+// key. It never creates, updates, or deletes anything in LiteLLM.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="GROUP-BY",type="string",JSONPath=".spec.forProvider.groupBy"
+// +kubebuilder:printcolumn:name="TOTAL-SPEND",type="number",JSONPath=".status.atProvider.totalSpend"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type SpendReport struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   SpendReportSpec   `json:"spec"`
+	Status SpendReportStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the SpendReport last synced with LiteLLM.
+func (mg *SpendReport) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the SpendReport last synced with LiteLLM.
+func (mg *SpendReport) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// SpendReportList contains a list of SpendReport
+type SpendReportList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []SpendReport `json:"items"`
+}
+
+// SpendReport type metadata.
+var (
+	SpendReportKind             = reflect.TypeOf(SpendReport{}).Name()
+	SpendReportGroupKind        = schema.GroupKind{Group: Group, Kind: SpendReportKind}.String()
+	SpendReportKindAPIVersion   = SpendReportKind + "." + SchemeGroupVersion.String()
+	SpendReportGroupVersionKind = SchemeGroupVersion.WithKind(SpendReportKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&SpendReport{}, &SpendReportList{})
+}
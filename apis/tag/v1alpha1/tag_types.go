@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// TagParameters are the configurable fields of a Tag. The LiteLLM tag name
+// is the external name of the Tag.
+type TagParameters struct {
+	// Name is the name requests are tagged with. Defaults to the external
+	// name of the Tag.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Description of the tag.
+	// +optional
+	Description string `json:"description,omitempty"`
+
+	// Models are the models requests with the tag are routed to. All models
+	// are allowed if unset.
+	// +optional
+	Models []string `json:"models,omitempty"`
+}
+
+// TagObservation are the observable fields of a Tag.
+type TagObservation struct {
+	// Name is the name of the tag in LiteLLM.
+	Name string `json:"name,omitempty"`
+
+	// Description is the effective description of the tag.
+	Description string `json:"description,omitempty"`
+
+	// Models are the effective models of the tag.
+	Models []string `json:"models,omitempty"`
+
+	// Spend is the current spend of requests with the tag in USD.
+	Spend float64 `json:"spend,omitempty"`
+
+	// CreatedAt is when the tag was created.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// UpdatedAt is when the tag was last updated.
+	UpdatedAt *metav1.Time `json:"updatedAt,omitempty"`
+
+	// LastSyncTime is when the Tag last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A TagSpec defines the desired state of a Tag.
+type TagSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       TagParameters `json:"forProvider"`
+}
+
+// A TagStatus represents the observed state of a Tag.
+type TagStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          TagObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A Tag is a LiteLLM request tag that spend is attributed by and that can
+// restrict the models its requests are routed to.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="SPEND",type="number",JSONPath=".status.atProvider.spend"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type Tag struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   TagSpec   `json:"spec"`
+	Status TagStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the Tag last synced with LiteLLM.
+func (mg *Tag) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Tag last synced with LiteLLM.
+func (mg *Tag) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// TagList contains a list of Tag
+type TagList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []Tag `json:"items"`
+}
+
+// Tag type metadata.
+var (
+	TagKind             = reflect.TypeOf(Tag{}).Name()
+	TagGroupKind        = schema.GroupKind{Group: Group, Kind: TagKind}.String()
+	TagKindAPIVersion   = TagKind + "." + SchemeGroupVersion.String()
+	TagGroupVersionKind = SchemeGroupVersion.WithKind(TagKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&Tag{}, &TagList{})
+}
@@ -25,14 +25,99 @@ import (
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 )
 
-// TeamParameters are the configurable fields of a Team.
+// ObjectPermission grants access to vector stores and MCP servers. Lists
+// that are not set are not managed.
+type ObjectPermission struct {
+	// VectorStores are the ids of the vector stores that may be used.
+	// +optional
+	VectorStores []string `json:"vectorStores,omitempty"`
+
+	// MCPServers are the ids of the MCP servers that may be used.
+	// +optional
+	MCPServers []string `json:"mcpServers,omitempty"`
+
+	// MCPAccessGroups are the MCP access groups whose servers may be used.
+	// +optional
+	MCPAccessGroups []string `json:"mcpAccessGroups,omitempty"`
+}
+
+// TeamParameters are the configurable fields of a Team. The LiteLLM team_id
+// is the external name of the Team.
 type TeamParameters struct {
-	ConfigurableField string `json:"configurableField"`
+	// TeamAlias is a human readable name for the team.
+	// +optional
+	TeamAlias string `json:"teamAlias,omitempty"`
+
+	// Models the team is allowed to call.
+	// +optional
+	Models []string `json:"models,omitempty"`
+
+	// MaxBudget is the maximum spend of the team in USD.
+	// +optional
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// BudgetDuration is how often the budget resets, e.g. 30d.
+	// +optional
+	BudgetDuration string `json:"budgetDuration,omitempty"`
+
+	// TPMLimit is the maximum number of tokens per minute of the team.
+	// +optional
+	TPMLimit *int64 `json:"tpmLimit,omitempty"`
+
+	// RPMLimit is the maximum number of requests per minute of the team.
+	// +optional
+	RPMLimit *int64 `json:"rpmLimit,omitempty"`
+
+	// Metadata attached to the team.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// Blocked teams can't make requests to the proxy, e.g. during an
+	// incident. Changing it blocks or unblocks the team.
+	// +optional
+	Blocked *bool `json:"blocked,omitempty"`
+
+	// Guardrails are the names of the guardrails that run on requests made
+	// by the team.
+	// +crossplane:generate:reference:type=github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1.Guardrail
+	// +crossplane:generate:reference:extractor=github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1.GuardrailName()
+	// +crossplane:generate:reference:refFieldName=GuardrailRefs
+	// +crossplane:generate:reference:selectorFieldName=GuardrailSelector
+	// +optional
+	Guardrails []string `json:"guardrails,omitempty"`
+
+	// GuardrailRefs reference Guardrails to set Guardrails.
+	// +optional
+	GuardrailRefs []xpv1.Reference `json:"guardrailRefs,omitempty"`
+
+	// GuardrailSelector selects Guardrails to set Guardrails.
+	// +optional
+	GuardrailSelector *xpv1.Selector `json:"guardrailSelector,omitempty"`
+
+	// ObjectPermission grants the team access to vector stores and MCP
+	// servers.
+	// +optional
+	ObjectPermission *ObjectPermission `json:"objectPermission,omitempty"`
 }
 
 // TeamObservation are the observable fields of a Team.
 type TeamObservation struct {
-	ObservableField string `json:"observableField,omitempty"`
+	// TeamID is the LiteLLM id of the team.
+	TeamID string `json:"teamId,omitempty"`
+
+	// SpendUSD is the current spend of the team in USD.
+	SpendUSD float64 `json:"spendUsd,omitempty"`
+
+	// MemberCount is the number of members of the team.
+	MemberCount int `json:"memberCount,omitempty"`
+
+	// Blocked is whether the team is blocked.
+	Blocked bool `json:"blocked,omitempty"`
+
+	// LastSyncTime is when the Team last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
 }
 
 // A TeamSpec defines the desired state of a Team.
@@ -49,10 +134,13 @@ type TeamStatus struct {
 
 // +kubebuilder:object:root=true
 
-// A Team is an example API type.
+// A Team is a LiteLLM team.
 // +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
 // +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
 // +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="SPEND",type="number",JSONPath=".status.atProvider.spendUsd"
+// +kubebuilder:printcolumn:name="MEMBERS",type="integer",JSONPath=".status.atProvider.memberCount"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:subresource:status
 // +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
@@ -64,6 +152,16 @@ type Team struct {
 	Status TeamStatus `json:"status,omitempty"`
 }
 
+// GetLastSyncTime returns when the Team last synced with LiteLLM.
+func (mg *Team) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the Team last synced with LiteLLM.
+func (mg *Team) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
 // +kubebuilder:object:root=true
 
 // TeamList contains a list of Team
@@ -0,0 +1,175 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	"reflect"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// UserParameters are the configurable fields of a User. The LiteLLM user_id
+// is the external name of the User.
+type UserParameters struct {
+	// UserEmail is the email address of the user.
+	// +optional
+	UserEmail string `json:"userEmail,omitempty"`
+
+	// UserAlias is a human readable name for the user.
+	// +optional
+	UserAlias string `json:"userAlias,omitempty"`
+
+	// SSOUserID is the id of the user in the SSO provider. Setting it lets
+	// LiteLLM match the user on their first SSO login.
+	// +optional
+	SSOUserID string `json:"ssoUserId,omitempty"`
+
+	// UserRole is the proxy-wide role of the user.
+	// +kubebuilder:validation:Enum=proxy_admin;proxy_admin_viewer;internal_user;internal_user_viewer
+	// +optional
+	UserRole string `json:"userRole,omitempty"`
+
+	// Models the user is allowed to call.
+	// +optional
+	Models []string `json:"models,omitempty"`
+
+	// MaxBudget is the maximum spend of the user in USD.
+	// +optional
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// BudgetDuration is how often the budget resets, e.g. 30d.
+	// +optional
+	BudgetDuration string `json:"budgetDuration,omitempty"`
+
+	// Metadata attached to the user.
+	// +optional
+	Metadata map[string]string `json:"metadata,omitempty"`
+
+	// SendInvite requests an invitation link for the user once it has been
+	// created. The link is published as the invitation_url connection detail.
+	// +optional
+	SendInvite bool `json:"sendInvite,omitempty"`
+
+	// InvitationSerial can be bumped to request a new invitation link while
+	// SendInvite is true.
+	// +optional
+	InvitationSerial int64 `json:"invitationSerial,omitempty"`
+}
+
+// UserObservation are the observable fields of a User.
+type UserObservation struct {
+	// UserID is the LiteLLM id of the user.
+	UserID string `json:"userId,omitempty"`
+
+	// UserRole is the proxy-wide role of the user.
+	UserRole string `json:"userRole,omitempty"`
+
+	// Spend is the current spend of the user in USD.
+	Spend float64 `json:"spend,omitempty"`
+
+	// MaxBudget is the maximum spend of the user in USD.
+	MaxBudget *float64 `json:"maxBudget,omitempty"`
+
+	// KeyCount is the number of keys owned by the user.
+	KeyCount int `json:"keyCount,omitempty"`
+
+	// Teams the user is a member of.
+	Teams []string `json:"teams,omitempty"`
+
+	// CreatedAt is when the user was created.
+	CreatedAt *metav1.Time `json:"createdAt,omitempty"`
+
+	// InvitationID is the id of the last invitation created for the user.
+	InvitationID string `json:"invitationId,omitempty"`
+
+	// InvitationSerial is the serial the last invitation was created for.
+	InvitationSerial int64 `json:"invitationSerial,omitempty"`
+
+	// InvitationExpiresAt is when the last invitation expires.
+	InvitationExpiresAt *metav1.Time `json:"invitationExpiresAt,omitempty"`
+
+	// LastSyncTime is when the User last synced with LiteLLM. A failed
+	// sync leaves it unchanged.
+	// +optional
+	LastSyncTime *metav1.Time `json:"lastSyncTime,omitempty"`
+}
+
+// A UserSpec defines the desired state of a User.
+type UserSpec struct {
+	xpv1.ResourceSpec `json:",inline"`
+	ForProvider       UserParameters `json:"forProvider"`
+}
+
+// A UserStatus represents the observed state of a User.
+type UserStatus struct {
+	xpv1.ResourceStatus `json:",inline"`
+	AtProvider          UserObservation `json:"atProvider,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// A User is a LiteLLM internal user.
+// +kubebuilder:printcolumn:name="READY",type="string",JSONPath=".status.conditions[?(@.type=='Ready')].status"
+// +kubebuilder:printcolumn:name="SYNCED",type="string",JSONPath=".status.conditions[?(@.type=='Synced')].status"
+// +kubebuilder:printcolumn:name="EXTERNAL-NAME",type="string",JSONPath=".metadata.annotations.crossplane\\.io/external-name"
+// +kubebuilder:printcolumn:name="ROLE",type="string",JSONPath=".status.atProvider.userRole"
+// +kubebuilder:printcolumn:name="SPEND",type="number",JSONPath=".status.atProvider.spend"
+// +kubebuilder:printcolumn:name="LAST-SYNC",type="date",JSONPath=".status.atProvider.lastSyncTime"
+// +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:scope=Cluster,categories={crossplane,managed,litellm}
+type User struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   UserSpec   `json:"spec"`
+	Status UserStatus `json:"status,omitempty"`
+}
+
+// GetLastSyncTime returns when the User last synced with LiteLLM.
+func (mg *User) GetLastSyncTime() *metav1.Time {
+	return mg.Status.AtProvider.LastSyncTime
+}
+
+// SetLastSyncTime sets when the User last synced with LiteLLM.
+func (mg *User) SetLastSyncTime(t *metav1.Time) {
+	mg.Status.AtProvider.LastSyncTime = t
+}
+
+// +kubebuilder:object:root=true
+
+// UserList contains a list of User
+type UserList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []User `json:"items"`
+}
+
+// User type metadata.
+var (
+	UserKind             = reflect.TypeOf(User{}).Name()
+	UserGroupKind        = schema.GroupKind{Group: Group, Kind: UserKind}.String()
+	UserKindAPIVersion   = UserKind + "." + SchemeGroupVersion.String()
+	UserGroupVersionKind = SchemeGroupVersion.WithKind(UserKind)
+)
+
+func init() {
+	SchemeBuilder.Register(&User{}, &UserList{})
+}
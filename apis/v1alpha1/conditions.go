@@ -0,0 +1,283 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+// Condition types shared by LiteLLM managed resources.
+const (
+	// TypeBudgetExceeded indicates whether the spend of a resource has
+	// reached its budget.
+	TypeBudgetExceeded xpv1.ConditionType = "BudgetExceeded"
+
+	// TypeSpendExceeded indicates whether a resource was blocked because its
+	// spend exceeded its alert threshold.
+	TypeSpendExceeded xpv1.ConditionType = "SpendExceeded"
+
+	// TypeHealthy indicates whether the proxy can reach the upstream of a
+	// model deployment, or whether LiteLLM can be reached and authenticated
+	// to with the settings of a ProviderConfig.
+	TypeHealthy xpv1.ConditionType = "Healthy"
+
+	// TypeReferenceResolution indicates whether the references of a resource
+	// to other managed resources could be resolved.
+	TypeReferenceResolution xpv1.ConditionType = "ReferenceResolution"
+
+	// TypeRejected indicates whether LiteLLM rejected the spec of a resource
+	// with an error that retrying won't fix.
+	TypeRejected xpv1.ConditionType = "Rejected"
+
+	// TypeExpiringSoon indicates whether a key expires within the expiry
+	// warn window of its ProviderConfig.
+	TypeExpiringSoon xpv1.ConditionType = "ExpiringSoon"
+
+	// TypeExpired indicates whether a key has expired.
+	TypeExpired xpv1.ConditionType = "Expired"
+
+	// TypeProxyUnavailable indicates whether requests for a resource fail
+	// fast because the circuit breaker of its LiteLLM proxy is open.
+	TypeProxyUnavailable xpv1.ConditionType = "ProxyUnavailable"
+)
+
+// Condition reasons shared by LiteLLM managed resources.
+const (
+	ReasonSpendAboveBudget xpv1.ConditionReason = "SpendAboveBudget"
+	ReasonWithinBudget     xpv1.ConditionReason = "WithinBudget"
+
+	ReasonSpendAboveThreshold xpv1.ConditionReason = "SpendAboveThreshold"
+	ReasonSpendBelowThreshold xpv1.ConditionReason = "SpendBelowThreshold"
+
+	ReasonHealthCheckPassed xpv1.ConditionReason = "HealthCheckPassed"
+	ReasonHealthCheckFailed xpv1.ConditionReason = "HealthCheckFailed"
+	ReasonUnauthorized      xpv1.ConditionReason = "Unauthorized"
+
+	ReasonReferencesResolved   xpv1.ConditionReason = "ReferencesResolved"
+	ReasonReferencesUnresolved xpv1.ConditionReason = "ReferencesUnresolved"
+
+	ReasonSpecRejected xpv1.ConditionReason = "SpecRejected"
+	ReasonSpecAccepted xpv1.ConditionReason = "SpecAccepted"
+
+	ReasonWithinWarnWindow  xpv1.ConditionReason = "WithinWarnWindow"
+	ReasonOutsideWarnWindow xpv1.ConditionReason = "OutsideWarnWindow"
+
+	ReasonPastExpiry   xpv1.ConditionReason = "PastExpiry"
+	ReasonBeforeExpiry xpv1.ConditionReason = "BeforeExpiry"
+
+	ReasonCircuitOpen    xpv1.ConditionReason = "CircuitOpen"
+	ReasonProxyReachable xpv1.ConditionReason = "ProxyReachable"
+)
+
+// BudgetExceeded returns a condition that indicates the spend of a resource
+// has reached its budget.
+func BudgetExceeded(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBudgetExceeded,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSpendAboveBudget,
+		Message:            msg,
+	}
+}
+
+// WithinBudget returns a condition that indicates the spend of a resource is
+// below its budget, or that it has no budget.
+func WithinBudget() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeBudgetExceeded,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonWithinBudget,
+	}
+}
+
+// SpendExceeded returns a condition that indicates a resource was blocked
+// because its spend exceeded its alert threshold.
+func SpendExceeded(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSpendExceeded,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSpendAboveThreshold,
+		Message:            msg,
+	}
+}
+
+// SpendBelowThreshold returns a condition that indicates the spend of a
+// resource is below its alert threshold, or that it has none.
+func SpendBelowThreshold() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeSpendExceeded,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSpendBelowThreshold,
+	}
+}
+
+// Healthy returns a condition that indicates the proxy can reach the upstream
+// of a model deployment, or that LiteLLM is healthy.
+func Healthy() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonHealthCheckPassed,
+	}
+}
+
+// Unhealthy returns a condition that indicates the proxy can't reach the
+// upstream of a model deployment, or that LiteLLM is unhealthy.
+func Unhealthy(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonHealthCheckFailed,
+		Message:            msg,
+	}
+}
+
+// Unauthorized returns a condition that indicates LiteLLM rejected the
+// credentials of a ProviderConfig.
+func Unauthorized(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeHealthy,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonUnauthorized,
+		Message:            msg,
+	}
+}
+
+// ReferencesResolved returns a condition that indicates the references of a
+// resource were resolved.
+func ReferencesResolved() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeReferenceResolution,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReferencesResolved,
+	}
+}
+
+// ReferencesUnresolved returns a condition that indicates the references of
+// a resource could not be resolved, e.g. because a referenced resource is not
+// ready yet.
+func ReferencesUnresolved(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeReferenceResolution,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonReferencesUnresolved,
+		Message:            msg,
+	}
+}
+
+// Rejected returns a condition that indicates LiteLLM rejected the spec of a
+// resource. The resource is retried with a growing backoff until its spec
+// changes or LiteLLM accepts it.
+func Rejected(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeRejected,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSpecRejected,
+		Message:            msg,
+	}
+}
+
+// Accepted returns a condition that indicates LiteLLM accepted the spec of a
+// resource it previously rejected.
+func Accepted() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeRejected,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonSpecAccepted,
+	}
+}
+
+// ExpiringSoon returns a condition that indicates a key expires within the
+// expiry warn window of its ProviderConfig.
+func ExpiringSoon(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeExpiringSoon,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonWithinWarnWindow,
+		Message:            msg,
+	}
+}
+
+// NotExpiringSoon returns a condition that indicates a key doesn't expire
+// within the expiry warn window of its ProviderConfig.
+func NotExpiringSoon() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeExpiringSoon,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonOutsideWarnWindow,
+	}
+}
+
+// Expired returns a condition that indicates a key has expired.
+func Expired(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeExpired,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonPastExpiry,
+		Message:            msg,
+	}
+}
+
+// NotExpired returns a condition that indicates a key has not expired.
+func NotExpired() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeExpired,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonBeforeExpiry,
+	}
+}
+
+// ProxyUnavailable returns a condition that indicates requests for a resource
+// fail fast because the circuit breaker of its LiteLLM proxy is open.
+func ProxyUnavailable(msg string) xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProxyUnavailable,
+		Status:             corev1.ConditionTrue,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonCircuitOpen,
+		Message:            msg,
+	}
+}
+
+// ProxyAvailable returns a condition that indicates the LiteLLM proxy of a
+// resource can be reached again.
+func ProxyAvailable() xpv1.Condition {
+	return xpv1.Condition{
+		Type:               TypeProxyUnavailable,
+		Status:             corev1.ConditionFalse,
+		LastTransitionTime: metav1.Now(),
+		Reason:             ReasonProxyReachable,
+	}
+}
@@ -26,32 +26,331 @@ import (
 )
 
 // A ProviderConfigSpec defines the desired state of a ProviderConfig.
+// +kubebuilder:validation:XValidation:rule="has(self.apiBase) != has(self.apiBaseFrom)",message="exactly one of apiBase and apiBaseFrom must be set"
 type ProviderConfigSpec struct {
-	// Credentials required to authenticate to this provider.
+	// Credentials required to authenticate to this provider. If a master key
+	// is configured these may be a scoped key, e.g. a team key, that is used
+	// for everything that doesn't need the master key.
 	Credentials ProviderCredentials `json:"credentials"`
 
-	// APIBase is the base URL for the LiteLLM API
-	APIBase string `json:"apiBase"`
+	// MasterKeyRef references the LiteLLM master key. If set, it is used for
+	// admin-only endpoints such as /team/new, /user/new, /customer/*,
+	// /budget/* and /organization/*, while the credentials are used for all
+	// other requests.
+	// +optional
+	MasterKeyRef *xpv1.SecretKeySelector `json:"masterKeyRef,omitempty"`
+
+	// APIBase is the base URL for the LiteLLM API, optionally with a path
+	// prefix, e.g. https://gateway.corp/litellm.
+	// +kubebuilder:validation:Pattern=`^https?://`
+	// +optional
+	APIBase string `json:"apiBase,omitempty"`
+
+	// APIBaseFrom resolves the base URL of the LiteLLM API, e.g. from the
+	// Service of LiteLLM when it runs in the same cluster. It can't be
+	// combined with APIBase.
+	// +optional
+	APIBaseFrom *APIBaseSource `json:"apiBaseFrom,omitempty"`
+
+	// TLS configures how the certificate of LiteLLM is verified and how the
+	// provider authenticates to it with a client certificate.
+	// +optional
+	TLS *TLSConfig `json:"tls,omitempty"`
+
+	// ProxyURL is the URL of an HTTP proxy that requests to LiteLLM are
+	// sent through, e.g. a corporate egress proxy. It overrides the
+	// HTTPS_PROXY, HTTP_PROXY and NO_PROXY environment variables of the
+	// provider, which are used if it is unset.
+	// +kubebuilder:validation:Pattern=`^(http|https|socks5)://`
+	// +optional
+	ProxyURL string `json:"proxyURL,omitempty"`
+
+	// Timeout is how long a single request to LiteLLM may take, e.g. 30s.
+	// Requests are only bounded by the reconcile if unset.
+	// +optional
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+
+	// MaxResponseBytes is the largest response body the provider reads from
+	// LiteLLM. Larger responses, e.g. of a list of many keys, are rejected
+	// rather than read. Defaults to 64 MiB.
+	// +kubebuilder:validation:Minimum=1024
+	// +optional
+	MaxResponseBytes *int64 `json:"maxResponseBytes,omitempty"`
+
+	// Retry configures how idempotent requests that fail to connect to
+	// LiteLLM, or get a 5xx response, are retried. Requests are not retried
+	// if unset.
+	// +optional
+	Retry *RetryPolicy `json:"retry,omitempty"`
+
+	// MaxRequestsPerSecond limits the rate of requests the provider sends to
+	// LiteLLM, across all resources that use this ProviderConfig, so that
+	// reconciling many resources at once doesn't get them rate limited.
+	// Requests are not limited if unset.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	MaxRequestsPerSecond int `json:"maxRequestsPerSecond,omitempty"`
+
+	// ObserveCacheTTL is how long the provider reuses what it observed of a
+	// key, e.g. when it is observed again right after it was reconciled,
+	// rather than fetch it from LiteLLM again. Changing a key drops what was
+	// observed of it. Nothing is reused if unset.
+	// +optional
+	ObserveCacheTTL *metav1.Duration `json:"observeCacheTTL,omitempty"`
+
+	// Headers are sent with every request to LiteLLM, e.g. those required
+	// by an authenticating proxy in front of it. They can't replace the
+	// Authorization and User-Agent headers the provider sends.
+	// +optional
+	Headers map[string]string `json:"headers,omitempty"`
+
+	// HeaderSecretRefs reference the values of headers that are sent with
+	// every request to LiteLLM, like Headers, but are read from secrets and
+	// never logged. They take precedence over Headers.
+	// +optional
+	HeaderSecretRefs map[string]xpv1.SecretKeySelector `json:"headerSecretRefs,omitempty"`
+
+	// AdoptExistingByAlias makes Key controllers adopt an existing key with
+	// the same alias that was not generated for the Key, if its token or
+	// alias matches the external name of the Key. Otherwise any key with the
+	// alias that was not generated for the Key fails the create with an
+	// "alias already in use" error.
+	// +optional
+	AdoptExistingByAlias bool `json:"adoptExistingByAlias,omitempty"`
+
+	// UserAgentSuffix is appended to the User-Agent sent to LiteLLM, e.g. to
+	// tell apart requests of several Crossplane installations.
+	// +optional
+	UserAgentSuffix string `json:"userAgentSuffix,omitempty"`
+
+	// ActorIdentity is sent as the created_by of the keys this provider
+	// generates, e.g. crossplane, so that LiteLLM's audit trail tells them
+	// apart from keys created by people. LiteLLM records the caller instead
+	// if it is unset.
+	// +optional
+	ActorIdentity string `json:"actorIdentity,omitempty"`
+
+	// ForceDeleteTeams deletes the keys of a Team when the Team is deleted.
+	// By default a Team is not deleted until all of its keys are gone.
+	// +optional
+	ForceDeleteTeams bool `json:"forceDeleteTeams,omitempty"`
+
+	// FieldSelection asks LiteLLM to return only the fields the provider
+	// compares, e.g. from /key/info, to reduce the size of large responses.
+	// Proxies that don't support field selection return every field.
+	// +optional
+	FieldSelection bool `json:"fieldSelection,omitempty"`
+
+	// ExpiryWarnWindow is how long before a key expires its Key reports
+	// that it is expiring soon. Keys don't report it if unset.
+	// +optional
+	ExpiryWarnWindow *metav1.Duration `json:"expiryWarnWindow,omitempty"`
+
+	// RegenerateExpiredKeys regenerates keys that have expired with the
+	// duration of their Key, publishing the new key to the connection
+	// secret. Keys without a duration are left expired.
+	// +optional
+	RegenerateExpiredKeys bool `json:"regenerateExpiredKeys,omitempty"`
+
+	// RetainUnpublishedKeys keeps a generated key in the status of its Key
+	// if it can't be written to the connection secret, until it is. LiteLLM
+	// only returns a key when it is generated, so such a key is otherwise
+	// lost and must be regenerated. A retained key is readable by anyone who
+	// can read its Key.
+	// +optional
+	RetainUnpublishedKeys bool `json:"retainUnpublishedKeys,omitempty"`
+
+	// MetadataFieldName is the field the metadata of a key is sent and read
+	// under. Depending on its version LiteLLM uses metadata or key_metadata.
+	// +kubebuilder:validation:Enum=metadata;key_metadata
+	// +kubebuilder:default=metadata
+	// +optional
+	MetadataFieldName string `json:"metadataFieldName,omitempty"`
+
+	// ValidateTeamExists checks that the team of a key exists before the key
+	// is generated, to report a missing team clearly rather than with the
+	// error LiteLLM returns.
+	// +optional
+	ValidateTeamExists bool `json:"validateTeamExists,omitempty"`
+
+	// SoftDeleteKeys blocks the key of a deleted Key and tags it as deleted
+	// in its metadata, rather than deleting it, so that it is retained in
+	// LiteLLM for audit. Keys are deleted by default.
+	// +optional
+	SoftDeleteKeys bool `json:"softDeleteKeys,omitempty"`
+
+	// DefaultPollInterval is how often managed resources that use this
+	// ProviderConfig are polled, instead of the poll interval of the
+	// provider. A poll interval of the managed resource itself still wins.
+	// +optional
+	DefaultPollInterval *metav1.Duration `json:"defaultPollInterval,omitempty"`
 }
 
+// Fields LiteLLM may expect the metadata of a key under.
+const (
+	MetadataFieldMetadata    = "metadata"
+	MetadataFieldKeyMetadata = "key_metadata"
+)
+
+// An APIBaseSource is a source of the base URL of the LiteLLM API.
+type APIBaseSource struct {
+	// ServiceRef references the Service of LiteLLM. The base URL is
+	// resolved to the cluster DNS name of the Service whenever the provider
+	// connects to LiteLLM.
+	ServiceRef ServiceReference `json:"serviceRef"`
+}
+
+// A ServiceReference references a port of a Service.
+type ServiceReference struct {
+	// Name of the Service.
+	Name string `json:"name"`
+
+	// Namespace of the Service.
+	Namespace string `json:"namespace"`
+
+	// Port of the Service LiteLLM listens on. Defaults to the first port of
+	// the Service.
+	// +optional
+	Port *int32 `json:"port,omitempty"`
+
+	// Scheme LiteLLM is served with.
+	// +kubebuilder:validation:Enum=http;https
+	// +kubebuilder:default=http
+	// +optional
+	Scheme string `json:"scheme,omitempty"`
+
+	// Path LiteLLM is served under, e.g. /litellm if it is behind a
+	// reverse proxy.
+	// +optional
+	Path string `json:"path,omitempty"`
+}
+
+// RetryPolicy configures how requests to LiteLLM are retried. Requests are
+// retried with exponential backoff and jitter, but never past the deadline of
+// the reconcile. Requests that get a 4xx response are never retried, and
+// neither are POST requests, which LiteLLM creates objects with, so that a
+// retry can't create an object twice.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a request is sent at most, including the
+	// first attempt.
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=10
+	MaxAttempts int `json:"maxAttempts"`
+
+	// BackoffBase is how long to wait before the first retry. The wait
+	// doubles with every further retry. Defaults to 500ms.
+	// +optional
+	BackoffBase *metav1.Duration `json:"backoffBase,omitempty"`
+
+	// BackoffMax is the longest wait between two attempts. Defaults to 10s.
+	// +optional
+	BackoffMax *metav1.Duration `json:"backoffMax,omitempty"`
+}
+
+// TLSConfig configures TLS connections to LiteLLM.
+// +kubebuilder:validation:XValidation:rule="has(self.clientCertSecretRef) == has(self.clientKeySecretRef)",message="clientCertSecretRef and clientKeySecretRef must be set together"
+type TLSConfig struct {
+	// CABundleSecretRef references PEM encoded CA certificates the
+	// certificate of LiteLLM is verified against, in addition to the system
+	// roots, e.g. those of an internal CA.
+	// +optional
+	CABundleSecretRef *xpv1.SecretKeySelector `json:"caBundleSecretRef,omitempty"`
+
+	// InsecureSkipVerify disables verification of the certificate of
+	// LiteLLM. Only use it for testing.
+	// +optional
+	InsecureSkipVerify bool `json:"insecureSkipVerify,omitempty"`
+
+	// ClientCertSecretRef references a PEM encoded client certificate that
+	// is presented to LiteLLM.
+	// +optional
+	ClientCertSecretRef *xpv1.SecretKeySelector `json:"clientCertSecretRef,omitempty"`
+
+	// ClientKeySecretRef references the PEM encoded private key of the
+	// client certificate.
+	// +optional
+	ClientKeySecretRef *xpv1.SecretKeySelector `json:"clientKeySecretRef,omitempty"`
+}
+
+// CredentialsSourceServiceAccountToken authenticates to LiteLLM with a bound
+// Kubernetes service account token, which LiteLLM verifies as a JWT.
+const CredentialsSourceServiceAccountToken xpv1.CredentialsSource = "ServiceAccountToken"
+
 // ProviderCredentials required to authenticate.
+// +kubebuilder:validation:XValidation:rule="self.source != 'ServiceAccountToken' || has(self.serviceAccountToken)",message="serviceAccountToken must be set when the source is ServiceAccountToken"
 type ProviderCredentials struct {
 	// Source of the provider credentials.
-	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem
+	// +kubebuilder:validation:Enum=None;Secret;InjectedIdentity;Environment;Filesystem;ServiceAccountToken
 	Source xpv1.CredentialsSource `json:"source"`
 
 	xpv1.CommonCredentialSelectors `json:",inline"`
+
+	// ServiceAccountToken configures the service account token used as the
+	// bearer token when the source is ServiceAccountToken. The token is
+	// refreshed before it expires.
+	// +optional
+	ServiceAccountToken *ServiceAccountTokenSource `json:"serviceAccountToken,omitempty"`
+}
+
+// A ServiceAccountTokenSource is a source of bound service account tokens.
+// +kubebuilder:validation:XValidation:rule="has(self.path) != has(self.serviceAccountRef)",message="exactly one of path and serviceAccountRef must be set"
+type ServiceAccountTokenSource struct {
+	// Path of a token projected into the provider's pod by a
+	// serviceAccountToken volume, e.g. /var/run/secrets/litellm/token.
+	// +optional
+	Path string `json:"path,omitempty"`
+
+	// ServiceAccountRef references a service account tokens are requested
+	// for with the TokenRequest API.
+	// +optional
+	ServiceAccountRef *ServiceAccountReference `json:"serviceAccountRef,omitempty"`
+
+	// Audience of requested tokens. It must be an audience LiteLLM accepts.
+	// +kubebuilder:default=litellm
+	// +optional
+	Audience string `json:"audience,omitempty"`
+
+	// ExpirationSeconds is how long requested tokens are valid for.
+	// +kubebuilder:validation:Minimum=600
+	// +kubebuilder:default=3600
+	// +optional
+	ExpirationSeconds *int64 `json:"expirationSeconds,omitempty"`
+}
+
+// A ServiceAccountReference references a service account.
+type ServiceAccountReference struct {
+	// Name of the service account.
+	Name string `json:"name"`
+
+	// Namespace of the service account.
+	Namespace string `json:"namespace"`
 }
 
 // A ProviderConfigStatus reflects the observed state of a ProviderConfig.
 type ProviderConfigStatus struct {
 	xpv1.ProviderConfigStatus `json:",inline"`
+
+	// APIBase is the base URL of the LiteLLM API that APIBaseFrom was last
+	// resolved to.
+	// +optional
+	APIBase string `json:"apiBase,omitempty"`
+
+	// ProxyVersion is the version of LiteLLM as of its last health check.
+	// +optional
+	ProxyVersion string `json:"proxyVersion,omitempty"`
+
+	// Database is the status of the database of LiteLLM as of its last
+	// health check, e.g. connected.
+	// +optional
+	Database string `json:"database,omitempty"`
 }
 
 // +kubebuilder:object:root=true
 
 // A ProviderConfig configures a Litellm provider.
 // +kubebuilder:subresource:status
+// +kubebuilder:printcolumn:name="HEALTHY",type="string",JSONPath=".status.conditions[?(@.type=='Healthy')].status"
+// +kubebuilder:printcolumn:name="VERSION",type="string",JSONPath=".status.proxyVersion"
 // +kubebuilder:printcolumn:name="AGE",type="date",JSONPath=".metadata.creationTimestamp"
 // +kubebuilder:printcolumn:name="SECRET-NAME",type="string",JSONPath=".spec.credentials.secretRef.name",priority=1
 // +kubebuilder:resource:scope=Cluster
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command litellm-import prints the manifests of managed resources that adopt
+// existing LiteLLM objects, e.g. to onboard an existing LiteLLM deployment.
+// It never changes anything in LiteLLM.
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/alecthomas/kingpin.v2"
+
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/importer"
+)
+
+func main() {
+	var (
+		app     = kingpin.New(filepath.Base(os.Args[0]), "Print the manifests of managed resources that adopt existing LiteLLM objects.").DefaultEnvars()
+		apiBase = app.Flag("api-base", "The base URL of the LiteLLM API.").Required().String()
+		apiKey  = app.Flag("api-key", "The key used to authenticate to LiteLLM.").Required().String()
+
+		teamKeys       = app.Command("team-keys", "Print a Key for each key of a team.")
+		team           = teamKeys.Flag("team", "The ID of the team.").Required().String()
+		providerConfig = teamKeys.Flag("provider-config", "The ProviderConfig the Keys use.").Default("default").String()
+	)
+
+	switch kingpin.MustParse(app.Parse(os.Args[1:])) {
+	case teamKeys.FullCommand():
+		c := litellm.NewClient(&litellm.Config{APIBase: *apiBase, APIKey: strings.TrimSpace(*apiKey)})
+		keys, err := importer.TeamKeys(context.Background(), c, *team, *providerConfig)
+		kingpin.FatalIfError(err, "Cannot list keys")
+		kingpin.FatalIfError(importer.Write(os.Stdout, keys), "Cannot write Keys")
+	}
+}
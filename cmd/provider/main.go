@@ -29,6 +29,7 @@ import (
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
@@ -39,13 +40,17 @@ import (
 
 	"github.com/crossplane/provider-litellm/apis"
 	"github.com/crossplane/provider-litellm/apis/v1alpha1"
+	litellmclient "github.com/crossplane/provider-litellm/internal/clients/litellm"
 	litellm "github.com/crossplane/provider-litellm/internal/controller"
 	"github.com/crossplane/provider-litellm/internal/features"
+	"github.com/crossplane/provider-litellm/internal/tracing"
+	"github.com/crossplane/provider-litellm/internal/version"
+	litellmwebhook "github.com/crossplane/provider-litellm/internal/webhook"
 )
 
 func main() {
 	var (
-		app            = kingpin.New(filepath.Base(os.Args[0]), "Litellm support for Crossplane.").DefaultEnvars()
+		app            = kingpin.New(filepath.Base(os.Args[0]), "Litellm support for Crossplane.").DefaultEnvars().Version(version.Version)
 		debug          = app.Flag("debug", "Run with debug logging.").Short('d').Bool()
 		leaderElection = app.Flag("leader-election", "Use leader election for the controller manager.").Short('l').Default("false").OverrideDefaultFromEnvar("LEADER_ELECTION").Bool()
 
@@ -56,6 +61,12 @@ func main() {
 		namespace                  = app.Flag("namespace", "Namespace used to set as default scope in default secret store config.").Default("crossplane-system").Envar("POD_NAMESPACE").String()
 		enableExternalSecretStores = app.Flag("enable-external-secret-stores", "Enable support for ExternalSecretStores.").Default("false").Envar("ENABLE_EXTERNAL_SECRET_STORES").Bool()
 		enableManagementPolicies   = app.Flag("enable-management-policies", "Enable support for Management Policies.").Default("false").Envar("ENABLE_MANAGEMENT_POLICIES").Bool()
+
+		defaultProviderConfig = app.Flag("default-provider-config", "The ProviderConfig used by managed resources that neither reference one nor select one with the "+litellmclient.LabelProviderConfig+" label.").Default("default").Envar("DEFAULT_PROVIDER_CONFIG").String()
+
+		allowInsecure = app.Flag("allow-insecure", "Honor the "+litellmclient.AnnotationInsecureSkipVerify+" annotation of ProviderConfigs, which skips TLS verification of LiteLLM. For debugging only.").Envar("ALLOW_INSECURE").Bool()
+
+		webhookTLSCertDir = app.Flag("webhook-tls-cert-dir", "The directory of the TLS certificate of the admission webhook server. Webhooks are disabled if unset.").Envar("WEBHOOK_TLS_CERT_DIR").String()
 	)
 	kingpin.MustParse(app.Parse(os.Args[1:]))
 
@@ -90,10 +101,27 @@ func main() {
 		LeaderElectionResourceLock: resourcelock.LeasesResourceLock,
 		LeaseDuration:              func() *time.Duration { d := 60 * time.Second; return &d }(),
 		RenewDeadline:              func() *time.Duration { d := 50 * time.Second; return &d }(),
+
+		WebhookServer: webhook.NewServer(webhook.Options{
+			CertDir: *webhookTLSCertDir,
+		}),
 	})
 	kingpin.FatalIfError(err, "Cannot create controller manager")
 	kingpin.FatalIfError(apis.AddToScheme(mgr.GetScheme()), "Cannot add Litellm APIs to scheme")
 
+	litellmclient.AllowInsecure = *allowInsecure
+	if *allowInsecure {
+		log.Info("Warning: ProviderConfigs may skip TLS verification of LiteLLM with the " + litellmclient.AnnotationInsecureSkipVerify + " annotation")
+	}
+
+	if tracing.Configured() {
+		shutdown, err := tracing.Setup(context.Background())
+		kingpin.FatalIfError(err, "Cannot set up tracing")
+		defer func() { _ = shutdown(context.Background()) }()
+		litellmclient.EnableTracing()
+		log.Info("Tracing requests to LiteLLM")
+	}
+
 	o := controller.Options{
 		Logger:                  log,
 		MaxConcurrentReconciles: *maxReconcileRate,
@@ -126,6 +154,10 @@ func main() {
 		log.Info("Alpha feature enabled", "flag", features.EnableAlphaManagementPolicies)
 	}
 
-	kingpin.FatalIfError(litellm.Setup(mgr, o), "Cannot setup Litellm controllers")
+	selector := litellmclient.NewProviderConfigSelector(mgr.GetClient(), *defaultProviderConfig)
+	kingpin.FatalIfError(litellm.Setup(mgr, o, selector), "Cannot setup Litellm controllers")
+	if *webhookTLSCertDir != "" {
+		kingpin.FatalIfError(litellmwebhook.Setup(mgr), "Cannot setup Litellm webhooks")
+	}
 	kingpin.FatalIfError(mgr.Start(ctrl.SetupSignalHandler()), "Cannot start controller manager")
 }
@@ -0,0 +1,91 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	errGetService        = "cannot get Service of LiteLLM"
+	errServicePort       = "Service of LiteLLM has no port %d"
+	errServiceNoPorts    = "Service of LiteLLM has no ports"
+	errUpdatePCStatus    = "cannot update ProviderConfig status"
+	defaultServiceScheme = "http"
+)
+
+// apiBase returns the base URL of the LiteLLM API of the supplied
+// ProviderConfig. A base URL resolved from a Service is recorded in the
+// status of the ProviderConfig.
+func apiBase(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig) (string, error) {
+	if pc.Spec.APIBaseFrom == nil {
+		return pc.Spec.APIBase, nil
+	}
+	u, err := serviceURL(ctx, kube, pc.Spec.APIBaseFrom.ServiceRef)
+	if err != nil {
+		return "", err
+	}
+	if pc.Status.APIBase != u {
+		orig := pc.DeepCopy()
+		pc.Status.APIBase = u
+		if err := kube.Status().Patch(ctx, pc, client.MergeFrom(orig)); err != nil {
+			return "", errors.Wrap(err, errUpdatePCStatus)
+		}
+	}
+	return u, nil
+}
+
+// serviceURL returns the URL of the supplied port of a Service.
+func serviceURL(ctx context.Context, kube client.Client, ref apisv1alpha1.ServiceReference) (string, error) {
+	svc := &corev1.Service{}
+	if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, svc); err != nil {
+		return "", errors.Wrap(err, errGetService)
+	}
+	if len(svc.Spec.Ports) == 0 {
+		return "", errors.New(errServiceNoPorts)
+	}
+	port := svc.Spec.Ports[0].Port
+	if ref.Port != nil {
+		port = -1
+		for _, p := range svc.Spec.Ports {
+			if p.Port == *ref.Port {
+				port = p.Port
+			}
+		}
+		if port < 0 {
+			return "", errors.Errorf(errServicePort, *ref.Port)
+		}
+	}
+	scheme := ref.Scheme
+	if scheme == "" {
+		scheme = defaultServiceScheme
+	}
+	path := ""
+	if ref.Path != "" {
+		path = "/" + strings.Trim(ref.Path, "/")
+	}
+	return fmt.Sprintf("%s://%s.%s.svc:%d%s", scheme, ref.Name, ref.Namespace, port, path), nil
+}
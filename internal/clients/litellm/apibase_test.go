@@ -0,0 +1,123 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+func TestAPIBase(t *testing.T) {
+	errBoom := errors.New("boom")
+	port := func(p int32) *int32 { return &p }
+
+	type want struct {
+		apiBase string
+		status  string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason string
+		spec   apisv1alpha1.ProviderConfigSpec
+		status string
+		getErr error
+		want   want
+	}{
+		"APIBase": {
+			reason: "A literal base URL should be used as is, and not recorded in the status.",
+			spec:   apisv1alpha1.ProviderConfigSpec{APIBase: "https://litellm.example.org"},
+			want:   want{apiBase: "https://litellm.example.org"},
+		},
+		"DefaultPort": {
+			reason: "A Service should resolve to its cluster DNS name and first port, and be recorded in the status.",
+			spec: apisv1alpha1.ProviderConfigSpec{APIBaseFrom: &apisv1alpha1.APIBaseSource{
+				ServiceRef: apisv1alpha1.ServiceReference{Name: "litellm", Namespace: "ai"},
+			}},
+			want: want{apiBase: "http://litellm.ai.svc:4000", status: "http://litellm.ai.svc:4000"},
+		},
+		"Port": {
+			reason: "A Service should resolve to the referenced port, scheme and path.",
+			spec: apisv1alpha1.ProviderConfigSpec{APIBaseFrom: &apisv1alpha1.APIBaseSource{
+				ServiceRef: apisv1alpha1.ServiceReference{Name: "litellm", Namespace: "ai", Port: port(443), Scheme: "https", Path: "litellm/"},
+			}},
+			want: want{apiBase: "https://litellm.ai.svc:443/litellm", status: "https://litellm.ai.svc:443/litellm"},
+		},
+		"Unchanged": {
+			reason: "A base URL that is already recorded should not be recorded again.",
+			spec: apisv1alpha1.ProviderConfigSpec{APIBaseFrom: &apisv1alpha1.APIBaseSource{
+				ServiceRef: apisv1alpha1.ServiceReference{Name: "litellm", Namespace: "ai"},
+			}},
+			status: "http://litellm.ai.svc:4000",
+			want:   want{apiBase: "http://litellm.ai.svc:4000", status: "http://litellm.ai.svc:4000"},
+		},
+		"NoSuchPort": {
+			reason: "A port the Service doesn't have should be an error.",
+			spec: apisv1alpha1.ProviderConfigSpec{APIBaseFrom: &apisv1alpha1.APIBaseSource{
+				ServiceRef: apisv1alpha1.ServiceReference{Name: "litellm", Namespace: "ai", Port: port(8080)},
+			}},
+			want: want{err: errors.Errorf(errServicePort, 8080)},
+		},
+		"GetServiceError": {
+			reason: "An error getting the Service should be returned.",
+			spec: apisv1alpha1.ProviderConfigSpec{APIBaseFrom: &apisv1alpha1.APIBaseSource{
+				ServiceRef: apisv1alpha1.ServiceReference{Name: "litellm", Namespace: "ai"},
+			}},
+			getErr: errBoom,
+			want:   want{err: errors.Wrap(errBoom, errGetService)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(tc.getErr, func(obj client.Object) error {
+					svc := obj.(*corev1.Service)
+					svc.Spec.Ports = []corev1.ServicePort{{Name: "http", Port: 4000}, {Name: "https", Port: 443}}
+					return nil
+				}),
+				MockStatusPatch: func(_ context.Context, _ client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					if tc.status != "" {
+						t.Errorf("\n%s\napiBase(...): an unchanged base URL should not be patched", tc.reason)
+					}
+					return nil
+				},
+			}
+			pc := &apisv1alpha1.ProviderConfig{Spec: tc.spec, Status: apisv1alpha1.ProviderConfigStatus{APIBase: tc.status}}
+
+			got, err := apiBase(context.Background(), kube, pc)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\napiBase(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.apiBase, got); diff != "" {
+				t.Errorf("\n%s\napiBase(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, pc.Status.APIBase); diff != "" {
+				t.Errorf("\n%s\napiBase(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
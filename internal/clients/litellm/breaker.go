@@ -0,0 +1,318 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	// breakerThreshold is the number of consecutive connection failures
+	// after which the circuit breaker of an API base opens.
+	breakerThreshold = 5
+
+	// breakerCooldown is how long the circuit breaker stays open before a
+	// single probe request is let through.
+	breakerCooldown = 30 * time.Second
+
+	reasonUnreachable event.Reason = "LiteLLMUnreachable"
+
+	errMarkUnavailable = "cannot mark managed resource as unable to reach LiteLLM"
+)
+
+// breakers are shared by the Clients of all controllers.
+var breakers = newBreakerCache()
+
+// A breakerState is the state of a circuit breaker.
+type breakerState int
+
+// Circuit breaker states.
+const (
+	// breakerClosed lets every request through.
+	breakerClosed breakerState = iota
+
+	// breakerOpen fails every request until the cooldown has passed.
+	breakerOpen
+
+	// breakerHalfOpen has let a single probe request through, and fails
+	// every other request until the probe's outcome is known.
+	breakerHalfOpen
+)
+
+// A breaker is the circuit breaker of an API base. It opens after a number
+// of consecutive connection failures, so that reconciles fail fast rather
+// than wait for a proxy that is down.
+type breaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	// apiBase labels the state metric of the breaker, if not empty.
+	apiBase string
+
+	mu        sync.Mutex
+	state     breakerState
+	failures  int
+	openedAt  time.Time
+	announced bool
+}
+
+func newBreaker(threshold int, cooldown time.Duration) *breaker {
+	return &breaker{threshold: threshold, cooldown: cooldown, now: time.Now}
+}
+
+// allow returns ErrCircuitOpen if a request must not be sent. The first
+// request after the cooldown is let through as a probe.
+func (b *breaker) allow() error {
+	if b == nil {
+		return nil
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		if b.now().Before(b.openedAt.Add(b.cooldown)) {
+			return ErrCircuitOpen
+		}
+		b.set(breakerHalfOpen)
+	case breakerHalfOpen:
+		return ErrCircuitOpen
+	case breakerClosed:
+	}
+	return nil
+}
+
+// record records the outcome of a request that was allowed. Only errors
+// connecting to the proxy count as failures; any response, even an error
+// status, means the proxy is up.
+func (b *breaker) record(err error) {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err == nil {
+		b.set(breakerClosed)
+		b.failures = 0
+		return
+	}
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.set(breakerOpen)
+		b.openedAt = b.now()
+		b.announced = false
+	}
+}
+
+// abandon records that a request that was allowed was cancelled by its
+// caller before its outcome was known. It isn't a failure, but a probe that
+// is abandoned must let another one through.
+func (b *breaker) abandon() {
+	if b == nil {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerHalfOpen {
+		b.set(breakerOpen)
+	}
+}
+
+// set the state of the breaker, and its metric. The caller must hold the
+// lock of the breaker.
+func (b *breaker) set(s breakerState) {
+	b.state = s
+	if b.apiBase != "" {
+		breakerStates.WithLabelValues(b.apiBase).Set(float64(s))
+	}
+}
+
+// backoff returns how long requests will fail fast, and whether they do at
+// all. It doesn't let a probe through.
+func (b *breaker) backoff() (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case breakerOpen:
+		wait := b.openedAt.Add(b.cooldown).Sub(b.now())
+		return wait, wait > 0
+	case breakerHalfOpen:
+		return b.cooldown, true
+	case breakerClosed:
+	}
+	return 0, false
+}
+
+// announce returns true once each time the breaker opens, so that only a
+// single event is emitted.
+func (b *breaker) announce() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == breakerClosed || b.announced {
+		return false
+	}
+	b.announced = true
+	return true
+}
+
+// A breakerCache holds a breaker per API base, so that the ProviderConfigs
+// of the same proxy share one, and a ProviderConfig that is changed to point
+// to another proxy gets that proxy's.
+type breakerCache struct {
+	mu       sync.Mutex
+	breakers map[string]*breaker
+}
+
+func newBreakerCache() *breakerCache {
+	return &breakerCache{breakers: map[string]*breaker{}}
+}
+
+// Get returns the breaker of the supplied API base.
+func (c *breakerCache) Get(apiBase string) *breaker {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if b, ok := c.breakers[apiBase]; ok {
+		return b
+	}
+	b := newBreaker(breakerThreshold, breakerCooldown)
+	if u, err := url.Parse(apiBase); err == nil {
+		b.apiBase = redactURL(u)
+	}
+	b.set(breakerClosed)
+	c.breakers[apiBase] = b
+	return b
+}
+
+// breakerBase returns the API base of the supplied ProviderConfig whose
+// breaker applies to its managed resources, or an empty string if it isn't
+// known yet. The API base of a Service is the one last resolved by a Client.
+func breakerBase(pc *apisv1alpha1.ProviderConfig) string {
+	base := pc.Spec.APIBase
+	if pc.Spec.APIBaseFrom != nil {
+		base = pc.Status.APIBase
+	}
+	if base == "" {
+		return ""
+	}
+	base, err := normalizeAPIBase(base)
+	if err != nil {
+		return ""
+	}
+	return base
+}
+
+// A BreakerReconciler skips reconciling managed resources while the circuit
+// breaker of the API base of their ProviderConfig is open. Instead of every
+// resource reporting the same connection error, a single event is emitted on
+// the ProviderConfig, the resources are marked ProxyUnavailable, and they are
+// requeued once the cooldown has passed.
+type BreakerReconciler struct {
+	kube     client.Client
+	recorder event.Recorder
+	of       resource.ManagedKind
+	inner    reconcile.Reconciler
+	breakers *breakerCache
+}
+
+// NewBreakerReconciler returns a BreakerReconciler that wraps the supplied
+// reconciler of the supplied kind of managed resource.
+func NewBreakerReconciler(kube client.Client, recorder event.Recorder, of resource.ManagedKind, r reconcile.Reconciler) *BreakerReconciler {
+	return &BreakerReconciler{kube: kube, recorder: recorder, of: of, inner: r, breakers: breakers}
+}
+
+// Reconcile the supplied managed resource, unless the circuit breaker of the
+// API base of its ProviderConfig is open. Any error looking up the
+// ProviderConfig is left to the wrapped reconciler to report. A managed
+// resource whose ProviderConfig skips TLS verification is warned about on
+// every reconcile; it is done here because every reconcile of every
+// controller passes through.
+func (r *BreakerReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	mg, pc, err := r.providerConfig(ctx, req)
+	if err != nil || pc == nil {
+		return r.inner.Reconcile(ctx, req)
+	}
+	if InsecureSkipVerify(pc) {
+		r.recorder.Event(mg, event.Warning(reasonInsecure, errors.New(errInsecure)))
+	}
+	base := breakerBase(pc)
+	if base == "" {
+		return r.inner.Reconcile(ctx, req)
+	}
+	b := r.breakers.Get(base)
+	wait, open := b.backoff()
+	if !open {
+		return r.inner.Reconcile(ctx, req)
+	}
+	if b.announce() {
+		r.recorder.Event(pc, event.Warning(reasonUnreachable, ErrCircuitOpen))
+	}
+	// The condition is cleared by the RejectionConnecter once a request
+	// reaches LiteLLM again.
+	if mg.GetCondition(apisv1alpha1.TypeProxyUnavailable).Status != corev1.ConditionTrue {
+		mg.SetConditions(apisv1alpha1.ProxyUnavailable(ErrCircuitOpen.Error()))
+		if err := r.kube.Status().Update(ctx, mg); err != nil {
+			return reconcile.Result{}, errors.Wrap(err, errMarkUnavailable)
+		}
+	}
+	return reconcile.Result{RequeueAfter: wait}, nil
+}
+
+// providerConfig returns the supplied managed resource and its
+// ProviderConfig, or nil if it doesn't reference one.
+func (r *BreakerReconciler) providerConfig(ctx context.Context, req reconcile.Request) (resource.Managed, *apisv1alpha1.ProviderConfig, error) {
+	obj, err := r.kube.Scheme().New(schema.GroupVersionKind(r.of))
+	if err != nil {
+		return nil, nil, err
+	}
+	mg, ok := obj.(resource.Managed)
+	if !ok {
+		return nil, nil, errors.Errorf("%T is not a managed resource", obj)
+	}
+	if err := r.kube.Get(ctx, req.NamespacedName, mg); err != nil {
+		return nil, nil, err
+	}
+	ref := mg.GetProviderConfigReference()
+	if ref == nil {
+		return mg, nil, nil
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return nil, nil, err
+	}
+	return mg, pc, nil
+}
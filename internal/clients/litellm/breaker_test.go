@@ -0,0 +1,373 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+var errConnect = errors.New("connection refused")
+
+func TestBreaker(t *testing.T) {
+	type want struct {
+		state breakerState
+		allow error
+	}
+
+	cases := map[string]struct {
+		reason string
+		steps  func(b *breaker, advance func(time.Duration))
+		want   want
+	}{
+		"ClosedBelowThreshold": {
+			reason: "Fewer consecutive failures than the threshold should leave the breaker closed.",
+			steps: func(b *breaker, _ func(time.Duration)) {
+				b.record(errConnect)
+				b.record(errConnect)
+			},
+			want: want{state: breakerClosed},
+		},
+		"SuccessResetsFailures": {
+			reason: "A success should reset the count of consecutive failures.",
+			steps: func(b *breaker, _ func(time.Duration)) {
+				b.record(errConnect)
+				b.record(errConnect)
+				b.record(nil)
+				b.record(errConnect)
+			},
+			want: want{state: breakerClosed},
+		},
+		"Open": {
+			reason: "Reaching the threshold of consecutive failures should open the breaker.",
+			steps: func(b *breaker, _ func(time.Duration)) {
+				for i := 0; i < 3; i++ {
+					b.record(errConnect)
+				}
+			},
+			want: want{state: breakerOpen, allow: ErrCircuitOpen},
+		},
+		"HalfOpen": {
+			reason: "After the cooldown a single probe should be let through, and every other request refused.",
+			steps: func(b *breaker, advance func(time.Duration)) {
+				for i := 0; i < 3; i++ {
+					b.record(errConnect)
+				}
+				advance(time.Minute)
+				if err := b.allow(); err != nil {
+					t.Errorf("b.allow(): the probe should be allowed, got %v", err)
+				}
+			},
+			want: want{state: breakerHalfOpen, allow: ErrCircuitOpen},
+		},
+		"ProbeSucceeded": {
+			reason: "A successful probe should close the breaker.",
+			steps: func(b *breaker, advance func(time.Duration)) {
+				for i := 0; i < 3; i++ {
+					b.record(errConnect)
+				}
+				advance(time.Minute)
+				_ = b.allow()
+				b.record(nil)
+			},
+			want: want{state: breakerClosed},
+		},
+		"ProbeFailed": {
+			reason: "A failed probe should open the breaker for another cooldown.",
+			steps: func(b *breaker, advance func(time.Duration)) {
+				for i := 0; i < 3; i++ {
+					b.record(errConnect)
+				}
+				advance(time.Minute)
+				_ = b.allow()
+				b.record(errConnect)
+			},
+			want: want{state: breakerOpen, allow: ErrCircuitOpen},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			b := newBreaker(3, time.Minute)
+			b.now = func() time.Time { return now }
+
+			tc.steps(b, func(d time.Duration) { now = now.Add(d) })
+			if diff := cmp.Diff(tc.want.state, b.state); diff != "" {
+				t.Errorf("\n%s\nb.state: -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.allow, b.allow(), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nb.allow(): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestClientBreaker(t *testing.T) {
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		requests.Add(1)
+		// Drop the connection, as a proxy that is going down would.
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		_ = conn.Close()
+	}))
+	defer srv.Close()
+
+	c := NewClient(&Config{APIBase: srv.URL})
+	c.breaker = newBreaker(2, time.Minute)
+
+	for i := 0; i < 2; i++ {
+		if err := c.Post(context.Background(), "/key/info", nil, nil); err == nil || errors.Is(err, ErrCircuitOpen) {
+			t.Fatalf("c.Post(...): want connection error, got %v", err)
+		}
+	}
+	sent := requests.Load()
+	err := c.Post(context.Background(), "/key/info", nil, nil)
+	if diff := cmp.Diff(ErrCircuitOpen, err, test.EquateErrors()); diff != "" {
+		t.Errorf("c.Post(...): -want error, +got error:\n%s", diff)
+	}
+	if requests.Load() != sent {
+		t.Errorf("c.Post(...): no request should be sent while the breaker is open")
+	}
+}
+
+func TestClientBreakerCancelled(t *testing.T) {
+	// The server never answers, so requests only end when they time out or
+	// are cancelled.
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	type want struct {
+		state breakerState
+		allow error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		state   breakerState
+		timeout time.Duration
+		want    want
+	}{
+		"Cancelled": {
+			reason: "A request cancelled by its caller should not count as a connection failure.",
+			state:  breakerClosed,
+			want:   want{state: breakerClosed},
+		},
+		"CancelledProbe": {
+			reason: "A probe cancelled by its caller should let another probe through.",
+			state:  breakerOpen,
+			want:   want{state: breakerOpen},
+		},
+		"TimedOut": {
+			reason:  "A request that exceeds the timeout of the Client should count as a connection failure.",
+			state:   breakerClosed,
+			timeout: 10 * time.Millisecond,
+			want:    want{state: breakerOpen, allow: ErrCircuitOpen},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(&Config{APIBase: srv.URL, Timeout: tc.timeout, Retry: RetryPolicy{MaxAttempts: 1}})
+			c.breaker = newBreaker(1, time.Minute)
+			c.breaker.state = tc.state
+			c.breaker.openedAt = time.Now().Add(-time.Hour)
+
+			ctx := context.Background()
+			if tc.timeout == 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, 10*time.Millisecond)
+				defer cancel()
+			}
+			if err := c.Get(ctx, "/key/info", nil, nil); err == nil {
+				t.Fatalf("\n%s\nc.Get(...): want error, got nil", tc.reason)
+			}
+			if diff := cmp.Diff(tc.want.state, c.breaker.state); diff != "" {
+				t.Errorf("\n%s\nc.Get(...): -want state, +got state:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.allow, c.breaker.allow(), test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nallow(): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// recorder records the events it is sent.
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestBreakerReconciler(t *testing.T) {
+	gvk := schema.GroupVersionKind{Group: "test.litellm.crossplane.io", Version: "v1alpha1", Kind: "Managed"}
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(gvk, &fake.Managed{})
+
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "pc-uid"},
+		Spec:       apisv1alpha1.ProviderConfigSpec{APIBase: "https://litellm.example.com/"},
+	}
+	req := reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool-key"}}
+
+	type want struct {
+		result     reconcile.Result
+		reconciled int
+		events     int
+		conditions []xpv1.Condition
+		updates    int
+	}
+
+	cases := map[string]struct {
+		reason string
+		state  breakerState
+		want   want
+	}{
+		"Closed": {
+			reason: "A closed breaker should let reconciles through.",
+			state:  breakerClosed,
+			want:   want{result: reconcile.Result{Requeue: true}, reconciled: 2},
+		},
+		"Open": {
+			reason: "An open breaker should requeue reconciles after the cooldown, emit a single event, and mark the resource ProxyUnavailable.",
+			state:  breakerOpen,
+			want: want{
+				result:     reconcile.Result{RequeueAfter: time.Minute},
+				events:     1,
+				conditions: []xpv1.Condition{apisv1alpha1.ProxyUnavailable(ErrCircuitOpen.Error())},
+				updates:    1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+			cache := newBreakerCache()
+			b := cache.Get("https://litellm.example.com")
+			b.now = func() time.Time { return now }
+			b.cooldown = time.Minute
+			b.state = tc.state
+			b.openedAt = now
+
+			// The persisted conditions are returned by later gets, so that
+			// the resource is only marked once.
+			var conditions []xpv1.Condition
+			updates := 0
+			kube := &test.MockClient{
+				MockScheme: test.NewMockSchemeFn(s),
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					switch o := obj.(type) {
+					case *fake.Managed:
+						o.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+						o.SetConditions(conditions...)
+					case *apisv1alpha1.ProviderConfig:
+						pc.DeepCopyInto(o)
+					}
+					return nil
+				}),
+				MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+					updates++
+					conditions = obj.(*fake.Managed).Conditions
+					return nil
+				},
+			}
+
+			reconciled := 0
+			inner := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				reconciled++
+				return reconcile.Result{Requeue: true}, nil
+			})
+			rec := &recorder{}
+			r := NewBreakerReconciler(kube, rec, resource.ManagedKind(gvk), inner)
+			r.breakers = cache
+
+			var got reconcile.Result
+			for i := 0; i < 2; i++ {
+				var err error
+				if got, err = r.Reconcile(context.Background(), req); err != nil {
+					t.Fatalf("\n%s\nr.Reconcile(...): %v", tc.reason, err)
+				}
+			}
+			if diff := cmp.Diff(tc.want.result, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reconciled, reconciled); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want reconciles, +got reconciles:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, len(rec.events)); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.conditions, conditions, test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want persisted conditions, +got persisted conditions:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updates, updates); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want status updates, +got status updates:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBreakerCache(t *testing.T) {
+	cache := newBreakerCache()
+	b := cache.Get("https://litellm.example.com")
+	if cache.Get("https://litellm.example.com") != b {
+		t.Errorf("Get(...): ProviderConfigs of the same API base should share a breaker")
+	}
+	if cache.Get("https://other.example.com") == b {
+		t.Errorf("Get(...): ProviderConfigs of another API base should not share a breaker")
+	}
+
+	state := func() float64 {
+		return testutil.ToFloat64(breakerStates.WithLabelValues("https://litellm.example.com"))
+	}
+	if diff := cmp.Diff(float64(breakerClosed), state()); diff != "" {
+		t.Errorf("breakerStates: -want closed, +got:\n%s", diff)
+	}
+	for i := 0; i < breakerThreshold; i++ {
+		b.record(errConnect)
+	}
+	if diff := cmp.Diff(float64(breakerOpen), state()); diff != "" {
+		t.Errorf("breakerStates: -want open, +got:\n%s", diff)
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// responseCaches are shared by the Clients of all controllers.
+var responseCaches = newResponseCacheCache()
+
+// A responseCache holds the responses of recent requests, so that a resource
+// observed again shortly after isn't fetched again.
+type responseCache struct {
+	mu        sync.Mutex
+	ttl       time.Duration
+	now       func() time.Time
+	responses map[string]cachedResponse
+}
+
+type cachedResponse struct {
+	body    json.RawMessage
+	expires time.Time
+}
+
+func newResponseCache(ttl time.Duration) *responseCache {
+	return &responseCache{ttl: ttl, now: time.Now, responses: map[string]cachedResponse{}}
+}
+
+func (c *responseCache) get(key string) (json.RawMessage, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	r, ok := c.responses[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(r.expires) {
+		delete(c.responses, key)
+		return nil, false
+	}
+	return r.body, true
+}
+
+func (c *responseCache) set(key string, body json.RawMessage) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.responses[key] = cachedResponse{body: body, expires: c.now().Add(c.ttl)}
+}
+
+func (c *responseCache) delete(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.responses, key)
+}
+
+// A responseCacheCache holds the response cache of each ProviderConfig that
+// caches responses.
+type responseCacheCache struct {
+	mu     sync.Mutex
+	caches map[types.UID]cachedResponseCache
+}
+
+type cachedResponseCache struct {
+	generation int64
+	cache      *responseCache
+}
+
+func newResponseCacheCache() *responseCacheCache {
+	return &responseCacheCache{caches: map[types.UID]cachedResponseCache{}}
+}
+
+// Get returns the response cache of the supplied ProviderConfig, or nil if
+// it doesn't cache responses. A ProviderConfig that changes gets an empty
+// cache.
+func (c *responseCacheCache) Get(pc *apisv1alpha1.ProviderConfig) *responseCache {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.caches[pc.GetUID()]; ok && cached.generation == pc.GetGeneration() {
+		return cached.cache
+	}
+	var rc *responseCache
+	if ttl := pc.Spec.ObserveCacheTTL; ttl != nil && ttl.Duration > 0 {
+		rc = newResponseCache(ttl.Duration)
+	}
+	c.caches[pc.GetUID()] = cachedResponseCache{generation: pc.GetGeneration(), cache: rc}
+	return rc
+}
+
+// GetCached sends a GET request like Get, but reuses the response cached for
+// the supplied path and key, e.g. the token of a key, if the ProviderConfig
+// caches responses and the cached response hasn't expired. Callers must
+// Invalidate the cached response whenever they change the object.
+func (c *Client) GetCached(ctx context.Context, path, key string, query url.Values, out interface{}) error {
+	if c.cache == nil {
+		return c.Get(ctx, path, query, out)
+	}
+	k := path + "/" + key
+	body, ok := c.cache.get(k)
+	if !ok {
+		if err := c.Get(ctx, path, query, &body); err != nil {
+			return err
+		}
+		c.cache.set(k, body)
+	}
+	if out == nil {
+		return nil
+	}
+	return errors.Wrap(json.Unmarshal(body, out), errDecodeBody)
+}
+
+// Invalidate drops the response cached for the supplied path and key, if
+// any.
+func (c *Client) Invalidate(path, key string) {
+	if c.cache != nil {
+		c.cache.delete(path + "/" + key)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestResponseCache(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	cases := map[string]struct {
+		reason string
+		after  time.Duration
+		want   bool
+	}{
+		"WithinTTL": {
+			reason: "A response should be reused within the TTL.",
+			after:  59 * time.Second,
+			want:   true,
+		},
+		"Expired": {
+			reason: "A response should not be reused once the TTL has passed.",
+			after:  time.Minute,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := newResponseCache(time.Minute)
+			c.now = func() time.Time { return now }
+			c.set("/key/info/tok-1", []byte(`{}`))
+
+			c.now = func() time.Time { return now.Add(tc.after) }
+			_, got := c.get("/key/info/tok-1")
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nget(...): -want cached, +got cached:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// Renamed maps the name of a response field to the other names LiteLLM
+// versions are known to return it under, in order of preference.
+type Renamed map[string][]string
+
+// RenamedSpend are the names LiteLLM returns the spend of a key, team, user,
+// customer, organization or tag under.
+var RenamedSpend = Renamed{"spend": {"spend_usd"}}
+
+// Decode decodes the JSON object data into out. A field that data lacks
+// under its own name is decoded from the first of its renamed names that data
+// has, so that a LiteLLM upgrade renaming a field doesn't break Observe. A
+// warning is logged whenever a renamed field is used.
+func Decode(ctx context.Context, data []byte, out interface{}, renamed Renamed) error {
+	if err := json.Unmarshal(data, out); err != nil {
+		return errors.Wrap(err, errDecodeBody)
+	}
+	if len(renamed) == 0 {
+		return nil
+	}
+
+	fields := map[string]json.RawMessage{}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		// Only objects have fields to rename.
+		return nil //nolint:nilerr // data was already decoded into out.
+	}
+
+	names := make([]string, 0, len(renamed))
+	for name := range renamed {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fallback := map[string]json.RawMessage{}
+	for _, name := range names {
+		if _, ok := fields[name]; ok {
+			continue
+		}
+		for _, alt := range renamed[name] {
+			if v, ok := fields[alt]; ok {
+				log.FromContext(ctx).Info("Warning: LiteLLM returned a field under a fallback name", "field", name, "fallback", alt)
+				fallback[name] = v
+				break
+			}
+		}
+	}
+	if len(fallback) == 0 {
+		return nil
+	}
+	b, err := json.Marshal(fallback)
+	if err != nil {
+		return errors.Wrap(err, errDecodeBody)
+	}
+	return errors.Wrap(json.Unmarshal(b, out), errDecodeBody)
+}
+
+// IsNull returns true if the supplied raw JSON is missing or null, e.g. an
+// info field LiteLLM omitted.
+func IsNull(raw json.RawMessage) bool {
+	return len(raw) == 0 || string(raw) == "null"
+}
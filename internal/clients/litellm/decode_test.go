@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-logr/logr/funcr"
+	"github.com/google/go-cmp/cmp"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+func TestDecode(t *testing.T) {
+	type info struct {
+		Alias string  `json:"alias"`
+		Spend float64 `json:"spend"`
+	}
+
+	cases := map[string]struct {
+		reason string
+		data   string
+		want   info
+		warned bool
+	}{
+		"Current": {
+			reason: "A field returned under its own name should be decoded without a warning.",
+			data:   `{"alias": "acme", "spend": 1.5}`,
+			want:   info{Alias: "acme", Spend: 1.5},
+		},
+		"Renamed": {
+			reason: "A field returned under a renamed name should be decoded into the same field, with a warning.",
+			data:   `{"alias": "acme", "spend_usd": 1.5}`,
+			want:   info{Alias: "acme", Spend: 1.5},
+			warned: true,
+		},
+		"Both": {
+			reason: "A field's own name should win over its renamed names.",
+			data:   `{"alias": "acme", "spend": 1.5, "spend_usd": 2}`,
+			want:   info{Alias: "acme", Spend: 1.5},
+		},
+		"Neither": {
+			reason: "A field returned under no known name should be left unset.",
+			data:   `{"alias": "acme"}`,
+			want:   info{Alias: "acme"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			warned := false
+			ctx := log.IntoContext(context.Background(), funcr.New(func(_, _ string) { warned = true }, funcr.Options{}))
+
+			got := info{}
+			if err := Decode(ctx, []byte(tc.data), &got, RenamedSpend); err != nil {
+				t.Fatalf("Decode(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDecode(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if warned != tc.warned {
+				t.Errorf("\n%s\nDecode(...): want warning %t, got %t", tc.reason, tc.warned, warned)
+			}
+		})
+	}
+}
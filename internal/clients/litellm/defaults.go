@@ -0,0 +1,68 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// pollIntervalTimeout bounds reading the ProviderConfig of a managed resource
+// to compute its poll interval.
+const pollIntervalTimeout = 5 * time.Second
+
+// providerConfigOf returns the ProviderConfig the supplied object uses, or nil
+// if it can't be read.
+func providerConfigOf(ctx context.Context, kube client.Reader, o resource.ProviderConfigReferencer) *apisv1alpha1.ProviderConfig {
+	ref := o.GetProviderConfigReference()
+	if ref == nil {
+		return nil
+	}
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return nil
+	}
+	return pc
+}
+
+// PollIntervalHook returns a hook that polls managed resources at the default
+// poll interval of their ProviderConfig, if it has one. The supplied hooks are
+// then called in order with that interval, so that a poll interval of the
+// managed resource itself wins.
+func PollIntervalHook(kube client.Reader, hooks ...managed.PollIntervalHook) managed.PollIntervalHook {
+	return func(mg resource.Managed, interval time.Duration) time.Duration {
+		// The hook isn't passed a context, so bound the read of the
+		// ProviderConfig with one of its own.
+		ctx, cancel := context.WithTimeout(context.Background(), pollIntervalTimeout)
+		defer cancel()
+		if pc := providerConfigOf(ctx, kube, mg); pc != nil && pc.Spec.DefaultPollInterval != nil && pc.Spec.DefaultPollInterval.Duration > 0 {
+			interval = pc.Spec.DefaultPollInterval.Duration
+		}
+		for _, h := range hooks {
+			interval = h(mg, interval)
+		}
+		return interval
+	}
+}
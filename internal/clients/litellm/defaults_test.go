@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// withSpec returns a client that reads a ProviderConfig with the supplied spec.
+func withSpec(spec apisv1alpha1.ProviderConfigSpec) client.Reader {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, _ client.ObjectKey, obj client.Object) error {
+			if pc, ok := obj.(*apisv1alpha1.ProviderConfig); ok {
+				pc.Spec = spec
+			}
+			return nil
+		},
+	}
+}
+
+func TestPollIntervalHook(t *testing.T) {
+	perResource := func(_ resource.Managed, _ time.Duration) time.Duration { return time.Second }
+
+	cases := map[string]struct {
+		reason string
+		spec   apisv1alpha1.ProviderConfigSpec
+		ref    *xpv1.Reference
+		hooks  []managed.PollIntervalHook
+		want   time.Duration
+	}{
+		"NoDefault": {
+			reason: "Without a default poll interval the poll interval of the provider should be used.",
+			ref:    &xpv1.Reference{Name: "default"},
+			want:   time.Minute,
+		},
+		"NoProviderConfig": {
+			reason: "A managed resource without a ProviderConfig should use the poll interval of the provider.",
+			spec:   apisv1alpha1.ProviderConfigSpec{DefaultPollInterval: &metav1.Duration{Duration: time.Hour}},
+			want:   time.Minute,
+		},
+		"Default": {
+			reason: "The default poll interval of the ProviderConfig should be used.",
+			spec:   apisv1alpha1.ProviderConfigSpec{DefaultPollInterval: &metav1.Duration{Duration: time.Hour}},
+			ref:    &xpv1.Reference{Name: "default"},
+			want:   time.Hour,
+		},
+		"PerResource": {
+			reason: "A poll interval of the managed resource itself should win over the default.",
+			spec:   apisv1alpha1.ProviderConfigSpec{DefaultPollInterval: &metav1.Duration{Duration: time.Hour}},
+			ref:    &xpv1.Reference{Name: "default"},
+			hooks:  []managed.PollIntervalHook{perResource},
+			want:   time.Second,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: tc.ref}}
+			got := PollIntervalHook(withSpec(tc.spec), tc.hooks...)(mg, time.Minute)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nPollIntervalHook(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPollIntervalHookContext(t *testing.T) {
+	// The hook isn't passed a context, so reading the ProviderConfig must not
+	// block the reconcile forever.
+	kube := &test.MockClient{
+		MockGet: func(ctx context.Context, _ client.ObjectKey, _ client.Object) error {
+			if _, ok := ctx.Deadline(); !ok {
+				t.Errorf("PollIntervalHook(...): the ProviderConfig should be read with a deadline")
+			}
+			return nil
+		},
+	}
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+	PollIntervalHook(kube)(mg, time.Minute)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package fake contains a fake LiteLLM proxy for use in tests.
+package fake
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+)
+
+// A Request is a request received by a Server.
+type Request struct {
+	Method string
+	Path   string
+	Query  url.Values
+	Body   map[string]interface{}
+}
+
+// A Response is returned by a Server. A zero Status means 200 OK.
+type Response struct {
+	Status int
+	Body   string
+}
+
+// A Server is a fake LiteLLM proxy. It answers requests using the responses
+// registered for "/path?query", "METHOD /path" or, failing that, "/path",
+// and answers 404 for anything else. Every request it receives is recorded.
+type Server struct {
+	*httptest.Server
+
+	mu        sync.Mutex
+	requests  []Request
+	responses map[string]Response
+}
+
+// NewServer starts a Server that answers with the supplied responses. The
+// caller must Close it.
+func NewServer(responses map[string]Response) *Server {
+	s := &Server{responses: responses}
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serve))
+	return s
+}
+
+func (s *Server) serve(w http.ResponseWriter, r *http.Request) {
+	req := Request{Method: r.Method, Path: r.URL.Path, Query: r.URL.Query()}
+	if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+		_ = json.Unmarshal(b, &req.Body)
+	}
+
+	s.mu.Lock()
+	s.requests = append(s.requests, req)
+	rsp, ok := s.responses[r.URL.Path+"?"+r.URL.RawQuery]
+	if !ok {
+		rsp, ok = s.responses[r.Method+" "+r.URL.Path]
+	}
+	if !ok {
+		rsp, ok = s.responses[r.URL.Path]
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	if rsp.Status != 0 {
+		w.WriteHeader(rsp.Status)
+	}
+	_, _ = w.Write([]byte(rsp.Body))
+}
+
+// Client returns a LiteLLM client that talks to the Server.
+func (s *Server) Client() *litellm.Client {
+	return litellm.NewClient(&litellm.Config{APIBase: s.URL, APIKey: "sk-test"})
+}
+
+// Requests returns all requests received so far.
+func (s *Server) Requests() []Request {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]Request{}, s.requests...)
+}
+
+// Paths returns the path of each request received so far.
+func (s *Server) Paths() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	paths := make([]string, 0, len(s.requests))
+	for _, r := range s.requests {
+		paths = append(paths, r.Path)
+	}
+	return paths
+}
+
+// Body returns the body of the last request received for the supplied path,
+// or nil if there was none.
+func (s *Server) Body(path string) map[string]interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].Path == path {
+			return s.requests[i].Body
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+const errGetHeader = "cannot get value of header %q"
+
+// redacted replaces the values of SecretHeaders wherever they are formatted.
+const redacted = "REDACTED"
+
+// SecretHeaders are headers whose values are read from secrets. Their values
+// are redacted whenever they are formatted or logged.
+type SecretHeaders map[string]string
+
+// String returns the headers with their values redacted.
+func (h SecretHeaders) String() string {
+	names := make([]string, 0, len(h))
+	for n := range h {
+		names = append(names, n+":"+redacted)
+	}
+	sort.Strings(names)
+	return "map[" + strings.Join(names, " ") + "]"
+}
+
+// GoString returns the headers with their values redacted.
+func (h SecretHeaders) GoString() string {
+	return h.String()
+}
+
+// MarshalLog returns the headers with their values redacted, for logr.
+func (h SecretHeaders) MarshalLog() interface{} {
+	m := make(map[string]string, len(h))
+	for n := range h {
+		m[n] = redacted
+	}
+	return m
+}
+
+// getSecretHeaders reads the values of the supplied headers from the secrets
+// they reference.
+func getSecretHeaders(ctx context.Context, kube client.Client, refs map[string]xpv1.SecretKeySelector) (SecretHeaders, error) {
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	h := make(SecretHeaders, len(refs))
+	for name, ref := range refs {
+		ref := ref
+		v, err := resource.CommonCredentialExtractor(ctx, xpv1.CredentialsSourceSecret, kube, xpv1.CommonCredentialSelectors{SecretRef: &ref})
+		if err != nil {
+			return nil, errors.Wrapf(err, errGetHeader, name)
+		}
+		h[name] = strings.TrimSpace(string(v))
+	}
+	return h, nil
+}
+
+// setHeaders sets the supplied headers, and then the secret ones, on the
+// supplied request.
+func setHeaders(req *http.Request, headers map[string]string, secret SecretHeaders) {
+	for n, v := range headers {
+		req.Header.Set(n, v)
+	}
+	for n, v := range secret {
+		req.Header.Set(n, v)
+	}
+}
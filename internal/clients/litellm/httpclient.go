@@ -0,0 +1,114 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"net/http"
+	"net/url"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// httpClients are shared by the Clients of all controllers.
+var httpClients = newHTTPClientCache()
+
+// proxyFromEnvironment returns the proxy of a request that is not sent
+// through an explicitly configured proxy, per HTTPS_PROXY, HTTP_PROXY and
+// NO_PROXY.
+var proxyFromEnvironment = http.ProxyFromEnvironment
+
+// An httpClientCache holds an http.Client per ProviderConfig, so that
+// connections are kept alive and reused across reconciles rather than
+// established, and TLS handshaked, anew by every Client. Clients are keyed by
+// the name of their ProviderConfig, so that only the client of its latest
+// incarnation and generation is kept.
+type httpClientCache struct {
+	mu      sync.Mutex
+	clients map[string]cachedHTTPClient
+}
+
+type cachedHTTPClient struct {
+	uid        types.UID
+	generation int64
+	tlsHash    string
+	client     *http.Client
+}
+
+func newHTTPClientCache() *httpClientCache {
+	return &httpClientCache{clients: map[string]cachedHTTPClient{}}
+}
+
+// ForgetHTTPClient drops the shared http.Client of the supplied
+// ProviderConfig once it has been deleted.
+func ForgetHTTPClient(name string) {
+	httpClients.Forget(name)
+}
+
+// Get returns the http.Client of the supplied ProviderConfig, which uses the
+// supplied TLS configuration and proxy if they are not nil. A new one is
+// created whenever the ProviderConfig or the secrets of its TLS configuration
+// have changed since the cached client was created.
+func (c *httpClientCache) Get(pc *apisv1alpha1.ProviderConfig, t *TLS, proxy *url.URL) *http.Client {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	hash := ""
+	if t != nil {
+		hash = t.Hash
+	}
+	if cached, ok := c.clients[pc.GetName()]; ok {
+		if cached.uid == pc.GetUID() && cached.generation == pc.GetGeneration() && cached.tlsHash == hash {
+			return cached.client
+		}
+		cached.client.CloseIdleConnections()
+	}
+
+	hc := &http.Client{Transport: newTransport(t, proxy)}
+	c.clients[pc.GetName()] = cachedHTTPClient{uid: pc.GetUID(), generation: pc.GetGeneration(), tlsHash: hash, client: hc}
+	return hc
+}
+
+// Forget closes the idle connections of the http.Client of the supplied
+// ProviderConfig, if any, and drops it from the cache.
+func (c *httpClientCache) Forget(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.clients[name]; ok {
+		cached.client.CloseIdleConnections()
+		delete(c.clients, name)
+	}
+}
+
+// newTransport returns a transport that uses the supplied TLS configuration,
+// if it is not nil, and sends requests through the supplied proxy. Requests
+// are sent through the proxy of the environment if the proxy is nil, and are
+// traced if tracing is enabled.
+func newTransport(t *TLS, proxy *url.URL) http.RoundTripper {
+	tr := http.DefaultTransport.(*http.Transport).Clone()
+	if t != nil {
+		tr.TLSClientConfig = t.Config
+	}
+	tr.Proxy = proxyFromEnvironment
+	if proxy != nil {
+		tr.Proxy = http.ProxyURL(proxy)
+	}
+	return traced(tr)
+}
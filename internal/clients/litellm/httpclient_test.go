@@ -0,0 +1,57 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"testing"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+func TestHTTPClientCache(t *testing.T) {
+	pc := func(uid string, generation int64) *apisv1alpha1.ProviderConfig {
+		return &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default", UID: types.UID(uid), Generation: generation}}
+	}
+	cache := newHTTPClientCache()
+
+	first := cache.Get(pc("a", 1), nil, nil)
+	if same := cache.Get(pc("a", 1), nil, nil); same != first {
+		t.Errorf("Get(...): an unchanged ProviderConfig should reuse the http.Client")
+	}
+	changed := cache.Get(pc("a", 2), nil, nil)
+	if changed == first {
+		t.Errorf("Get(...): a changed ProviderConfig should get a new http.Client")
+	}
+	recreated := cache.Get(pc("b", 1), nil, nil)
+	if recreated == changed {
+		t.Errorf("Get(...): a recreated ProviderConfig should get a new http.Client")
+	}
+	if n := len(cache.clients); n != 1 {
+		t.Errorf("Get(...): want only the latest http.Client of a ProviderConfig cached, got %d", n)
+	}
+
+	cache.Forget("default")
+	if n := len(cache.clients); n != 0 {
+		t.Errorf("Forget(...): want no http.Clients cached, got %d", n)
+	}
+	if got := cache.Get(pc("b", 1), nil, nil); got == recreated {
+		t.Errorf("Get(...): a forgotten ProviderConfig should get a new http.Client")
+	}
+}
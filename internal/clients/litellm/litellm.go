@@ -0,0 +1,952 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package litellm contains a minimal client for the LiteLLM proxy management
+// API that is shared by all managed resource controllers.
+package litellm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/version"
+)
+
+const (
+	errGetPC        = "cannot get ProviderConfig"
+	errGetCreds     = "cannot get credentials"
+	errGetMasterKey = "cannot get master key"
+	errNoPCRef      = "managed resource does not reference a ProviderConfig"
+	errUnauthorized = "ProviderConfig %q is unhealthy: %s"
+	errProxyURL     = "cannot parse proxy URL"
+	errAPIBase      = "apiBase must be an absolute http or https URL, got"
+	errMarshalBody  = "cannot marshal request body"
+	errNewRequest   = "cannot create request"
+	errDoRequest    = "cannot send request"
+	errReadResponse = "cannot read response body"
+	errDecodeBody   = "cannot decode response body"
+	errParseTime    = "cannot parse time"
+
+	errGetAccessGroups = "cannot get model access groups"
+
+	// fieldsParam is the query parameter that selects the fields LiteLLM
+	// returns.
+	fieldsParam = "fields"
+
+	// metadataGuardrails is the metadata LiteLLM stores the guardrails of a
+	// key or team in.
+	metadataGuardrails = "guardrails"
+
+	// MetadataModelRPMLimit and MetadataModelTPMLimit are the metadata
+	// LiteLLM stores the per-model limits of a key in.
+	MetadataModelRPMLimit = "model_rpm_limit"
+	MetadataModelTPMLimit = "model_tpm_limit"
+
+	// defaultMaxResponseBytes is the largest response body read from
+	// LiteLLM unless the ProviderConfig sets another limit.
+	defaultMaxResponseBytes = 64 << 20
+)
+
+// Errors returned by a Client for the corresponding LiteLLM responses. Use
+// errors.Is to test for them.
+var (
+	ErrBadRequest   = errors.New("bad request")
+	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound     = errors.New("not found")
+	ErrConflict     = errors.New("already exists")
+	ErrRateLimited  = errors.New("rate limited")
+
+	// ErrResponseTooLarge is returned when the body of a response exceeds
+	// the maximum size the Client reads.
+	ErrResponseTooLarge = errors.New("response too large")
+)
+
+// ErrCircuitOpen is returned instead of sending a request while the circuit
+// breaker of the API base of a Client is open, i.e. after LiteLLM repeatedly
+// could not be reached there.
+var ErrCircuitOpen = errors.New("LiteLLM unreachable, circuit breaker open")
+
+// privilegedPaths are the path prefixes of endpoints that require the master
+// key, i.e. a proxy admin. Everything else, e.g. /key/*, /team/info and
+// /user/info, works with a scoped key.
+var privilegedPaths = []string{
+	"/team/new",
+	"/team/delete",
+	"/team/block",
+	"/team/unblock",
+	"/user/new",
+	"/user/delete",
+	"/invitation/",
+	"/customer/",
+	"/budget/",
+	"/organization/",
+	"/global/",
+	"/config/",
+	"/get/config/",
+	"/model/",
+}
+
+// userAgentProduct identifies this provider in the User-Agent header.
+const userAgentProduct = "crossplane-provider-litellm"
+
+// timeLayouts are the timestamp layouts LiteLLM is known to return. Python's
+// isoformat omits the zone of naive datetimes.
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04:05.999999999",
+}
+
+// Config holds everything required to talk to a LiteLLM proxy.
+type Config struct {
+	// APIBase is the base URL of the LiteLLM proxy.
+	APIBase string
+
+	// APIKey is the key used to authenticate to the LiteLLM proxy.
+	APIKey string
+
+	// TokenSource supplies the bearer token used to authenticate to the
+	// LiteLLM proxy instead of the APIKey, if set.
+	TokenSource TokenSource
+
+	// MasterKey is used instead of the APIKey for endpoints that require the
+	// master key, if set.
+	MasterKey string
+
+	// UserAgent is sent with every request to the LiteLLM proxy.
+	UserAgent string
+
+	// FieldSelection requests only the fields that are decoded from
+	// endpoints that support it.
+	FieldSelection bool
+
+	// TLS configures TLS connections to the LiteLLM proxy, if set.
+	TLS *TLS
+
+	// Proxy is the proxy requests are sent through. The proxy of the
+	// environment, if any, is used if it is nil.
+	Proxy *url.URL
+
+	// Timeout bounds every request to the LiteLLM proxy, if not zero.
+	Timeout time.Duration
+
+	// MaxResponseBytes is the largest response body read from the LiteLLM
+	// proxy. A default limit applies if it is not positive.
+	MaxResponseBytes int64
+
+	// Retry configures how failed requests are retried.
+	Retry RetryPolicy
+
+	// Headers are sent with every request to the LiteLLM proxy.
+	Headers map[string]string
+
+	// SecretHeaders are sent with every request to the LiteLLM proxy, like
+	// Headers, but their values are never logged.
+	SecretHeaders SecretHeaders
+
+	// ProviderConfig is the ProviderConfig the configuration was read from.
+	ProviderConfig *apisv1alpha1.ProviderConfig
+}
+
+// GetConfig reads the ProviderConfig referenced by the supplied managed
+// resource and extracts the settings required to talk to LiteLLM.
+func GetConfig(ctx context.Context, kube client.Client, mg resource.Managed) (*Config, error) {
+	ref := mg.GetProviderConfigReference()
+	if ref == nil {
+		return nil, errors.New(errNoPCRef)
+	}
+
+	pc := &apisv1alpha1.ProviderConfig{}
+	if err := kube.Get(ctx, types.NamespacedName{Name: ref.Name}, pc); err != nil {
+		return nil, errors.Wrap(err, errGetPC)
+	}
+
+	// Every request would be rejected with the credentials the health check
+	// found LiteLLM rejects, so say so rather than send them.
+	if c := pc.Status.GetCondition(apisv1alpha1.TypeHealthy); c.Status == corev1.ConditionFalse && c.Reason == apisv1alpha1.ReasonUnauthorized {
+		return nil, errors.Errorf(errUnauthorized, pc.GetName(), c.Message)
+	}
+
+	return ConfigFor(ctx, kube, pc)
+}
+
+// ConfigFor extracts the settings required to talk to LiteLLM from the
+// supplied ProviderConfig.
+func ConfigFor(ctx context.Context, kube client.Client, pc *apisv1alpha1.ProviderConfig) (*Config, error) {
+	cd := pc.Spec.Credentials
+	var data []byte
+	var ts TokenSource
+	var err error
+	if cd.Source == apisv1alpha1.CredentialsSourceServiceAccountToken {
+		ts, err = tokenSources.Get(kube, pc)
+	} else {
+		data, err = resource.CommonCredentialExtractor(ctx, cd.Source, kube, cd.CommonCredentialSelectors)
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, errGetCreds)
+	}
+
+	var masterKey []byte
+	if ref := pc.Spec.MasterKeyRef; ref != nil {
+		masterKey, err = resource.CommonCredentialExtractor(ctx, xpv1.CredentialsSourceSecret, kube, xpv1.CommonCredentialSelectors{SecretRef: ref})
+		if err != nil {
+			return nil, errors.Wrap(err, errGetMasterKey)
+		}
+	}
+
+	base, err := apiBase(ctx, kube, pc)
+	if err != nil {
+		return nil, err
+	}
+	base, err = normalizeAPIBase(base)
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := getTLS(ctx, kube, tlsConfig(pc))
+	if err != nil {
+		return nil, err
+	}
+
+	var proxy *url.URL
+	if pc.Spec.ProxyURL != "" {
+		proxy, err = url.Parse(pc.Spec.ProxyURL)
+		if err != nil || proxy.Scheme == "" || proxy.Host == "" {
+			return nil, errors.Errorf("%s %q", errProxyURL, pc.Spec.ProxyURL)
+		}
+	}
+
+	sh, err := getSecretHeaders(ctx, kube, pc.Spec.HeaderSecretRefs)
+	if err != nil {
+		return nil, err
+	}
+
+	var timeout time.Duration
+	if pc.Spec.Timeout != nil {
+		timeout = pc.Spec.Timeout.Duration
+	}
+
+	var maxResponseBytes int64
+	if pc.Spec.MaxResponseBytes != nil {
+		maxResponseBytes = *pc.Spec.MaxResponseBytes
+	}
+
+	return &Config{
+		APIBase:          base,
+		APIKey:           strings.TrimSpace(string(data)),
+		TokenSource:      ts,
+		MasterKey:        strings.TrimSpace(string(masterKey)),
+		UserAgent:        UserAgent(pc.Spec.UserAgentSuffix),
+		FieldSelection:   pc.Spec.FieldSelection,
+		TLS:              t,
+		Proxy:            proxy,
+		Timeout:          timeout,
+		MaxResponseBytes: maxResponseBytes,
+		Retry:            retryPolicy(pc.Spec.Retry),
+		Headers:          pc.Spec.Headers,
+		SecretHeaders:    sh,
+		ProviderConfig:   pc,
+	}, nil
+}
+
+// normalizeAPIBase returns the supplied base URL of the LiteLLM API without
+// trailing slashes, so that paths are joined to it, including any path
+// prefix it has, the same way whether or not it ends in a slash.
+func normalizeAPIBase(base string) (string, error) {
+	u, err := url.Parse(base)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", errors.Errorf("%s %q", errAPIBase, base)
+	}
+	u.Path = strings.TrimRight(u.Path, "/")
+	u.RawPath = strings.TrimRight(u.RawPath, "/")
+	return u.String(), nil
+}
+
+// UserAgent returns the User-Agent of this provider, followed by the supplied
+// suffix if it is not empty.
+func UserAgent(suffix string) string {
+	ua := userAgentProduct + "/" + version.Version
+	if suffix != "" {
+		ua += " " + suffix
+	}
+	return ua
+}
+
+// A Client sends requests to the LiteLLM proxy management API.
+type Client struct {
+	apiBase   string
+	token     TokenSource
+	masterKey string
+	userAgent string
+	http      *http.Client
+	breaker   *breaker
+	limiter   *rate.Limiter
+	cache     *responseCache
+	timeout   time.Duration
+	maxBytes  int64
+	retry     RetryPolicy
+	headers   map[string]string
+	secret    SecretHeaders
+
+	fieldSelection bool
+}
+
+// NewClient returns a Client for the supplied configuration.
+func NewClient(cfg *Config) *Client {
+	ua := cfg.UserAgent
+	if ua == "" {
+		ua = UserAgent("")
+	}
+	var ts TokenSource = StaticToken(cfg.APIKey)
+	if cfg.TokenSource != nil {
+		ts = cfg.TokenSource
+	}
+	maxBytes := cfg.MaxResponseBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxResponseBytes
+	}
+	hc := &http.Client{Transport: newTransport(cfg.TLS, cfg.Proxy)}
+	var b *breaker
+	var l *rate.Limiter
+	var rc *responseCache
+	if cfg.ProviderConfig != nil {
+		hc = httpClients.Get(cfg.ProviderConfig, cfg.TLS, cfg.Proxy)
+		b = breakers.Get(cfg.APIBase)
+		l = limiters.Get(cfg.ProviderConfig)
+		rc = responseCaches.Get(cfg.ProviderConfig)
+	}
+	return &Client{
+		apiBase:   cfg.APIBase,
+		token:     ts,
+		masterKey: cfg.MasterKey,
+		userAgent: ua,
+		http:      hc,
+		breaker:   b,
+		limiter:   l,
+		cache:     rc,
+		timeout:   cfg.Timeout,
+		maxBytes:  maxBytes,
+		retry:     cfg.Retry,
+		headers:   cfg.Headers,
+		secret:    cfg.SecretHeaders,
+
+		fieldSelection: cfg.FieldSelection,
+	}
+}
+
+// WithFields returns the supplied query with the JSON fields of the supplied
+// struct added as the fields parameter, if field selection is enabled. LiteLLM
+// then returns only those fields; versions that don't support field selection
+// ignore the parameter.
+func (c *Client) WithFields(query url.Values, v interface{}) url.Values {
+	if !c.fieldSelection {
+		return query
+	}
+	var fields []string
+	t := reflect.TypeOf(v)
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			fields = append(fields, name)
+		}
+	}
+	q := url.Values{}
+	for k, v := range query {
+		q[k] = v
+	}
+	q.Set(fieldsParam, strings.Join(fields, ","))
+	return q
+}
+
+// Get sends a GET request to the supplied path and decodes the response into
+// out, if it is not nil.
+func (c *Client) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	return c.Do(ctx, http.MethodGet, path, query, nil, out)
+}
+
+// Post sends a POST request with the supplied JSON body to the supplied path
+// and decodes the response into out, if it is not nil.
+func (c *Client) Post(ctx context.Context, path string, in, out interface{}) error {
+	return c.Do(ctx, http.MethodPost, path, nil, in, out)
+}
+
+// Do sends a request to the LiteLLM proxy. The body in is encoded as JSON if
+// it is not nil, and the response is decoded into out if it is not nil. Any
+// non-2xx response is returned as an error. Idempotent requests that fail
+// transiently are retried per the RetryPolicy of the Client.
+func (c *Client) Do(ctx context.Context, method, path string, query url.Values, in, out interface{}) error {
+	var body []byte
+	if in != nil {
+		b, err := json.Marshal(in)
+		if err != nil {
+			return errors.Wrap(err, errMarshalBody)
+		}
+		body = b
+	}
+
+	// The path may carry a query of its own, e.g. that of a RawRequest.
+	p, q, _ := strings.Cut(path, "?")
+	u, err := url.JoinPath(c.apiBase, p)
+	if err != nil {
+		return errors.Wrap(err, errNewRequest)
+	}
+	if len(query) > 0 {
+		if q != "" {
+			q += "&"
+		}
+		q += query.Encode()
+	}
+	if q != "" {
+		u += "?" + q
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, nil)
+	if err != nil {
+		return errors.Wrap(err, errNewRequest)
+	}
+	key, err := c.keyFor(ctx, path)
+	if err != nil {
+		return errors.Wrap(err, errGetToken)
+	}
+	setHeaders(req, c.headers, c.secret)
+	req.Header.Set("Authorization", "Bearer "+key)
+	req.Header.Set("User-Agent", c.userAgent)
+	if in != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	for attempt := 0; ; attempt++ {
+		if c.limiter != nil {
+			if err := c.limiter.Wait(ctx); err != nil {
+				return errors.Wrap(err, errThrottle)
+			}
+		}
+		var code int
+		code, err = c.send(ctx, req, path, body, out)
+		if !idempotent(method) || !c.retry.retry(ctx, attempt, code, err) {
+			break
+		}
+	}
+	return err
+}
+
+// send sends a single attempt of the supplied request, with the supplied
+// body, decodes the body of a 2xx response into out, if not nil, and returns
+// the status code of the response. The status code is 0 if no response was
+// received. Any non-2xx response is returned as an error. The body of the
+// response is decoded as it is read, so that large responses, e.g. those of
+// list endpoints, aren't buffered in full, and is never read beyond the
+// maximum response size of the Client.
+func (c *Client) send(ctx context.Context, req *http.Request, path string, body []byte, out interface{}) (int, error) {
+	if err := c.breaker.allow(); err != nil {
+		return 0, err
+	}
+	caller := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	req = req.Clone(withEndpoint(ctx, path))
+	if body != nil {
+		req.Body = io.NopCloser(bytes.NewReader(body))
+		req.ContentLength = int64(len(body))
+	}
+
+	start := time.Now()
+	resp, err := c.http.Do(req)
+	if err != nil && caller.Err() != nil {
+		// The caller gave up on the request, e.g. because its reconcile
+		// timed out, which says nothing about the proxy.
+		c.breaker.abandon()
+	} else {
+		c.breaker.record(err)
+	}
+	if err != nil {
+		d := time.Since(start)
+		observeRequest(ctx, req.Method, path, 0, d)
+		logRequest(ctx, req.Method, req.URL, 0, d, err)
+		return 0, errors.Wrap(err, errDoRequest)
+	}
+	d := time.Since(start)
+	observeRequest(ctx, req.Method, path, resp.StatusCode, d)
+	logRequest(ctx, req.Method, req.URL, resp.StatusCode, d, nil)
+
+	r := http.MaxBytesReader(nil, resp.Body, c.maxBytes)
+	defer func() {
+		// Drain what is left of the body, so that the connection is reused.
+		_, _ = io.Copy(io.Discard, r)
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// The body of an error is only reported, so a truncated one will do.
+		b, err := io.ReadAll(r)
+		if err != nil && !isTooLarge(err) {
+			return 0, errors.Wrap(err, errReadResponse)
+		}
+		ae := newAPIError(req.Method, path, resp.StatusCode, b)
+		if resp.StatusCode == http.StatusTooManyRequests {
+			ae.retryAfter = parseRetryAfter(resp.Header.Get("Retry-After"), time.Now())
+		}
+		return resp.StatusCode, ae
+	}
+
+	if out == nil {
+		return resp.StatusCode, nil
+	}
+	err = json.NewDecoder(r).Decode(out)
+	switch {
+	case err == nil, errors.Is(err, io.EOF):
+		// An empty body leaves out as it is.
+		return resp.StatusCode, nil
+	case isTooLarge(err):
+		return resp.StatusCode, errors.Wrapf(ErrResponseTooLarge, "%s %s returned more than %d bytes", req.Method, path, c.maxBytes)
+	default:
+		return resp.StatusCode, errors.Wrap(err, errDecodeBody)
+	}
+}
+
+// isTooLarge returns true if the supplied error was returned because a
+// response exceeded the maximum response size.
+func isTooLarge(err error) bool {
+	var mbe *http.MaxBytesError
+	return errors.As(err, &mbe)
+}
+
+// keyFor returns the key to authenticate requests to the supplied path with.
+func (c *Client) keyFor(ctx context.Context, path string) (string, error) {
+	if c.masterKey != "" {
+		for _, p := range privilegedPaths {
+			if strings.HasPrefix(path, p) {
+				return c.masterKey, nil
+			}
+		}
+	}
+	return c.token.Token(ctx)
+}
+
+// An APIError is returned when LiteLLM responds with a non-2xx status.
+type APIError struct {
+	// Method and Path of the request.
+	Method string
+	Path   string
+
+	// StatusCode of the response.
+	StatusCode int
+
+	// Type, Message and Param are read from the error LiteLLM returned, if
+	// it returned one in a format the Client knows. The Message defaults
+	// to the Body.
+	Type    string
+	Message string
+	Param   string
+
+	// Body of the response, possibly truncated.
+	Body string
+
+	// retryAfter is how long LiteLLM asked to wait before retrying a rate
+	// limited request.
+	retryAfter time.Duration
+}
+
+// newAPIError returns the APIError of a response to the supplied request with
+// the supplied status code and body. LiteLLM returns errors as
+// {"error": "..."}, in the OpenAI format {"error": {"message": "...",
+// "type": "...", "param": "..."}}, or as FastAPI's {"detail": "..."}.
+func newAPIError(method, path string, code int, body []byte) *APIError {
+	e := &APIError{Method: method, Path: path, StatusCode: code, Body: strings.TrimSpace(string(body))}
+	e.Message = e.Body
+
+	rsp := struct {
+		Error  json.RawMessage `json:"error"`
+		Detail json.RawMessage `json:"detail"`
+	}{}
+	if err := json.Unmarshal(body, &rsp); err != nil {
+		return e
+	}
+	for _, raw := range []json.RawMessage{rsp.Error, rsp.Detail} {
+		var msg string
+		if err := json.Unmarshal(raw, &msg); err == nil && msg != "" {
+			e.Message = msg
+			return e
+		}
+		detail := struct {
+			Message string `json:"message"`
+			Type    string `json:"type"`
+			Param   string `json:"param"`
+		}{}
+		if err := json.Unmarshal(raw, &detail); err == nil && detail.Message != "" {
+			e.Message, e.Type, e.Param = detail.Message, detail.Type, detail.Param
+			return e
+		}
+	}
+	return e
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("%s %s returned unexpected status %d: %s", e.Method, e.Path, e.StatusCode, e.Body)
+}
+
+// Is maps the status code of the error to the matching sentinel error. Some
+// endpoints, e.g. /customer/info, answer 400 rather than 404 for objects that
+// don't exist, and some answer 400 rather than 409 for objects that already
+// exist, so those are also treated as ErrNotFound and ErrConflict.
+func (e *APIError) Is(target error) bool {
+	switch target { //nolint:errorlint // Comparing against our own sentinels.
+	case ErrBadRequest:
+		return e.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound ||
+			e.StatusCode == http.StatusBadRequest && strings.Contains(e.Body, "does not exist")
+	case ErrConflict:
+		return e.StatusCode == http.StatusConflict ||
+			e.StatusCode == http.StatusBadRequest && strings.Contains(strings.ToLower(e.Body), "already exists")
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	}
+	return false
+}
+
+// IsNotFound returns true if the supplied error indicates that LiteLLM could
+// not find the requested object.
+func IsNotFound(err error) bool {
+	return errors.Is(err, ErrNotFound)
+}
+
+// IsConflict returns true if the supplied error indicates that the object
+// LiteLLM was asked to create already exists.
+func IsConflict(err error) bool {
+	return errors.Is(err, ErrConflict)
+}
+
+// IsAuthError returns true if the supplied error indicates that LiteLLM
+// rejected the credentials of a request, or didn't allow them to make it.
+func IsAuthError(err error) bool {
+	return errors.Is(err, ErrUnauthorized)
+}
+
+// IsRetryable returns true if the supplied error is transient, i.e. the
+// request failed to reach LiteLLM, or got a 5xx, request timeout or rate
+// limited response. Requests aren't sent while the circuit breaker is open,
+// so those aren't retryable.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, ErrCircuitOpen) {
+		return false
+	}
+	var ae *APIError
+	if !errors.As(err, &ae) {
+		return true
+	}
+	return ae.StatusCode >= http.StatusInternalServerError ||
+		ae.StatusCode == http.StatusRequestTimeout ||
+		ae.StatusCode == http.StatusTooManyRequests
+}
+
+// IsRejected returns true if the supplied error indicates that LiteLLM
+// rejected a request in a way retrying won't fix, i.e. with a 4xx status
+// other than not found, request timeout or rate limited. Transient errors,
+// e.g. a 503, are not rejections. Neither are auth errors, which are fixed by
+// the ProviderConfig's credentials rather than the spec of the resource.
+func IsRejected(err error) bool {
+	var ae *APIError
+	if !errors.As(err, &ae) || ae.StatusCode < 400 || ae.StatusCode > 499 {
+		return false
+	}
+	return !IsNotFound(err) && !IsRetryable(err) && !IsAuthError(err)
+}
+
+// ParseTime parses a timestamp returned by LiteLLM. Timestamps without a zone
+// are assumed to be UTC.
+func ParseTime(s string) (time.Time, error) {
+	for _, l := range timeLayouts {
+		if t, err := time.Parse(l, s); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, errors.Errorf("%s %q", errParseTime, s)
+}
+
+// MergeMetadata returns the observed metadata of a key or team with the
+// desired metadata applied on top of it. LiteLLM replaces the whole metadata
+// object on update, so it is merged to keep the keys that aren't managed.
+func MergeMetadata(observed, desired map[string]interface{}) map[string]interface{} {
+	merged := make(map[string]interface{}, len(observed)+len(desired))
+	for k, v := range observed {
+		merged[k] = v
+	}
+	for k, v := range desired {
+		merged[k] = v
+	}
+	return merged
+}
+
+// SameStrings returns true if a and b contain the same strings, regardless of
+// order.
+func SameStrings(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	as, bs := append([]string{}, a...), append([]string{}, b...)
+	sort.Strings(as)
+	sort.Strings(bs)
+	for i := range as {
+		if as[i] != bs[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// SameValue returns true if the observed JSON value matches the desired one.
+// Only the keys of desired objects are compared, because LiteLLM adds its own
+// defaults, and numbers are compared by value, because LiteLLM may return an
+// integer as a float.
+func SameValue(want, got interface{}) bool {
+	switch w := want.(type) {
+	case map[string]interface{}:
+		g, ok := got.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		for k, v := range w {
+			if !SameValue(v, g[k]) {
+				return false
+			}
+		}
+		return true
+	case []interface{}:
+		g, ok := got.([]interface{})
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !SameValue(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	}
+	if wf, ok := toFloat(want); ok {
+		gf, ok := toFloat(got)
+		return ok && wf == gf
+	}
+	return reflect.DeepEqual(want, got)
+}
+
+// toFloat returns the supplied JSON number as a float64.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case json.Number:
+		f, err := n.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// SameModels returns true if the desired and observed model lists allow the
+// same models. LiteLLM may expand a model access group into its models, so
+// access groups on either side are expanded before comparing, which requires
+// looking them up if the lists differ.
+func (c *Client) SameModels(ctx context.Context, desired, observed []string) (bool, error) {
+	if SameStrings(desired, observed) {
+		return true, nil
+	}
+	groups, err := c.accessGroups(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, errGetAccessGroups)
+	}
+	d, o := expandModels(desired, groups), expandModels(observed, groups)
+	if len(d) != len(o) {
+		return false, nil
+	}
+	for m := range d {
+		if !o[m] {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// accessGroups returns the model names of each model access group.
+func (c *Client) accessGroups(ctx context.Context) (map[string][]string, error) {
+	var rsp struct {
+		Data []struct {
+			ModelName string `json:"model_name"`
+			ModelInfo struct {
+				AccessGroups []string `json:"access_groups"`
+			} `json:"model_info"`
+		} `json:"data"`
+	}
+	if err := c.Get(ctx, "/model/info", nil, &rsp); err != nil {
+		return nil, err
+	}
+	groups := map[string][]string{}
+	for _, m := range rsp.Data {
+		for _, g := range m.ModelInfo.AccessGroups {
+			groups[g] = append(groups[g], m.ModelName)
+		}
+	}
+	return groups, nil
+}
+
+// expandModels returns the set of the supplied models, with access groups
+// replaced by their models.
+func expandModels(models []string, groups map[string][]string) map[string]bool {
+	set := map[string]bool{}
+	for _, m := range models {
+		if g, ok := groups[m]; ok {
+			for _, gm := range g {
+				set[gm] = true
+			}
+			continue
+		}
+		set[m] = true
+	}
+	return set
+}
+
+// Guardrails returns the guardrails in the supplied metadata of a key or
+// team. LiteLLM accepts guardrails as a field of their own, but stores them
+// in the metadata.
+func Guardrails(md map[string]interface{}) []string {
+	l, ok := md[metadataGuardrails].([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(l))
+	for _, g := range l {
+		if s, ok := g.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// ModelLimits returns the per-model limits stored under the supplied field,
+// e.g. MetadataModelRPMLimit, of the supplied metadata of a key. LiteLLM
+// accepts them as fields of their own, but stores them in the metadata.
+func ModelLimits(md map[string]interface{}, field string) map[string]int64 {
+	m, ok := md[field].(map[string]interface{})
+	if !ok || len(m) == 0 {
+		return nil
+	}
+	out := make(map[string]int64, len(m))
+	for model, v := range m {
+		if n, ok := v.(float64); ok {
+			out[model] = int64(n)
+		}
+	}
+	return out
+}
+
+// ObjectPermission returns the object_permission of a key or team granting
+// the supplied vector stores, MCP servers and MCP access groups. Lists that
+// are nil are omitted and left as they are.
+func ObjectPermission(vectorStores, mcpServers, mcpAccessGroups []string) map[string][]string {
+	p := map[string][]string{}
+	for k, v := range map[string][]string{
+		"vector_stores":     vectorStores,
+		"mcp_servers":       mcpServers,
+		"mcp_access_groups": mcpAccessGroups,
+	} {
+		if v != nil {
+			p[k] = v
+		}
+	}
+	return p
+}
+
+// SameObjectPermission returns true if the observed object_permission of a
+// key or team grants the lists of the desired one.
+func SameObjectPermission(desired map[string][]string, observed map[string]json.RawMessage) bool {
+	for k, want := range desired {
+		var got []string
+		if raw, ok := observed[k]; ok {
+			if err := json.Unmarshal(raw, &got); err != nil {
+				return false
+			}
+		}
+		if !SameStrings(want, got) {
+			return false
+		}
+	}
+	return true
+}
+
+// ParseModelMaxBudget parses a model_max_budget map returned by LiteLLM.
+// Older LiteLLM versions map each model straight to its budget, while newer
+// ones map it to an object holding the budget_limit, or the max_budget for
+// budget objects.
+func ParseModelMaxBudget(in map[string]json.RawMessage) map[string]float64 {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make(map[string]float64, len(in))
+	for m, raw := range in {
+		var budget float64
+		if err := json.Unmarshal(raw, &budget); err == nil {
+			out[m] = budget
+			continue
+		}
+		var obj struct {
+			BudgetLimit *float64 `json:"budget_limit"`
+			MaxBudget   *float64 `json:"max_budget"`
+		}
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			continue
+		}
+		switch {
+		case obj.BudgetLimit != nil:
+			out[m] = *obj.BudgetLimit
+		case obj.MaxBudget != nil:
+			out[m] = *obj.MaxBudget
+		default:
+			out[m] = 0
+		}
+	}
+	return out
+}
@@ -0,0 +1,684 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/version"
+)
+
+func TestUserAgent(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cfg    *Config
+		want   string
+	}{
+		"Default": {
+			reason: "Requests should identify the provider and its version by default.",
+			cfg:    &Config{},
+			want:   "crossplane-provider-litellm/" + version.Version,
+		},
+		"Suffix": {
+			reason: "The User-Agent suffix of the ProviderConfig should follow the version.",
+			cfg:    &Config{UserAgent: UserAgent("team-a")},
+			want:   "crossplane-provider-litellm/" + version.Version + " team-a",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("User-Agent")
+			}))
+			defer srv.Close()
+
+			tc.cfg.APIBase = srv.URL
+			if err := NewClient(tc.cfg).Get(context.Background(), "/health", nil, nil); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want User-Agent, +got User-Agent:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestStatusErrors(t *testing.T) {
+	sentinels := []error{ErrBadRequest, ErrUnauthorized, ErrNotFound, ErrRateLimited}
+
+	cases := map[string]struct {
+		reason string
+		status int
+		body   string
+		want   []error
+	}{
+		"BadRequest": {
+			reason: "A 400 should be ErrBadRequest.",
+			status: http.StatusBadRequest,
+			body:   `{"error": "invalid max_budget"}`,
+			want:   []error{ErrBadRequest},
+		},
+		"DoesNotExist": {
+			reason: "A 400 for an object that does not exist should be both ErrBadRequest and ErrNotFound.",
+			status: http.StatusBadRequest,
+			body:   `{"error": "End User Id=acme does not exist in db"}`,
+			want:   []error{ErrBadRequest, ErrNotFound},
+		},
+		"Unauthorized": {
+			reason: "A 401 should be ErrUnauthorized.",
+			status: http.StatusUnauthorized,
+			want:   []error{ErrUnauthorized},
+		},
+		"Forbidden": {
+			reason: "A 403 should be ErrUnauthorized.",
+			status: http.StatusForbidden,
+			want:   []error{ErrUnauthorized},
+		},
+		"NotFound": {
+			reason: "A 404 should be ErrNotFound.",
+			status: http.StatusNotFound,
+			want:   []error{ErrNotFound},
+		},
+		"RateLimited": {
+			reason: "A 429 should be ErrRateLimited.",
+			status: http.StatusTooManyRequests,
+			want:   []error{ErrRateLimited},
+		},
+		"ServerError": {
+			reason: "A 500 should not match any sentinel.",
+			status: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(tc.status)
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			err := NewClient(&Config{APIBase: srv.URL}).Get(context.Background(), "/key/info", nil, nil)
+			if err == nil {
+				t.Fatalf("\n%s\nGet(...): want error, got nil", tc.reason)
+			}
+			// Callers see the error wrapped with their own context.
+			err = errors.Wrap(err, "cannot get key")
+
+			var got []error
+			for _, s := range sentinels {
+				if errors.Is(err, s) {
+					got = append(got, s)
+				}
+			}
+			if diff := cmp.Diff(tc.want, got, cmpopts.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want sentinels, +got sentinels:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSameModels(t *testing.T) {
+	modelInfo := `{"data": [
+		{"model_name": "gpt-4o", "model_info": {"access_groups": ["prod-models"]}},
+		{"model_name": "claude-sonnet", "model_info": {"access_groups": ["prod-models", "anthropic"]}},
+		{"model_name": "openai/*", "model_info": {}}
+	]}`
+
+	cases := map[string]struct {
+		reason   string
+		desired  []string
+		observed []string
+		want     bool
+		lookups  int
+	}{
+		"Same": {
+			reason:   "Identical lists should be the same without looking up access groups.",
+			desired:  []string{"openai/*", "gpt-4o"},
+			observed: []string{"gpt-4o", "openai/*"},
+			want:     true,
+		},
+		"Expanded": {
+			reason:   "An access group expanded into its models should be the same.",
+			desired:  []string{"prod-models", "openai/*"},
+			observed: []string{"gpt-4o", "claude-sonnet", "openai/*"},
+			want:     true,
+			lookups:  1,
+		},
+		"PartlyExpanded": {
+			reason:   "Overlapping access groups should be the same as their combined models.",
+			desired:  []string{"prod-models", "anthropic"},
+			observed: []string{"gpt-4o", "claude-sonnet"},
+			want:     true,
+			lookups:  1,
+		},
+		"Different": {
+			reason:   "An access group is not the same as only some of its models.",
+			desired:  []string{"prod-models"},
+			observed: []string{"gpt-4o"},
+			want:     false,
+			lookups:  1,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			lookups := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				lookups++
+				_, _ = w.Write([]byte(modelInfo))
+			}))
+			defer srv.Close()
+
+			c := NewClient(&Config{APIBase: srv.URL, APIKey: "sk-team"})
+			got, err := c.SameModels(context.Background(), tc.desired, tc.observed)
+			if err != nil {
+				t.Fatalf("\n%s\nSameModels(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nSameModels(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.lookups, lookups); diff != "" {
+				t.Errorf("\n%s\nSameModels(...): -want lookups, +got lookups:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAuthScope(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		masterKey string
+		method    string
+		path      string
+		want      string
+	}{
+		"TeamCreateUsesMasterKey": {
+			reason:    "Creating a team requires the master key.",
+			masterKey: "sk-master",
+			method:    http.MethodPost,
+			path:      "/team/new",
+			want:      "Bearer sk-master",
+		},
+		"KeyInfoUsesScopedKey": {
+			reason:    "Reading a key works with the scoped key.",
+			masterKey: "sk-master",
+			method:    http.MethodGet,
+			path:      "/key/info",
+			want:      "Bearer sk-team",
+		},
+		"NoMasterKey": {
+			reason: "Without a master key the scoped key should be used for everything.",
+			method: http.MethodPost,
+			path:   "/team/new",
+			want:   "Bearer sk-team",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var got string
+			srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+				got = r.Header.Get("Authorization")
+			}))
+			defer srv.Close()
+
+			c := NewClient(&Config{APIBase: srv.URL, APIKey: "sk-team", MasterKey: tc.masterKey})
+			if err := c.Do(context.Background(), tc.method, tc.path, nil, nil, nil); err != nil {
+				t.Fatalf("\n%s\nDo(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want Authorization, +got Authorization:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHTTPClientReuse(t *testing.T) {
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "pc-uid", Generation: 1},
+		Spec: apisv1alpha1.ProviderConfigSpec{
+			APIBase: "http://litellm",
+			Credentials: apisv1alpha1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "creds"}, Key: "key"},
+				},
+			},
+		},
+	}
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			switch o := obj.(type) {
+			case *apisv1alpha1.ProviderConfig:
+				pc.DeepCopyInto(o)
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"key": []byte("sk-test")}
+			}
+			return nil
+		}),
+	}
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+
+	// connect mimics the Connect of a controller.
+	connect := func() *Client {
+		cfg, err := GetConfig(context.Background(), kube, mg)
+		if err != nil {
+			t.Fatalf("GetConfig(...): %v", err)
+		}
+		return NewClient(cfg)
+	}
+
+	first, second := connect(), connect()
+	if first.http != second.http {
+		t.Errorf("NewClient(...): two reconciles of the same ProviderConfig should share an http.Client")
+	}
+
+	pc.Generation = 2
+	if third := connect(); third.http == first.http {
+		t.Errorf("NewClient(...): a changed ProviderConfig should get a new http.Client")
+	}
+}
+
+func TestProxy(t *testing.T) {
+	// The proxy answers every request itself, and records the host it was
+	// asked to forward the request to.
+	var forwarded string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		forwarded = r.Host
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer proxy.Close()
+	proxyURL, _ := url.Parse(proxy.URL)
+
+	cases := map[string]struct {
+		reason    string
+		proxyURL  string
+		env       *url.URL
+		configErr error
+	}{
+		"Environment": {
+			reason: "Requests should be sent through the proxy of the environment if no proxy URL is configured.",
+			env:    proxyURL,
+		},
+		"Explicit": {
+			reason:   "Requests should be sent through the configured proxy URL, overriding the environment.",
+			proxyURL: proxy.URL,
+			env:      &url.URL{Scheme: "http", Host: "127.0.0.1:1"},
+		},
+		"Invalid": {
+			reason:    "A proxy URL that isn't absolute should be an error.",
+			proxyURL:  "egress.corp:3128",
+			configErr: errors.Errorf("%s %q", errProxyURL, "egress.corp:3128"),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			proxyFromEnvironment = func(_ *http.Request) (*url.URL, error) { return tc.env, nil }
+			defer func() { proxyFromEnvironment = http.ProxyFromEnvironment }()
+			forwarded = ""
+
+			pc := &apisv1alpha1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", UID: types.UID("proxy-" + name)},
+				Spec: apisv1alpha1.ProviderConfigSpec{
+					APIBase:  "http://litellm.test",
+					ProxyURL: tc.proxyURL,
+					Credentials: apisv1alpha1.ProviderCredentials{
+						Source: xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+							SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "creds"}, Key: "key"},
+						},
+					},
+				},
+			}
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					switch o := obj.(type) {
+					case *apisv1alpha1.ProviderConfig:
+						pc.DeepCopyInto(o)
+					case *corev1.Secret:
+						o.Data = map[string][]byte{"key": []byte("sk-test")}
+					}
+					return nil
+				}),
+			}
+			mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+
+			cfg, err := GetConfig(context.Background(), kube, mg)
+			if diff := cmp.Diff(tc.configErr, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nGetConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if err := NewClient(cfg).Get(context.Background(), "/key/info", nil, nil); err != nil {
+				t.Fatalf("\n%s\nGet(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff("litellm.test", forwarded); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want forwarded host, +got forwarded host:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.Header
+	}))
+	defer srv.Close()
+
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "headers"},
+		Spec: apisv1alpha1.ProviderConfigSpec{
+			APIBase: srv.URL,
+			Credentials: apisv1alpha1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "creds"}, Key: "key"},
+				},
+			},
+			Headers: map[string]string{"X-Team": "ml", "X-Org-Token": "overridden", "Authorization": "Basic overridden"},
+			HeaderSecretRefs: map[string]xpv1.SecretKeySelector{
+				"X-Org-Token": {SecretReference: xpv1.SecretReference{Name: "org-token"}, Key: "key"},
+			},
+		},
+	}
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			switch o := obj.(type) {
+			case *apisv1alpha1.ProviderConfig:
+				pc.DeepCopyInto(o)
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"key": []byte(map[string]string{"creds": "sk-test", "org-token": "s3cr3t"}[key.Name])}
+			}
+			return nil
+		},
+	}
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+
+	cfg, err := GetConfig(context.Background(), kube, mg)
+	if err != nil {
+		t.Fatalf("GetConfig(...): %v", err)
+	}
+	if err := NewClient(cfg).Get(context.Background(), "/key/info", nil, nil); err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+
+	want := map[string]string{
+		"X-Team":        "ml",
+		"X-Org-Token":   "s3cr3t",
+		"Authorization": "Bearer sk-test",
+	}
+	for name, v := range want {
+		if diff := cmp.Diff(v, got.Get(name)); diff != "" {
+			t.Errorf("Get(...): -want %s header, +got %s header:\n%s", name, name, diff)
+		}
+	}
+
+	for _, format := range []string{"%v", "%+v", "%#v", "%s"} {
+		if s := fmt.Sprintf(format, cfg.SecretHeaders); strings.Contains(s, "s3cr3t") {
+			t.Errorf("fmt.Sprintf(%q, ...): secret header value should be redacted, got %s", format, s)
+		}
+	}
+}
+
+func TestUnauthorizedProviderConfig(t *testing.T) {
+	pc := &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: "default"}}
+	pc.SetConditions(apisv1alpha1.Unauthorized("invalid key"))
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			pc.DeepCopyInto(obj.(*apisv1alpha1.ProviderConfig))
+			return nil
+		}),
+	}
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+
+	_, err := GetConfig(context.Background(), kube, mg)
+	want := errors.Errorf(errUnauthorized, "default", "invalid key")
+	if diff := cmp.Diff(want, err, test.EquateErrors()); diff != "" {
+		t.Errorf("GetConfig(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestNormalizeAPIBase(t *testing.T) {
+	cases := map[string]struct {
+		base    string
+		want    string
+		wantErr bool
+	}{
+		"Host":              {base: "http://litellm", want: "http://litellm"},
+		"TrailingSlash":     {base: "http://litellm/", want: "http://litellm"},
+		"Port":              {base: "http://litellm:4000/", want: "http://litellm:4000"},
+		"PathPrefix":        {base: "https://gateway.corp/litellm", want: "https://gateway.corp/litellm"},
+		"PathPrefixSlashes": {base: "https://gateway.corp/litellm//", want: "https://gateway.corp/litellm"},
+		"NoScheme":          {base: "litellm:4000", wantErr: true},
+		"OtherScheme":       {base: "ftp://litellm", wantErr: true},
+		"NoHost":            {base: "http:///litellm", wantErr: true},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got, err := normalizeAPIBase(tc.base)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("normalizeAPIBase(%q): want error %t, got %v", tc.base, tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("normalizeAPIBase(%q): -want, +got:\n%s", tc.base, diff)
+			}
+		})
+	}
+}
+
+func TestRequestURL(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(_ http.ResponseWriter, r *http.Request) {
+		got = r.URL.RequestURI()
+	}))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		base string
+		path string
+		want string
+	}{
+		"Host":          {base: srv.URL, path: "/key/info", want: "/key/info"},
+		"TrailingSlash": {base: srv.URL + "/", path: "/key/info", want: "/key/info"},
+		"PathPrefix":    {base: srv.URL + "/litellm", path: "/key/info", want: "/litellm/key/info"},
+		"PrefixSlash":   {base: srv.URL + "/litellm/", path: "/key/info", want: "/litellm/key/info"},
+		"Escaped":       {base: srv.URL + "/litellm", path: "/credentials/" + url.PathEscape("azure/prod"), want: "/litellm/credentials/azure%2Fprod"},
+		"TrailingPath":  {base: srv.URL, path: "/credentials/", want: "/credentials/"},
+		"PathQuery":     {base: srv.URL + "/litellm", path: "/tag/info?names=prod", want: "/litellm/tag/info?names=prod"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if err := NewClient(&Config{APIBase: tc.base, APIKey: "sk-test"}).Get(context.Background(), tc.path, nil, nil); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("Get(...): -want request URI, +got request URI:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestMaxResponseBytes(t *testing.T) {
+	keys := `[` + strings.Repeat(`"sk-0000000000",`, 100) + `"sk-0000000000"]`
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/key/list":
+			_, _ = fmt.Fprintf(w, `{"keys":%s}`, keys)
+		case "/key/info":
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(strings.Repeat("x", 4096)))
+		case "/key/delete":
+			// An empty body decodes into nothing.
+		}
+	}))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		reason   string
+		maxBytes int64
+		path     string
+		wantErr  error
+		wantKeys int
+	}{
+		"WithinLimit": {
+			reason:   "A response within the limit should be decoded.",
+			path:     "/key/list",
+			wantKeys: 101,
+		},
+		"TooLarge": {
+			reason:   "A response beyond the limit should be rejected rather than read.",
+			maxBytes: 1024,
+			path:     "/key/list",
+			wantErr:  ErrResponseTooLarge,
+		},
+		"TooLargeError": {
+			reason:   "The body of an error beyond the limit should be truncated, keeping the error.",
+			maxBytes: 1024,
+			path:     "/key/info",
+			wantErr:  ErrNotFound,
+		},
+		"Empty": {
+			reason: "An empty response should leave the output as it is.",
+			path:   "/key/delete",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewClient(&Config{APIBase: srv.URL, APIKey: "sk-test", MaxResponseBytes: tc.maxBytes})
+			out := struct {
+				Keys []string `json:"keys"`
+			}{}
+			err := c.Get(context.Background(), tc.path, nil, &out)
+			if !errors.Is(err, tc.wantErr) || (err == nil) != (tc.wantErr == nil) {
+				t.Errorf("\n%s\nGet(...): want error %v, got %v", tc.reason, tc.wantErr, err)
+			}
+			if diff := cmp.Diff(tc.wantKeys, len(out.Keys)); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want keys, +got keys:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestAPIError(t *testing.T) {
+	type want struct {
+		err       *APIError
+		notFound  bool
+		conflict  bool
+		authError bool
+		retryable bool
+		rejected  bool
+	}
+	cases := map[string]struct {
+		reason string
+		code   int
+		body   string
+		want   want
+	}{
+		"StringError": {
+			reason: "The message of an error returned as a string should be read.",
+			code:   http.StatusBadRequest,
+			body:   `{"error": "Invalid model name passed in model=gpt-5"}`,
+			want: want{
+				err:      &APIError{StatusCode: http.StatusBadRequest, Message: "Invalid model name passed in model=gpt-5"},
+				rejected: true,
+			},
+		},
+		"OpenAIError": {
+			reason: "The message, type and param of an error in the OpenAI format should be read.",
+			code:   http.StatusUnauthorized,
+			body:   `{"error": {"message": "Authentication Error, Invalid proxy server token passed.", "type": "auth_error", "param": "None", "code": "401"}}`,
+			want: want{
+				err:       &APIError{StatusCode: http.StatusUnauthorized, Type: "auth_error", Message: "Authentication Error, Invalid proxy server token passed.", Param: "None"},
+				authError: true,
+			},
+		},
+		"Detail": {
+			reason: "The message of a FastAPI error should be read.",
+			code:   http.StatusNotFound,
+			body:   `{"detail": "Not Found"}`,
+			want: want{
+				err:      &APIError{StatusCode: http.StatusNotFound, Message: "Not Found"},
+				notFound: true,
+			},
+		},
+		"AlreadyExists": {
+			reason: "A bad request for an object that already exists should be a conflict.",
+			code:   http.StatusBadRequest,
+			body:   `{"error": {"message": "Team with id=platform already exists", "type": "bad_request_error", "param": "team_id"}}`,
+			want: want{
+				err:      &APIError{StatusCode: http.StatusBadRequest, Type: "bad_request_error", Message: "Team with id=platform already exists", Param: "team_id"},
+				conflict: true,
+				rejected: true,
+			},
+		},
+		"Unavailable": {
+			reason: "A 5xx should be retryable, and its plain text body used as the message.",
+			code:   http.StatusServiceUnavailable,
+			body:   "upstream connect error",
+			want: want{
+				err:       &APIError{StatusCode: http.StatusServiceUnavailable, Message: "upstream connect error"},
+				retryable: true,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			err := newAPIError(http.MethodPost, "/team/new", tc.code, []byte(tc.body))
+			if diff := cmp.Diff(tc.want.err, err, cmpopts.IgnoreFields(APIError{}, "Method", "Path", "Body"), cmpopts.IgnoreUnexported(APIError{})); diff != "" {
+				t.Errorf("\n%s\nnewAPIError(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			wrapped := errors.Wrap(err, "cannot create team")
+			got := want{
+				notFound:  IsNotFound(wrapped),
+				conflict:  IsConflict(wrapped),
+				authError: IsAuthError(wrapped),
+				retryable: IsRetryable(wrapped),
+				rejected:  IsRejected(wrapped),
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{}), cmpopts.IgnoreFields(want{}, "err")); diff != "" {
+				t.Errorf("\n%s\nclassification: -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// secretParams are query parameters whose values are key tokens or other
+// credentials, and are redacted whenever a request is logged.
+var secretParams = map[string]bool{
+	"key":     true,
+	"keys":    true,
+	"api_key": true,
+	"token":   true,
+}
+
+// keyPrefix is the prefix of LiteLLM key tokens. Query values with it are
+// redacted whatever their parameter.
+const keyPrefix = "sk-"
+
+// logRequest logs a request to LiteLLM that got the supplied status code, or
+// 0 if no response was received, after the supplied duration. Requests are
+// logged at debug level, which is only enabled when the provider runs with
+// --debug. Neither headers, which carry the Authorization header and secret
+// headers, nor bodies, which may carry key tokens and values read from
+// secrets, are logged.
+func logRequest(ctx context.Context, method string, u *url.URL, code int, d time.Duration, err error) {
+	l := log.FromContext(ctx).V(1)
+	if !l.Enabled() {
+		return
+	}
+	kv := []interface{}{"method", method, "url", redactURL(u), "status", code, "latency", d.String()}
+	if err != nil {
+		// A url.Error formats the URL of the request, query and all.
+		var ue *url.Error
+		if errors.As(err, &ue) {
+			err = ue.Err
+		}
+		kv = append(kv, "error", err.Error())
+	}
+	l.Info("Sent request to LiteLLM", kv...)
+}
+
+// redactURL returns the supplied URL with its user info, and the values of
+// query parameters that carry credentials, redacted.
+func redactURL(u *url.URL) string {
+	r := *u
+	if r.User != nil {
+		r.User = url.User(redacted)
+	}
+	if r.RawQuery != "" {
+		q := r.Query()
+		for p, vs := range q {
+			for i, v := range vs {
+				if secretParams[p] || strings.HasPrefix(v, keyPrefix) {
+					vs[i] = redacted
+				}
+			}
+		}
+		r.RawQuery = q.Encode()
+	}
+	return r.String()
+}
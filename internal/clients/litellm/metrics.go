@@ -0,0 +1,193 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Operations of an ExternalClient, as recorded by the operations metric.
+const (
+	operationObserve = "observe"
+	operationCreate  = "create"
+	operationUpdate  = "update"
+	operationDelete  = "delete"
+)
+
+// Results of an operation or request, as recorded by the metrics.
+const (
+	resultSuccess = "success"
+	resultError   = "error"
+)
+
+var (
+	operations = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "litellm_external_operations_total",
+		Help: "Number of observe, create, update, and delete operations by kind and result.",
+	}, []string{"kind", "operation", "result"})
+
+	requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "litellm_api_requests_total",
+		Help: "Number of requests sent to LiteLLM by kind, method, endpoint, and response status code.",
+	}, []string{"kind", "method", "endpoint", "code"})
+
+	requestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "litellm_api_request_duration_seconds",
+		Help:    "Latency of requests sent to LiteLLM by kind, method, and endpoint.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"kind", "method", "endpoint"})
+
+	breakerStates = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "litellm_circuit_breaker_state",
+		Help: "State of the circuit breaker of each LiteLLM API base: 0 closed, 1 open, 2 half-open.",
+	}, []string{"api_base"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(operations, requests, requestDuration, breakerStates)
+}
+
+// identifiedPaths are the path prefixes of endpoints whose remaining path
+// identifies a resource, e.g. /credentials/{name}. The identifier is not
+// recorded, to bound the number of endpoints.
+var identifiedPaths = []string{
+	"/credentials/by_name/",
+	"/credentials/",
+	"/guardrails/",
+	"/v1/mcp/server/",
+}
+
+// endpoint returns the endpoint of the supplied request path.
+func endpoint(path string) string {
+	for _, p := range identifiedPaths {
+		if strings.HasPrefix(path, p) && len(path) > len(p) {
+			return p + "{id}"
+		}
+	}
+	return path
+}
+
+// kindKey is the context key of the kind of managed resource a request is
+// sent for.
+type kindKey struct{}
+
+// kindFrom returns the kind of managed resource requests with the supplied
+// context are sent for, if known.
+func kindFrom(ctx context.Context) string {
+	k, _ := ctx.Value(kindKey{}).(string)
+	return k
+}
+
+// nameKey is the context key of the name of the managed resource a request is
+// sent for.
+type nameKey struct{}
+
+// nameFrom returns the name of the managed resource requests with the
+// supplied context are sent for, if known.
+func nameFrom(ctx context.Context) string {
+	n, _ := ctx.Value(nameKey{}).(string)
+	return n
+}
+
+// observeRequest records a request to LiteLLM that got the supplied status
+// code, or 0 if no response was received, after the supplied duration.
+func observeRequest(ctx context.Context, method, path string, code int, d time.Duration) {
+	kind, ep := kindFrom(ctx), endpoint(path)
+	c := resultError
+	if code != 0 {
+		c = strconv.Itoa(code)
+	}
+	requests.WithLabelValues(kind, method, ep, c).Inc()
+	requestDuration.WithLabelValues(kind, method, ep).Observe(d.Seconds())
+}
+
+// A MetricsConnecter wraps the ExternalClients of another connecter, so that
+// their operations, and the requests they send to LiteLLM, are recorded in
+// the metrics of the supplied kind of managed resource.
+type MetricsConnecter struct {
+	kind  string
+	inner managed.ExternalConnecter
+}
+
+// NewMetricsConnecter returns a MetricsConnecter for the supplied kind that
+// wraps the supplied connecter.
+func NewMetricsConnecter(kind string, c managed.ExternalConnecter) *MetricsConnecter {
+	return &MetricsConnecter{kind: kind, inner: c}
+}
+
+// Connect to LiteLLM using the wrapped connecter.
+func (c *MetricsConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	e, err := c.inner.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &metricsClient{kind: c.kind, inner: e}, nil
+}
+
+// A metricsClient records the operations of an ExternalClient, and tells the
+// Client the kind of managed resource its requests are sent for.
+type metricsClient struct {
+	kind  string
+	inner managed.ExternalClient
+}
+
+func (e *metricsClient) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.inner.Observe(e.context(ctx, mg), mg)
+	e.record(operationObserve, err)
+	return o, err
+}
+
+func (e *metricsClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.inner.Create(e.context(ctx, mg), mg)
+	e.record(operationCreate, err)
+	return c, err
+}
+
+func (e *metricsClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.inner.Update(e.context(ctx, mg), mg)
+	e.record(operationUpdate, err)
+	return u, err
+}
+
+func (e *metricsClient) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.inner.Delete(e.context(ctx, mg), mg)
+	e.record(operationDelete, err)
+	return err
+}
+
+// context returns the supplied context with the kind and name of the supplied
+// managed resource, for the Client.
+func (e *metricsClient) context(ctx context.Context, mg resource.Managed) context.Context {
+	return context.WithValue(context.WithValue(ctx, kindKey{}, e.kind), nameKey{}, mg.GetName())
+}
+
+func (e *metricsClient) record(operation string, err error) {
+	r := resultSuccess
+	if err != nil {
+		r = resultError
+	}
+	operations.WithLabelValues(e.kind, operation, r).Inc()
+}
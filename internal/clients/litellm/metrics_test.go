@@ -0,0 +1,110 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+func TestMetrics(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/key/delete" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	c := NewClient(&Config{APIBase: srv.URL, APIKey: "sk-test"})
+	conn := NewMetricsConnecter("MetricsTest", managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return &managed.ExternalClientFns{
+			ObserveFn: func(ctx context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				return managed.ExternalObservation{}, c.Get(ctx, "/key/info", nil, nil)
+			},
+			DeleteFn: func(ctx context.Context, _ resource.Managed) error {
+				return c.Post(ctx, "/key/delete", nil, nil)
+			},
+		}, nil
+	}))
+
+	// The counters are registered globally, so their increments are checked
+	// rather than their values, which earlier runs of the test add to.
+	counters := map[string]prometheus.Counter{
+		"ObserveSucceeded": operations.WithLabelValues("MetricsTest", operationObserve, resultSuccess),
+		"DeleteFailed":     operations.WithLabelValues("MetricsTest", operationDelete, resultError),
+		"InfoRequest":      requests.WithLabelValues("MetricsTest", http.MethodGet, "/key/info", "200"),
+		"DeleteRequest":    requests.WithLabelValues("MetricsTest", http.MethodPost, "/key/delete", "404"),
+	}
+	before := map[string]float64{}
+	for name, m := range counters {
+		before[name] = testutil.ToFloat64(m)
+	}
+
+	e, err := conn.Connect(context.Background(), &fake.Managed{})
+	if err != nil {
+		t.Fatalf("Connect(...): %v", err)
+	}
+	if _, err := e.Observe(context.Background(), &fake.Managed{}); err != nil {
+		t.Fatalf("Observe(...): %v", err)
+	}
+	if err := e.Delete(context.Background(), &fake.Managed{}); err == nil {
+		t.Fatalf("Delete(...): want error, got nil")
+	}
+
+	for name, m := range counters {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(float64(1), testutil.ToFloat64(m)-before[name]); diff != "" {
+				t.Errorf("-want increment, +got increment:\n%s", diff)
+			}
+		})
+	}
+
+	if n := testutil.CollectAndCount(requestDuration, "litellm_api_request_duration_seconds"); n == 0 {
+		t.Errorf("requestDuration: want observed latencies, got none")
+	}
+}
+
+func TestEndpoint(t *testing.T) {
+	cases := map[string]struct {
+		path string
+		want string
+	}{
+		"Static":     {path: "/key/info", want: "/key/info"},
+		"Credential": {path: "/credentials/azure-prod", want: "/credentials/{id}"},
+		"ByName":     {path: "/credentials/by_name/azure-prod", want: "/credentials/by_name/{id}"},
+		"List":       {path: "/credentials/", want: "/credentials/"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, endpoint(tc.path)); diff != "" {
+				t.Errorf("endpoint(%q): -want, +got:\n%s", tc.path, diff)
+			}
+		})
+	}
+}
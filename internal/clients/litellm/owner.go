@@ -0,0 +1,66 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// Owner returns the name of the managed resource that manages the settings
+// of the ProviderConfig of the supplied managed resource, i.e. the oldest of
+// the supplied kind of list that uses the same ProviderConfig and isn't being
+// deleted. It is used by kinds of which only one may manage the proxy-wide
+// settings of a ProviderConfig; several would otherwise keep overwriting
+// each other's settings. It returns an empty string if there is none.
+func Owner(ctx context.Context, kube client.Reader, mg resource.Managed, l resource.ManagedList) (string, error) {
+	if err := kube.List(ctx, l); err != nil {
+		return "", err
+	}
+
+	pc := mg.GetProviderConfigReference()
+	if pc == nil {
+		return "", nil
+	}
+	var owner resource.Managed
+	for _, m := range l.GetItems() {
+		ref := m.GetProviderConfigReference()
+		if meta.WasDeleted(m) || ref == nil || ref.Name != pc.Name {
+			continue
+		}
+		if owner == nil || olderThan(m, owner) {
+			owner = m
+		}
+	}
+	if owner == nil {
+		return "", nil
+	}
+	return owner.GetName(), nil
+}
+
+// olderThan returns true if a was created before b. Names break ties.
+func olderThan(a, b resource.Managed) bool {
+	ta, tb := a.GetCreationTimestamp(), b.GetCreationTimestamp()
+	if !ta.Equal(&tb) {
+		return ta.Before(&tb)
+	}
+	return a.GetName() < b.GetName()
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+// managedList is a resource.ManagedList of fake managed resources.
+type managedList struct {
+	metav1.TypeMeta
+	metav1.ListMeta
+	Items []resource.Managed
+}
+
+func (l *managedList) GetItems() []resource.Managed { return l.Items }
+
+func (l *managedList) DeepCopyObject() runtime.Object {
+	return &managedList{TypeMeta: l.TypeMeta, ListMeta: *l.ListMeta.DeepCopy(), Items: append([]resource.Managed(nil), l.Items...)}
+}
+
+func TestOwner(t *testing.T) {
+	errBoom := errors.New("boom")
+	now := time.Now()
+	managed := func(name, pc string, age time.Duration, deleted bool) resource.Managed {
+		mg := &fake.Managed{ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			CreationTimestamp: metav1.NewTime(now.Add(-age)),
+		}}
+		if pc != "" {
+			mg.SetProviderConfigReference(&xpv1.Reference{Name: pc})
+		}
+		if deleted {
+			t := metav1.NewTime(now)
+			mg.SetDeletionTimestamp(&t)
+		}
+		return mg
+	}
+
+	cases := map[string]struct {
+		reason  string
+		items   []resource.Managed
+		listErr error
+		want    string
+		wantErr error
+	}{
+		"ListError": {
+			reason:  "Errors listing the resources should be returned.",
+			listErr: errBoom,
+			wantErr: errBoom,
+		},
+		"None": {
+			reason: "There should be no owner if no resource uses the ProviderConfig.",
+			items:  []resource.Managed{managed("other", "other-pc", time.Hour, false)},
+		},
+		"Oldest": {
+			reason: "The oldest resource should own the settings.",
+			items: []resource.Managed{
+				managed("cool", "pc", time.Minute, false),
+				managed("older", "pc", time.Hour, false),
+			},
+			want: "older",
+		},
+		"NameBreaksTies": {
+			reason: "The name should break ties between resources created at the same time.",
+			items: []resource.Managed{
+				managed("b", "pc", time.Hour, false),
+				managed("a", "pc", time.Hour, false),
+			},
+			want: "a",
+		},
+		"DeletedSkipped": {
+			reason: "Resources that are being deleted shouldn't own the settings.",
+			items: []resource.Managed{
+				managed("cool", "pc", time.Minute, false),
+				managed("deleted", "pc", time.Hour, true),
+			},
+			want: "cool",
+		},
+		"OtherProviderConfigIgnored": {
+			reason: "Resources that use another ProviderConfig, or none, shouldn't own the settings.",
+			items: []resource.Managed{
+				managed("cool", "pc", time.Minute, false),
+				managed("other", "other-pc", time.Hour, false),
+				managed("none", "", time.Hour, false),
+			},
+			want: "cool",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+					obj.(*managedList).Items = tc.items
+					return tc.listErr
+				},
+			}
+			mg := managed("cool", "pc", time.Minute, false)
+
+			got, err := Owner(context.Background(), kube, mg, &managedList{})
+			if diff := cmp.Diff(tc.wantErr, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nOwner(...): -want error, +got error:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nOwner(...): -want, +got:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/time/rate"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	// rateLimitedBackoff is how long a resource is requeued after when
+	// LiteLLM rate limits it without a Retry-After.
+	rateLimitedBackoff = 30 * time.Second
+
+	// rateLimitedBackoffMax is the longest a rate limited resource is
+	// requeued after, whatever Retry-After LiteLLM asks for.
+	rateLimitedBackoffMax = 10 * time.Minute
+
+	errThrottle = "cannot wait for the request rate limit of the ProviderConfig"
+)
+
+// limiters are shared by the Clients of all controllers.
+var limiters = newLimiterCache()
+
+// RetryAfter returns how long to wait before sending a request that LiteLLM
+// rate limited with the supplied error again, and whether it was rate
+// limited at all.
+func RetryAfter(err error) (time.Duration, bool) {
+	var ae *APIError
+	if !errors.As(err, &ae) || !errors.Is(ae, ErrRateLimited) {
+		return 0, false
+	}
+	return ae.retryAfter, true
+}
+
+// parseRetryAfter parses the supplied Retry-After header, which is either a
+// number of seconds or an HTTP date. It returns the default backoff of a rate
+// limited request if the header is missing or invalid.
+func parseRetryAfter(v string, now time.Time) time.Duration {
+	v = strings.TrimSpace(v)
+	var d time.Duration
+	if s, err := strconv.Atoi(v); err == nil {
+		d = time.Duration(s) * time.Second
+	} else if t, err := http.ParseTime(v); err == nil {
+		d = t.Sub(now)
+	}
+	switch {
+	case d <= 0:
+		return rateLimitedBackoff
+	case d > rateLimitedBackoffMax:
+		return rateLimitedBackoffMax
+	}
+	return d
+}
+
+// A limiterCache holds the client-side rate limiter of each ProviderConfig
+// that limits the rate of requests sent to LiteLLM.
+type limiterCache struct {
+	mu       sync.Mutex
+	limiters map[types.UID]cachedLimiter
+}
+
+type cachedLimiter struct {
+	generation int64
+	limiter    *rate.Limiter
+}
+
+func newLimiterCache() *limiterCache {
+	return &limiterCache{limiters: map[types.UID]cachedLimiter{}}
+}
+
+// Get returns the rate limiter of the supplied ProviderConfig, or nil if it
+// doesn't limit the rate of requests. Up to a second's worth of requests may
+// be sent at once.
+func (c *limiterCache) Get(pc *apisv1alpha1.ProviderConfig) *rate.Limiter {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.limiters[pc.GetUID()]; ok && cached.generation == pc.GetGeneration() {
+		return cached.limiter
+	}
+	var l *rate.Limiter
+	if rps := pc.Spec.MaxRequestsPerSecond; rps > 0 {
+		l = rate.NewLimiter(rate.Limit(rps), rps)
+	}
+	c.limiters[pc.GetUID()] = cachedLimiter{generation: pc.GetGeneration(), limiter: l}
+	return l
+}
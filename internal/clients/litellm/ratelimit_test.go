@@ -0,0 +1,156 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2024, 9, 1, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason string
+		header string
+		want   time.Duration
+	}{
+		"Seconds": {
+			reason: "A number of seconds should be waited for.",
+			header: "45",
+			want:   45 * time.Second,
+		},
+		"Date": {
+			reason: "An HTTP date should be waited for.",
+			header: now.Add(2 * time.Minute).Format(http.TimeFormat),
+			want:   2 * time.Minute,
+		},
+		"Missing": {
+			reason: "A missing Retry-After should wait for the default backoff.",
+			want:   rateLimitedBackoff,
+		},
+		"Invalid": {
+			reason: "An invalid Retry-After should wait for the default backoff.",
+			header: "soon",
+			want:   rateLimitedBackoff,
+		},
+		"Past": {
+			reason: "A date in the past should wait for the default backoff.",
+			header: now.Add(-time.Minute).Format(http.TimeFormat),
+			want:   rateLimitedBackoff,
+		},
+		"Capped": {
+			reason: "A very long Retry-After should be capped.",
+			header: "86400",
+			want:   rateLimitedBackoffMax,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, parseRetryAfter(tc.header, now)); diff != "" {
+				t.Errorf("\n%s\nparseRetryAfter(%q): -want, +got:\n%s\n", tc.reason, tc.header, diff)
+			}
+		})
+	}
+}
+
+func TestRetryAfter(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/key/generate" {
+			w.Header().Set("Retry-After", "12")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer srv.Close()
+	c := NewClient(&Config{APIBase: srv.URL})
+
+	err := errors.Wrap(c.Post(context.Background(), "/key/generate", nil, nil), "cannot generate key")
+	d, limited := RetryAfter(err)
+	if !limited || d != 12*time.Second {
+		t.Errorf("RetryAfter(...): want 12s, true, got %s, %t", d, limited)
+	}
+	if !errors.Is(err, ErrRateLimited) {
+		t.Errorf("Post(...): want ErrRateLimited, got %v", err)
+	}
+
+	if _, limited := RetryAfter(c.Get(context.Background(), "/key/info", nil, nil)); limited {
+		t.Errorf("RetryAfter(...): a 503 should not be rate limited")
+	}
+}
+
+func TestMaxRequestsPerSecond(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	cases := map[string]struct {
+		reason string
+		rps    int
+		// requests are sent within a deadline that is too short to wait
+		// for another token.
+		requests int
+		wantErr  bool
+	}{
+		"Unlimited": {
+			reason:   "Requests should not be limited unless configured.",
+			requests: 5,
+		},
+		"WithinBurst": {
+			reason:   "A second's worth of requests should be sent at once.",
+			rps:      2,
+			requests: 2,
+		},
+		"Throttled": {
+			reason:   "Requests beyond the rate should wait, and fail if they can't within the deadline.",
+			rps:      1,
+			requests: 2,
+			wantErr:  true,
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &apisv1alpha1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{UID: types.UID("rps-" + name)},
+				Spec:       apisv1alpha1.ProviderConfigSpec{MaxRequestsPerSecond: tc.rps},
+			}
+			c := NewClient(&Config{APIBase: srv.URL, ProviderConfig: pc})
+
+			ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+			defer cancel()
+			var err error
+			for i := 0; i < tc.requests && err == nil; i++ {
+				err = c.Get(ctx, "/key/info", nil, nil)
+			}
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nGet(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// A ReferenceResolver resolves the references of a managed resource, and
+// records whether they resolved in its ReferenceResolution condition. A
+// resource whose references don't resolve, e.g. because a referenced
+// resource isn't ready yet, is not reconciled any further.
+type ReferenceResolver struct {
+	resolver managed.ReferenceResolver
+}
+
+// NewReferenceResolver returns a ReferenceResolver that resolves references
+// using the supplied client.
+func NewReferenceResolver(c client.Client) *ReferenceResolver {
+	return &ReferenceResolver{resolver: managed.NewAPISimpleReferenceResolver(c)}
+}
+
+// ResolveReferences of the supplied managed resource.
+func (r *ReferenceResolver) ResolveReferences(ctx context.Context, mg resource.Managed) error {
+	if err := r.resolver.ResolveReferences(ctx, mg); err != nil {
+		mg.SetConditions(apisv1alpha1.ReferencesUnresolved(err.Error()))
+		return err
+	}
+	mg.SetConditions(apisv1alpha1.ReferencesResolved())
+	return nil
+}
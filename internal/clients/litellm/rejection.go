@@ -0,0 +1,251 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	// rejectionBackoffBase is how long a resource LiteLLM rejected once is
+	// requeued after. It doubles with every further rejection.
+	rejectionBackoffBase = 30 * time.Second
+
+	// rejectionBackoffMax is the longest a rejected resource is requeued
+	// after.
+	rejectionBackoffMax = 30 * time.Minute
+)
+
+// outcomeKey is the context key of the outcome of a reconcile.
+type outcomeKey struct{}
+
+// An outcome records whether LiteLLM rejected, or rate limited, a resource
+// during a reconcile. A resource whose proxy was unavailable is recorded as
+// rate limited for the cooldown of the circuit breaker.
+type outcome struct {
+	mu         sync.Mutex
+	rejected   bool
+	generation int64
+	retryAfter time.Duration
+}
+
+func (o *outcome) reject(mg resource.Managed) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.rejected = true
+	o.generation = mg.GetGeneration()
+}
+
+func (o *outcome) get() (bool, int64) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.rejected, o.generation
+}
+
+func (o *outcome) rateLimit(d time.Duration) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.retryAfter = max(o.retryAfter, d)
+}
+
+func (o *outcome) rateLimited() (time.Duration, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.retryAfter, o.retryAfter > 0
+}
+
+// A RejectionConnecter wraps the ExternalClients of another connecter, so
+// that a managed resource LiteLLM rejects is marked as such and requeued
+// with a growing backoff by a RejectionReconciler, and one LiteLLM rate
+// limits is requeued once LiteLLM is ready to accept requests again. A
+// managed resource whose requests fail fast because the circuit breaker of
+// its proxy is open is marked as such, and requeued after the cooldown of the
+// breaker.
+type RejectionConnecter struct {
+	inner managed.ExternalConnecter
+}
+
+// NewRejectionConnecter returns a RejectionConnecter that wraps the supplied
+// connecter.
+func NewRejectionConnecter(c managed.ExternalConnecter) *RejectionConnecter {
+	return &RejectionConnecter{inner: c}
+}
+
+// Connect to LiteLLM using the wrapped connecter.
+func (c *RejectionConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	e, err := c.inner.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &rejectionClient{inner: e}, nil
+}
+
+// A rejectionClient sets the Rejected condition of the managed resources
+// LiteLLM rejects, and records the rejection in the outcome of the reconcile.
+// The condition is persisted along with the ReconcileError the managed
+// reconciler sets for the same error.
+type rejectionClient struct {
+	inner managed.ExternalClient
+}
+
+func (e *rejectionClient) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	o, err := e.inner.Observe(ctx, mg)
+	e.record(ctx, mg, err, err == nil && o.ResourceExists && o.ResourceUpToDate)
+	return o, err
+}
+
+func (e *rejectionClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	c, err := e.inner.Create(ctx, mg)
+	e.record(ctx, mg, err, err == nil)
+	return c, err
+}
+
+func (e *rejectionClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	u, err := e.inner.Update(ctx, mg)
+	e.record(ctx, mg, err, err == nil)
+	return u, err
+}
+
+func (e *rejectionClient) Delete(ctx context.Context, mg resource.Managed) error {
+	err := e.inner.Delete(ctx, mg)
+	e.record(ctx, mg, err, false)
+	return err
+}
+
+// record the supplied error. A resource that is accepted by LiteLLM again
+// only gets its Rejected condition cleared if it had one, so that resources
+// that were never rejected don't carry the condition at all.
+func (e *rejectionClient) record(ctx context.Context, mg resource.Managed, err error, accepted bool) {
+	if errors.Is(err, ErrCircuitOpen) {
+		mg.SetConditions(apisv1alpha1.ProxyUnavailable(err.Error()))
+		if o, ok := ctx.Value(outcomeKey{}).(*outcome); ok {
+			o.rateLimit(breakerCooldown)
+		}
+		return
+	}
+	// Any response, even an error, means the proxy can be reached.
+	var ae *APIError
+	if (err == nil || errors.As(err, &ae)) && mg.GetCondition(apisv1alpha1.TypeProxyUnavailable).Status == corev1.ConditionTrue {
+		mg.SetConditions(apisv1alpha1.ProxyAvailable())
+	}
+	if d, ok := RetryAfter(err); ok {
+		if o, ok := ctx.Value(outcomeKey{}).(*outcome); ok {
+			o.rateLimit(d)
+		}
+		return
+	}
+	if IsRejected(err) {
+		mg.SetConditions(apisv1alpha1.Rejected(err.Error()))
+		if o, ok := ctx.Value(outcomeKey{}).(*outcome); ok {
+			o.reject(mg)
+		}
+		return
+	}
+	if accepted && mg.GetCondition(apisv1alpha1.TypeRejected).Status == corev1.ConditionTrue {
+		mg.SetConditions(apisv1alpha1.Accepted())
+	}
+}
+
+// A RejectionReconciler requeues managed resources LiteLLM keeps rejecting,
+// e.g. because of an invalid model name, with an exponential backoff instead
+// of retrying them at the rate of transient errors. Changing the spec of a
+// resource resets its backoff, and triggers a reconcile right away. Resources
+// LiteLLM rate limits are requeued once its Retry-After has passed, rather
+// than retried right away.
+type RejectionReconciler struct {
+	inner reconcile.Reconciler
+	base  time.Duration
+	max   time.Duration
+
+	mu       sync.Mutex
+	failures map[types.NamespacedName]rejections
+}
+
+// rejections are the consecutive rejections of a generation of a resource.
+type rejections struct {
+	generation int64
+	count      int
+}
+
+// NewRejectionReconciler returns a RejectionReconciler that wraps the
+// supplied reconciler. The reconciler's ExternalConnecter must be wrapped by
+// a RejectionConnecter.
+func NewRejectionReconciler(r reconcile.Reconciler) *RejectionReconciler {
+	return &RejectionReconciler{
+		inner:    r,
+		base:     rejectionBackoffBase,
+		max:      rejectionBackoffMax,
+		failures: map[types.NamespacedName]rejections{},
+	}
+}
+
+// Reconcile the supplied managed resource, and back off if LiteLLM rejected
+// it.
+func (r *RejectionReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	o := &outcome{}
+	result, err := r.inner.Reconcile(context.WithValue(ctx, outcomeKey{}, o), req)
+
+	if err != nil {
+		return result, err
+	}
+
+	if d, limited := o.rateLimited(); limited {
+		return reconcile.Result{RequeueAfter: d}, nil
+	}
+
+	rejected, generation := o.get()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if !rejected {
+		delete(r.failures, req.NamespacedName)
+		return result, nil
+	}
+
+	f := r.failures[req.NamespacedName]
+	if f.generation != generation {
+		f = rejections{generation: generation}
+	}
+	f.count++
+	r.failures[req.NamespacedName] = f
+
+	return reconcile.Result{RequeueAfter: r.backoff(f.count)}, nil
+}
+
+// backoff returns how long to wait after the supplied number of consecutive
+// rejections.
+func (r *RejectionReconciler) backoff(count int) time.Duration {
+	d := r.base
+	for i := 1; i < count && d < r.max; i++ {
+		d *= 2
+	}
+	if d > r.max {
+		return r.max
+	}
+	return d
+}
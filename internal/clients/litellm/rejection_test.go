@@ -0,0 +1,270 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+func TestIsRejected(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		err    error
+		want   bool
+	}{
+		"BadRequest": {
+			reason: "A 400 should be a rejection.",
+			err:    &APIError{StatusCode: http.StatusBadRequest, Body: "invalid model name"},
+			want:   true,
+		},
+		"Wrapped": {
+			reason: "A wrapped 422 should be a rejection.",
+			err:    errors.Wrap(&APIError{StatusCode: http.StatusUnprocessableEntity}, "cannot create model"),
+			want:   true,
+		},
+		"NotFound": {
+			reason: "A 404 should not be a rejection.",
+			err:    &APIError{StatusCode: http.StatusNotFound},
+			want:   false,
+		},
+		"DoesNotExist": {
+			reason: "A 400 for an object that does not exist should not be a rejection.",
+			err:    &APIError{StatusCode: http.StatusBadRequest, Body: "Team does not exist"},
+			want:   false,
+		},
+		"Unauthorized": {
+			reason: "A 401 should not be a rejection, since it isn't caused by the spec.",
+			err:    &APIError{StatusCode: http.StatusUnauthorized},
+			want:   false,
+		},
+		"Forbidden": {
+			reason: "A 403 should not be a rejection, since it isn't caused by the spec.",
+			err:    &APIError{StatusCode: http.StatusForbidden},
+			want:   false,
+		},
+		"RateLimited": {
+			reason: "A 429 should not be a rejection.",
+			err:    &APIError{StatusCode: http.StatusTooManyRequests},
+			want:   false,
+		},
+		"Unavailable": {
+			reason: "A 503 should not be a rejection.",
+			err:    &APIError{StatusCode: http.StatusServiceUnavailable},
+			want:   false,
+		},
+		"Connection": {
+			reason: "A connection error should not be a rejection.",
+			err:    errConnect,
+			want:   false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, IsRejected(tc.err)); diff != "" {
+				t.Errorf("\n%s\nIsRejected(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// observer returns a reconciler that, like the managed reconciler, observes
+// the supplied resource using the supplied connecter and requeues it on
+// error.
+func observer(c managed.ExternalConnecter, mg resource.Managed) reconcile.Reconciler {
+	return reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+		e, err := c.Connect(ctx, mg)
+		if err != nil {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		if _, err := e.Observe(ctx, mg); err != nil {
+			return reconcile.Result{Requeue: true}, nil
+		}
+		return reconcile.Result{RequeueAfter: time.Minute}, nil
+	})
+}
+
+func TestRejectionReconciler(t *testing.T) {
+	type want struct {
+		results  []reconcile.Result
+		rejected corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason   string
+		statuses []int
+		// generations of the resource at each reconcile.
+		generations []int64
+		want        want
+	}{
+		"BadRequest": {
+			reason:      "A 400 should be requeued after a longer backoff than a transient error, and mark the resource rejected.",
+			statuses:    []int{http.StatusBadRequest},
+			generations: []int64{1},
+			want: want{
+				results:  []reconcile.Result{{RequeueAfter: 30 * time.Second}},
+				rejected: corev1.ConditionTrue,
+			},
+		},
+		"Unavailable": {
+			reason:      "A 503 should be requeued at the rate of the controller, without marking the resource rejected.",
+			statuses:    []int{http.StatusServiceUnavailable},
+			generations: []int64{1},
+			want: want{
+				results:  []reconcile.Result{{Requeue: true}},
+				rejected: corev1.ConditionUnknown,
+			},
+		},
+		"Persistent": {
+			reason:      "Repeated rejections should double the backoff up to its maximum.",
+			statuses:    []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest},
+			generations: []int64{1, 1, 1, 1},
+			want: want{
+				results: []reconcile.Result{
+					{RequeueAfter: 30 * time.Second},
+					{RequeueAfter: time.Minute},
+					{RequeueAfter: 2 * time.Minute},
+					{RequeueAfter: 2 * time.Minute},
+				},
+				rejected: corev1.ConditionTrue,
+			},
+		},
+		"SpecChanged": {
+			reason:      "A rejected resource whose spec changed should start over with the shortest backoff.",
+			statuses:    []int{http.StatusBadRequest, http.StatusBadRequest, http.StatusBadRequest},
+			generations: []int64{1, 1, 2},
+			want: want{
+				results: []reconcile.Result{
+					{RequeueAfter: 30 * time.Second},
+					{RequeueAfter: time.Minute},
+					{RequeueAfter: 30 * time.Second},
+				},
+				rejected: corev1.ConditionTrue,
+			},
+		},
+		"RateLimited": {
+			reason:      "A 429 should be requeued after its Retry-After, without marking the resource rejected.",
+			statuses:    []int{http.StatusTooManyRequests},
+			generations: []int64{1},
+			want: want{
+				results:  []reconcile.Result{{RequeueAfter: 45 * time.Second}},
+				rejected: corev1.ConditionUnknown,
+			},
+		},
+		"Recovered": {
+			reason:      "A rejected resource LiteLLM accepts again should be requeued as usual and no longer be marked rejected.",
+			statuses:    []int{http.StatusBadRequest, http.StatusOK},
+			generations: []int64{1, 2},
+			want: want{
+				results: []reconcile.Result{
+					{RequeueAfter: 30 * time.Second},
+					{RequeueAfter: time.Minute},
+				},
+				rejected: corev1.ConditionFalse,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			i := 0
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				if tc.statuses[i] == http.StatusTooManyRequests {
+					w.Header().Set("Retry-After", "45")
+				}
+				w.WriteHeader(tc.statuses[i])
+				_, _ = w.Write([]byte(`{}`))
+			}))
+			defer srv.Close()
+
+			mg := &fake.Managed{}
+			c := NewRejectionConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return managed.ExternalClientFns{ObserveFn: func(ctx context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+					err := NewClient(&Config{APIBase: srv.URL}).Get(ctx, "/model/info", nil, nil)
+					return managed.ExternalObservation{ResourceExists: err == nil, ResourceUpToDate: err == nil}, err
+				}}, nil
+			}))
+			r := NewRejectionReconciler(observer(c, mg))
+			r.max = 2 * time.Minute
+
+			var got []reconcile.Result
+			for i = range tc.statuses {
+				mg.SetGeneration(tc.generations[i])
+				result, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "m"}})
+				if err != nil {
+					t.Fatalf("\n%s\nr.Reconcile(...): %v", tc.reason, err)
+				}
+				got = append(got, result)
+			}
+			if diff := cmp.Diff(tc.want.results, got); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want results, +got results:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rejected, mg.GetCondition(apisv1alpha1.TypeRejected).Status); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want Rejected condition, +got Rejected condition:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestProxyUnavailable(t *testing.T) {
+	mg := &fake.Managed{}
+	errs := []error{ErrCircuitOpen, nil}
+	c := managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+		return &managed.ExternalClientFns{
+			ObserveFn: func(_ context.Context, _ resource.Managed) (managed.ExternalObservation, error) {
+				err := errs[0]
+				errs = errs[1:]
+				return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, err
+			},
+		}, nil
+	})
+	r := NewRejectionReconciler(observer(NewRejectionConnecter(c), mg))
+
+	got, err := r.Reconcile(context.Background(), reconcile.Request{})
+	if err != nil {
+		t.Fatalf("r.Reconcile(...): %v", err)
+	}
+	if diff := cmp.Diff(reconcile.Result{RequeueAfter: breakerCooldown}, got); diff != "" {
+		t.Errorf("r.Reconcile(...): a resource whose proxy is unavailable should be requeued after the cooldown: -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(corev1.ConditionTrue, mg.GetCondition(apisv1alpha1.TypeProxyUnavailable).Status); diff != "" {
+		t.Errorf("r.Reconcile(...): -want ProxyUnavailable, +got ProxyUnavailable:\n%s", diff)
+	}
+
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{}); err != nil {
+		t.Fatalf("r.Reconcile(...): %v", err)
+	}
+	if diff := cmp.Diff(corev1.ConditionFalse, mg.GetCondition(apisv1alpha1.TypeProxyUnavailable).Status); diff != "" {
+		t.Errorf("r.Reconcile(...): a reachable proxy should clear ProxyUnavailable: -want, +got:\n%s", diff)
+	}
+}
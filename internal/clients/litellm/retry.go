@@ -0,0 +1,125 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	defaultBackoffBase = 500 * time.Millisecond
+	defaultBackoffMax  = 10 * time.Second
+)
+
+// A RetryPolicy configures how idempotent requests that fail to connect to
+// LiteLLM, or get a 5xx response, are retried. The zero value doesn't retry.
+type RetryPolicy struct {
+	// MaxAttempts is how many times a request is sent at most.
+	MaxAttempts int
+
+	// BackoffBase is the wait before the first retry, which doubles with
+	// every further retry up to BackoffMax.
+	BackoffBase time.Duration
+	BackoffMax  time.Duration
+}
+
+// retryPolicy returns the RetryPolicy of the supplied configuration.
+func retryPolicy(cfg *apisv1alpha1.RetryPolicy) RetryPolicy {
+	if cfg == nil {
+		return RetryPolicy{}
+	}
+	p := RetryPolicy{MaxAttempts: cfg.MaxAttempts, BackoffBase: defaultBackoffBase, BackoffMax: defaultBackoffMax}
+	if cfg.BackoffBase != nil {
+		p.BackoffBase = cfg.BackoffBase.Duration
+	}
+	if cfg.BackoffMax != nil {
+		p.BackoffMax = cfg.BackoffMax.Duration
+	}
+	return p
+}
+
+// retry returns true if the supplied failed attempt, counted from zero,
+// should be retried. It waits for the backoff before it returns, unless the
+// supplied context is done, or its deadline would pass, first.
+func (p RetryPolicy) retry(ctx context.Context, attempt, code int, err error) bool {
+	if attempt+1 >= p.MaxAttempts || !retryable(ctx, code, err) {
+		return false
+	}
+	d := p.backoff(attempt)
+	if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < d {
+		return false
+	}
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return false
+	case <-t.C:
+		return true
+	}
+}
+
+// backoff returns how long to wait after the supplied failed attempt. Half
+// of the exponential backoff is jittered, so that the retries of concurrent
+// reconciles don't hit LiteLLM at once.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.BackoffBase
+	for i := 0; i < attempt && d < p.BackoffMax; i++ {
+		d *= 2
+	}
+	if d > p.BackoffMax {
+		d = p.BackoffMax
+	}
+	if d < 2 {
+		return d
+	}
+	return d/2 + time.Duration(rand.Int63n(int64(d/2))) //nolint:gosec // Jitter needn't be cryptographically random.
+}
+
+// idempotent returns true if requests with the supplied method can safely be
+// sent more than once. LiteLLM creates objects, e.g. keys, with POST requests,
+// so a POST that failed may still have created one, and sending it again
+// could create another that nothing tracks.
+func idempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+// retryable returns true if a request that got the supplied status code, or
+// 0 if it got no response, and error may succeed when sent again. Only
+// connection errors, request timeouts and 5xx responses are retried; rate
+// limited requests are requeued once LiteLLM is ready for them instead. A
+// request is never retried once the supplied context is done, if its response
+// was received but couldn't be read, or if the circuit breaker is open.
+func retryable(ctx context.Context, code int, err error) bool {
+	switch {
+	case ctx.Err() != nil, code >= 200 && code <= 299, errors.Is(err, ErrRateLimited):
+		return false
+	default:
+		return IsRetryable(err)
+	}
+}
@@ -0,0 +1,192 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestRetry(t *testing.T) {
+	retry := RetryPolicy{MaxAttempts: 3, BackoffBase: time.Millisecond, BackoffMax: time.Millisecond}
+
+	type want struct {
+		attempts int32
+		err      bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		policy RetryPolicy
+		method string
+		// responses are the status codes of consecutive attempts. An attempt
+		// beyond the last is answered with the last. A zero status code
+		// drops the connection, and a negative one never answers.
+		responses []int
+		timeout   time.Duration
+		deadline  time.Duration
+		want      want
+	}{
+		"NoRetryByDefault": {
+			reason:    "Requests should not be retried unless a retry policy is configured.",
+			method:    http.MethodGet,
+			responses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			want:      want{attempts: 1, err: true},
+		},
+		"ServerError": {
+			reason:    "Requests that get a 5xx response should be retried.",
+			method:    http.MethodGet,
+			policy:    retry,
+			responses: []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			want:      want{attempts: 3},
+		},
+		"ConnectionError": {
+			reason:    "Requests that fail to get a response should be retried.",
+			method:    http.MethodGet,
+			policy:    retry,
+			responses: []int{0, http.StatusOK},
+			want:      want{attempts: 2},
+		},
+		"ClientError": {
+			reason:    "Requests that get a 4xx response should never be retried.",
+			method:    http.MethodGet,
+			policy:    retry,
+			responses: []int{http.StatusConflict, http.StatusOK},
+			want:      want{attempts: 1, err: true},
+		},
+		"Exhausted": {
+			reason:    "Requests should be retried no more than the maximum number of attempts.",
+			method:    http.MethodGet,
+			policy:    retry,
+			responses: []int{http.StatusInternalServerError},
+			want:      want{attempts: 3, err: true},
+		},
+		"Deadline": {
+			reason:    "Requests should not be retried if the backoff would pass the deadline of the reconcile.",
+			method:    http.MethodGet,
+			policy:    RetryPolicy{MaxAttempts: 3, BackoffBase: time.Minute, BackoffMax: time.Minute},
+			responses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			deadline:  time.Second,
+			want:      want{attempts: 1, err: true},
+		},
+		"Timeout": {
+			reason:    "Requests that take longer than the timeout should fail.",
+			method:    http.MethodGet,
+			responses: []int{-1},
+			timeout:   10 * time.Millisecond,
+			want:      want{attempts: 1, err: true},
+		},
+		"RetryTimeout": {
+			reason:    "Requests that time out should be retried.",
+			method:    http.MethodGet,
+			policy:    retry,
+			responses: []int{-1, http.StatusOK},
+			timeout:   10 * time.Millisecond,
+			want:      want{attempts: 2},
+		},
+		"Put": {
+			reason:    "PUT requests are idempotent, so they should be retried.",
+			method:    http.MethodPut,
+			policy:    retry,
+			responses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			want:      want{attempts: 2},
+		},
+		"Post": {
+			reason:    "POST requests may create objects, so they should never be retried.",
+			method:    http.MethodPost,
+			policy:    retry,
+			responses: []int{http.StatusServiceUnavailable, http.StatusOK},
+			want:      want{attempts: 1, err: true},
+		},
+		"PostConnectionError": {
+			reason:    "POST requests that fail to get a response may have created an object, so they should never be retried.",
+			method:    http.MethodPost,
+			policy:    retry,
+			responses: []int{0, http.StatusOK},
+			want:      want{attempts: 1, err: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var attempts atomic.Int32
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				n := int(attempts.Add(1)) - 1
+				code := tc.responses[min(n, len(tc.responses)-1)]
+				switch code {
+				case 0:
+					conn, _, _ := w.(http.Hijacker).Hijack()
+					_ = conn.Close()
+				case -1:
+					_, _ = io.Copy(io.Discard, r.Body)
+					select {
+					case <-r.Context().Done():
+					case <-time.After(time.Second):
+					}
+				default:
+					w.WriteHeader(code)
+					_, _ = w.Write([]byte(`{}`))
+				}
+			}))
+			defer srv.Close()
+
+			ctx := context.Background()
+			if tc.deadline > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.deadline)
+				defer cancel()
+			}
+			c := NewClient(&Config{APIBase: srv.URL, APIKey: "sk-test", Timeout: tc.timeout, Retry: tc.policy})
+			err := c.Do(ctx, tc.method, "/key/info", nil, nil, nil)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want error, +got error:\n%s\n%v", tc.reason, diff, err)
+			}
+			if diff := cmp.Diff(tc.want.attempts, attempts.Load()); diff != "" {
+				t.Errorf("\n%s\nDo(...): -want attempts, +got attempts:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	p := RetryPolicy{MaxAttempts: 10, BackoffBase: 100 * time.Millisecond, BackoffMax: time.Second}
+
+	cases := map[string]struct {
+		attempt  int
+		min, max time.Duration
+	}{
+		"First":  {attempt: 0, min: 50 * time.Millisecond, max: 100 * time.Millisecond},
+		"Third":  {attempt: 2, min: 200 * time.Millisecond, max: 400 * time.Millisecond},
+		"Capped": {attempt: 9, min: 500 * time.Millisecond, max: time.Second},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			for i := 0; i < 100; i++ {
+				if d := p.backoff(tc.attempt); d < tc.min || d > tc.max {
+					t.Fatalf("backoff(%d): want between %s and %s, got %s", tc.attempt, tc.min, tc.max, d)
+				}
+			}
+		})
+	}
+}
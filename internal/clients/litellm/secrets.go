@@ -0,0 +1,64 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+)
+
+const errGetSecretOf = "cannot get secret of param %q"
+
+// SecretValues returns the values of the supplied secret keys, and the
+// resource versions of their secrets, both keyed like the supplied
+// references. The values are only ever sent to LiteLLM, never stored.
+func SecretValues(ctx context.Context, kube client.Reader, refs map[string]xpv1.SecretKeySelector) (map[string]string, map[string]string, error) {
+	values, versions := make(map[string]string, len(refs)), make(map[string]string, len(refs))
+	for k, ref := range refs {
+		s := &corev1.Secret{}
+		if err := kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, nil, errors.Wrapf(err, errGetSecretOf, k)
+		}
+		values[k] = strings.TrimSpace(string(s.Data[ref.Key]))
+		versions[k] = s.GetResourceVersion()
+	}
+	return values, versions, nil
+}
+
+// SecretsUpToDate returns true if the supplied resource versions of secrets
+// are the ones recorded when their values were last sent to LiteLLM. LiteLLM
+// redacts credentials, so whether they are up to date is tracked by the
+// versions of the secrets they were read from instead. The versions must be
+// recorded whenever the values are sent, on create as well as on update.
+func SecretsUpToDate(versions, recorded map[string]string) bool {
+	if len(versions) != len(recorded) {
+		return false
+	}
+	for k, v := range versions {
+		if rv, ok := recorded[k]; !ok || rv != v {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,134 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestSecretValues(t *testing.T) {
+	errBoom := errors.New("boom")
+	ref := func(name, key string) xpv1.SecretKeySelector {
+		return xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: name, Namespace: "crossplane-system"}, Key: key}
+	}
+	get := func(obj client.Object) error {
+		s := obj.(*corev1.Secret)
+		s.SetResourceVersion(s.GetName() + "-1")
+		s.Data = map[string][]byte{"key": []byte(" " + s.GetName() + "\n")}
+		return nil
+	}
+
+	type want struct {
+		values   map[string]string
+		versions map[string]string
+		err      error
+	}
+	cases := map[string]struct {
+		reason string
+		kube   client.Reader
+		refs   map[string]xpv1.SecretKeySelector
+		want   want
+	}{
+		"NoRefs": {
+			reason: "No references should return no values or versions.",
+			kube:   &test.MockClient{},
+			want:   want{values: map[string]string{}, versions: map[string]string{}},
+		},
+		"Refs": {
+			reason: "Values should be trimmed and keyed like their references, along with the versions of their secrets.",
+			kube: &test.MockClient{MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+				obj.SetName(key.Name)
+				return get(obj)
+			}},
+			refs: map[string]xpv1.SecretKeySelector{"api_key": ref("openai", "key"), "aws_secret_access_key": ref("aws", "key")},
+			want: want{
+				values:   map[string]string{"api_key": "openai", "aws_secret_access_key": "aws"},
+				versions: map[string]string{"api_key": "openai-1", "aws_secret_access_key": "aws-1"},
+			},
+		},
+		"GetError": {
+			reason: "Errors getting a secret should be returned.",
+			kube:   &test.MockClient{MockGet: test.NewMockGetFn(errBoom)},
+			refs:   map[string]xpv1.SecretKeySelector{"api_key": ref("openai", "key")},
+			want:   want{err: errors.Wrapf(errBoom, errGetSecretOf, "api_key")},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			values, versions, err := SecretValues(context.Background(), tc.kube, tc.refs)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nSecretValues(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.values, values); diff != "" {
+				t.Errorf("\n%s\nSecretValues(...): -want values, +got values:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.versions, versions); diff != "" {
+				t.Errorf("\n%s\nSecretValues(...): -want versions, +got versions:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestSecretsUpToDate(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		versions map[string]string
+		recorded map[string]string
+		want     bool
+	}{
+		"NoneRecorded": {
+			reason:   "No secrets and no recorded versions should be up to date, whether nil or empty.",
+			versions: map[string]string{},
+			want:     true,
+		},
+		"Same": {
+			reason:   "Secrets at their recorded versions should be up to date.",
+			versions: map[string]string{"api_key": "1"},
+			recorded: map[string]string{"api_key": "1"},
+			want:     true,
+		},
+		"Changed": {
+			reason:   "A secret that changed since it was sent should not be up to date.",
+			versions: map[string]string{"api_key": "2"},
+			recorded: map[string]string{"api_key": "1"},
+		},
+		"NotRecorded": {
+			reason:   "A secret whose version was never recorded should not be up to date.",
+			versions: map[string]string{"api_key": "1"},
+		},
+		"Removed": {
+			reason:   "A recorded secret that is no longer referenced should not be up to date.",
+			recorded: map[string]string{"api_key": "1"},
+		},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, SecretsUpToDate(tc.versions, tc.recorded)); diff != "" {
+				t.Errorf("\n%s\nSecretsUpToDate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
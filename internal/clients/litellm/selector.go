@@ -0,0 +1,80 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// LabelProviderConfig is the label that selects the ProviderConfig of a
+// managed resource that doesn't reference one explicitly.
+const LabelProviderConfig = "litellm.crossplane.io/provider-config"
+
+// AnnotationSelectedProviderConfig records the ProviderConfig that was
+// selected for a managed resource, so that a reference that was selected
+// rather than set explicitly is selected again when the label or the default
+// ProviderConfig changes.
+const AnnotationSelectedProviderConfig = "litellm.crossplane.io/selected-provider-config"
+
+// crdDefaultProviderConfig is the name the CRDs default providerConfigRef to,
+// so a reference to it counts as unset.
+const crdDefaultProviderConfig = "default"
+
+const errSelectProviderConfig = "cannot persist the selected ProviderConfig"
+
+// A ProviderConfigSelector selects the ProviderConfig of managed resources
+// that don't reference one explicitly: the one named by their
+// LabelProviderConfig label, or else the default one.
+type ProviderConfigSelector struct {
+	kube client.Client
+	def  string
+}
+
+// NewProviderConfigSelector returns a ProviderConfigSelector that selects the
+// supplied default ProviderConfig for managed resources without a label.
+func NewProviderConfigSelector(kube client.Client, def string) *ProviderConfigSelector {
+	return &ProviderConfigSelector{kube: kube, def: def}
+}
+
+// Initialize sets the ProviderConfig reference of the supplied managed
+// resource to the selected ProviderConfig, and persists it, so that
+// everything that reads the reference uses the selected ProviderConfig.
+// Managed reconcilers run it before they resolve references or connect.
+func (s *ProviderConfigSelector) Initialize(ctx context.Context, mg resource.Managed) error {
+	ref := mg.GetProviderConfigReference()
+	selected := mg.GetAnnotations()[AnnotationSelectedProviderConfig]
+	if ref != nil && ref.Name != crdDefaultProviderConfig && ref.Name != selected {
+		return nil
+	}
+	name := s.def
+	if l := mg.GetLabels()[LabelProviderConfig]; l != "" {
+		name = l
+	}
+	if ref != nil && ref.Name == name {
+		return nil
+	}
+	mg.SetProviderConfigReference(&xpv1.Reference{Name: name})
+	meta.AddAnnotations(mg, map[string]string{AnnotationSelectedProviderConfig: name})
+	return errors.Wrap(s.kube.Update(ctx, mg), errSelectProviderConfig)
+}
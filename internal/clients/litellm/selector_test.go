@@ -0,0 +1,120 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+)
+
+func TestProviderConfigSelector(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	type want struct {
+		ref     string
+		updated bool
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason      string
+		ref         *xpv1.Reference
+		labels      map[string]string
+		annotations map[string]string
+		def         string
+		updateErr   error
+		want        want
+	}{
+		"Unset": {
+			reason: "A resource without a reference or label should use the default ProviderConfig.",
+			def:    "default",
+			want:   want{ref: "default", updated: true},
+		},
+		"CRDDefault": {
+			reason: "A reference to the default ProviderConfig of the CRDs should not be persisted again.",
+			ref:    &xpv1.Reference{Name: "default"},
+			def:    "default",
+			want:   want{ref: "default"},
+		},
+		"CustomDefault": {
+			reason: "A reference to the default ProviderConfig of the CRDs counts as unset, so the ProviderConfig named by the flag should be used.",
+			ref:    &xpv1.Reference{Name: "default"},
+			def:    "shared",
+			want:   want{ref: "shared", updated: true},
+		},
+		"Labeled": {
+			reason: "A labeled resource without a reference should use the labeled ProviderConfig.",
+			ref:    &xpv1.Reference{Name: "default"},
+			labels: map[string]string{LabelProviderConfig: "team-a"},
+			def:    "shared",
+			want:   want{ref: "team-a", updated: true},
+		},
+		"Explicit": {
+			reason: "An explicit reference should win over the label.",
+			ref:    &xpv1.Reference{Name: "team-b"},
+			labels: map[string]string{LabelProviderConfig: "team-a"},
+			def:    "shared",
+			want:   want{ref: "team-b"},
+		},
+		"LabelRemoved": {
+			reason:      "A ProviderConfig that was selected by a label that was removed should be selected again.",
+			ref:         &xpv1.Reference{Name: "team-a"},
+			annotations: map[string]string{AnnotationSelectedProviderConfig: "team-a"},
+			def:         "shared",
+			want:        want{ref: "shared", updated: true},
+		},
+		"UpdateError": {
+			reason:    "Errors persisting the selected ProviderConfig should be returned.",
+			ref:       &xpv1.Reference{Name: "default"},
+			def:       "shared",
+			updateErr: errBoom,
+			want:      want{ref: "shared", updated: true, err: errors.Wrap(errBoom, errSelectProviderConfig)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var updated bool
+			kube := &test.MockClient{MockUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+				updated = true
+				return tc.updateErr
+			}}
+			mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: tc.ref}}
+			mg.SetLabels(tc.labels)
+			mg.SetAnnotations(tc.annotations)
+
+			err := NewProviderConfigSelector(kube, tc.def).Initialize(context.Background(), mg)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nInitialize(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ref, mg.GetProviderConfigReference().Name); diff != "" {
+				t.Errorf("\n%s\nInitialize(...): -want ProviderConfig, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.updated, updated); diff != "" {
+				t.Errorf("\n%s\nInitialize(...): -want updated, +got updated:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
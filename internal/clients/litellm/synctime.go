@@ -0,0 +1,105 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+)
+
+// A SyncTimer is a managed resource that records when it last synced with
+// LiteLLM.
+type SyncTimer interface {
+	GetLastSyncTime() *metav1.Time
+	SetLastSyncTime(t *metav1.Time)
+}
+
+// A SyncTimeConnecter wraps the ExternalClients of another connecter, so that
+// managed resources that are SyncTimers record when they were last observed
+// or updated successfully.
+type SyncTimeConnecter struct {
+	inner managed.ExternalConnecter
+	now   func() time.Time
+}
+
+// NewSyncTimeConnecter returns a SyncTimeConnecter that wraps the supplied
+// connecter.
+func NewSyncTimeConnecter(c managed.ExternalConnecter) *SyncTimeConnecter {
+	return &SyncTimeConnecter{inner: c, now: time.Now}
+}
+
+// Connect to LiteLLM using the wrapped connecter.
+func (c *SyncTimeConnecter) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	e, err := c.inner.Connect(ctx, mg)
+	if err != nil {
+		return nil, err
+	}
+	return &syncTimeClient{inner: e, now: c.now}, nil
+}
+
+// A syncTimeClient sets the last sync time of the managed resources it
+// observes or updates successfully. An ExternalClient may replace the whole
+// observation of a resource, so the last sync time is restored when it fails.
+type syncTimeClient struct {
+	inner managed.ExternalClient
+	now   func() time.Time
+}
+
+func (e *syncTimeClient) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	done := e.sync(mg)
+	o, err := e.inner.Observe(ctx, mg)
+	done(err == nil && o.ResourceExists)
+	return o, err
+}
+
+func (e *syncTimeClient) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	return e.inner.Create(ctx, mg)
+}
+
+func (e *syncTimeClient) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	done := e.sync(mg)
+	u, err := e.inner.Update(ctx, mg)
+	done(err == nil)
+	return u, err
+}
+
+func (e *syncTimeClient) Delete(ctx context.Context, mg resource.Managed) error {
+	return e.inner.Delete(ctx, mg)
+}
+
+// sync returns a function that sets the last sync time of the supplied
+// managed resource to now if it synced, and back to what it was otherwise.
+func (e *syncTimeClient) sync(mg resource.Managed) func(synced bool) {
+	st, ok := mg.(SyncTimer)
+	if !ok {
+		return func(bool) {}
+	}
+	last := st.GetLastSyncTime()
+	return func(synced bool) {
+		if !synced {
+			st.SetLastSyncTime(last)
+			return
+		}
+		now := metav1.NewTime(e.now())
+		st.SetLastSyncTime(&now)
+	}
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+)
+
+// syncTimed is a managed resource that records when it last synced.
+type syncTimed struct {
+	fake.Managed
+	last *metav1.Time
+}
+
+func (m *syncTimed) GetLastSyncTime() *metav1.Time  { return m.last }
+func (m *syncTimed) SetLastSyncTime(t *metav1.Time) { m.last = t }
+
+func TestSyncTime(t *testing.T) {
+	before := metav1.NewTime(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC))
+	now := time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)
+	errBoom := errors.New("boom")
+
+	type want struct {
+		observe *metav1.Time
+		update  *metav1.Time
+	}
+	cases := map[string]struct {
+		reason string
+		o      managed.ExternalObservation
+		err    error
+		want   want
+	}{
+		"Synced": {
+			reason: "A successful observe and update should advance the last sync time.",
+			o:      managed.ExternalObservation{ResourceExists: true},
+			want:   want{observe: &metav1.Time{Time: now}, update: &metav1.Time{Time: now}},
+		},
+		"NotFound": {
+			reason: "A resource that doesn't exist hasn't synced, so its last sync time should be kept.",
+			o:      managed.ExternalObservation{ResourceExists: false},
+			want:   want{observe: &before, update: &metav1.Time{Time: now}},
+		},
+		"Failed": {
+			reason: "A failed observe or update should restore the last sync time, even if the observation was replaced.",
+			err:    errBoom,
+			want:   want{observe: &before, update: &before},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			c := NewSyncTimeConnecter(managed.ExternalConnectorFn(func(_ context.Context, _ resource.Managed) (managed.ExternalClient, error) {
+				return &managed.ExternalClientFns{
+					ObserveFn: func(_ context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+						mg.(*syncTimed).last = nil
+						return tc.o, tc.err
+					},
+					UpdateFn: func(_ context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+						mg.(*syncTimed).last = nil
+						return managed.ExternalUpdate{}, tc.err
+					},
+				}, nil
+			}))
+			c.now = func() time.Time { return now }
+
+			mg := &syncTimed{last: &before}
+			e, err := c.Connect(context.Background(), mg)
+			if err != nil {
+				t.Fatalf("Connect(...): %v", err)
+			}
+
+			_, _ = e.Observe(context.Background(), mg)
+			if diff := cmp.Diff(tc.want.observe, mg.last); diff != "" {
+				t.Errorf("\n%s\nObserve(...): -want last sync time, +got last sync time:\n%s\n", tc.reason, diff)
+			}
+
+			mg.last = &before
+			_, _ = e.Update(context.Background(), mg)
+			if diff := cmp.Diff(tc.want.update, mg.last); diff != "" {
+				t.Errorf("\n%s\nUpdate(...): -want last sync time, +got last sync time:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
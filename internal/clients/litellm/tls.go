@@ -0,0 +1,149 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	errGetCABundle      = "cannot get CA bundle"
+	errParseCABundle    = "CA bundle contains no PEM encoded certificates"
+	errGetClientCert    = "cannot get client certificate"
+	errGetClientKey     = "cannot get client key"
+	errLoadClientCert   = "cannot load client certificate"
+	errClientCertAndKey = "client certificate and key must be set together"
+	errInsecure         = "TLS verification of LiteLLM is disabled by the " + AnnotationInsecureSkipVerify + " annotation of the ProviderConfig; do not use this in production"
+)
+
+// AnnotationInsecureSkipVerify skips verification of the certificate of
+// LiteLLM for a ProviderConfig when set to true, e.g. to debug against a proxy
+// with a self-signed certificate. It is ignored unless AllowInsecure is set.
+const AnnotationInsecureSkipVerify = "litellm.crossplane.io/insecure-skip-verify"
+
+// reasonInsecure is the reason of the event emitted on every reconcile of a
+// managed resource whose ProviderConfig skips TLS verification.
+const reasonInsecure event.Reason = "InsecureSkipVerify"
+
+// AllowInsecure honors AnnotationInsecureSkipVerify. It is set by the
+// --allow-insecure flag, so that the annotation can't disable verification
+// in production by accident.
+var AllowInsecure bool
+
+// InsecureSkipVerify returns true if the annotation of the supplied
+// ProviderConfig skips TLS verification, and it is allowed to.
+func InsecureSkipVerify(pc *apisv1alpha1.ProviderConfig) bool {
+	return AllowInsecure && pc.GetAnnotations()[AnnotationInsecureSkipVerify] == "true"
+}
+
+// tlsConfig returns the TLS configuration of the supplied ProviderConfig,
+// which skips verification if its annotation says so.
+func tlsConfig(pc *apisv1alpha1.ProviderConfig) *apisv1alpha1.TLSConfig {
+	if !InsecureSkipVerify(pc) {
+		return pc.Spec.TLS
+	}
+	cfg := &apisv1alpha1.TLSConfig{}
+	if pc.Spec.TLS != nil {
+		cfg = pc.Spec.TLS.DeepCopy()
+	}
+	cfg.InsecureSkipVerify = true
+	return cfg
+}
+
+// TLS is the TLS configuration of a ProviderConfig.
+type TLS struct {
+	// Config is used by the transport of the http.Client.
+	Config *tls.Config
+
+	// Hash identifies the secrets Config was built from, so that an
+	// http.Client is rebuilt when they change.
+	Hash string
+}
+
+// getTLS reads the secrets referenced by the supplied TLS configuration and
+// builds the TLS configuration of the transport from them. It returns nil if
+// the configuration is nil.
+func getTLS(ctx context.Context, kube client.Client, cfg *apisv1alpha1.TLSConfig) (*TLS, error) {
+	if cfg == nil {
+		return nil, nil
+	}
+	get := func(ref *xpv1.SecretKeySelector, msg string) ([]byte, error) {
+		if ref == nil {
+			return nil, nil
+		}
+		b, err := resource.CommonCredentialExtractor(ctx, xpv1.CredentialsSourceSecret, kube, xpv1.CommonCredentialSelectors{SecretRef: ref})
+		return b, errors.Wrap(err, msg)
+	}
+	ca, err := get(cfg.CABundleSecretRef, errGetCABundle)
+	if err != nil {
+		return nil, err
+	}
+	cert, err := get(cfg.ClientCertSecretRef, errGetClientCert)
+	if err != nil {
+		return nil, err
+	}
+	key, err := get(cfg.ClientKeySecretRef, errGetClientKey)
+	if err != nil {
+		return nil, err
+	}
+
+	tc := &tls.Config{
+		MinVersion:         tls.VersionTLS12,
+		InsecureSkipVerify: cfg.InsecureSkipVerify, //nolint:gosec // Explicitly requested by the ProviderConfig.
+	}
+	if len(ca) > 0 {
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, errors.New(errParseCABundle)
+		}
+		tc.RootCAs = pool
+	}
+	if (len(cert) > 0) != (len(key) > 0) {
+		return nil, errors.New(errClientCertAndKey)
+	}
+	if len(cert) > 0 {
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, errors.Wrap(err, errLoadClientCert)
+		}
+		tc.Certificates = []tls.Certificate{pair}
+	}
+
+	h := sha256.New()
+	for _, b := range [][]byte{ca, cert, key, []byte(strconv.FormatBool(cfg.InsecureSkipVerify))} {
+		_, _ = h.Write([]byte(strconv.Itoa(len(b))))
+		_, _ = h.Write(b)
+	}
+	return &TLS{Config: tc, Hash: hex.EncodeToString(h.Sum(nil))}, nil
+}
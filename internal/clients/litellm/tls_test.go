@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+func TestTLS(t *testing.T) {
+	// The server presents a self-signed certificate, and asks for a client
+	// certificate it doesn't verify.
+	srv := httptest.NewUnstartedServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/mtls" && len(r.TLS.PeerCertificates) == 0 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	srv.TLS = &tls.Config{ClientAuth: tls.RequestClientCert, MinVersion: tls.VersionTLS12}
+	srv.StartTLS()
+	defer srv.Close()
+
+	key, err := x509.MarshalPKCS8PrivateKey(srv.TLS.Certificates[0].PrivateKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secrets := map[string][]byte{
+		"creds":  []byte("sk-test"),
+		"ca":     pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.Certificate().Raw}),
+		"cert":   pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: srv.TLS.Certificates[0].Certificate[0]}),
+		"key":    pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: key}),
+		"not-ca": []byte("not a certificate"),
+	}
+	ref := func(name string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: name}, Key: "data"}
+	}
+
+	cases := map[string]struct {
+		reason    string
+		tls       *apisv1alpha1.TLSConfig
+		path      string
+		configErr error
+		wantErr   bool
+	}{
+		"Unconfigured": {
+			reason:  "A self-signed certificate should not be trusted without a CA bundle.",
+			path:    "/key/info",
+			wantErr: true,
+		},
+		"CABundle": {
+			reason: "A certificate signed by the CA bundle should be trusted.",
+			tls:    &apisv1alpha1.TLSConfig{CABundleSecretRef: ref("ca")},
+			path:   "/key/info",
+		},
+		"InsecureSkipVerify": {
+			reason: "Any certificate should be accepted if verification is skipped.",
+			tls:    &apisv1alpha1.TLSConfig{InsecureSkipVerify: true},
+			path:   "/key/info",
+		},
+		"ClientCert": {
+			reason: "The client certificate should be presented to LiteLLM.",
+			tls:    &apisv1alpha1.TLSConfig{CABundleSecretRef: ref("ca"), ClientCertSecretRef: ref("cert"), ClientKeySecretRef: ref("key")},
+			path:   "/mtls",
+		},
+		"NoClientCert": {
+			reason:  "No client certificate should be presented unless configured.",
+			tls:     &apisv1alpha1.TLSConfig{CABundleSecretRef: ref("ca")},
+			path:    "/mtls",
+			wantErr: true,
+		},
+		"InvalidCABundle": {
+			reason:    "A CA bundle without certificates should be an error.",
+			tls:       &apisv1alpha1.TLSConfig{CABundleSecretRef: ref("not-ca")},
+			configErr: errors.New(errParseCABundle),
+		},
+		"CertWithoutKey": {
+			reason:    "A client certificate without a key should be an error.",
+			tls:       &apisv1alpha1.TLSConfig{ClientCertSecretRef: ref("cert")},
+			configErr: errors.New(errClientCertAndKey),
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &apisv1alpha1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", UID: types.UID("tls-" + name)},
+				Spec: apisv1alpha1.ProviderConfigSpec{
+					APIBase: srv.URL,
+					TLS:     tc.tls,
+					Credentials: apisv1alpha1.ProviderCredentials{
+						Source:                    xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: ref("creds")},
+					},
+				},
+			}
+			kube := &test.MockClient{
+				MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+					switch o := obj.(type) {
+					case *apisv1alpha1.ProviderConfig:
+						pc.DeepCopyInto(o)
+					case *corev1.Secret:
+						o.Data = map[string][]byte{"data": secrets[key.Name]}
+					}
+					return nil
+				},
+			}
+			mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+
+			cfg, err := GetConfig(context.Background(), kube, mg)
+			if diff := cmp.Diff(tc.configErr, err, test.EquateErrors()); diff != "" {
+				t.Fatalf("\n%s\nGetConfig(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			err = NewClient(cfg).Get(context.Background(), tc.path, nil, nil)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nGet(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestHTTPClientTLSChange(t *testing.T) {
+	pc := &apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{UID: "tls-change", Generation: 1}}
+	cache := newHTTPClientCache()
+
+	first := cache.Get(pc, &TLS{Config: &tls.Config{MinVersion: tls.VersionTLS12}, Hash: "a"}, nil)
+	if same := cache.Get(pc, &TLS{Config: &tls.Config{MinVersion: tls.VersionTLS12}, Hash: "a"}, nil); same != first {
+		t.Errorf("Get(...): unchanged TLS secrets should reuse the http.Client")
+	}
+	if rotated := cache.Get(pc, &TLS{Config: &tls.Config{MinVersion: tls.VersionTLS12}, Hash: "b"}, nil); rotated == first {
+		t.Errorf("Get(...): changed TLS secrets should get a new http.Client")
+	}
+}
+
+func TestInsecureSkipVerify(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	gvk := schema.GroupVersionKind{Group: "test.litellm.crossplane.io", Version: "v1alpha1", Kind: "Managed"}
+	s := runtime.NewScheme()
+	s.AddKnownTypeWithName(gvk, &fake.Managed{})
+
+	cases := map[string]struct {
+		reason     string
+		allow      bool
+		annotation string
+		wantErr    bool
+		wantEvents int
+	}{
+		"NotAllowed": {
+			reason:     "The annotation should be ignored unless insecure ProviderConfigs are allowed.",
+			annotation: "true",
+			wantErr:    true,
+		},
+		"Allowed": {
+			reason:     "The annotation should skip verification, loudly, if insecure ProviderConfigs are allowed.",
+			allow:      true,
+			annotation: "true",
+			wantEvents: 2,
+		},
+		"NotAnnotated": {
+			reason:  "Verification should not be skipped just because insecure ProviderConfigs are allowed.",
+			allow:   true,
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			AllowInsecure = tc.allow
+			defer func() { AllowInsecure = false }()
+
+			pc := &apisv1alpha1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", UID: types.UID("insecure-" + name)},
+				Spec: apisv1alpha1.ProviderConfigSpec{
+					APIBase: srv.URL,
+					Credentials: apisv1alpha1.ProviderCredentials{
+						Source:                    xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: &xpv1.SecretKeySelector{Key: "creds"}},
+					},
+				},
+			}
+			if tc.annotation != "" {
+				pc.SetAnnotations(map[string]string{AnnotationInsecureSkipVerify: tc.annotation})
+			}
+			kube := &test.MockClient{
+				MockScheme: test.NewMockSchemeFn(s),
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					switch o := obj.(type) {
+					case *fake.Managed:
+						o.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+					case *apisv1alpha1.ProviderConfig:
+						pc.DeepCopyInto(o)
+					case *corev1.Secret:
+						o.Data = map[string][]byte{"creds": []byte("sk-test")}
+					}
+					return nil
+				}),
+			}
+
+			cfg, err := ConfigFor(context.Background(), kube, pc)
+			if err != nil {
+				t.Fatalf("\n%s\nConfigFor(...): %v", tc.reason, err)
+			}
+			err = NewClient(cfg).Get(context.Background(), "/key/info", nil, nil)
+			if (err != nil) != tc.wantErr {
+				t.Errorf("\n%s\nGet(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+
+			rec := &recorder{}
+			inner := reconcile.Func(func(_ context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			})
+			r := NewBreakerReconciler(kube, rec, resource.ManagedKind(gvk), inner)
+			r.breakers = newBreakerCache()
+			for i := 0; i < 2; i++ {
+				if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "cool-key"}}); err != nil {
+					t.Fatalf("\n%s\nr.Reconcile(...): %v", tc.reason, err)
+				}
+			}
+			if diff := cmp.Diff(tc.wantEvents, len(rec.events)); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+const (
+	errNoTokenSource = "serviceAccountToken must be set when the credentials source is ServiceAccountToken"
+	errReadToken     = "cannot read service account token"
+	errRequestToken  = "cannot request service account token"
+	errGetToken      = "cannot get bearer token"
+)
+
+const (
+	// tokenRefreshAfter is the fraction of the lifetime of a token after
+	// which it is refreshed, like the kubelet refreshes projected tokens.
+	tokenRefreshAfter = 0.8
+
+	// fileTokenTTL is how long a projected token whose expiry can't be read
+	// is reused before it is read again.
+	fileTokenTTL = time.Minute
+)
+
+// A TokenSource supplies the bearer token requests to LiteLLM are
+// authenticated with.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+}
+
+// A StaticToken is a TokenSource that always supplies the same token, e.g. a
+// LiteLLM key.
+type StaticToken string
+
+// Token returns the static token.
+func (t StaticToken) Token(_ context.Context) (string, error) {
+	return string(t), nil
+}
+
+// A refreshingToken is a TokenSource that reuses a fetched token until most
+// of its lifetime has passed, and then fetches a new one.
+type refreshingToken struct {
+	mu sync.Mutex
+
+	// fetch returns a token and when it expires, or the zero time if that
+	// is unknown.
+	fetch func(ctx context.Context) (string, time.Time, error)
+	now   func() time.Time
+
+	token   string
+	refresh time.Time
+}
+
+func newRefreshingToken(fetch func(ctx context.Context) (string, time.Time, error)) *refreshingToken {
+	return &refreshingToken{fetch: fetch, now: time.Now}
+}
+
+// Token returns the current token, fetching a new one if it is due to be
+// refreshed.
+func (t *refreshingToken) Token(ctx context.Context) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	now := t.now()
+	if t.token != "" && now.Before(t.refresh) {
+		return t.token, nil
+	}
+	tok, expires, err := t.fetch(ctx)
+	if err != nil {
+		return "", err
+	}
+	t.token = tok
+	t.refresh = now.Add(fileTokenTTL)
+	if !expires.IsZero() {
+		t.refresh = now.Add(time.Duration(float64(expires.Sub(now)) * tokenRefreshAfter))
+	}
+	return t.token, nil
+}
+
+// fileToken returns a function that fetches the token projected to the
+// supplied path. The kubelet refreshes the token in place.
+func fileToken(path string) func(ctx context.Context) (string, time.Time, error) {
+	return func(_ context.Context) (string, time.Time, error) {
+		b, err := os.ReadFile(path) //nolint:gosec // The path is configured by the ProviderConfig.
+		if err != nil {
+			return "", time.Time{}, errors.Wrap(err, errReadToken)
+		}
+		tok := strings.TrimSpace(string(b))
+		return tok, jwtExpiry(tok), nil
+	}
+}
+
+// requestedToken returns a function that fetches a token for the supplied
+// service account with the TokenRequest API.
+func requestedToken(kube client.Client, src *apisv1alpha1.ServiceAccountTokenSource) func(ctx context.Context) (string, time.Time, error) {
+	return func(ctx context.Context) (string, time.Time, error) {
+		sa := &corev1.ServiceAccount{ObjectMeta: metav1.ObjectMeta{Name: src.ServiceAccountRef.Name, Namespace: src.ServiceAccountRef.Namespace}}
+		tr := &authenticationv1.TokenRequest{Spec: authenticationv1.TokenRequestSpec{ExpirationSeconds: src.ExpirationSeconds}}
+		if src.Audience != "" {
+			tr.Spec.Audiences = []string{src.Audience}
+		}
+		if err := kube.SubResource("token").Create(ctx, sa, tr); err != nil {
+			return "", time.Time{}, errors.Wrap(err, errRequestToken)
+		}
+		return tr.Status.Token, tr.Status.ExpirationTimestamp.Time, nil
+	}
+}
+
+// jwtExpiry returns when the supplied JWT expires, or the zero time if it
+// can't be read. The JWT is not verified; LiteLLM does that.
+func jwtExpiry(tok string) time.Time {
+	parts := strings.Split(tok, ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	b, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return time.Time{}
+	}
+	claims := struct {
+		Exp int64 `json:"exp"`
+	}{}
+	if err := json.Unmarshal(b, &claims); err != nil || claims.Exp == 0 {
+		return time.Time{}
+	}
+	return time.Unix(claims.Exp, 0)
+}
+
+// tokenSources are shared by the Clients of all controllers, so that a
+// token is refreshed only once for every ProviderConfig.
+var tokenSources = newTokenSourceCache()
+
+// A tokenSourceCache holds the TokenSource of each ProviderConfig that
+// authenticates with a service account token.
+type tokenSourceCache struct {
+	mu      sync.Mutex
+	sources map[types.UID]cachedTokenSource
+}
+
+type cachedTokenSource struct {
+	generation int64
+	source     TokenSource
+}
+
+func newTokenSourceCache() *tokenSourceCache {
+	return &tokenSourceCache{sources: map[types.UID]cachedTokenSource{}}
+}
+
+// Get returns the TokenSource of the supplied ProviderConfig. A
+// ProviderConfig that changes gets a new TokenSource.
+func (c *tokenSourceCache) Get(kube client.Client, pc *apisv1alpha1.ProviderConfig) (TokenSource, error) {
+	src := pc.Spec.Credentials.ServiceAccountToken
+	if src == nil {
+		return nil, errors.New(errNoTokenSource)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if cached, ok := c.sources[pc.GetUID()]; ok && cached.generation == pc.GetGeneration() {
+		return cached.source, nil
+	}
+	fetch := fileToken(src.Path)
+	if src.ServiceAccountRef != nil {
+		fetch = requestedToken(kube, src.DeepCopy())
+	}
+	ts := newRefreshingToken(fetch)
+	c.sources[pc.GetUID()] = cachedTokenSource{generation: pc.GetGeneration(), source: ts}
+	return ts, nil
+}
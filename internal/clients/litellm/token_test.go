@@ -0,0 +1,187 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// jwt returns an unsigned JWT that expires at the supplied time.
+func jwt(exp time.Time) string {
+	claims := base64.RawURLEncoding.EncodeToString([]byte(fmt.Sprintf(`{"exp":%d}`, exp.Unix())))
+	return "e30." + claims + ".sig"
+}
+
+func TestRefreshingToken(t *testing.T) {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason  string
+		expires time.Time
+		elapsed time.Duration
+		want    int
+	}{
+		"Fresh": {
+			reason:  "A token should be reused while most of its lifetime remains.",
+			expires: start.Add(time.Hour),
+			elapsed: 30 * time.Minute,
+			want:    1,
+		},
+		"NearExpiry": {
+			reason:  "A token should be refreshed before it expires.",
+			expires: start.Add(time.Hour),
+			elapsed: 50 * time.Minute,
+			want:    2,
+		},
+		"UnknownExpiry": {
+			reason:  "A token whose expiry is unknown should be fetched again shortly.",
+			elapsed: 2 * time.Minute,
+			want:    2,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			fetched := 0
+			ts := newRefreshingToken(func(_ context.Context) (string, time.Time, error) {
+				fetched++
+				return fmt.Sprintf("token-%d", fetched), tc.expires, nil
+			})
+			now := start
+			ts.now = func() time.Time { return now }
+
+			if _, err := ts.Token(context.Background()); err != nil {
+				t.Fatalf("Token(...): %v", err)
+			}
+			now = now.Add(tc.elapsed)
+			got, err := ts.Token(context.Background())
+			if err != nil {
+				t.Fatalf("Token(...): %v", err)
+			}
+			if diff := cmp.Diff(fmt.Sprintf("token-%d", tc.want), got); diff != "" {
+				t.Errorf("\n%s\nToken(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestJWTExpiry(t *testing.T) {
+	exp := time.Unix(1700000000, 0)
+
+	cases := map[string]struct {
+		token string
+		want  time.Time
+	}{
+		"JWT":      {token: jwt(exp), want: exp},
+		"NotJWT":   {token: "sk-1234"},
+		"BadClaim": {token: "e30.!!!.sig"},
+	}
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, jwtExpiry(tc.token)); diff != "" {
+				t.Errorf("jwtExpiry(%q): -want, +got:\n%s", tc.token, diff)
+			}
+		})
+	}
+}
+
+func TestServiceAccountToken(t *testing.T) {
+	var got string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	projected := jwt(time.Now().Add(time.Hour))
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(projected+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := map[string]struct {
+		reason string
+		src    *apisv1alpha1.ServiceAccountTokenSource
+		want   string
+	}{
+		"Projected": {
+			reason: "The token projected to the path should be sent as the bearer token.",
+			src:    &apisv1alpha1.ServiceAccountTokenSource{Path: path},
+			want:   "Bearer " + projected,
+		},
+		"TokenRequest": {
+			reason: "A token requested for the service account should be sent as the bearer token.",
+			src: &apisv1alpha1.ServiceAccountTokenSource{
+				ServiceAccountRef: &apisv1alpha1.ServiceAccountReference{Name: "litellm", Namespace: "crossplane-system"},
+				Audience:          "litellm",
+			},
+			want: "Bearer requested-litellm",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			pc := &apisv1alpha1.ProviderConfig{
+				ObjectMeta: metav1.ObjectMeta{Name: "default", UID: types.UID("sa-token-" + name)},
+				Spec: apisv1alpha1.ProviderConfigSpec{
+					APIBase: srv.URL,
+					Credentials: apisv1alpha1.ProviderCredentials{
+						Source:              apisv1alpha1.CredentialsSourceServiceAccountToken,
+						ServiceAccountToken: tc.src,
+					},
+				},
+			}
+			kube := &test.MockClient{
+				MockSubResourceCreate: func(_ context.Context, _, sub client.Object, _ ...client.SubResourceCreateOption) error {
+					tr := sub.(*authenticationv1.TokenRequest)
+					tr.Status.Token = "requested-" + tr.Spec.Audiences[0]
+					tr.Status.ExpirationTimestamp = metav1.NewTime(time.Now().Add(time.Hour))
+					return nil
+				},
+			}
+
+			cfg, err := ConfigFor(context.Background(), kube, pc)
+			if err != nil {
+				t.Fatalf("ConfigFor(...): %v", err)
+			}
+			if err := NewClient(cfg).Get(context.Background(), "/key/info", nil, nil); err != nil {
+				t.Fatalf("Get(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nGet(...): -want Authorization, +got Authorization:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
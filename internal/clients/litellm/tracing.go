@@ -0,0 +1,97 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel/attribute"
+	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Attributes of the spans of requests to LiteLLM.
+const (
+	attrKind = attribute.Key("crossplane.managed.kind")
+	attrName = attribute.Key("crossplane.managed.name")
+)
+
+// tracing is whether requests to LiteLLM are traced. It is set by
+// EnableTracing before any Client is created.
+var tracing bool
+
+// EnableTracing traces the requests of all Clients created from now on with
+// the global OpenTelemetry TracerProvider. Requests aren't traced, nor is
+// their transport wrapped, unless it is called.
+func EnableTracing() {
+	tracing = true
+}
+
+// endpointKey is the context key of the endpoint a request is sent to, which
+// names its span.
+type endpointKey struct{}
+
+// withEndpoint returns the supplied context with the endpoint of the supplied
+// request path, if requests are traced.
+func withEndpoint(ctx context.Context, path string) context.Context {
+	if !tracing {
+		return ctx
+	}
+	p, _, _ := strings.Cut(path, "?")
+	return context.WithValue(ctx, endpointKey{}, endpoint(p))
+}
+
+// traced returns the supplied transport, wrapped so that its requests are
+// traced if tracing is enabled.
+func traced(rt http.RoundTripper) http.RoundTripper {
+	if !tracing {
+		return rt
+	}
+	return otelhttp.NewTransport(spanAttributes{inner: rt}, otelhttp.WithSpanNameFormatter(spanName))
+}
+
+// spanName returns the name of the span of the supplied request, e.g.
+// POST /key/generate.
+func spanName(_ string, r *http.Request) string {
+	ep, _ := r.Context().Value(endpointKey{}).(string)
+	if ep == "" {
+		ep = endpoint(r.URL.Path)
+	}
+	return r.Method + " " + ep
+}
+
+// spanAttributes tags the span of a request with the managed resource it is
+// sent for. It also replaces the URL otelhttp records with one whose key
+// tokens are redacted.
+type spanAttributes struct {
+	inner http.RoundTripper
+}
+
+func (t spanAttributes) RoundTrip(r *http.Request) (*http.Response, error) {
+	s := trace.SpanFromContext(r.Context())
+	if s.IsRecording() {
+		s.SetAttributes(
+			semconv.HTTPURL(redactURL(r.URL)),
+			attrKind.String(kindFrom(r.Context())),
+			attrName.String(nameFrom(r.Context())),
+		)
+	}
+	return t.inner.RoundTrip(r)
+}
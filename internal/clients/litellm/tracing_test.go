@@ -0,0 +1,85 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Traceparent") == "" {
+			t.Errorf("RoundTrip(...): want trace context propagated to LiteLLM")
+		}
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	if _, ok := newTransport(nil, nil).(*http.Transport); !ok {
+		t.Errorf("newTransport(...): requests should not be traced unless tracing is enabled")
+	}
+
+	sr := tracetest.NewSpanRecorder()
+	prev, prevProp := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(sr)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	EnableTracing()
+	defer func() {
+		tracing = false
+		otel.SetTracerProvider(prev)
+		otel.SetTextMapPropagator(prevProp)
+	}()
+
+	ctx := context.WithValue(context.WithValue(context.Background(), kindKey{}, "Key"), nameKey{}, "ci")
+	c := NewClient(&Config{APIBase: srv.URL, APIKey: "sk-master"})
+	if err := c.Get(ctx, "/key/info", url.Values{"key": []string{"sk-secret"}}, nil); err != nil {
+		t.Fatalf("Get(...): %v", err)
+	}
+
+	spans := sr.Ended()
+	if len(spans) != 1 {
+		t.Fatalf("Get(...): want 1 span, got %d", len(spans))
+	}
+	if diff := cmp.Diff("GET /key/info", spans[0].Name()); diff != "" {
+		t.Errorf("Get(...): -want span name, +got span name:\n%s", diff)
+	}
+	got := map[attribute.Key]string{}
+	for _, a := range spans[0].Attributes() {
+		got[a.Key] = a.Value.Emit()
+	}
+	want := map[attribute.Key]string{
+		attrKind:   "Key",
+		attrName:   "ci",
+		"http.url": srv.URL + "/key/info?key=REDACTED",
+	}
+	for k, v := range want {
+		if diff := cmp.Diff(v, got[k]); diff != "" {
+			t.Errorf("Get(...): -want %s, +got %s:\n%s", k, k, diff)
+		}
+	}
+}
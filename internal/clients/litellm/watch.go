@@ -0,0 +1,111 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+// secretRefs returns the Secrets referenced by the supplied ProviderConfig.
+func secretRefs(pc *apisv1alpha1.ProviderConfig) []xpv1.SecretReference {
+	refs := []*xpv1.SecretKeySelector{pc.Spec.MasterKeyRef}
+	if pc.Spec.Credentials.Source == xpv1.CredentialsSourceSecret {
+		refs = append(refs, pc.Spec.Credentials.SecretRef)
+	}
+	if t := pc.Spec.TLS; t != nil {
+		refs = append(refs, t.CABundleSecretRef, t.ClientCertSecretRef, t.ClientKeySecretRef)
+	}
+	for _, ref := range pc.Spec.HeaderSecretRefs {
+		refs = append(refs, ref.DeepCopy())
+	}
+	out := make([]xpv1.SecretReference, 0, len(refs))
+	for _, ref := range refs {
+		if ref != nil {
+			out = append(out, ref.SecretReference)
+		}
+	}
+	return out
+}
+
+// providerConfigsFor returns the names of the ProviderConfigs that reference
+// the supplied Secret.
+func providerConfigsFor(ctx context.Context, kube client.Client, s client.Object) map[string]bool {
+	l := &apisv1alpha1.ProviderConfigList{}
+	if err := kube.List(ctx, l); err != nil {
+		return nil
+	}
+	names := map[string]bool{}
+	for i := range l.Items {
+		for _, ref := range secretRefs(&l.Items[i]) {
+			if ref.Name == s.GetName() && ref.Namespace == s.GetNamespace() {
+				names[l.Items[i].GetName()] = true
+				break
+			}
+		}
+	}
+	return names
+}
+
+// ProviderConfigsForSecret returns a function that maps a Secret to the
+// ProviderConfigs that reference it.
+func ProviderConfigsForSecret(kube client.Client) handler.MapFunc {
+	return func(ctx context.Context, s client.Object) []reconcile.Request {
+		var reqs []reconcile.Request
+		for name := range providerConfigsFor(ctx, kube, s) {
+			reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: name}})
+		}
+		return reqs
+	}
+}
+
+// ManagedForSecret returns a function that maps a Secret to the managed
+// resources of the supplied kind whose ProviderConfig references it, so that
+// they connect with rotated credentials rather than fail until their next
+// poll. Managed resources are found by their ProviderConfigUsages, which
+// they record when they connect.
+func ManagedForSecret(kube client.Client, of resource.ManagedKind) handler.MapFunc {
+	apiVersion, kind := schema.GroupVersionKind(of).ToAPIVersionAndKind()
+	return func(ctx context.Context, s client.Object) []reconcile.Request {
+		pcs := providerConfigsFor(ctx, kube, s)
+		if len(pcs) == 0 {
+			return nil
+		}
+		l := &apisv1alpha1.ProviderConfigUsageList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, u := range l.Items {
+			r := u.ResourceReference
+			if pcs[u.ProviderConfigReference.Name] && r.APIVersion == apiVersion && r.Kind == kind {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: r.Name}})
+			}
+		}
+		return reqs
+	}
+}
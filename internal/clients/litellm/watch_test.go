@@ -0,0 +1,155 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package litellm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+	"github.com/crossplane/crossplane-runtime/pkg/resource/fake"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+)
+
+func TestForSecret(t *testing.T) {
+	ref := func(name string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Namespace: "crossplane-system", Name: name}, Key: "key"}
+	}
+	pc := func(name string, spec apisv1alpha1.ProviderConfigSpec) apisv1alpha1.ProviderConfig {
+		return apisv1alpha1.ProviderConfig{ObjectMeta: metav1.ObjectMeta{Name: name}, Spec: spec}
+	}
+	usage := func(pc, kind, name string) apisv1alpha1.ProviderConfigUsage {
+		u := apisv1alpha1.ProviderConfigUsage{}
+		u.ProviderConfigReference = xpv1.Reference{Name: pc}
+		u.ResourceReference = xpv1.TypedReference{APIVersion: "key.litellm.crossplane.io/v1alpha1", Kind: kind, Name: name}
+		return u
+	}
+	kube := &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			switch l := obj.(type) {
+			case *apisv1alpha1.ProviderConfigList:
+				l.Items = []apisv1alpha1.ProviderConfig{
+					pc("credentials", apisv1alpha1.ProviderConfigSpec{Credentials: apisv1alpha1.ProviderCredentials{
+						Source:                    xpv1.CredentialsSourceSecret,
+						CommonCredentialSelectors: xpv1.CommonCredentialSelectors{SecretRef: ref("master-key")},
+					}}),
+					pc("master-key", apisv1alpha1.ProviderConfigSpec{MasterKeyRef: ref("master-key")}),
+					pc("tls", apisv1alpha1.ProviderConfigSpec{TLS: &apisv1alpha1.TLSConfig{CABundleSecretRef: ref("ca")}}),
+				}
+			case *apisv1alpha1.ProviderConfigUsageList:
+				l.Items = []apisv1alpha1.ProviderConfigUsage{
+					usage("credentials", "Key", "ci"),
+					usage("master-key", "Key", "web"),
+					usage("master-key", "Team", "ml"),
+					usage("tls", "Key", "other"),
+				}
+			}
+			return nil
+		},
+	}
+	of := resource.ManagedKind(schema.GroupVersionKind{Group: "key.litellm.crossplane.io", Version: "v1alpha1", Kind: "Key"})
+	req := func(name string) reconcile.Request {
+		return reconcile.Request{NamespacedName: types.NamespacedName{Name: name}}
+	}
+	sort := cmpopts.SortSlices(func(a, b reconcile.Request) bool { return a.Name < b.Name })
+
+	cases := map[string]struct {
+		reason  string
+		secret  *corev1.Secret
+		wantPCs []reconcile.Request
+		wantMRs []reconcile.Request
+	}{
+		"Referenced": {
+			reason:  "The ProviderConfigs referencing the Secret, and the managed resources of the kind using them, should be reconciled.",
+			secret:  &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "crossplane-system", Name: "master-key"}},
+			wantPCs: []reconcile.Request{req("credentials"), req("master-key")},
+			wantMRs: []reconcile.Request{req("ci"), req("web")},
+		},
+		"OtherNamespace": {
+			reason: "A Secret of the same name in another namespace should be ignored.",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "master-key"}},
+		},
+		"Unreferenced": {
+			reason: "A Secret no ProviderConfig references should be ignored.",
+			secret: &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "crossplane-system", Name: "unrelated"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := ProviderConfigsForSecret(kube)(context.Background(), tc.secret)
+			if diff := cmp.Diff(tc.wantPCs, got, sort); diff != "" {
+				t.Errorf("\n%s\nProviderConfigsForSecret(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			got = ManagedForSecret(kube, of)(context.Background(), tc.secret)
+			if diff := cmp.Diff(tc.wantMRs, got, sort); diff != "" {
+				t.Errorf("\n%s\nManagedForSecret(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRotatedCredentials(t *testing.T) {
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "default", UID: "rotated"},
+		Spec: apisv1alpha1.ProviderConfigSpec{
+			APIBase: "http://litellm",
+			Credentials: apisv1alpha1.ProviderCredentials{
+				Source: xpv1.CredentialsSourceSecret,
+				CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+					SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "creds"}, Key: "key"},
+				},
+			},
+		},
+	}
+	current := "sk-old"
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			switch o := obj.(type) {
+			case *apisv1alpha1.ProviderConfig:
+				pc.DeepCopyInto(o)
+			case *corev1.Secret:
+				o.Data = map[string][]byte{"key": []byte(current)}
+			}
+			return nil
+		}),
+	}
+	mg := &fake.Managed{ProviderConfigReferencer: fake.ProviderConfigReferencer{Ref: &xpv1.Reference{Name: "default"}}}
+
+	for _, want := range []string{"sk-old", "sk-new"} {
+		current = want
+		cfg, err := GetConfig(context.Background(), kube, mg)
+		if err != nil {
+			t.Fatalf("GetConfig(...): %v", err)
+		}
+		if diff := cmp.Diff(want, cfg.APIKey); diff != "" {
+			t.Errorf("GetConfig(...): the current credentials should be read on every connect: -want, +got:\n%s", diff)
+		}
+	}
+}
@@ -0,0 +1,386 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertingconfig
+
+import (
+	"context"
+	"net/url"
+	"reflect"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/alerting/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotAlertingConfig = "managed resource is not an AlertingConfig custom resource"
+	errTrackPCUsage      = "cannot track ProviderConfig usage"
+	errGetConfig         = "cannot get LiteLLM configuration"
+
+	errGetSettings         = "cannot get alerting settings"
+	errGetAlertingArgs     = "cannot get alerting args"
+	errGetThreshold        = "cannot get alerting threshold"
+	errDeleteSetting       = "cannot delete alerting setting %q"
+	errUpdateSettings      = "cannot update alerting settings"
+	errDisableAlerting     = "cannot disable alerting"
+	errGetSecret           = "cannot get webhook URL secret"
+	errListAlertingConfigs = "cannot list AlertingConfigs"
+	errManagedByOtherOwner = "alerting settings of ProviderConfig %q are managed by AlertingConfig %q"
+)
+
+const (
+	// alertingSlack is the alerting integration that posts to a Slack
+	// webhook.
+	alertingSlack = "slack"
+
+	// envSlackWebhookURL is the environment variable the proxy reads the
+	// Slack webhook URL from.
+	envSlackWebhookURL = "SLACK_WEBHOOK_URL"
+
+	// fieldAlertingArgs is the general setting that holds the arguments of
+	// the alerting integrations.
+	fieldAlertingArgs = "alerting_args"
+
+	// argBudgetAlertThresholds is the alerting arg that holds the budget
+	// alert thresholds.
+	argBudgetAlertThresholds = "budget_alert_thresholds"
+
+	// fieldAlertTypes is the general setting that holds the types of
+	// alerts that are posted.
+	fieldAlertTypes = "alert_types"
+
+	// fieldAlertingThreshold is the general setting that holds how many
+	// seconds a request may take before it is reported as slow or hanging.
+	fieldAlertingThreshold = "alerting_threshold"
+)
+
+// Setup adds a controller that reconciles AlertingConfig managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.AlertingConfigGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.AlertingConfigGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.AlertingConfigKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.AlertingConfig{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.AlertingConfigGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.AlertingConfigGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied AlertingConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.AlertingConfig); !ok {
+		return nil, errors.New(errNotAlertingConfig)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external keeps the alerting settings of a LiteLLM proxy in line with an
+// AlertingConfig. Alerting exists while it is enabled.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// alert is an alerting integration reported by /get/config/callbacks.
+type alert struct {
+	Name         string            `json:"name"`
+	Variables    map[string]string `json:"variables"`
+	ActiveAlerts []string          `json:"active_alerts"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.AlertingConfig)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotAlertingConfig)
+	}
+
+	owner, err := litellm.Owner(ctx, c.kube, cr, &v1alpha1.AlertingConfigList{})
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errListAlertingConfigs)
+	}
+	// A deleted AlertingConfig that another one takes over from must not
+	// disable alerting.
+	if meta.WasDeleted(cr) && owner != "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if owner != "" && owner != cr.GetName() {
+		return managed.ExternalObservation{}, errors.Errorf(errManagedByOtherOwner, cr.GetProviderConfigReference().Name, owner)
+	}
+
+	var rsp struct {
+		Alerts []alert `json:"alerts"`
+	}
+	if err := c.client.Get(ctx, "/get/config/callbacks", nil, &rsp); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetSettings)
+	}
+	slack := slackAlert(rsp.Alerts)
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: slack != nil}, nil
+	}
+
+	args, err := c.alertingArgs(ctx)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	threshold, err := c.alertingThreshold(ctx)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	webhookURL, err := c.webhookURL(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	o := v1alpha1.AlertingConfigObservation{Enabled: slack != nil, BudgetAlertThresholds: thresholds(args), AlertingThreshold: threshold}
+	if slack != nil {
+		o.AlertTypes = slack.ActiveAlerts
+	}
+	cr.Status.AtProvider = o
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: slack != nil && slack.Variables[envSlackWebhookURL] == webhookURL && isUpToDate(cr.Spec.ForProvider, o),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	_, err := c.Update(ctx, mg)
+	return managed.ExternalCreation{}, err
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.AlertingConfig)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotAlertingConfig)
+	}
+	p := cr.Spec.ForProvider
+
+	webhookURL, err := c.webhookURL(ctx, p)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	// LiteLLM merges the supplied general settings into the existing ones,
+	// but replaces each setting as a whole.
+	settings := map[string]interface{}{"alerting": []string{alertingSlack}}
+	if p.AlertTypes != nil {
+		settings[fieldAlertTypes] = p.AlertTypes
+	}
+	if p.AlertingThreshold != nil {
+		settings[fieldAlertingThreshold] = *p.AlertingThreshold
+	}
+	if p.BudgetAlertThresholds != nil {
+		args, err := c.alertingArgs(ctx)
+		if err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+		args[argBudgetAlertThresholds] = p.BudgetAlertThresholds
+		settings[fieldAlertingArgs] = args
+	}
+
+	payload := map[string]interface{}{
+		"general_settings":      settings,
+		"environment_variables": map[string]string{envSlackWebhookURL: webhookURL},
+	}
+	if err := c.client.Post(ctx, "/config/update", payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateSettings)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete disables alerting and removes the alerting settings set by the
+// AlertingConfig. The other alerting settings are left as they are.
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.AlertingConfig)
+	if !ok {
+		return errors.New(errNotAlertingConfig)
+	}
+	p := cr.Spec.ForProvider
+
+	settings := map[string]interface{}{"alerting": []string{}}
+	if p.BudgetAlertThresholds != nil {
+		args, err := c.alertingArgs(ctx)
+		if err != nil {
+			return err
+		}
+		delete(args, argBudgetAlertThresholds)
+		settings[fieldAlertingArgs] = args
+	}
+	payload := map[string]interface{}{"general_settings": settings}
+	if err := c.client.Post(ctx, "/config/update", payload, nil); err != nil {
+		return errors.Wrap(err, errDisableAlerting)
+	}
+
+	var fields []string
+	if p.AlertTypes != nil {
+		fields = append(fields, fieldAlertTypes)
+	}
+	if p.AlertingThreshold != nil {
+		fields = append(fields, fieldAlertingThreshold)
+	}
+	for _, f := range fields {
+		payload := map[string]string{"config_type": "general_settings", "field_name": f}
+		if err := c.client.Post(ctx, "/config/field/delete", payload, nil); err != nil && !litellm.IsNotFound(err) {
+			return errors.Wrapf(err, errDeleteSetting, f)
+		}
+	}
+	return nil
+}
+
+// alertingArgs returns the alerting_args general setting, or an empty map if
+// it is not set.
+func (c *external) alertingArgs(ctx context.Context) (map[string]interface{}, error) {
+	var rsp struct {
+		FieldValue map[string]interface{} `json:"field_value"`
+	}
+	err := c.client.Get(ctx, "/config/field/info", url.Values{"field_name": []string{fieldAlertingArgs}}, &rsp)
+	if err != nil && !litellm.IsNotFound(err) {
+		return nil, errors.Wrap(err, errGetAlertingArgs)
+	}
+	if rsp.FieldValue == nil {
+		return map[string]interface{}{}, nil
+	}
+	return rsp.FieldValue, nil
+}
+
+// alertingThreshold returns the alerting_threshold general setting, or nil
+// if it is not set.
+func (c *external) alertingThreshold(ctx context.Context) (*int64, error) {
+	var rsp struct {
+		FieldValue interface{} `json:"field_value"`
+	}
+	err := c.client.Get(ctx, "/config/field/info", url.Values{"field_name": []string{fieldAlertingThreshold}}, &rsp)
+	if err != nil && !litellm.IsNotFound(err) {
+		return nil, errors.Wrap(err, errGetThreshold)
+	}
+	f, ok := rsp.FieldValue.(float64)
+	if !ok {
+		return nil, nil
+	}
+	t := int64(f)
+	return &t, nil
+}
+
+// webhookURL returns the webhook URL referenced by the supplied parameters.
+func (c *external) webhookURL(ctx context.Context, p v1alpha1.AlertingConfigParameters) (string, error) {
+	ref := p.WebhookURLSecretRef
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", errors.Wrap(err, errGetSecret)
+	}
+	return strings.TrimSpace(string(s.Data[ref.Key])), nil
+}
+
+// slackAlert returns the Slack alerting integration, or nil if alerting to
+// Slack is disabled.
+func slackAlert(alerts []alert) *alert {
+	for i := range alerts {
+		if alerts[i].Name == alertingSlack {
+			return &alerts[i]
+		}
+	}
+	return nil
+}
+
+// thresholds returns the budget alert thresholds of the supplied alerting
+// args, or nil if they are not set.
+func thresholds(args map[string]interface{}) []float64 {
+	l, ok := args[argBudgetAlertThresholds].([]interface{})
+	if !ok {
+		return nil
+	}
+	t := make([]float64, 0, len(l))
+	for _, v := range l {
+		if f, ok := v.(float64); ok {
+			t = append(t, f)
+		}
+	}
+	return t
+}
+
+// isUpToDate returns true if the observed alerting settings match every
+// setting in the supplied parameters. Settings that are not set are not
+// managed.
+func isUpToDate(p v1alpha1.AlertingConfigParameters, o v1alpha1.AlertingConfigObservation) bool {
+	if p.AlertTypes != nil && !litellm.SameStrings(p.AlertTypes, o.AlertTypes) {
+		return false
+	}
+	if p.AlertingThreshold != nil && (o.AlertingThreshold == nil || *p.AlertingThreshold != *o.AlertingThreshold) {
+		return false
+	}
+	return p.BudgetAlertThresholds == nil || reflect.DeepEqual(p.BudgetAlertThresholds, o.BudgetAlertThresholds)
+}
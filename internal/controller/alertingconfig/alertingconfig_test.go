@@ -0,0 +1,292 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package alertingconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/alerting/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+var created = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+var webhook = xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "slack", Namespace: "crossplane-system"}, Key: "webhook-url"}
+
+func alertingConfig(name string, age time.Duration, p v1alpha1.AlertingConfigParameters) *v1alpha1.AlertingConfig {
+	p.WebhookURLSecretRef = webhook
+	cr := &v1alpha1.AlertingConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Time{Time: created.Add(-age)}},
+		Spec:       v1alpha1.AlertingConfigSpec{ForProvider: p},
+	}
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+	return cr
+}
+
+// kube returns a client that lists the supplied AlertingConfigs, and gets a
+// secret holding the supplied webhook URL.
+func kube(url string, acs ...*v1alpha1.AlertingConfig) client.Client {
+	return &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			l := obj.(*v1alpha1.AlertingConfigList)
+			for _, ac := range acs {
+				l.Items = append(l.Items, *ac)
+			}
+			return nil
+		},
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.(*corev1.Secret).Data = map[string][]byte{"webhook-url": []byte(url + "\n")}
+			return nil
+		}),
+	}
+}
+
+func TestObserve(t *testing.T) {
+	enabled := fake.Response{Body: `{"alerts": [{"name": "slack", "variables": {"SLACK_WEBHOOK_URL": "https://hooks.slack.com/1"}, "active_alerts": ["llm_exceptions", "budget_alerts"]}]}`}
+	disabled := fake.Response{Body: `{"alerts": []}`}
+	args := fake.Response{Body: `{"field_name": "alerting_args", "field_value": {"budget_alert_thresholds": [0.5, 0.8], "budget_alert_ttl": 86400}}`}
+	desired := v1alpha1.AlertingConfigParameters{
+		AlertTypes:            []string{"budget_alerts", "llm_exceptions"},
+		BudgetAlertThresholds: []float64{0.5, 0.8},
+	}
+	threshold := int64(600)
+	deleted := alertingConfig("deleted", time.Hour, desired)
+	deleted.SetDeletionTimestamp(&metav1.Time{Time: created})
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		url       string
+		cr        *v1alpha1.AlertingConfig
+		others    []*v1alpha1.AlertingConfig
+		want      want
+	}{
+		"UpToDate": {
+			reason:    "Alerting settings matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled, "/config/field/info": args},
+			url:       "https://hooks.slack.com/1",
+			cr:        alertingConfig("default", 0, desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"UnsetSettingsIgnored": {
+			reason:    "Alerting settings that are not in the spec should not be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled},
+			url:       "https://hooks.slack.com/1",
+			cr:        alertingConfig("default", 0, v1alpha1.AlertingConfigParameters{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"Disabled": {
+			reason:    "Disabled alerting should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": disabled, "/config/field/info": args},
+			url:       "https://hooks.slack.com/1",
+			cr:        alertingConfig("default", 0, desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"WebhookRotated": {
+			reason:    "A webhook URL that differs from its secret should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled, "/config/field/info": args},
+			url:       "https://hooks.slack.com/2",
+			cr:        alertingConfig("default", 0, desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"AlertTypesDrifted": {
+			reason:    "Alert types that differ from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled, "/config/field/info": args},
+			url:       "https://hooks.slack.com/1",
+			cr:        alertingConfig("default", 0, v1alpha1.AlertingConfigParameters{AlertTypes: []string{"budget_alerts"}}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"AlertingThresholdDrifted": {
+			reason: "An alerting threshold that differs from the spec should be reported as drift.",
+			responses: map[string]fake.Response{
+				"/get/config/callbacks":                            enabled,
+				"/config/field/info":                               args,
+				"/config/field/info?field_name=alerting_threshold": {Body: `{"field_name": "alerting_threshold", "field_value": 300}`},
+			},
+			url:  "https://hooks.slack.com/1",
+			cr:   alertingConfig("default", 0, v1alpha1.AlertingConfigParameters{AlertingThreshold: &threshold}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ThresholdsDrifted": {
+			reason:    "Budget alert thresholds that differ from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled, "/config/field/info": args},
+			url:       "https://hooks.slack.com/1",
+			cr:        alertingConfig("default", 0, v1alpha1.AlertingConfigParameters{BudgetAlertThresholds: []float64{0.9}}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ManagedByOlder": {
+			reason:    "An AlertingConfig should not manage alerting settings that an older AlertingConfig of the same ProviderConfig manages.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled},
+			cr:        alertingConfig("newer", 0, desired),
+			others:    []*v1alpha1.AlertingConfig{alertingConfig("older", time.Hour, desired)},
+			want:      want{err: errors.Errorf(errManagedByOtherOwner, "default", "older")},
+		},
+		"DeletedEnabled": {
+			reason:    "A deleted AlertingConfig should exist until alerting is disabled.",
+			responses: map[string]fake.Response{"/get/config/callbacks": enabled},
+			cr:        deleted,
+			want:      want{o: managed.ExternalObservation{ResourceExists: true}},
+		},
+		"DeletedDisabled": {
+			reason:    "A deleted AlertingConfig should be gone once alerting is disabled.",
+			responses: map[string]fake.Response{"/get/config/callbacks": disabled},
+			cr:        deleted,
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"DeletedTakenOver": {
+			reason:    "A deleted AlertingConfig another AlertingConfig takes over from should be gone without disabling alerting.",
+			responses: map[string]fake.Response{},
+			cr:        deleted,
+			others:    []*v1alpha1.AlertingConfig{alertingConfig("newer", 0, desired)},
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{kube: kube(tc.url, append(tc.others, tc.cr)...), client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/config/field/info": {Body: `{"field_name": "alerting_args", "field_value": {"budget_alert_ttl": 86400}}`},
+		"/config/update":     {Body: `{}`},
+	})
+	defer srv.Close()
+
+	threshold := int64(600)
+	cr := alertingConfig("default", 0, v1alpha1.AlertingConfigParameters{
+		AlertTypes:            []string{"budget_alerts"},
+		BudgetAlertThresholds: []float64{0.5, 0.8},
+		AlertingThreshold:     &threshold,
+	})
+	e := external{kube: kube("https://hooks.slack.com/1", cr), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"general_settings": map[string]interface{}{
+			"alerting":           []interface{}{"slack"},
+			"alert_types":        []interface{}{"budget_alerts"},
+			"alerting_threshold": float64(600),
+			"alerting_args": map[string]interface{}{
+				"budget_alert_ttl":        float64(86400),
+				"budget_alert_thresholds": []interface{}{0.5, 0.8},
+			},
+		},
+		"environment_variables": map[string]interface{}{"SLACK_WEBHOOK_URL": "https://hooks.slack.com/1"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/config/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	threshold := int64(600)
+
+	type want struct {
+		update  map[string]interface{}
+		deleted []interface{}
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.AlertingConfigParameters
+		want   want
+	}{
+		"DisableOnly": {
+			reason: "An AlertingConfig that set no other settings should only disable alerting.",
+			want: want{
+				update: map[string]interface{}{"general_settings": map[string]interface{}{"alerting": []interface{}{}}},
+			},
+		},
+		"RemoveSettings": {
+			reason: "An AlertingConfig should remove the settings it set, and keep the alerting args it didn't.",
+			p: v1alpha1.AlertingConfigParameters{
+				AlertTypes:            []string{"budget_alerts"},
+				BudgetAlertThresholds: []float64{0.5},
+				AlertingThreshold:     &threshold,
+			},
+			want: want{
+				update: map[string]interface{}{"general_settings": map[string]interface{}{
+					"alerting":      []interface{}{},
+					"alerting_args": map[string]interface{}{"budget_alert_ttl": float64(86400)},
+				}},
+				deleted: []interface{}{"alert_types", "alerting_threshold"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/config/update":       {Body: `{}`},
+				"/config/field/delete": {Body: `{}`},
+				"/config/field/info":   {Body: `{"field_name": "alerting_args", "field_value": {"budget_alert_thresholds": [0.5], "budget_alert_ttl": 86400}}`},
+			})
+			defer srv.Close()
+
+			e := external{kube: kube(""), client: srv.Client()}
+			if err := e.Delete(context.Background(), alertingConfig("default", 0, tc.p)); err != nil {
+				t.Fatalf("\n%s\ne.Delete(...): %v", tc.reason, err)
+			}
+
+			if diff := cmp.Diff(tc.want.update, srv.Body("/config/update")); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			var deleted []interface{}
+			for _, r := range srv.Requests() {
+				if r.Path == "/config/field/delete" {
+					deleted = append(deleted, r.Body["field_name"])
+				}
+			}
+			if diff := cmp.Diff(tc.want.deleted, deleted); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want deleted settings, +got deleted settings:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
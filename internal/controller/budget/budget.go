@@ -0,0 +1,327 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package budget
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/budget/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotBudget    = "managed resource is not a Budget custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetBudget    = "cannot get budget"
+	errCreateBudget = "cannot create budget"
+	errUpdateBudget = "cannot update budget"
+	errDeleteBudget = "cannot delete budget"
+
+	// resetDelay is how long after the reset of a budget it is observed
+	// again, to give LiteLLM time to reset it.
+	resetDelay = 30 * time.Second
+)
+
+// Setup adds a controller that reconciles Budget managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.BudgetGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.BudgetGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.BudgetKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient(), pollInterval(time.Now))),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Budget{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.BudgetGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.BudgetGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Budget.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Budget); !ok {
+		return nil, errors.New(errNotBudget)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// budget to ensure it reflects the managed resource's desired state.
+type external struct {
+	client *litellm.Client
+}
+
+// budgetInfo is a budget as returned by /budget/info.
+type budgetInfo struct {
+	BudgetID            string                     `json:"budget_id"`
+	MaxBudget           *float64                   `json:"max_budget"`
+	SoftBudget          *float64                   `json:"soft_budget"`
+	BudgetDuration      string                     `json:"budget_duration"`
+	TPMLimit            *int64                     `json:"tpm_limit"`
+	RPMLimit            *int64                     `json:"rpm_limit"`
+	MaxParallelRequests *int64                     `json:"max_parallel_requests"`
+	ModelMaxBudget      map[string]json.RawMessage `json:"model_max_budget"`
+	BudgetResetAt       string                     `json:"budget_reset_at"`
+	CreatedAt           string                     `json:"created_at"`
+	UpdatedAt           string                     `json:"updated_at"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Budget)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotBudget)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	// /budget/info looks up several budgets at once and omits unknown ones.
+	var rsp []budgetInfo
+	err := c.client.Post(ctx, "/budget/info", map[string]interface{}{"budgets": []string{id}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetBudget)
+	}
+	var info *budgetInfo
+	for i := range rsp {
+		if rsp[i].BudgetID == id {
+			info = &rsp[i]
+		}
+	}
+	if info == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	setObservation(&cr.Status.AtProvider, info)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr.Spec.ForProvider, info),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Budget)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotBudget)
+	}
+
+	var rsp struct {
+		BudgetID string `json:"budget_id"`
+	}
+	if err := c.client.Post(ctx, "/budget/new", generatePayload(meta.GetExternalName(cr), cr.Spec.ForProvider), &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateBudget)
+	}
+	if rsp.BudgetID != "" {
+		meta.SetExternalName(cr, rsp.BudgetID)
+	}
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Budget)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotBudget)
+	}
+
+	if err := c.client.Post(ctx, "/budget/update", generatePayload(meta.GetExternalName(cr), cr.Spec.ForProvider), nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateBudget)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Budget)
+	if !ok {
+		return errors.New(errNotBudget)
+	}
+
+	err := c.client.Post(ctx, "/budget/delete", map[string]interface{}{"id": meta.GetExternalName(cr)}, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteBudget)
+}
+
+// setObservation updates the supplied observation with what LiteLLM returned.
+func setObservation(o *v1alpha1.BudgetObservation, info *budgetInfo) {
+	o.BudgetID = info.BudgetID
+	o.BudgetResetAt = parseTime(info.BudgetResetAt)
+	o.CreatedAt = parseTime(info.CreatedAt)
+	o.UpdatedAt = parseTime(info.UpdatedAt)
+	o.MaxBudget = info.MaxBudget
+	o.SoftBudget = info.SoftBudget
+	o.BudgetDuration = info.BudgetDuration
+	o.TPMLimit = info.TPMLimit
+	o.RPMLimit = info.RPMLimit
+	o.MaxParallelRequests = info.MaxParallelRequests
+}
+
+// parseTime returns the supplied LiteLLM timestamp, or nil if it is empty or
+// can't be parsed.
+func parseTime(s string) *metav1.Time {
+	t, err := litellm.ParseTime(s)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+// pollInterval returns a hook that observes a Budget shortly after its next
+// reset if that is sooner than the regular poll interval, so that its status
+// reflects the new budget window quickly.
+func pollInterval(now func() time.Time) managed.PollIntervalHook {
+	return func(mg resource.Managed, interval time.Duration) time.Duration {
+		cr, ok := mg.(*v1alpha1.Budget)
+		if !ok || cr.Status.AtProvider.BudgetResetAt == nil {
+			return interval
+		}
+		until := cr.Status.AtProvider.BudgetResetAt.Sub(now()) + resetDelay
+		if until <= 0 || until >= interval {
+			return interval
+		}
+		return until
+	}
+}
+
+// generatePayload returns the /budget/new and /budget/update payload for the
+// supplied parameters.
+func generatePayload(id string, p v1alpha1.BudgetParameters) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if id != "" {
+		payload["budget_id"] = id
+	}
+	if p.MaxBudget != nil {
+		payload["max_budget"] = *p.MaxBudget
+	}
+	if p.SoftBudget != nil {
+		payload["soft_budget"] = *p.SoftBudget
+	}
+	if p.BudgetDuration != "" {
+		payload["budget_duration"] = p.BudgetDuration
+	}
+	if p.TPMLimit != nil {
+		payload["tpm_limit"] = *p.TPMLimit
+	}
+	if p.RPMLimit != nil {
+		payload["rpm_limit"] = *p.RPMLimit
+	}
+	if p.MaxParallelRequests != nil {
+		payload["max_parallel_requests"] = *p.MaxParallelRequests
+	}
+	if len(p.ModelMaxBudget) > 0 {
+		payload["model_max_budget"] = p.ModelMaxBudget
+	}
+	return payload
+}
+
+// isUpToDate returns true if the observed budget matches every field set in
+// the supplied parameters. Fields that are not set are not managed.
+func isUpToDate(p v1alpha1.BudgetParameters, o *budgetInfo) bool {
+	if !sameFloat(p.MaxBudget, o.MaxBudget) || !sameFloat(p.SoftBudget, o.SoftBudget) {
+		return false
+	}
+	if p.BudgetDuration != "" && p.BudgetDuration != o.BudgetDuration {
+		return false
+	}
+	if !sameInt(p.TPMLimit, o.TPMLimit) || !sameInt(p.RPMLimit, o.RPMLimit) || !sameInt(p.MaxParallelRequests, o.MaxParallelRequests) {
+		return false
+	}
+	observed := litellm.ParseModelMaxBudget(o.ModelMaxBudget)
+	for m, b := range p.ModelMaxBudget {
+		if ob, ok := observed[m]; !ok || ob != b {
+			return false
+		}
+	}
+	return true
+}
+
+// sameFloat returns true if desired is unset or equal to observed.
+func sameFloat(desired, observed *float64) bool {
+	return desired == nil || observed != nil && *desired == *observed
+}
+
+// sameInt returns true if desired is unset or equal to observed.
+func sameInt(desired, observed *int64) bool {
+	return desired == nil || observed != nil && *desired == *observed
+}
@@ -0,0 +1,202 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/budget/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func budget(id string, p v1alpha1.BudgetParameters) *v1alpha1.Budget {
+	cr := &v1alpha1.Budget{Spec: v1alpha1.BudgetSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	maxBudget := 100.0
+	rpm := int64(60)
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Budget
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A budget missing from the /budget/info response should be reported as absent.",
+			responses: map[string]fake.Response{"/budget/info": {Body: `[]`}},
+			cr:        budget("tier-1", v1alpha1.BudgetParameters{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A budget matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/budget/info": {Body: `[{"budget_id": "tier-1", "max_budget": 100, "rpm_limit": 60, "budget_duration": "30d", "model_max_budget": {"gpt-4o": {"max_budget": 20}}}]`}},
+			cr: budget("tier-1", v1alpha1.BudgetParameters{
+				MaxBudget:      &maxBudget,
+				RPMLimit:       &rpm,
+				BudgetDuration: "30d",
+				ModelMaxBudget: map[string]float64{"gpt-4o": 20},
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"Drifted": {
+			reason:    "A budget whose max budget differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/budget/info": {Body: `[{"budget_id": "tier-1", "max_budget": 50}]`}},
+			cr:        budget("tier-1", v1alpha1.BudgetParameters{MaxBudget: &maxBudget}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveStatus(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/budget/info": {Body: `[{"budget_id": "tier-1", "max_budget": 100, "soft_budget": 80, "budget_duration": "30d", "rpm_limit": 60, "budget_reset_at": "2024-07-01T00:00:00", "created_at": "2024-05-01T10:00:00Z", "updated_at": "2024-06-01 10:00:00"}]`}})
+	defer srv.Close()
+
+	cr := budget("tier-1", v1alpha1.BudgetParameters{})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+
+	maxBudget, softBudget, rpm := 100.0, 80.0, int64(60)
+	want := v1alpha1.BudgetObservation{
+		BudgetID:       "tier-1",
+		BudgetResetAt:  &metav1.Time{Time: time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)},
+		CreatedAt:      &metav1.Time{Time: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)},
+		UpdatedAt:      &metav1.Time{Time: time.Date(2024, 6, 1, 10, 0, 0, 0, time.UTC)},
+		MaxBudget:      &maxBudget,
+		SoftBudget:     &softBudget,
+		BudgetDuration: "30d",
+		RPMLimit:       &rpm,
+	}
+	if diff := cmp.Diff(want, cr.Status.AtProvider); diff != "" {
+		t.Errorf("e.Observe(...): -want status, +got status:\n%s", diff)
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	now := time.Date(2024, 6, 30, 23, 55, 0, 0, time.UTC)
+	at := func(t time.Time) v1alpha1.BudgetObservation {
+		return v1alpha1.BudgetObservation{BudgetResetAt: &metav1.Time{Time: t}}
+	}
+
+	cases := map[string]struct {
+		reason string
+		obs    v1alpha1.BudgetObservation
+		want   time.Duration
+	}{
+		"NoReset": {
+			reason: "A budget that never resets should be polled at the regular interval.",
+			want:   time.Hour,
+		},
+		"ResetSoon": {
+			reason: "A budget that resets before the next poll should be observed shortly after its reset.",
+			obs:    at(now.Add(5 * time.Minute)),
+			want:   5*time.Minute + resetDelay,
+		},
+		"ResetLater": {
+			reason: "A budget that resets after the next poll should be polled at the regular interval.",
+			obs:    at(now.Add(24 * time.Hour)),
+			want:   time.Hour,
+		},
+		"ResetPast": {
+			reason: "A budget whose reset time has passed should be polled at the regular interval.",
+			obs:    at(now.Add(-time.Hour)),
+			want:   time.Hour,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := budget("tier-1", v1alpha1.BudgetParameters{})
+			cr.Status.AtProvider = tc.obs
+			got := pollInterval(func() time.Time { return now })(cr, time.Hour)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\npollInterval(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/budget/new": {Body: `{"budget_id": "tier-1"}`}})
+	defer srv.Close()
+
+	maxBudget := 100.0
+	cr := budget("tier-1", v1alpha1.BudgetParameters{MaxBudget: &maxBudget, BudgetDuration: "30d"})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"budget_id":       "tier-1",
+		"max_budget":      float64(100),
+		"budget_duration": "30d",
+	}
+	if diff := cmp.Diff(want, srv.Body("/budget/new")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/budget/delete": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), budget("tier-1", v1alpha1.BudgetParameters{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"id": "tier-1"}
+	if diff := cmp.Diff(want, srv.Body("/budget/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
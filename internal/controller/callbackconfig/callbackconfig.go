@@ -0,0 +1,372 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callbackconfig
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/callback/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotCallbackConfig = "managed resource is not a CallbackConfig custom resource"
+	errTrackPCUsage      = "cannot track ProviderConfig usage"
+	errGetConfig         = "cannot get LiteLLM configuration"
+
+	errGetCallbacks         = "cannot get callbacks"
+	errUpdateCallbacks      = "cannot update callbacks"
+	errDeleteCallback       = "cannot delete callback %q"
+	errGetSecret            = "cannot get secret of environment variable %q"
+	errListCallbackConfigs  = "cannot list CallbackConfigs"
+	errSecretKeyNotFound    = "secret %s/%s has no key %q"
+	errEnvVarValueUndefined = "environment variable %q has no value"
+)
+
+const (
+	// typeSuccess and typeFailure are the types of the callbacks reported
+	// by /get/config/callbacks that are called for successful and failed
+	// requests. A callback that is called for both is reported once for
+	// each type.
+	typeSuccess = "success"
+	typeFailure = "failure"
+)
+
+// Setup adds a controller that reconciles CallbackConfig managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.CallbackConfigGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.CallbackConfigGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.CallbackConfigKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.CallbackConfig{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.CallbackConfigGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.CallbackConfigGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied CallbackConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.CallbackConfig); !ok {
+		return nil, errors.New(errNotCallbackConfig)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external keeps the logging callbacks of a LiteLLM proxy in line with a
+// CallbackConfig. The callbacks exist while any of them is active.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// callback is a logging callback reported by /get/config/callbacks.
+type callback struct {
+	Name      string            `json:"name"`
+	Type      string            `json:"type"`
+	Variables map[string]string `json:"variables"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.CallbackConfig)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCallbackConfig)
+	}
+
+	var rsp struct {
+		Callbacks []callback `json:"callbacks"`
+	}
+	if err := c.client.Get(ctx, "/get/config/callbacks", nil, &rsp); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetCallbacks)
+	}
+
+	// Only the callbacks of this CallbackConfig are observed, so that other
+	// CallbackConfigs and the config file can manage the rest.
+	p := cr.Spec.ForProvider
+	o := v1alpha1.CallbackConfigObservation{
+		SuccessCallbacks: active(rsp.Callbacks, typeSuccess, p.SuccessCallbacks),
+		FailureCallbacks: active(rsp.Callbacks, typeFailure, p.FailureCallbacks),
+		AppliedCallbacks: cr.Status.AtProvider.AppliedCallbacks,
+	}
+	exists := len(o.SuccessCallbacks)+len(o.FailureCallbacks) > 0
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: exists}, nil
+	}
+	if !exists {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	env, err := c.env(ctx, p.Env)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	cr.Status.AtProvider = o
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists: true,
+		ResourceUpToDate: len(o.SuccessCallbacks) == len(p.SuccessCallbacks) &&
+			len(o.FailureCallbacks) == len(p.FailureCallbacks) &&
+			len(removed(o.AppliedCallbacks, p)) == 0 &&
+			envUpToDate(env, rsp.Callbacks),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	_, err := c.Update(ctx, mg)
+	return managed.ExternalCreation{}, err
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.CallbackConfig)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCallbackConfig)
+	}
+	p := cr.Spec.ForProvider
+
+	env, err := c.env(ctx, p.Env)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+
+	// LiteLLM adds the supplied callbacks to the existing ones rather than
+	// replacing them.
+	settings := map[string]interface{}{}
+	if len(p.SuccessCallbacks) > 0 {
+		settings["success_callback"] = p.SuccessCallbacks
+	}
+	if len(p.FailureCallbacks) > 0 {
+		settings["failure_callback"] = p.FailureCallbacks
+	}
+	payload := map[string]interface{}{"litellm_settings": settings}
+	if len(env) > 0 {
+		payload["environment_variables"] = env
+	}
+	if err := c.client.Post(ctx, "/config/update", payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCallbacks)
+	}
+	// Which is why the callbacks that were removed from the spec must be
+	// deleted one by one.
+	if err := c.remove(ctx, cr, removed(cr.Status.AtProvider.AppliedCallbacks, p)); err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	cr.Status.AtProvider.AppliedCallbacks = names(p)
+
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete removes the callbacks of the CallbackConfig that no other
+// CallbackConfig of the same ProviderConfig lists.
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.CallbackConfig)
+	if !ok {
+		return errors.New(errNotCallbackConfig)
+	}
+
+	return c.remove(ctx, cr, append(names(cr.Spec.ForProvider), removed(cr.Status.AtProvider.AppliedCallbacks, cr.Spec.ForProvider)...))
+}
+
+// remove deletes the supplied callbacks of the supplied CallbackConfig that
+// no other CallbackConfig of the same ProviderConfig lists.
+func (c *external) remove(ctx context.Context, cr *v1alpha1.CallbackConfig, callbacks []string) error {
+	if len(callbacks) == 0 {
+		return nil
+	}
+	shared, err := c.shared(ctx, cr)
+	if err != nil {
+		return errors.Wrap(err, errListCallbackConfigs)
+	}
+	for _, name := range callbacks {
+		if shared[name] {
+			continue
+		}
+		err := c.client.Post(ctx, "/config/callback/delete", map[string]string{"callback_name": name}, nil)
+		if err != nil && !litellm.IsNotFound(err) {
+			return errors.Wrapf(err, errDeleteCallback, name)
+		}
+	}
+	return nil
+}
+
+// shared returns the callbacks listed by the other CallbackConfigs of the
+// ProviderConfig of the supplied CallbackConfig that aren't being deleted.
+func (c *external) shared(ctx context.Context, cr *v1alpha1.CallbackConfig) (map[string]bool, error) {
+	l := &v1alpha1.CallbackConfigList{}
+	if err := c.kube.List(ctx, l); err != nil {
+		return nil, err
+	}
+
+	pc := cr.GetProviderConfigReference()
+	shared := map[string]bool{}
+	for i := range l.Items {
+		cc := &l.Items[i]
+		ref := cc.GetProviderConfigReference()
+		if cc.GetName() == cr.GetName() || meta.WasDeleted(cc) || ref == nil || pc == nil || ref.Name != pc.Name {
+			continue
+		}
+		for _, name := range names(cc.Spec.ForProvider) {
+			shared[name] = true
+		}
+	}
+	return shared, nil
+}
+
+// env returns the values of the supplied environment variables.
+func (c *external) env(ctx context.Context, vars []v1alpha1.EnvVar) (map[string]string, error) {
+	env := make(map[string]string, len(vars))
+	for _, v := range vars {
+		switch {
+		case v.Value != nil:
+			env[v.Name] = *v.Value
+		case v.SecretKeyRef != nil:
+			ref := v.SecretKeyRef
+			s := &corev1.Secret{}
+			if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+				return nil, errors.Wrapf(err, errGetSecret, v.Name)
+			}
+			val, ok := s.Data[ref.Key]
+			if !ok {
+				return nil, errors.Errorf(errSecretKeyNotFound, ref.Namespace, ref.Name, ref.Key)
+			}
+			env[v.Name] = strings.TrimSpace(string(val))
+		default:
+			return nil, errors.Errorf(errEnvVarValueUndefined, v.Name)
+		}
+	}
+	return env, nil
+}
+
+// names returns the names of the callbacks of the supplied parameters.
+func names(p v1alpha1.CallbackConfigParameters) []string {
+	seen := map[string]bool{}
+	var out []string
+	for _, name := range append(append([]string{}, p.SuccessCallbacks...), p.FailureCallbacks...) {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// removed returns the supplied applied callbacks that the supplied parameters
+// no longer list.
+func removed(applied []string, p v1alpha1.CallbackConfigParameters) []string {
+	listed := map[string]bool{}
+	for _, name := range names(p) {
+		listed[name] = true
+	}
+	var out []string
+	for _, name := range applied {
+		if !listed[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// active returns the desired callbacks of the supplied type that are
+// active, in the order they are desired.
+func active(callbacks []callback, typ string, desired []string) []string {
+	set := map[string]bool{}
+	for _, cb := range callbacks {
+		if cb.Type == typ {
+			set[cb.Name] = true
+		}
+	}
+	var out []string
+	for _, name := range desired {
+		if set[name] {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// envUpToDate returns true if every desired environment variable that the
+// proxy reports as a variable of a callback has the desired value. The proxy
+// only reports the variables of the callbacks it knows, so the others can't
+// be compared.
+func envUpToDate(env map[string]string, callbacks []callback) bool {
+	for _, cb := range callbacks {
+		for k, v := range cb.Variables {
+			if want, ok := env[k]; ok && want != v {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,259 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package callbackconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/callback/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+var secretKey = v1alpha1.EnvVar{
+	Name:         "LANGFUSE_SECRET_KEY",
+	SecretKeyRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "langfuse", Namespace: "crossplane-system"}, Key: "secret-key"},
+}
+
+func callbackConfig(name string, p v1alpha1.CallbackConfigParameters) *v1alpha1.CallbackConfig {
+	cr := &v1alpha1.CallbackConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       v1alpha1.CallbackConfigSpec{ForProvider: p},
+	}
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+	return cr
+}
+
+// kube returns a client that lists the supplied CallbackConfigs, and gets a
+// secret holding the supplied Langfuse secret key.
+func kube(key string, ccs ...*v1alpha1.CallbackConfig) client.Client {
+	return &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			l := obj.(*v1alpha1.CallbackConfigList)
+			for _, cc := range ccs {
+				l.Items = append(l.Items, *cc)
+			}
+			return nil
+		},
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.(*corev1.Secret).Data = map[string][]byte{"secret-key": []byte(key)}
+			return nil
+		}),
+	}
+}
+
+func TestObserve(t *testing.T) {
+	callbacks := fake.Response{Body: `{"callbacks": [
+		{"name": "langfuse", "type": "success", "variables": {"LANGFUSE_SECRET_KEY": "sk-lf-1", "LANGFUSE_HOST": null}},
+		{"name": "datadog", "type": "success", "variables": {}},
+		{"name": "langfuse", "type": "failure", "variables": {"LANGFUSE_SECRET_KEY": "sk-lf-1"}}
+	]}`}
+	none := fake.Response{Body: `{"callbacks": []}`}
+	desired := v1alpha1.CallbackConfigParameters{
+		SuccessCallbacks: []string{"langfuse"},
+		FailureCallbacks: []string{"langfuse"},
+		Env:              []v1alpha1.EnvVar{secretKey},
+	}
+	removedOne := callbackConfig("langfuse", desired)
+	removedOne.Status.AtProvider.AppliedCallbacks = []string{"langfuse", "datadog"}
+	deleted := callbackConfig("deleted", desired)
+	deleted.SetDeletionTimestamp(&metav1.Time{Time: time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)})
+
+	type want struct {
+		o  managed.ExternalObservation
+		ob v1alpha1.CallbackConfigObservation
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		key       string
+		cr        *v1alpha1.CallbackConfig
+		want      want
+	}{
+		"UpToDate": {
+			reason:    "Active callbacks matching the spec should be up to date, regardless of other callbacks.",
+			responses: map[string]fake.Response{"/get/config/callbacks": callbacks},
+			key:       "sk-lf-1",
+			cr:        callbackConfig("langfuse", desired),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				ob: v1alpha1.CallbackConfigObservation{SuccessCallbacks: []string{"langfuse"}, FailureCallbacks: []string{"langfuse"}},
+			},
+		},
+		"CallbackMissing": {
+			reason:    "A desired callback that isn't active should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": callbacks},
+			key:       "sk-lf-1",
+			cr:        callbackConfig("langfuse", v1alpha1.CallbackConfigParameters{SuccessCallbacks: []string{"langfuse", "otel"}}),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				ob: v1alpha1.CallbackConfigObservation{SuccessCallbacks: []string{"langfuse"}},
+			},
+		},
+		"SecretRotated": {
+			reason:    "A callback variable that differs from its secret should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": callbacks},
+			key:       "sk-lf-2",
+			cr:        callbackConfig("langfuse", desired),
+			want: want{
+				o:  managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				ob: v1alpha1.CallbackConfigObservation{SuccessCallbacks: []string{"langfuse"}, FailureCallbacks: []string{"langfuse"}},
+			},
+		},
+		"CallbackRemoved": {
+			reason:    "A callback that was applied but removed from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": callbacks},
+			key:       "sk-lf-1",
+			cr:        removedOne,
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				ob: v1alpha1.CallbackConfigObservation{
+					SuccessCallbacks: []string{"langfuse"},
+					FailureCallbacks: []string{"langfuse"},
+					AppliedCallbacks: []string{"langfuse", "datadog"},
+				},
+			},
+		},
+		"NotActive": {
+			reason:    "A CallbackConfig none of whose callbacks are active should not exist.",
+			responses: map[string]fake.Response{"/get/config/callbacks": none},
+			cr:        callbackConfig("langfuse", desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"DeletedActive": {
+			reason:    "A deleted CallbackConfig should exist until its callbacks are removed.",
+			responses: map[string]fake.Response{"/get/config/callbacks": callbacks},
+			cr:        deleted,
+			want:      want{o: managed.ExternalObservation{ResourceExists: true}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{kube: kube(tc.key), client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.ob, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/config/update": {Body: `{}`}})
+	defer srv.Close()
+
+	host := "https://cloud.langfuse.com"
+	cr := callbackConfig("langfuse", v1alpha1.CallbackConfigParameters{
+		SuccessCallbacks: []string{"langfuse"},
+		Env:              []v1alpha1.EnvVar{secretKey, {Name: "LANGFUSE_HOST", Value: &host}},
+	})
+	e := external{kube: kube("sk-lf-1\n"), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"litellm_settings": map[string]interface{}{"success_callback": []interface{}{"langfuse"}},
+		"environment_variables": map[string]interface{}{
+			"LANGFUSE_SECRET_KEY": "sk-lf-1",
+			"LANGFUSE_HOST":       host,
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/config/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"langfuse"}, cr.Status.AtProvider.AppliedCallbacks); diff != "" {
+		t.Errorf("e.Update(...): -want applied callbacks, +got applied callbacks:\n%s", diff)
+	}
+}
+
+func TestUpdateRemoved(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/config/update":          {Body: `{}`},
+		"/config/callback/delete": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	cr := callbackConfig("logging", v1alpha1.CallbackConfigParameters{SuccessCallbacks: []string{"langfuse"}})
+	cr.Status.AtProvider.AppliedCallbacks = []string{"langfuse", "datadog", "otel"}
+	other := callbackConfig("otel", v1alpha1.CallbackConfigParameters{SuccessCallbacks: []string{"otel"}})
+	e := external{kube: kube("", cr, other), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	var got []interface{}
+	for _, r := range srv.Requests() {
+		if r.Path == "/config/callback/delete" {
+			got = append(got, r.Body["callback_name"])
+		}
+	}
+	// otel is listed by another CallbackConfig too, so it is kept.
+	if diff := cmp.Diff([]interface{}{"datadog"}, got); diff != "" {
+		t.Errorf("e.Update(...): -want deleted callbacks, +got deleted callbacks:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{"langfuse"}, cr.Status.AtProvider.AppliedCallbacks); diff != "" {
+		t.Errorf("e.Update(...): -want applied callbacks, +got applied callbacks:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/config/callback/delete": {Body: `{}`}})
+	defer srv.Close()
+
+	cr := callbackConfig("logging", v1alpha1.CallbackConfigParameters{
+		SuccessCallbacks: []string{"langfuse", "datadog"},
+		FailureCallbacks: []string{"langfuse"},
+	})
+	cr.Status.AtProvider.AppliedCallbacks = []string{"langfuse", "datadog", "otel"}
+	other := callbackConfig("datadog", v1alpha1.CallbackConfigParameters{SuccessCallbacks: []string{"datadog"}})
+	e := external{kube: kube("", cr, other), client: srv.Client()}
+	if err := e.Delete(context.Background(), cr); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	var got []interface{}
+	for _, r := range srv.Requests() {
+		got = append(got, r.Body["callback_name"])
+	}
+	// datadog is listed by another CallbackConfig too, so it is kept, while
+	// otel was removed from the spec but never from the proxy.
+	if diff := cmp.Diff([]interface{}{"langfuse", "otel"}, got); diff != "" {
+		t.Errorf("e.Delete(...): -want deleted callbacks, +got deleted callbacks:\n%s", diff)
+	}
+}
@@ -0,0 +1,150 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/providerconfig"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+)
+
+const (
+	errGetPC        = "cannot get ProviderConfig"
+	errUpdateStatus = "cannot update ProviderConfig status"
+	errGetConfig    = "cannot get LiteLLM settings of ProviderConfig"
+	errReadiness    = "cannot check readiness of LiteLLM"
+	errAuthenticate = "cannot authenticate to LiteLLM"
+
+	reasonUnauthorized event.Reason = "Unauthorized"
+)
+
+// SetupHealth adds a controller that periodically checks whether LiteLLM can
+// be reached and authenticated to with the settings of each ProviderConfig.
+func SetupHealth(mgr ctrl.Manager, o controller.Options) error {
+	name := "health/" + providerconfig.ControllerName(v1alpha1.ProviderConfigGroupKind)
+
+	r := &HealthReconciler{
+		kube:        mgr.GetClient(),
+		recorder:    event.NewAPIRecorder(mgr.GetEventRecorderFor(name)),
+		newClientFn: litellm.NewClient,
+		forgetFn:    litellm.ForgetHTTPClient,
+		interval:    o.PollInterval,
+	}
+
+	// Status updates, including our own, don't change the generation, so
+	// they don't trigger a health check; the interval does. So does a
+	// rotated secret, so that fixed credentials aren't reported unhealthy
+	// until the next interval.
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ProviderConfig{}, builder.WithPredicates(predicate.GenerationChangedPredicate{})).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ProviderConfigsForSecret(mgr.GetClient()))).
+		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+}
+
+// A HealthReconciler records in the status of a ProviderConfig whether
+// LiteLLM can be reached and authenticated to with its settings, along with
+// the version of LiteLLM and the status of its database.
+type HealthReconciler struct {
+	kube        client.Client
+	recorder    event.Recorder
+	newClientFn func(*litellm.Config) *litellm.Client
+	forgetFn    func(name string)
+	interval    time.Duration
+}
+
+// Reconcile checks the health of the supplied ProviderConfig, and checks it
+// again once the interval has passed.
+func (r *HealthReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	pc := &v1alpha1.ProviderConfig{}
+	if err := r.kube.Get(ctx, req.NamespacedName, pc); err != nil {
+		// A ProviderConfig that is gone won't be used again, so drop the
+		// connections to its LiteLLM.
+		if kerrors.IsNotFound(err) {
+			r.forgetFn(req.Name)
+		}
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errGetPC)
+	}
+	if meta.WasDeleted(pc) {
+		return reconcile.Result{}, nil
+	}
+
+	orig := pc.DeepCopy()
+	r.check(ctx, pc)
+	if err := r.kube.Status().Patch(ctx, pc, client.MergeFrom(orig)); err != nil {
+		return reconcile.Result{}, errors.Wrap(resource.IgnoreNotFound(err), errUpdateStatus)
+	}
+	return reconcile.Result{RequeueAfter: r.interval}, nil
+}
+
+// check sets the Healthy condition of the supplied ProviderConfig. LiteLLM is
+// healthy if it is ready, and accepts the credentials of the ProviderConfig.
+func (r *HealthReconciler) check(ctx context.Context, pc *v1alpha1.ProviderConfig) {
+	cfg, err := litellm.ConfigFor(ctx, r.kube, pc)
+	if err != nil {
+		pc.SetConditions(v1alpha1.Unhealthy(errors.Wrap(err, errGetConfig).Error()))
+		return
+	}
+	c := r.newClientFn(cfg)
+
+	var rsp struct {
+		DB      string `json:"db"`
+		Version string `json:"litellm_version"`
+	}
+	if err := c.Get(ctx, "/health/readiness", nil, &rsp); err != nil {
+		pc.SetConditions(v1alpha1.Unhealthy(errors.Wrap(err, errReadiness).Error()))
+		return
+	}
+	pc.Status.ProxyVersion = rsp.Version
+	pc.Status.Database = rsp.DB
+
+	// /key/info describes the key the request is authenticated with. The
+	// master key isn't stored as a key, so it may not be found, but it is
+	// authenticated all the same.
+	err = c.Get(ctx, "/key/info", nil, nil)
+	if litellm.IsAuthError(err) {
+		err = errors.Wrap(err, errAuthenticate)
+		r.recorder.Event(pc, event.Warning(reasonUnauthorized, err))
+		pc.SetConditions(v1alpha1.Unauthorized(err.Error()))
+		return
+	}
+	if err != nil && !litellm.IsNotFound(err) {
+		pc.SetConditions(v1alpha1.Unhealthy(errors.Wrap(err, errAuthenticate).Error()))
+		return
+	}
+	pc.SetConditions(v1alpha1.Healthy())
+}
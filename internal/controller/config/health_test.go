@@ -0,0 +1,176 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+// recorder records the events it is sent.
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestHealthReconciler(t *testing.T) {
+	readiness := `{"status": "healthy", "db": "connected", "litellm_version": "1.74.0"}`
+
+	type want struct {
+		status   corev1.ConditionStatus
+		reason   xpv1.ConditionReason
+		version  string
+		database string
+		events   int
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		want      want
+	}{
+		"Healthy": {
+			reason: "A ready LiteLLM that accepts the credentials should be healthy.",
+			responses: map[string]fake.Response{
+				"/health/readiness": {Body: readiness},
+				"/key/info":         {Body: `{"key": "sk-test", "info": {}}`},
+			},
+			want: want{status: corev1.ConditionTrue, reason: v1alpha1.ReasonHealthCheckPassed, version: "1.74.0", database: "connected"},
+		},
+		"MasterKey": {
+			reason: "The master key, which isn't stored as a key, should be healthy.",
+			responses: map[string]fake.Response{
+				"/health/readiness": {Body: readiness},
+			},
+			want: want{status: corev1.ConditionTrue, reason: v1alpha1.ReasonHealthCheckPassed, version: "1.74.0", database: "connected"},
+		},
+		"Unauthorized": {
+			reason: "Rejected credentials should be reported, and emit a warning.",
+			responses: map[string]fake.Response{
+				"/health/readiness": {Body: readiness},
+				"/key/info":         {Status: http.StatusUnauthorized, Body: `{"error": "invalid key"}`},
+			},
+			want: want{status: corev1.ConditionFalse, reason: v1alpha1.ReasonUnauthorized, version: "1.74.0", database: "connected", events: 1},
+		},
+		"NotReady": {
+			reason: "A LiteLLM that isn't ready should be unhealthy.",
+			responses: map[string]fake.Response{
+				"/health/readiness": {Status: http.StatusServiceUnavailable, Body: `{"status": "unhealthy"}`},
+			},
+			want: want{status: corev1.ConditionFalse, reason: v1alpha1.ReasonHealthCheckFailed},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			var got *v1alpha1.ProviderConfig
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					switch o := obj.(type) {
+					case *v1alpha1.ProviderConfig:
+						o.ObjectMeta = metav1.ObjectMeta{Name: "default"}
+						o.Spec.APIBase = srv.URL
+						o.Spec.Credentials = v1alpha1.ProviderCredentials{
+							Source: xpv1.CredentialsSourceSecret,
+							CommonCredentialSelectors: xpv1.CommonCredentialSelectors{
+								SecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "creds"}, Key: "key"},
+							},
+						}
+					case *corev1.Secret:
+						o.Data = map[string][]byte{"key": []byte("sk-test")}
+					}
+					return nil
+				}),
+				MockStatusPatch: func(_ context.Context, obj client.Object, _ client.Patch, _ ...client.SubResourcePatchOption) error {
+					got = obj.(*v1alpha1.ProviderConfig)
+					return nil
+				},
+			}
+			rec := &recorder{}
+			r := &HealthReconciler{
+				kube:        kube,
+				recorder:    rec,
+				newClientFn: func(_ *litellm.Config) *litellm.Client { return srv.Client() },
+				interval:    time.Minute,
+			}
+
+			res, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "default"}})
+			if err != nil {
+				t.Fatalf("\n%s\nr.Reconcile(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(reconcile.Result{RequeueAfter: time.Minute}, res); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want result, +got result:\n%s\n", tc.reason, diff)
+			}
+			c := got.Status.GetCondition(v1alpha1.TypeHealthy)
+			if diff := cmp.Diff(tc.want.status, c.Status); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.reason, c.Reason); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want reason, +got reason:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.version, got.Status.ProxyVersion); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want version, +got version:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.database, got.Status.Database); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want database, +got database:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, len(rec.events)); diff != "" {
+				t.Errorf("\n%s\nr.Reconcile(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestHealthReconcilerDeleted(t *testing.T) {
+	var forgot []string
+	r := &HealthReconciler{
+		kube:     &test.MockClient{MockGet: test.NewMockGetFn(kerrors.NewNotFound(schema.GroupResource{}, "default"))},
+		forgetFn: func(name string) { forgot = append(forgot, name) },
+	}
+	if _, err := r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "default"}}); err != nil {
+		t.Fatalf("r.Reconcile(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{"default"}, forgot); diff != "" {
+		t.Errorf("r.Reconcile(...): -want forgotten, +got forgotten:\n%s", diff)
+	}
+}
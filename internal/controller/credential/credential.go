@@ -0,0 +1,349 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/credential/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotCredential = "managed resource is not a Credential custom resource"
+	errTrackPCUsage  = "cannot track ProviderConfig usage"
+	errGetConfig     = "cannot get LiteLLM configuration"
+
+	errGetCredential    = "cannot get credential"
+	errCreateCredential = "cannot create credential"
+	errUpdateCredential = "cannot update credential"
+	errDeleteCredential = "cannot delete credential"
+	errGetSecret        = "cannot get secret %s/%s"
+	errCredentialInfo   = "credentialInfo must be a JSON object"
+)
+
+// Setup adds a controller that reconciles Credential managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.CredentialGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.CredentialGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.CredentialKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Credential{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.CredentialGroupVersionKind)))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(credentialsForSecret(mgr.GetClient()))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.CredentialGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// credentialsForSecret returns a function that maps a Secret to the
+// Credentials whose values it holds, so that rotated values are sent to the
+// proxy.
+func credentialsForSecret(kube client.Client) handler.MapFunc {
+	return func(ctx context.Context, s client.Object) []reconcile.Request {
+		l := &v1alpha1.CredentialList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, c := range l.Items {
+			for _, ref := range secretRefs(c.Spec.ForProvider) {
+				if ref.Name == s.GetName() && ref.Namespace == s.GetNamespace() {
+					reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: c.GetName()}})
+					break
+				}
+			}
+		}
+		return reqs
+	}
+}
+
+// secretRefs returns the secrets referenced by the supplied parameters.
+func secretRefs(p v1alpha1.CredentialParameters) []xpv1.SecretReference {
+	refs := make([]xpv1.SecretReference, 0, len(p.CredentialValues)+1)
+	if p.CredentialValuesSecretRef != nil {
+		refs = append(refs, *p.CredentialValuesSecretRef)
+	}
+	for _, ref := range p.CredentialValues {
+		refs = append(refs, ref.SecretReference)
+	}
+	return refs
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Credential.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Credential); !ok {
+		return nil, errors.New(errNotCredential)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// credential to ensure it reflects the managed resource's desired state.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// credentialInfo is a credential returned by /credentials/by_name.
+type credentialInfo struct {
+	CredentialName string                 `json:"credential_name"`
+	CredentialInfo map[string]interface{} `json:"credential_info"`
+}
+
+// name returns the credential_name of the supplied Credential.
+func name(cr *v1alpha1.Credential) string {
+	if n := cr.Spec.ForProvider.CredentialName; n != "" {
+		return n
+	}
+	return meta.GetExternalName(cr)
+}
+
+// path returns the /credentials path of the supplied credential name.
+func path(name string) string {
+	return "/credentials/" + url.PathEscape(name)
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Credential)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCredential)
+	}
+
+	n := name(cr)
+	if n == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	info := &credentialInfo{}
+	err := c.client.Get(ctx, "/credentials/by_name/"+url.PathEscape(n), nil, info)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetCredential)
+	}
+
+	ci, err := credentialInfoObject(cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	_, versions, err := c.values(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	valuesUpToDate := litellm.SecretsUpToDate(versions, cr.Status.AtProvider.SecretVersions)
+
+	cr.Status.AtProvider.CredentialName = info.CredentialName
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: valuesUpToDate && litellm.SameValue(ci, nonNilObject(info.CredentialInfo)),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Credential)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCredential)
+	}
+
+	n := name(cr)
+	payload, versions, err := c.generatePayload(ctx, n, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	// A credential that already exists is adopted, and updated on the next
+	// reconcile if it differs. Its values can't be told apart from ours, so
+	// their versions are only recorded if it was created.
+	err = c.client.Post(ctx, "/credentials", payload, nil)
+	if err != nil && !litellm.IsConflict(err) {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateCredential)
+	}
+	meta.SetExternalName(cr, n)
+	if err == nil && len(versions) > 0 {
+		cr.Status.AtProvider.SecretVersions = versions
+	}
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Credential)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCredential)
+	}
+
+	n := name(cr)
+	payload, versions, err := c.generatePayload(ctx, n, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := c.client.Do(ctx, http.MethodPatch, path(n), nil, payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCredential)
+	}
+	cr.Status.AtProvider.SecretVersions = nil
+	if len(versions) > 0 {
+		cr.Status.AtProvider.SecretVersions = versions
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Credential)
+	if !ok {
+		return errors.New(errNotCredential)
+	}
+
+	err := c.client.Do(ctx, http.MethodDelete, path(name(cr)), nil, nil, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteCredential)
+}
+
+// values returns the credential values read from the secrets referenced by
+// the supplied parameters, and the resource versions of those secrets. The
+// version of CredentialValuesSecretRef is keyed by its namespace/name, and
+// those of CredentialValues by value name.
+func (c *external) values(ctx context.Context, p v1alpha1.CredentialParameters) (map[string]interface{}, map[string]string, error) {
+	values, versions := map[string]interface{}{}, map[string]string{}
+	if ref := p.CredentialValuesSecretRef; ref != nil {
+		s := &corev1.Secret{}
+		if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+			return nil, nil, errors.Wrapf(err, errGetSecret, ref.Namespace, ref.Name)
+		}
+		versions[ref.Namespace+"/"+ref.Name] = s.GetResourceVersion()
+		for k, v := range s.Data {
+			values[k] = strings.TrimSpace(string(v))
+		}
+	}
+	vs, vv, err := litellm.SecretValues(ctx, c.kube, p.CredentialValues)
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range vs {
+		values[k] = v
+		versions[k] = vv[k]
+	}
+	return values, versions, nil
+}
+
+// nonNilObject returns the supplied object, or an empty object if it is nil.
+func nonNilObject(m map[string]interface{}) map[string]interface{} {
+	if m == nil {
+		return map[string]interface{}{}
+	}
+	return m
+}
+
+// generatePayload returns the POST and PATCH /credentials payload for the
+// supplied parameters, and the resource versions of the secrets the values
+// were read from.
+func (c *external) generatePayload(ctx context.Context, name string, p v1alpha1.CredentialParameters) (map[string]interface{}, map[string]string, error) {
+	ci, err := credentialInfoObject(p)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, versions, err := c.values(ctx, p)
+	if err != nil {
+		return nil, nil, err
+	}
+	return map[string]interface{}{
+		"credential_name":   name,
+		"credential_values": values,
+		"credential_info":   ci,
+	}, versions, nil
+}
+
+// credentialInfoObject returns the credentialInfo of the supplied parameters,
+// or an empty object if it is unset.
+func credentialInfoObject(p v1alpha1.CredentialParameters) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if p.CredentialInfo == nil || len(p.CredentialInfo.Raw) == 0 {
+		return obj, nil
+	}
+	if err := json.Unmarshal(p.CredentialInfo.Raw, &obj); err != nil {
+		return nil, errors.Wrap(err, errCredentialInfo)
+	}
+	return obj, nil
+}
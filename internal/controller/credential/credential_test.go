@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package credential
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/credential/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func credential(name string, p v1alpha1.CredentialParameters) *v1alpha1.Credential {
+	cr := &v1alpha1.Credential{Spec: v1alpha1.CredentialSpec{ForProvider: p}}
+	meta.SetExternalName(cr, name)
+	return cr
+}
+
+// params sources all values of the azure-prod secret, and overrides its
+// api_key with a key of the azure-openai secret.
+func params() v1alpha1.CredentialParameters {
+	return v1alpha1.CredentialParameters{
+		CredentialInfo:            &runtime.RawExtension{Raw: []byte(`{"custom_llm_provider": "azure"}`)},
+		CredentialValuesSecretRef: &xpv1.SecretReference{Name: "azure-prod", Namespace: "crossplane-system"},
+		CredentialValues: map[string]xpv1.SecretKeySelector{
+			"api_key": {SecretReference: xpv1.SecretReference{Name: "azure-openai", Namespace: "crossplane-system"}, Key: "api-key"},
+		},
+	}
+}
+
+func secretClient(version string) *test.MockClient {
+	return &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			s := obj.(*corev1.Secret)
+			s.SetResourceVersion(version)
+			switch key.Name {
+			case "azure-prod":
+				s.Data = map[string][]byte{"api_key": []byte("stale"), "api_base": []byte("https://prod.openai.azure.com")}
+			case "azure-openai":
+				s.Data = map[string][]byte{"api-key": []byte("az-1\n")}
+			}
+			return nil
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	info := fake.Response{Body: `{
+		"credential_name": "azure-prod",
+		"credential_values": {"api_key": "az-*****", "api_base": "https://prod.openai.azure.com"},
+		"credential_info": {"custom_llm_provider": "azure"}
+	}`}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		p         func(p *v1alpha1.CredentialParameters)
+		version   string
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A credential LiteLLM doesn't know should be reported as absent.",
+			responses: map[string]fake.Response{},
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A credential matching the spec should be up to date, even though its values are redacted.",
+			responses: map[string]fake.Response{"GET /credentials/by_name/azure-prod": info},
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"SecretRotated": {
+			reason:    "A credential whose secrets changed since they were sent should not be up to date.",
+			responses: map[string]fake.Response{"GET /credentials/by_name/azure-prod": info},
+			version:   "2",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"InfoDrifted": {
+			reason:    "A credential whose info differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"GET /credentials/by_name/azure-prod": info},
+			p: func(p *v1alpha1.CredentialParameters) {
+				p.CredentialInfo = &runtime.RawExtension{Raw: []byte(`{"custom_llm_provider": "openai"}`)}
+			},
+			version: "1",
+			want:    want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"Error": {
+			reason:    "Errors getting the credential should be returned.",
+			responses: map[string]fake.Response{"GET /credentials/by_name/azure-prod": {Status: http.StatusInternalServerError, Body: "boom"}},
+			version:   "1",
+			want: want{
+				err: errors.Wrap(errors.New("GET /credentials/by_name/azure-prod returned unexpected status 500: boom"), errGetCredential),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			p := params()
+			if tc.p != nil {
+				tc.p(&p)
+			}
+			cr := credential("azure-prod", p)
+			cr.Status.AtProvider.SecretVersions = map[string]string{"crossplane-system/azure-prod": "1", "api_key": "1"}
+			e := external{kube: secretClient(tc.version), client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		rsp      fake.Response
+		versions map[string]string
+	}{
+		"Created": {
+			reason:   "The versions of the secrets sent to a created credential should be recorded.",
+			rsp:      fake.Response{Body: `{"success": true}`},
+			versions: map[string]string{"crossplane-system/azure-prod": "1", "api_key": "1"},
+		},
+		"Adopted": {
+			reason: "The versions of the secrets should not be recorded for an adopted credential, whose values may differ.",
+			rsp:    fake.Response{Status: http.StatusConflict, Body: "exists"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"POST /credentials": tc.rsp})
+			defer srv.Close()
+
+			p := params()
+			p.CredentialName = "azure-prod-eu"
+			cr := credential("azure-prod", p)
+			e := external{kube: secretClient("1"), client: srv.Client()}
+			if _, err := e.Create(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Create(...): %v", tc.reason, err)
+			}
+
+			want := map[string]interface{}{
+				"credential_name": "azure-prod-eu",
+				"credential_values": map[string]interface{}{
+					"api_key":  "az-1",
+					"api_base": "https://prod.openai.azure.com",
+				},
+				"credential_info": map[string]interface{}{"custom_llm_provider": "azure"},
+			}
+			if diff := cmp.Diff(want, srv.Body("/credentials")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want body, +got body:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff("azure-prod-eu", meta.GetExternalName(cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.versions, cr.Status.AtProvider.SecretVersions); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want secret versions, +got secret versions:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"PATCH /credentials/azure-prod": {Body: `{"success": true}`}})
+	defer srv.Close()
+
+	cr := credential("azure-prod", params())
+	e := external{kube: secretClient("2"), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"credential_name": "azure-prod",
+		"credential_values": map[string]interface{}{
+			"api_key":  "az-1",
+			"api_base": "https://prod.openai.azure.com",
+		},
+		"credential_info": map[string]interface{}{"custom_llm_provider": "azure"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/credentials/azure-prod")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+	versions := map[string]string{"crossplane-system/azure-prod": "2", "api_key": "2"}
+	if diff := cmp.Diff(versions, cr.Status.AtProvider.SecretVersions); diff != "" {
+		t.Errorf("e.Update(...): -want secret versions, +got secret versions:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		want      error
+	}{
+		"Deleted": {
+			reason:    "Deleting a credential should succeed.",
+			responses: map[string]fake.Response{"DELETE /credentials/azure-prod": {Body: `{"success": true}`}},
+		},
+		"NotFound": {
+			reason:    "Deleting a credential that is already gone should succeed.",
+			responses: map[string]fake.Response{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			err := e.Delete(context.Background(), credential("azure-prod", v1alpha1.CredentialParameters{}))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,342 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/customer/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotCustomer  = "managed resource is not a Customer custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetCustomer     = "cannot get customer"
+	errCreateCustomer  = "cannot create customer"
+	errUpdateCustomer  = "cannot update customer"
+	errDeleteCustomer  = "cannot delete customer"
+	errBlockCustomer   = "cannot block customer"
+	errUnblockCustomer = "cannot unblock customer"
+
+	// Event reasons.
+	reasonBlocked    event.Reason = "CustomerBlocked"
+	reasonUnblocked  event.Reason = "CustomerUnblocked"
+	reasonOverBudget event.Reason = "BudgetExceeded"
+)
+
+// Setup adds a controller that reconciles Customer managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.CustomerGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.CustomerGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.CustomerKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			recorder:    recorder,
+			newClientFn: litellm.NewClient})))),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Customer{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.CustomerGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.CustomerGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	recorder    event.Recorder
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Customer.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Customer); !ok {
+		return nil, errors.New(errNotCustomer)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg), recorder: c.recorder}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// end-user to ensure it reflects the managed resource's desired state.
+type external struct {
+	client   *litellm.Client
+	recorder event.Recorder
+}
+
+// customerInfo is returned by /customer/info.
+type customerInfo struct {
+	UserID             string  `json:"user_id"`
+	Alias              string  `json:"alias"`
+	BudgetID           string  `json:"budget_id"`
+	AllowedModelRegion string  `json:"allowed_model_region"`
+	DefaultModel       string  `json:"default_model"`
+	Blocked            bool    `json:"blocked"`
+	Spend              float64 `json:"spend"`
+	Budget             *struct {
+		MaxBudget     *float64 `json:"max_budget"`
+		BudgetResetAt string   `json:"budget_reset_at"`
+	} `json:"litellm_budget_table"`
+}
+
+// maxBudget returns the max budget of the customer, if any.
+func (i *customerInfo) maxBudget() *float64 {
+	if i.Budget == nil {
+		return nil
+	}
+	return i.Budget.MaxBudget
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Customer)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotCustomer)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var raw json.RawMessage
+	err := c.client.Get(ctx, "/customer/info", url.Values{"end_user_id": []string{id}}, &raw)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetCustomer)
+	}
+	if litellm.IsNull(raw) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	info := &customerInfo{}
+	if err := litellm.Decode(ctx, raw, info, litellm.RenamedSpend); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetCustomer)
+	}
+
+	setObservation(&cr.Status.AtProvider, info)
+	c.setBudgetCondition(cr, info.Alias)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr.Spec.ForProvider, info),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Customer)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotCustomer)
+	}
+
+	id := cr.Spec.ForProvider.UserID
+	if id == "" {
+		id = meta.GetExternalName(cr)
+	}
+
+	payload := generatePayload(id, cr.Spec.ForProvider)
+	if cr.Spec.ForProvider.Blocked {
+		payload["blocked"] = true
+	}
+	// A customer that already exists is adopted, and updated on the next
+	// reconcile if it differs.
+	if err := c.client.Post(ctx, "/customer/new", payload, nil); err != nil && !litellm.IsConflict(err) {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateCustomer)
+	}
+	meta.SetExternalName(cr, id)
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Customer)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotCustomer)
+	}
+
+	id := meta.GetExternalName(cr)
+	if err := c.client.Post(ctx, "/customer/update", generatePayload(id, cr.Spec.ForProvider), nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateCustomer)
+	}
+
+	// Blocking has dedicated endpoints; /customer/update doesn't reliably
+	// apply it.
+	if cr.Spec.ForProvider.Blocked == cr.Status.AtProvider.Blocked {
+		return managed.ExternalUpdate{}, nil
+	}
+	ids := map[string]interface{}{"user_ids": []string{id}}
+	if cr.Spec.ForProvider.Blocked {
+		if err := c.client.Post(ctx, "/customer/block", ids, nil); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errBlockCustomer)
+		}
+		c.recorder.Event(cr, event.Normal(reasonBlocked, "Blocked customer "+id))
+	} else {
+		if err := c.client.Post(ctx, "/customer/unblock", ids, nil); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUnblockCustomer)
+		}
+		c.recorder.Event(cr, event.Normal(reasonUnblocked, "Unblocked customer "+id))
+	}
+	cr.Status.AtProvider.Blocked = cr.Spec.ForProvider.Blocked
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Customer)
+	if !ok {
+		return errors.New(errNotCustomer)
+	}
+
+	payload := map[string]interface{}{"user_ids": []string{meta.GetExternalName(cr)}}
+	err := c.client.Post(ctx, "/customer/delete", payload, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteCustomer)
+}
+
+// setObservation updates the supplied observation with what LiteLLM returned.
+func setObservation(o *v1alpha1.CustomerObservation, info *customerInfo) {
+	o.UserID = info.UserID
+	o.Blocked = info.Blocked
+	o.Spend = info.Spend
+	o.MaxBudget = info.maxBudget()
+	o.BudgetResetAt = nil
+	if info.Budget == nil {
+		return
+	}
+	if t, err := litellm.ParseTime(info.Budget.BudgetResetAt); err == nil {
+		o.BudgetResetAt = &metav1.Time{Time: t}
+	}
+}
+
+// setBudgetCondition sets whether the customer has reached its budget, and
+// emits a warning event when it first does.
+func (c *external) setBudgetCondition(cr *v1alpha1.Customer, alias string) {
+	o := cr.Status.AtProvider
+	if o.MaxBudget == nil || o.Spend < *o.MaxBudget {
+		cr.SetConditions(apisv1alpha1.WithinBudget())
+		return
+	}
+
+	if alias == "" {
+		alias = meta.GetExternalName(cr)
+	}
+	msg := fmt.Sprintf("Customer %s has spent %.2f USD of its %.2f USD budget", alias, o.Spend, *o.MaxBudget)
+	if cr.GetCondition(apisv1alpha1.TypeBudgetExceeded).Status != corev1.ConditionTrue {
+		c.recorder.Event(cr, event.Warning(reasonOverBudget, errors.New(msg)))
+	}
+	cr.SetConditions(apisv1alpha1.BudgetExceeded(msg))
+}
+
+// generatePayload returns the /customer/new and /customer/update payload for
+// the supplied parameters. Blocked is handled separately.
+func generatePayload(id string, p v1alpha1.CustomerParameters) map[string]interface{} {
+	payload := map[string]interface{}{"user_id": id}
+	if p.Alias != "" {
+		payload["alias"] = p.Alias
+	}
+	if p.MaxBudget != nil {
+		payload["max_budget"] = *p.MaxBudget
+	}
+	if p.BudgetID != "" {
+		payload["budget_id"] = p.BudgetID
+	}
+	if p.AllowedModelRegion != "" {
+		payload["allowed_model_region"] = p.AllowedModelRegion
+	}
+	if p.DefaultModel != "" {
+		payload["default_model"] = p.DefaultModel
+	}
+	return payload
+}
+
+// isUpToDate returns true if the observed customer matches every field set
+// in the supplied parameters. Blocked is always managed.
+func isUpToDate(p v1alpha1.CustomerParameters, o *customerInfo) bool {
+	if p.Blocked != o.Blocked {
+		return false
+	}
+	if p.Alias != "" && p.Alias != o.Alias {
+		return false
+	}
+	if mb := o.maxBudget(); p.MaxBudget != nil && (mb == nil || *p.MaxBudget != *mb) {
+		return false
+	}
+	if p.BudgetID != "" && p.BudgetID != o.BudgetID {
+		return false
+	}
+	if p.AllowedModelRegion != "" && p.AllowedModelRegion != o.AllowedModelRegion {
+		return false
+	}
+	return p.DefaultModel == "" || p.DefaultModel == o.DefaultModel
+}
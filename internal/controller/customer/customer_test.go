@@ -0,0 +1,419 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package customer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	budgetv1alpha1 "github.com/crossplane/provider-litellm/apis/budget/v1alpha1"
+	"github.com/crossplane/provider-litellm/apis/customer/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func customer(id string, p v1alpha1.CustomerParameters) *v1alpha1.Customer {
+	cr := &v1alpha1.Customer{Spec: v1alpha1.CustomerSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+// recorder records the events it is asked to emit.
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestObserve(t *testing.T) {
+	budget := 10.0
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Customer
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A 404 from /customer/info should report the customer as absent.",
+			responses: map[string]fake.Response{},
+			cr:        customer("acme", v1alpha1.CustomerParameters{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"DoesNotExist": {
+			reason:    "LiteLLM answers 400 for unknown customers, which should report the customer as absent.",
+			responses: map[string]fake.Response{"/customer/info": {Status: 400, Body: `{"detail": {"error": "End User Id=acme does not exist in db"}}`}},
+			cr:        customer("acme", v1alpha1.CustomerParameters{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A customer matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/customer/info": {Body: `{"user_id": "acme", "alias": "Acme", "allowed_model_region": "eu", "default_model": "gpt-4o", "blocked": false, "litellm_budget_table": {"max_budget": 10}}`}},
+			cr: customer("acme", v1alpha1.CustomerParameters{
+				Alias:              "Acme",
+				MaxBudget:          &budget,
+				AllowedModelRegion: "eu",
+				DefaultModel:       "gpt-4o",
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"BudgetDrifted": {
+			reason:    "A customer without the desired budget should not be up to date.",
+			responses: map[string]fake.Response{"/customer/info": {Body: `{"user_id": "acme", "litellm_budget_table": null}`}},
+			cr:        customer("acme", v1alpha1.CustomerParameters{MaxBudget: &budget}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"BlockedDrifted": {
+			reason:    "A blocked customer should not be up to date when the spec doesn't block it.",
+			responses: map[string]fake.Response{"/customer/info": {Body: `{"user_id": "acme", "blocked": true}`}},
+			cr:        customer("acme", v1alpha1.CustomerParameters{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client(), recorder: &recorder{}}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveResponseShapes(t *testing.T) {
+	// LiteLLM versions differ in the name they return the spend under.
+	shapes := map[string]string{
+		"Spend":    `{"user_id": "acme", "spend": 4.2, "blocked": false}`,
+		"SpendUSD": `{"user_id": "acme", "spend_usd": 4.2, "blocked": false}`,
+	}
+	want := v1alpha1.CustomerObservation{UserID: "acme", Spend: 4.2}
+
+	for name, body := range shapes {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/customer/info": {Body: body}})
+			defer srv.Close()
+
+			cr := customer("acme", v1alpha1.CustomerParameters{})
+			e := external{client: srv.Client(), recorder: &recorder{}}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("e.Observe(...): %v", err)
+			}
+			if diff := cmp.Diff(want, cr.Status.AtProvider); diff != "" {
+				t.Errorf("e.Observe(...): -want observation, +got observation:\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestObserveBudget(t *testing.T) {
+	budget := 10.0
+	reset := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+	msg := "Customer Acme has spent 12.50 USD of its 10.00 USD budget"
+
+	type want struct {
+		obs    v1alpha1.CustomerObservation
+		budget corev1.ConditionStatus
+		events []event.Event
+	}
+
+	cases := map[string]struct {
+		reason     string
+		body       string
+		conditions []xpv1.Condition
+		want       want
+	}{
+		"WithinBudget": {
+			reason: "Spend, budget and reset time should be reported from /customer/info.",
+			body:   `{"user_id": "acme", "alias": "Acme", "spend": 2.5, "litellm_budget_table": {"max_budget": 10, "budget_reset_at": "2024-07-01T00:00:00"}}`,
+			want: want{
+				obs:    v1alpha1.CustomerObservation{UserID: "acme", Spend: 2.5, MaxBudget: &budget, BudgetResetAt: &metav1.Time{Time: reset}},
+				budget: corev1.ConditionFalse,
+			},
+		},
+		"BudgetExceeded": {
+			reason: "Crossing the budget should set the BudgetExceeded condition and emit a warning naming the customer.",
+			body:   `{"user_id": "acme", "alias": "Acme", "spend": 12.5, "litellm_budget_table": {"max_budget": 10}}`,
+			want: want{
+				obs:    v1alpha1.CustomerObservation{UserID: "acme", Spend: 12.5, MaxBudget: &budget},
+				budget: corev1.ConditionTrue,
+				events: []event.Event{event.Warning(reasonOverBudget, errors.New(msg))},
+			},
+		},
+		"StillExceeded": {
+			reason:     "A customer that already exceeded its budget should not emit another warning.",
+			body:       `{"user_id": "acme", "alias": "Acme", "spend": 12.5, "litellm_budget_table": {"max_budget": 10}}`,
+			conditions: []xpv1.Condition{apisv1alpha1.BudgetExceeded(msg)},
+			want: want{
+				obs:    v1alpha1.CustomerObservation{UserID: "acme", Spend: 12.5, MaxBudget: &budget},
+				budget: corev1.ConditionTrue,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/customer/info": {Body: tc.body}})
+			defer srv.Close()
+
+			cr := customer("acme", v1alpha1.CustomerParameters{})
+			cr.SetConditions(tc.conditions...)
+			r := &recorder{}
+			e := external{client: srv.Client(), recorder: r}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.budget, cr.GetCondition(apisv1alpha1.TypeBudgetExceeded).Status); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want BudgetExceeded, +got BudgetExceeded:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, r.events); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		cr      *v1alpha1.Customer
+		body    map[string]interface{}
+		extName string
+	}{
+		"ExternalName": {
+			reason:  "The external name should be used as the end-user id by default.",
+			cr:      customer("acme", v1alpha1.CustomerParameters{Alias: "Acme", AllowedModelRegion: "eu"}),
+			body:    map[string]interface{}{"user_id": "acme", "alias": "Acme", "allowed_model_region": "eu"},
+			extName: "acme",
+		},
+		"UserID": {
+			reason:  "An explicit userId should be used as the end-user id and external name.",
+			cr:      customer("my-customer", v1alpha1.CustomerParameters{UserID: "cus_123", BudgetID: "tier-1"}),
+			body:    map[string]interface{}{"user_id": "cus_123", "budget_id": "tier-1"},
+			extName: "cus_123",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/customer/new": {Body: `{}`}})
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			if _, err := e.Create(context.Background(), tc.cr); err != nil {
+				t.Fatalf("\n%s\ne.Create(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.body, srv.Body("/customer/new")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.extName, meta.GetExternalName(tc.cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/customer/update": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if _, err := e.Update(context.Background(), customer("acme", v1alpha1.CustomerParameters{DefaultModel: "gpt-4o"})); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{"user_id": "acme", "default_model": "gpt-4o"}
+	if diff := cmp.Diff(want, srv.Body("/customer/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestUpdateBlocked(t *testing.T) {
+	type want struct {
+		paths  []string
+		events []event.Event
+		obs    v1alpha1.CustomerObservation
+	}
+
+	cases := map[string]struct {
+		reason string
+		spec   bool
+		obs    bool
+		want   want
+	}{
+		"Block": {
+			reason: "Setting blocked should call /customer/block and emit an event.",
+			spec:   true,
+			want: want{
+				paths:  []string{"/customer/update", "/customer/block"},
+				events: []event.Event{event.Normal(reasonBlocked, "Blocked customer acme")},
+				obs:    v1alpha1.CustomerObservation{Blocked: true},
+			},
+		},
+		"Unblock": {
+			reason: "Clearing blocked should call /customer/unblock and emit an event.",
+			obs:    true,
+			want: want{
+				paths:  []string{"/customer/update", "/customer/unblock"},
+				events: []event.Event{event.Normal(reasonUnblocked, "Unblocked customer acme")},
+			},
+		},
+		"Unchanged": {
+			reason: "Neither endpoint should be called when the blocked state is as desired.",
+			spec:   true,
+			obs:    true,
+			want: want{
+				paths: []string{"/customer/update"},
+				obs:   v1alpha1.CustomerObservation{Blocked: true},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/customer/update":  {Body: `{}`},
+				"/customer/block":   {Body: `{}`},
+				"/customer/unblock": {Body: `{}`},
+			})
+			defer srv.Close()
+
+			cr := customer("acme", v1alpha1.CustomerParameters{Blocked: tc.spec})
+			cr.Status.AtProvider.Blocked = tc.obs
+			r := &recorder{}
+			e := external{client: srv.Client(), recorder: r}
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.paths[len(tc.want.paths)-1] != "/customer/update" {
+				want := map[string]interface{}{"user_ids": []interface{}{"acme"}}
+				if diff := cmp.Diff(want, srv.Body(tc.want.paths[1])); diff != "" {
+					t.Errorf("\n%s\ne.Update(...): -want body, +got body:\n%s\n", tc.reason, diff)
+				}
+			}
+			if diff := cmp.Diff(tc.want.events, r.events); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/customer/delete": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), customer("acme", v1alpha1.CustomerParameters{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"user_ids": []interface{}{"acme"}}
+	if diff := cmp.Diff(want, srv.Body("/customer/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestResolveReferences(t *testing.T) {
+	budget := func(ready bool) *budgetv1alpha1.Budget {
+		b := &budgetv1alpha1.Budget{}
+		meta.SetExternalName(b, "tier-1")
+		if ready {
+			b.SetConditions(xpv1.Available())
+		}
+		return b
+	}
+
+	type want struct {
+		budgetID string
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason string
+		budget *budgetv1alpha1.Budget
+		want   want
+	}{
+		"BudgetReady": {
+			reason: "A ready Budget should resolve to its external name.",
+			budget: budget(true),
+			want:   want{budgetID: "tier-1"},
+		},
+		"BudgetNotReady": {
+			reason: "A Budget that is not ready should block the Customer rather than resolve to an empty budget id.",
+			budget: budget(false),
+			want: want{
+				err: errors.Wrap(errors.New("referenced field was empty (referenced resource may not yet be ready)"), "mg.Spec.ForProvider.BudgetID"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					tc.budget.DeepCopyInto(obj.(*budgetv1alpha1.Budget))
+					return nil
+				}),
+			}
+			cr := customer("acme", v1alpha1.CustomerParameters{BudgetIDRef: &xpv1.Reference{Name: "tier-1"}})
+			err := cr.ResolveReferences(context.Background(), kube)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.budgetID, cr.Spec.ForProvider.BudgetID); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want budget id, +got budget id:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
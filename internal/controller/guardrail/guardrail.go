@@ -0,0 +1,390 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotGuardrail = "managed resource is not a Guardrail custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetGuardrail    = "cannot get guardrail"
+	errCreateGuardrail = "cannot create guardrail"
+	errUpdateGuardrail = "cannot update guardrail"
+	errDeleteGuardrail = "cannot delete guardrail"
+	errExtraParams     = "extraParams must be a JSON object"
+	errGuardrailInfo   = "guardrailInfo must be a JSON object"
+)
+
+// Setup adds a controller that reconciles Guardrail managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.GuardrailGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.GuardrailGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.GuardrailKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Guardrail{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.GuardrailGroupVersionKind)))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(guardrailsForSecret(mgr.GetClient()))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.GuardrailGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// guardrailsForSecret returns a function that maps a Secret to the Guardrails
+// whose credentials it holds, so that rotated credentials are sent to the
+// proxy.
+func guardrailsForSecret(kube client.Client) handler.MapFunc {
+	return func(ctx context.Context, s client.Object) []reconcile.Request {
+		l := &v1alpha1.GuardrailList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, g := range l.Items {
+			for _, ref := range secretRefs(g.Spec.ForProvider.LiteLLMParams) {
+				if ref.Name == s.GetName() && ref.Namespace == s.GetNamespace() {
+					reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: g.GetName()}})
+					break
+				}
+			}
+		}
+		return reqs
+	}
+}
+
+// secretRefs returns the secrets referenced by the supplied params, keyed by
+// the litellm_params they are sent as.
+func secretRefs(p v1alpha1.GuardrailLiteLLMParams) map[string]xpv1.SecretKeySelector {
+	refs := make(map[string]xpv1.SecretKeySelector, len(p.ExtraSecretParams)+3)
+	for k, ref := range p.ExtraSecretParams {
+		refs[k] = ref
+	}
+	for k, ref := range map[string]*xpv1.SecretKeySelector{
+		"api_key":               p.APIKeySecretRef,
+		"aws_access_key_id":     p.AWSAccessKeyIDSecretRef,
+		"aws_secret_access_key": p.AWSSecretAccessKeySecretRef,
+	} {
+		if ref != nil {
+			refs[k] = *ref
+		}
+	}
+	return refs
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Guardrail.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Guardrail); !ok {
+		return nil, errors.New(errNotGuardrail)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// guardrail to ensure it reflects the managed resource's desired state.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// guardrailInfo is a guardrail returned by /guardrails.
+type guardrailInfo struct {
+	GuardrailID   string                 `json:"guardrail_id"`
+	GuardrailName string                 `json:"guardrail_name"`
+	LiteLLMParams map[string]interface{} `json:"litellm_params"`
+	GuardrailInfo map[string]interface{} `json:"guardrail_info"`
+	CreatedAt     string                 `json:"created_at"`
+	UpdatedAt     string                 `json:"updated_at"`
+}
+
+// path returns the /guardrails path of the supplied guardrail id.
+func path(id string) string {
+	return "/guardrails/" + url.PathEscape(id)
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Guardrail)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotGuardrail)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	info := &guardrailInfo{}
+	err := c.client.Get(ctx, path(id), nil, info)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetGuardrail)
+	}
+
+	p := cr.Spec.ForProvider
+	extra, err := object(p.LiteLLMParams.ExtraParams, errExtraParams)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	gi, err := object(p.GuardrailInfo, errGuardrailInfo)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	_, versions, err := litellm.SecretValues(ctx, c.kube, secretRefs(p.LiteLLMParams))
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	secretsUpToDate := litellm.SecretsUpToDate(versions, cr.Status.AtProvider.SecretVersions)
+
+	o := &cr.Status.AtProvider
+	o.GuardrailID = info.GuardrailID
+	o.CreatedAt = parseTime(info.CreatedAt)
+	o.UpdatedAt = parseTime(info.UpdatedAt)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: secretsUpToDate && isUpToDate(p, extra, gi, info),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Guardrail)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotGuardrail)
+	}
+
+	payload, versions, err := c.generatePayload(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	rsp := &guardrailInfo{}
+	if err := c.client.Post(ctx, "/guardrails", payload, rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateGuardrail)
+	}
+	meta.SetExternalName(cr, rsp.GuardrailID)
+	cr.Status.AtProvider.SecretVersions = nil
+	if len(versions) > 0 {
+		cr.Status.AtProvider.SecretVersions = versions
+	}
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Guardrail)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotGuardrail)
+	}
+
+	payload, versions, err := c.generatePayload(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := c.client.Do(ctx, http.MethodPut, path(meta.GetExternalName(cr)), nil, payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateGuardrail)
+	}
+	cr.Status.AtProvider.SecretVersions = nil
+	if len(versions) > 0 {
+		cr.Status.AtProvider.SecretVersions = versions
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Guardrail)
+	if !ok {
+		return errors.New(errNotGuardrail)
+	}
+
+	err := c.client.Do(ctx, http.MethodDelete, path(meta.GetExternalName(cr)), nil, nil, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteGuardrail)
+}
+
+// generatePayload returns the POST and PUT /guardrails payload for the
+// supplied parameters, and the resource versions of the secrets credentials
+// were read from, keyed by param name.
+func (c *external) generatePayload(ctx context.Context, p v1alpha1.GuardrailParameters) (map[string]interface{}, map[string]string, error) {
+	lp := p.LiteLLMParams
+	params, err := object(lp.ExtraParams, errExtraParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	values, versions, err := litellm.SecretValues(ctx, c.kube, secretRefs(lp))
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range values {
+		params[k] = v
+	}
+	params["guardrail"] = lp.Guardrail
+	params["mode"] = lp.Mode
+	if lp.DefaultOn != nil {
+		params["default_on"] = *lp.DefaultOn
+	}
+	if lp.APIBase != "" {
+		params["api_base"] = lp.APIBase
+	}
+
+	g := map[string]interface{}{
+		"guardrail_name": p.GuardrailName,
+		"litellm_params": params,
+	}
+	if p.GuardrailInfo != nil {
+		info, err := object(p.GuardrailInfo, errGuardrailInfo)
+		if err != nil {
+			return nil, nil, err
+		}
+		g["guardrail_info"] = info
+	}
+
+	return map[string]interface{}{"guardrail": g}, versions, nil
+}
+
+// object returns the supplied raw JSON object, or an empty map if it is
+// unset.
+func object(raw *runtime.RawExtension, errMsg string) (map[string]interface{}, error) {
+	obj := map[string]interface{}{}
+	if raw == nil || len(raw.Raw) == 0 {
+		return obj, nil
+	}
+	if err := json.Unmarshal(raw.Raw, &obj); err != nil {
+		return nil, errors.Wrap(err, errMsg)
+	}
+	return obj, nil
+}
+
+// parseTime returns the supplied LiteLLM timestamp, or nil if it is empty or
+// can't be parsed.
+func parseTime(s string) *metav1.Time {
+	t, err := litellm.ParseTime(s)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+// secretParams are the litellm_params LiteLLM redacts when it returns a
+// guardrail, so they can't be compared even if they are set as extra params.
+var secretParams = map[string]bool{
+	"api_key":               true,
+	"aws_access_key_id":     true,
+	"aws_secret_access_key": true,
+	"aws_session_token":     true,
+}
+
+// isUpToDate returns true if the observed guardrail matches the supplied
+// parameters. Credentials are redacted by LiteLLM, so they can't be compared;
+// whether they are up to date is tracked by the versions of their secrets.
+func isUpToDate(p v1alpha1.GuardrailParameters, extra, gi map[string]interface{}, o *guardrailInfo) bool {
+	if p.GuardrailName != o.GuardrailName {
+		return false
+	}
+	lp := p.LiteLLMParams
+	want := map[string]interface{}{"guardrail": lp.Guardrail, "mode": lp.Mode}
+	if lp.DefaultOn != nil {
+		want["default_on"] = *lp.DefaultOn
+	}
+	if lp.APIBase != "" {
+		want["api_base"] = lp.APIBase
+	}
+	secrets := secretRefs(lp)
+	for k, v := range extra {
+		if _, ok := secrets[k]; ok || secretParams[k] {
+			continue
+		}
+		if _, ok := want[k]; !ok {
+			want[k] = v
+		}
+	}
+	return litellm.SameValue(want, o.LiteLLMParams) && litellm.SameValue(gi, o.GuardrailInfo)
+}
@@ -0,0 +1,320 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package guardrail
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func guardrail(id string, p v1alpha1.GuardrailParameters) *v1alpha1.Guardrail {
+	cr := &v1alpha1.Guardrail{Spec: v1alpha1.GuardrailSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+var apiKey = &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "lakera", Namespace: "crossplane-system"}, Key: "api-key"}
+
+func secretClient(version string) *test.MockClient {
+	return &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			s := obj.(*corev1.Secret)
+			s.SetResourceVersion(version)
+			s.Data = map[string][]byte{"api-key": []byte("lk-1\n")}
+			return nil
+		}),
+	}
+}
+
+func TestObserve(t *testing.T) {
+	on := true
+	info := fake.Response{Body: `{
+		"guardrail_id": "g-1",
+		"guardrail_name": "prompt-injection",
+		"litellm_params": {"guardrail": "lakera_prompt_injection", "mode": "pre_call", "default_on": true, "api_key": "lk-*****", "category_thresholds": {"jailbreak": 0.1}},
+		"guardrail_info": {"description": "Blocks prompt injection", "owner": "security"},
+		"created_at": "2024-06-01T00:00:00"
+	}`}
+	desired := func() v1alpha1.GuardrailParameters {
+		return v1alpha1.GuardrailParameters{
+			GuardrailName: "prompt-injection",
+			LiteLLMParams: v1alpha1.GuardrailLiteLLMParams{
+				Guardrail:       "lakera_prompt_injection",
+				Mode:            "pre_call",
+				DefaultOn:       &on,
+				APIKeySecretRef: apiKey,
+				ExtraParams:     &runtime.RawExtension{Raw: []byte(`{"category_thresholds": {"jailbreak": 0.1}}`)},
+			},
+			GuardrailInfo: &runtime.RawExtension{Raw: []byte(`{"description": "Blocks prompt injection"}`)},
+		}
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		p         func(p *v1alpha1.GuardrailParameters)
+		version   string
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A guardrail LiteLLM doesn't know should be reported as absent.",
+			responses: map[string]fake.Response{},
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A guardrail matching the spec should be up to date, even though its API key is redacted.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": info},
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"SecretRotated": {
+			reason:    "A guardrail whose API key secret changed since it was sent should not be up to date.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": info},
+			version:   "2",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"RedactedParam": {
+			reason: "A credential set as an extra param should not be compared with its redacted value.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": {Body: `{
+				"guardrail_id": "g-1",
+				"guardrail_name": "prompt-injection",
+				"litellm_params": {"guardrail": "lakera_prompt_injection", "mode": "pre_call", "default_on": true, "api_key": "lk-*****", "aws_session_token": "IQo*****", "category_thresholds": {"jailbreak": 0.1}},
+				"guardrail_info": {"description": "Blocks prompt injection"}
+			}`}},
+			p: func(p *v1alpha1.GuardrailParameters) {
+				p.LiteLLMParams.ExtraParams = &runtime.RawExtension{Raw: []byte(`{"aws_session_token": "IQoJb3JpZ2luX2VjE", "category_thresholds": {"jailbreak": 0.1}}`)}
+			},
+			version: "1",
+			want:    want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"ModeDrifted": {
+			reason:    "A guardrail running at a different point of the call should not be up to date.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": info},
+			p:         func(p *v1alpha1.GuardrailParameters) { p.LiteLLMParams.Mode = "post_call" },
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ExtraParamsDrifted": {
+			reason:    "A guardrail whose provider-specific params differ from the spec should not be up to date.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": info},
+			p: func(p *v1alpha1.GuardrailParameters) {
+				p.LiteLLMParams.ExtraParams = &runtime.RawExtension{Raw: []byte(`{"category_thresholds": {"jailbreak": 0.5}}`)}
+			},
+			version: "1",
+			want:    want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"InfoDrifted": {
+			reason:    "A guardrail whose info differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": info},
+			p: func(p *v1alpha1.GuardrailParameters) {
+				p.GuardrailInfo = &runtime.RawExtension{Raw: []byte(`{"description": "Blocks jailbreaks"}`)}
+			},
+			version: "1",
+			want:    want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"Error": {
+			reason:    "Errors getting the guardrail should be returned.",
+			responses: map[string]fake.Response{"GET /guardrails/g-1": {Status: http.StatusInternalServerError, Body: "boom"}},
+			version:   "1",
+			want: want{
+				err: errors.Wrap(errors.New("GET /guardrails/g-1 returned unexpected status 500: boom"), errGetGuardrail),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			p := desired()
+			if tc.p != nil {
+				tc.p(&p)
+			}
+			cr := guardrail("g-1", p)
+			cr.Status.AtProvider.SecretVersions = map[string]string{"api_key": "1"}
+			e := external{kube: secretClient(tc.version), client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGuardrailsForSecret(t *testing.T) {
+	ref := func(name string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: name, Namespace: "crossplane-system"}, Key: "key"}
+	}
+	named := func(name string, p v1alpha1.GuardrailLiteLLMParams) v1alpha1.Guardrail {
+		g := guardrail("", v1alpha1.GuardrailParameters{LiteLLMParams: p})
+		g.SetName(name)
+		return *g
+	}
+	kube := &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1alpha1.GuardrailList).Items = []v1alpha1.Guardrail{
+				named("lakera", v1alpha1.GuardrailLiteLLMParams{APIKeySecretRef: ref("lakera")}),
+				named("aporia", v1alpha1.GuardrailLiteLLMParams{APIKeySecretRef: ref("aporia")}),
+				named("bedrock", v1alpha1.GuardrailLiteLLMParams{AWSAccessKeyIDSecretRef: ref("aws"), AWSSecretAccessKeySecretRef: ref("lakera")}),
+				named("presidio", v1alpha1.GuardrailLiteLLMParams{}),
+			}
+			return nil
+		},
+	}
+
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "lakera", Namespace: "crossplane-system"}}
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "lakera"}},
+		{NamespacedName: types.NamespacedName{Name: "bedrock"}},
+	}
+	if diff := cmp.Diff(want, guardrailsForSecret(kube)(context.Background(), s)); diff != "" {
+		t.Errorf("guardrailsForSecret(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"POST /guardrails": {Body: `{"guardrail_id": "g-1", "guardrail_name": "prompt-injection"}`}})
+	defer srv.Close()
+
+	off := false
+	cr := guardrail("prompt-injection", v1alpha1.GuardrailParameters{
+		GuardrailName: "prompt-injection",
+		LiteLLMParams: v1alpha1.GuardrailLiteLLMParams{
+			Guardrail:       "lakera_prompt_injection",
+			Mode:            "during_call",
+			DefaultOn:       &off,
+			APIBase:         "https://api.lakera.ai",
+			APIKeySecretRef: apiKey,
+			ExtraParams:     &runtime.RawExtension{Raw: []byte(`{"mode": "ignored", "category_thresholds": {"jailbreak": 0.1}}`)},
+		},
+		GuardrailInfo: &runtime.RawExtension{Raw: []byte(`{"description": "Blocks prompt injection"}`)},
+	})
+	e := external{kube: secretClient("1"), client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"guardrail": map[string]interface{}{
+			"guardrail_name": "prompt-injection",
+			"litellm_params": map[string]interface{}{
+				"guardrail":           "lakera_prompt_injection",
+				"mode":                "during_call",
+				"default_on":          false,
+				"api_base":            "https://api.lakera.ai",
+				"api_key":             "lk-1",
+				"category_thresholds": map[string]interface{}{"jailbreak": 0.1},
+			},
+			"guardrail_info": map[string]interface{}{"description": "Blocks prompt injection"},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/guardrails")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("g-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"api_key": "1"}, cr.Status.AtProvider.SecretVersions); diff != "" {
+		t.Errorf("e.Create(...): -want secret versions, +got secret versions:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"PUT /guardrails/g-1": {Body: `{"guardrail_id": "g-1"}`}})
+	defer srv.Close()
+
+	cr := guardrail("g-1", v1alpha1.GuardrailParameters{
+		GuardrailName: "pii",
+		LiteLLMParams: v1alpha1.GuardrailLiteLLMParams{Guardrail: "presidio", Mode: "pre_call", APIKeySecretRef: apiKey},
+	})
+	e := external{kube: secretClient("2"), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"guardrail": map[string]interface{}{
+			"guardrail_name": "pii",
+			"litellm_params": map[string]interface{}{"guardrail": "presidio", "mode": "pre_call", "api_key": "lk-1"},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/guardrails/g-1")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff(map[string]string{"api_key": "2"}, cr.Status.AtProvider.SecretVersions); diff != "" {
+		t.Errorf("e.Update(...): -want secret versions, +got secret versions:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+	}{
+		"Deleted": {
+			reason:    "A guardrail should be deleted by its id.",
+			responses: map[string]fake.Response{"DELETE /guardrails/g-1": {Body: `{}`}},
+		},
+		"NotFound": {
+			reason:    "A guardrail that is already gone should be treated as deleted.",
+			responses: map[string]fake.Response{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			if err := e.Delete(context.Background(), guardrail("g-1", v1alpha1.GuardrailParameters{})); err != nil {
+				t.Errorf("\n%s\ne.Delete(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff([]string{"/guardrails/g-1"}, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -17,48 +17,131 @@ limitations under the License.
 package key
 
 import (
-	"bytes"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
-	"net/http"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/duration"
+	"k8s.io/client-go/util/retry"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-litellm/apis/key/v1alpha1"
 	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
 	"github.com/crossplane/provider-litellm/internal/features"
 )
 
 const (
 	errNotKey       = "managed resource is not a Key custom resource"
 	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
+	errGetConfig    = "cannot get LiteLLM configuration"
 
-	errNewClient = "cannot create new Service"
+	errGetKey      = "cannot get key"
+	errGenerateKey = "cannot generate key"
+	errUpdateKey   = "cannot update key"
+	errDeleteKey   = "cannot delete key"
+	errListKeys    = "cannot list keys"
+	errRegenerate  = "cannot regenerate key"
+	errPersistKey  = "cannot persist external name of regenerated key"
+	errPublishKey  = "cannot publish generated key; it must be regenerated unless publishing it succeeds on retry"
+	errRetainKey   = "cannot publish generated key; it is retained in the status until it is published"
+	errStoreKey    = "cannot retain unpublished key in status"
+	errAliasInUse  = "key alias %q is already in use by another key"
+	errRenameKey   = "LiteLLM refused to change the key alias from %q to %q; restore the alias or recreate the Key"
+	errIntent      = "cannot record intent to generate key"
+	errMetadata    = "metadata_json must be a JSON object"
+	errBlockKey    = "cannot block key"
+	errUnblockKey  = "cannot unblock key"
+	errPendingKey  = "pending key generation returned no token id to poll"
+	errGetTeam     = "cannot get team of key"
+	errNoTeam      = "team %s not found"
+
+	errParseDuration  = "cannot parse duration %q"
+	errBudgetResetAt  = "budget_reset_at must be an RFC 3339 time"
+	errBudgetConflict = "budget_duration and budget_reset_at are mutually exclusive"
+	errExpiresAt      = "expires_at must be an RFC 3339 time"
+	errExpiresAtPast  = "expires_at %s has passed"
 )
 
-// A NoOpService does nothing.
-type NoOpService struct{}
+// keyPrefix is the prefix of the keys LiteLLM generates.
+const keyPrefix = "sk-"
+
+// keyStatusPending is the status of a key LiteLLM generates asynchronously,
+// until its value is available.
+const keyStatusPending = "pending"
+
+// keyStatusGenerated is the status of a key whose value is available.
+const keyStatusGenerated = "generated"
+
+// metadataDeletedAt is the metadata a soft deleted key is tagged with. It
+// holds the time the key was deleted.
+const metadataDeletedAt = "crossplane_deleted_at"
+
+// annotationCreateIntent is set to the UID of a Key without an alias before
+// its key is generated, so that a later create knows to look for a key that
+// was generated but whose token was never recorded.
+const annotationCreateIntent = "litellm.crossplane.io/create-intent"
+
+// metadataCreateIntent is the metadata a key generated for a Key is tagged
+// with. It holds the UID of the Key, and proves that the key was generated for
+// it, so that a key without an alias can be found by findByIntent, and a key
+// with an alias is only adopted by the Key it was generated for.
+const metadataCreateIntent = "crossplane_create_intent"
 
-var (
-	newNoOpService = func(_ []byte) (interface{}, error) { return &NoOpService{}, nil }
+// keyPageSize is the number of keys requested per /key/list page.
+const keyPageSize = 100
+
+// reasonSoftDeleted is the reason of the event emitted when a key is soft
+// deleted.
+const reasonSoftDeleted event.Reason = "SoftDeletedKey"
+
+// Reasons of the events emitted when a generated key can't be published, and
+// when a retained one is published after all.
+const (
+	reasonCannotPublishKey event.Reason = "CannotPublishKey"
+	reasonPublishedKey     event.Reason = "PublishedRetainedKey"
+)
+
+// Types of logging callbacks, and the metadata they are set in.
+const (
+	metadataLogging           = "logging"
+	callbackSuccess           = "success"
+	callbackFailure           = "failure"
+	callbackSuccessAndFailure = "success_and_failure"
+)
+
+// Actions taken because of the spend alert threshold of a key.
+const (
+	actionNone    = ""
+	actionBlock   = "block"
+	actionUnblock = "unblock"
 )
 
 // Setup adds a controller that reconciles Key managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
 	name := managed.ControllerName(v1alpha1.KeyGroupKind)
 
 	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
@@ -66,31 +149,40 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
 	}
 
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.KeyGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube:         mgr.GetClient(),
-			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: newNoOpService}),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.KeyKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			recorder:    recorder,
+			publisher:   managed.PublisherChain(cps),
+			newClientFn: litellm.NewClient})))),
+		managed.WithReferenceResolver(litellm.NewReferenceResolver(mgr.GetClient())),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
 		managed.WithConnectionPublishers(cps...))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
-		WithEventFilter(resource.DesiredStateChanged()).
-		For(&v1alpha1.Key{}).
-		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+		For(&v1alpha1.Key{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.KeyGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.KeyGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
 }
 
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
-	kube         client.Client
-	usage        resource.Tracker
-	newServiceFn func(creds []byte) (interface{}, error)
+	kube        client.Client
+	usage       resource.Tracker
+	recorder    event.Recorder
+	publisher   managed.ConnectionPublisher
+	newClientFn func(cfg *litellm.Config) *litellm.Client
 }
 
 // Connect typically produces an ExternalClient by:
@@ -99,8 +191,7 @@ type connector struct {
 // 3. Getting the credentials specified by the ProviderConfig.
 // 4. Using the credentials to form a client.
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Key)
-	if !ok {
+	if _, ok := mg.(*v1alpha1.Key); !ok {
 		return nil, errors.New(errNotKey)
 	}
 
@@ -108,42 +199,165 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errTrackPCUsage)
 	}
 
-	pc := &apisv1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
-	}
-
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return nil, errors.Wrap(err, errGetConfig)
 	}
 
-	svc, err := c.newServiceFn(data)
-	if err != nil {
-		return nil, errors.Wrap(err, errNewClient)
+	e := &external{
+		kube:              c.kube,
+		client:            c.newClientFn(cfg),
+		recorder:          c.recorder,
+		publisher:         c.publisher,
+		retainKeys:        cfg.ProviderConfig.Spec.RetainUnpublishedKeys,
+		adoptByAlias:      cfg.ProviderConfig.Spec.AdoptExistingByAlias,
+		regenerateExpired: cfg.ProviderConfig.Spec.RegenerateExpiredKeys,
+		metadataField:     cfg.ProviderConfig.Spec.MetadataFieldName,
+		validateTeam:      cfg.ProviderConfig.Spec.ValidateTeamExists,
+		softDelete:        cfg.ProviderConfig.Spec.SoftDeleteKeys,
+		actorIdentity:     cfg.ProviderConfig.Spec.ActorIdentity,
+		now:               time.Now,
 	}
-
-	apiBase := pc.Spec.APIBase
-	apiKey := string(data[xpv1.ResourceCredentialsSecretAPIKeyKey])
-
-	return &external{
-		service: svc,
-		client:  &http.Client{},
-		apiBase: apiBase,
-		apiKey:  apiKey,
-	}, nil
+	if w := cfg.ProviderConfig.Spec.ExpiryWarnWindow; w != nil {
+		e.expiryWarnWindow = w.Duration
+	}
+	return e, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
 // external resource to ensure it reflects the managed resource's desired state.
 type external struct {
-	// A 'client' used to connect to the external resource API. In practice this
-	// would be something like an AWS SDK client.
-	service interface{}
-	client  *http.Client
-	apiBase string
-	apiKey  string
+	kube     client.Client
+	client   *litellm.Client
+	recorder event.Recorder
+	now      func() time.Time
+
+	// adoptByAlias enables the key alias pre-check before generating a key.
+	adoptByAlias bool
+
+	// expiryWarnWindow is how long before a key expires it is reported as
+	// expiring soon. Zero disables the warning.
+	expiryWarnWindow time.Duration
+
+	// regenerateExpired enables regenerating keys that have expired.
+	regenerateExpired bool
+
+	// metadataField is the field the metadata of a key is sent and read
+	// under. It is metadata if empty.
+	metadataField string
+
+	// validateTeam enables checking that the team of a key exists before
+	// generating it.
+	validateTeam bool
+
+	// softDelete blocks and tags keys as deleted rather than deleting them.
+	softDelete bool
+
+	// actorIdentity is sent as the created_by of generated keys, if set.
+	actorIdentity string
+
+	// publisher writes generated keys to the connection secret as soon as
+	// they are returned. Keys are only returned as connection details if it
+	// is nil.
+	publisher managed.ConnectionPublisher
+
+	// retainKeys keeps keys that can't be published in the status of their
+	// Key until they are.
+	retainKeys bool
+
+	// observed is the key as last returned by /key/info. Observe and Update
+	// are called on the same external within a reconcile, so Update can use
+	// it to avoid clobbering server-managed state.
+	observed *keyInfo
+}
+
+// keyInfo is the info object returned by /key/info.
+type keyInfo struct {
+	KeyAlias         string                     `json:"key_alias"`
+	TeamID           string                     `json:"team_id"`
+	UserID           string                     `json:"user_id"`
+	Models           []string                   `json:"models"`
+	MaxBudget        *float64                   `json:"max_budget"`
+	SoftBudget       *float64                   `json:"soft_budget"`
+	BudgetTable      *budgetTable               `json:"litellm_budget_table"`
+	BudgetDuration   string                     `json:"budget_duration"`
+	Expires          string                     `json:"expires"`
+	CreatedAt        string                     `json:"created_at"`
+	Metadata         map[string]interface{}     `json:"metadata"`
+	KeyMetadata      map[string]interface{}     `json:"key_metadata"`
+	ModelMaxBudget   map[string]json.RawMessage `json:"model_max_budget"`
+	Spend            float64                    `json:"spend"`
+	Blocked          *bool                      `json:"blocked"`
+	BudgetResetAt    string                     `json:"budget_reset_at"`
+	Status           string                     `json:"status"`
+	Key              string                     `json:"key"`
+	TPMLimit         *int64                     `json:"tpm_limit"`
+	RPMLimit         *int64                     `json:"rpm_limit"`
+	ObjectPermission map[string]json.RawMessage `json:"object_permission"`
+	CreatedBy        string                     `json:"created_by"`
+	UpdatedBy        string                     `json:"updated_by"`
+}
+
+// budgetTable is the budget linked to a key, which holds its soft budget in
+// LiteLLM versions that don't return it on the key itself.
+type budgetTable struct {
+	SoftBudget *float64 `json:"soft_budget"`
+}
+
+// softBudget returns the soft budget of the key, if any.
+func (i *keyInfo) softBudget() *float64 {
+	if i.SoftBudget == nil && i.BudgetTable != nil {
+		return i.BudgetTable.SoftBudget
+	}
+	return i.SoftBudget
+}
+
+// teamExists returns an error if team validation is enabled and the supplied
+// team doesn't exist. LiteLLM's own error for a missing team is cryptic.
+func (c *external) teamExists(ctx context.Context, teamID string) error {
+	if !c.validateTeam || teamID == "" {
+		return nil
+	}
+	err := c.client.Get(ctx, "/team/info", url.Values{"team_id": []string{teamID}}, nil)
+	if litellm.IsNotFound(err) {
+		return errors.Errorf(errNoTeam, teamID)
+	}
+	return errors.Wrap(err, errGetTeam)
+}
+
+// limits returns the effective TPM and RPM limits of the supplied key. LiteLLM
+// bounds the requests of a key without limits of its own by those of its
+// team, so they are read from the team.
+func (c *external) limits(ctx context.Context, info *keyInfo) (tpm, rpm *int64, err error) {
+	tpm, rpm = info.TPMLimit, info.RPMLimit
+	if info.TeamID == "" || tpm != nil && rpm != nil {
+		return tpm, rpm, nil
+	}
+	var rsp struct {
+		TeamInfo struct {
+			TPMLimit *int64 `json:"tpm_limit"`
+			RPMLimit *int64 `json:"rpm_limit"`
+		} `json:"team_info"`
+	}
+	err = c.client.Get(ctx, "/team/info", url.Values{"team_id": []string{info.TeamID}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return tpm, rpm, nil
+	}
+	if err != nil {
+		return nil, nil, errors.Wrap(err, errGetTeam)
+	}
+	if tpm == nil {
+		tpm = rsp.TeamInfo.TPMLimit
+	}
+	if rpm == nil {
+		rpm = rsp.TeamInfo.RPMLimit
+	}
+	return tpm, rpm, nil
+}
+
+// blocked returns true if the key is blocked.
+func (i *keyInfo) blocked() bool {
+	return i.Blocked != nil && *i.Blocked
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -152,98 +366,642 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotKey)
 	}
 
-	// These fmt statements should be removed in the real implementation.
-	fmt.Printf("Observing: %+v", cr)
+	en := meta.GetExternalName(cr)
+	if en == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	token := tokenID(en, "")
+
+	var rsp struct {
+		Key  string          `json:"key"`
+		Info json.RawMessage `json:"info"`
+	}
+	err := c.client.GetCached(ctx, "/key/info", token, c.client.WithFields(url.Values{"key": []string{token}}, keyInfo{}), &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+	}
+	if litellm.IsNull(rsp.Info) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	info := &keyInfo{}
+	if err := litellm.Decode(ctx, rsp.Info, info, litellm.RenamedSpend); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+	}
+	if c.metadataField == apisv1alpha1.MetadataFieldKeyMetadata {
+		info.Metadata = info.KeyMetadata
+	}
+	// A soft deleted key is retained in LiteLLM, but is gone as far as its
+	// Key is concerned.
+	if meta.WasDeleted(cr) && c.softDelete && info.Metadata[metadataDeletedAt] != nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	// A key that is generated asynchronously is only returned once LiteLLM
+	// has generated it. It isn't available, nor updated, until then.
+	var cd managed.ConnectionDetails
+	if cr.Status.AtProvider.Status == keyStatusPending {
+		if info.Status == keyStatusPending || !strings.HasPrefix(info.Key, keyPrefix) {
+			cr.SetConditions(xpv1.Creating())
+			return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+		}
+		cr.Status.AtProvider.Status = keyStatusGenerated
+		cd = managed.ConnectionDetails{"key": []byte(info.Key)}
+	}
+
+	// A retained key is cleared from the status once it is published.
+	if k := cr.Status.AtProvider.UnpublishedKey; k != "" && c.publisher != nil {
+		cd = managed.ConnectionDetails{"key": []byte(k)}
+		if _, err := c.publisher.PublishConnection(ctx, cr, cd); err == nil {
+			cr.Status.AtProvider.UnpublishedKey = ""
+			c.recorder.Event(cr, event.Normal(reasonPublishedKey, "Published the retained key to the connection secret and cleared it from the status"))
+		}
+	}
+
+	c.observed = info
+	md, err := desiredMetadata(cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	// Access groups may come back expanded into their models.
+	if m := parameters(cr).Models; m != nil {
+		same, err := c.client.SameModels(ctx, m, info.Models)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetKey)
+		}
+		if same {
+			info.Models = m
+		}
+	}
+
+	// Keys created by earlier versions of this provider are named by the key
+	// itself. Name them by their token id instead, to keep the key out of
+	// annotations, logs and events.
+	migrated := token != en
+	if migrated {
+		meta.SetExternalName(cr, token)
+	}
+	cr.Status.AtProvider.TokenID = token
+	cr.Status.AtProvider.UserID = info.UserID
+	cr.Status.AtProvider.ModelMaxBudget = litellm.ParseModelMaxBudget(info.ModelMaxBudget)
+	cr.Status.AtProvider.ModelRPMLimit = litellm.ModelLimits(info.Metadata, litellm.MetadataModelRPMLimit)
+	cr.Status.AtProvider.ModelTPMLimit = litellm.ModelLimits(info.Metadata, litellm.MetadataModelTPMLimit)
+	cr.Status.AtProvider.Spend = info.Spend
+	cr.Status.AtProvider.Blocked = info.blocked()
+	cr.Status.AtProvider.KeyAlias = info.KeyAlias
+	cr.Status.AtProvider.CreatedBy = info.CreatedBy
+	cr.Status.AtProvider.UpdatedBy = info.UpdatedBy
+	cr.Status.AtProvider.TPMLimit, cr.Status.AtProvider.RPMLimit, err = c.limits(ctx, info)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	if t, err := litellm.ParseTime(info.Expires); err == nil {
+		cr.Status.AtProvider.Expires = metav1.Time{Time: t}
+	}
+	// A key whose duration was never recorded was generated with the one in
+	// its spec.
+	if cr.Status.AtProvider.Duration == "" {
+		cr.Status.AtProvider.Duration = cr.Spec.ForProvider.Duration
+	}
+	cr.SetConditions(xpv1.Available())
+	c.setExpiry(cr)
+
+	action := spendAction(cr, info)
+	if action == actionNone && (cr.Spec.ForProvider.SpendAlertThreshold != nil || cr.GetCondition(apisv1alpha1.TypeSpendExceeded).Status == corev1.ConditionTrue) {
+		setSpendCondition(cr)
+	}
 
 	return managed.ExternalObservation{
-		// Return false when the external resource does not exist. This lets
-		// the managed resource reconciler know that it needs to call Create to
-		// (re)create the resource, or that it has successfully been deleted.
-		ResourceExists: true,
-
-		// Return false when the external resource exists, but it not up to date
-		// with the desired managed resource state. This lets the managed
-		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: true,
-
-		// Return any details that may be required to connect to the external
-		// resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
+		ResourceExists:          true,
+		ResourceUpToDate:        isUpToDate(parameters(cr), md, info, cr.Status.AtProvider) && !c.rotationDue(cr) && !c.regenerationDue(cr) && action == actionNone,
+		ResourceLateInitialized: migrated,
+		ConnectionDetails:       cd,
 	}, nil
 }
+
 func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
 	cr, ok := mg.(*v1alpha1.Key)
 	if !ok {
 		return managed.ExternalCreation{}, errors.New(errNotKey)
 	}
 
-	// Prepare the request payload
-	payload := map[string]interface{}{
-		"duration":        cr.Spec.ForProvider.Duration,
-		"key_alias":       cr.Spec.ForProvider.KeyAlias,
-		"key":             cr.Spec.ForProvider.Key,
-		"team_id":         cr.Spec.ForProvider.TeamID,
-		"user_id":         cr.Spec.ForProvider.UserID,
-		"models":          cr.Spec.ForProvider.Models,
-		"max_budget":      cr.Spec.ForProvider.MaxBudget,
-		"budget_duration": cr.Spec.ForProvider.BudgetDuration,
-		"metadata":        cr.Spec.ForProvider.Metadata,
-	}
-
-	jsonPayload, err := json.Marshal(payload)
+	token, err := c.existing(ctx, cr)
 	if err != nil {
-		return managed.ExternalCreation{}, errors.Wrap(err, "failed to marshal payload")
+		return managed.ExternalCreation{}, err
 	}
-
-	// Make the API call to /key/generate
-	req, err := http.NewRequest("POST", c.apiBase+"/key/generate", bytes.NewBuffer(jsonPayload))
-	if err != nil {
-		return managed.ExternalCreation{}, errors.Wrap(err, "failed to create request")
+	if token != "" {
+		// The key's secret is only returned when it is generated, so an
+		// adopted key has no connection details.
+		meta.SetExternalName(cr, token)
+		return managed.ExternalCreation{}, nil
 	}
-	req.Header.Set("Content-Type", "application/json")
-	req.Header.Set("Authorization", "Bearer "+c.apiKey)
-
-	resp, err := c.client.Do(req)
-	if err != nil {
-		return managed.ExternalCreation{}, errors.Wrap(err, "failed to create key")
+	if err := c.teamExists(ctx, cr.Spec.ForProvider.TeamID); err != nil {
+		return managed.ExternalCreation{}, err
 	}
 
 	// Parse the response
 	var keyResponse struct {
-		Key     string    `json:"key"`
-		Expires time.Time `json:"expires"`
-		UserID  string    `json:"user_id"`
-		Status  string    `json:"status"`
+		Key     string `json:"key"`
+		TokenID string `json:"token_id"`
+		Expires string `json:"expires"`
+		UserID  string `json:"user_id"`
+		Status  string `json:"status"`
+	}
+	payload, err := generatePayload(parameters(cr))
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	if err := c.setExpiresIn(payload, cr.Spec.ForProvider); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	// Tag the key with the UID of the Key, so that a later create can tell
+	// whether a key was generated for it. A key without an alias can only be
+	// found by its metadata, so also record that it is about to be generated.
+	if uid := string(cr.GetUID()); uid != "" {
+		if keyAlias(cr) == "" {
+			meta.AddAnnotations(cr, map[string]string{annotationCreateIntent: uid})
+			if err := c.kube.Update(ctx, cr); err != nil {
+				return managed.ExternalCreation{}, errors.Wrap(err, errIntent)
+			}
+		}
+		md, _ := payload["metadata"].(map[string]interface{})
+		if md == nil {
+			md = map[string]interface{}{}
+		}
+		md[metadataCreateIntent] = uid
+		payload["metadata"] = md
+	}
+	if c.actorIdentity != "" {
+		payload["created_by"] = c.actorIdentity
+	}
+	// LiteLLM generates a single key per request and has no batch endpoint
+	// to coalesce the creates of many Keys into. They are created
+	// concurrently instead, up to --max-reconcile-rate at a time.
+	if err := c.client.Post(ctx, "/key/generate", c.withMetadataField(payload), &keyResponse); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errGenerateKey)
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&keyResponse); err != nil {
-		return managed.ExternalCreation{}, errors.Wrap(err, "failed to decode key response")
+
+	// LiteLLM may generate the key asynchronously, in which case it is
+	// published by Observe once it has been generated.
+	pending := keyResponse.Status == keyStatusPending
+	if pending && keyResponse.TokenID == "" {
+		return managed.ExternalCreation{}, errors.New(errPendingKey)
+	}
+
+	var cd managed.ConnectionDetails
+	if !pending {
+		cd = c.publishKey(ctx, cr, keyResponse.Key)
 	}
 
+	token = tokenID(keyResponse.Key, keyResponse.TokenID)
+	meta.SetExternalName(cr, token)
+	c.client.Invalidate("/key/info", token)
+
 	// Update the resource status
-	cr.Status.AtProvider.Key = keyResponse.Key
-	cr.Status.AtProvider.Expires = metav1.Time{Time: keyResponse.Expires}
+	cr.Status.AtProvider.TokenID = token
 	cr.Status.AtProvider.UserID = keyResponse.UserID
 	cr.Status.AtProvider.Status = keyResponse.Status
+	cr.Status.AtProvider.KeyAlias = keyAlias(cr)
+	if t, err := litellm.ParseTime(keyResponse.Expires); err == nil {
+		cr.Status.AtProvider.Expires = metav1.Time{Time: t}
+	}
+	return managed.ExternalCreation{ConnectionDetails: cd}, nil
+}
 
-	return managed.ExternalCreation{
-		ConnectionDetails: managed.ConnectionDetails{
-			"key": []byte(keyResponse.Key),
-		},
-	}, nil
+// existing returns the token of a key that already exists for the supplied
+// Key, or an empty string if a key must be generated. Reconciles of a Key may
+// overlap, e.g. after a restart before its external name was persisted, so a
+// key generated by an earlier create must be adopted rather than generated
+// again and leaked.
+func (c *external) existing(ctx context.Context, cr *v1alpha1.Key) (string, error) {
+	en := meta.GetExternalName(cr)
+	alias := keyAlias(cr)
+
+	// An external name other than the default is a token that was recorded
+	// before.
+	if en != "" && en != cr.GetName() && en != alias {
+		err := c.client.Get(ctx, "/key/info", url.Values{"key": []string{en}}, nil)
+		if err == nil {
+			return en, nil
+		}
+		if !litellm.IsNotFound(err) {
+			return "", errors.Wrap(err, errGetKey)
+		}
+	}
+	if alias == "" {
+		token, err := c.findByIntent(ctx, cr)
+		return token, errors.Wrap(err, errListKeys)
+	}
+
+	token, owner, err := c.findByAlias(ctx, alias)
+	if err != nil {
+		return "", errors.Wrap(err, errListKeys)
+	}
+	switch {
+	case token == "":
+		return "", nil
+	case owner != "" && owner == string(cr.GetUID()):
+		// The key was generated for this Key, but its token was never
+		// recorded.
+		return token, nil
+	case c.adoptByAlias && (en == token || en == alias):
+		return token, nil
+	}
+	// LiteLLM key aliases are unique, so a key that was created outside of
+	// this Key must not be taken over, and deleted with it, unless adopting
+	// it was asked for.
+	return "", errors.Errorf(errAliasInUse, alias)
+}
+
+// findByIntent returns the token of the key generated by an earlier create
+// of the supplied Key whose token was never recorded, or an empty string if
+// there is none. Keys without an alias can only be told apart by their
+// metadata, so all keys of the user and team of the Key are listed, but only
+// if a create was attempted at all.
+func (c *external) findByIntent(ctx context.Context, cr *v1alpha1.Key) (string, error) {
+	uid := string(cr.GetUID())
+	if en := meta.GetExternalName(cr); uid == "" || cr.GetAnnotations()[annotationCreateIntent] != uid || (en != "" && en != cr.GetName()) {
+		return "", nil
+	}
+	for page := 1; ; page++ {
+		var rsp struct {
+			Keys []struct {
+				Token       string                 `json:"token"`
+				Metadata    map[string]interface{} `json:"metadata"`
+				KeyMetadata map[string]interface{} `json:"key_metadata"`
+			} `json:"keys"`
+			TotalPages int `json:"total_pages"`
+		}
+		q := url.Values{
+			"return_full_object": []string{"true"},
+			"page":               []string{strconv.Itoa(page)},
+			"size":               []string{strconv.Itoa(keyPageSize)},
+		}
+		if u := cr.Spec.ForProvider.UserID; u != "" {
+			q.Set("user_id", u)
+		}
+		if t := cr.Spec.ForProvider.TeamID; t != "" {
+			q.Set("team_id", t)
+		}
+		if err := c.client.Get(ctx, "/key/list", q, &rsp); err != nil {
+			return "", err
+		}
+		for _, k := range rsp.Keys {
+			md := k.Metadata
+			if c.metadataField == apisv1alpha1.MetadataFieldKeyMetadata {
+				md = k.KeyMetadata
+			}
+			if md[metadataCreateIntent] == uid {
+				return k.Token, nil
+			}
+		}
+		if page >= rsp.TotalPages {
+			return "", nil
+		}
+	}
+}
+
+// findByAlias returns the token of the key with the supplied alias and the
+// UID of the Key it was generated for, or empty strings if there is none.
+func (c *external) findByAlias(ctx context.Context, alias string) (string, string, error) {
+	var rsp struct {
+		Keys []struct {
+			Token       string                 `json:"token"`
+			KeyAlias    string                 `json:"key_alias"`
+			Metadata    map[string]interface{} `json:"metadata"`
+			KeyMetadata map[string]interface{} `json:"key_metadata"`
+		} `json:"keys"`
+	}
+	q := url.Values{"key_alias": []string{alias}, "return_full_object": []string{"true"}}
+	if err := c.client.Get(ctx, "/key/list", q, &rsp); err != nil {
+		return "", "", err
+	}
+	// Older LiteLLM versions ignore the key_alias filter.
+	for _, k := range rsp.Keys {
+		if k.KeyAlias != alias {
+			continue
+		}
+		md := k.Metadata
+		if c.metadataField == apisv1alpha1.MetadataFieldKeyMetadata {
+			md = k.KeyMetadata
+		}
+		owner, _ := md[metadataCreateIntent].(string)
+		return k.Token, owner, nil
+	}
+	return "", "", nil
 }
+
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
 	cr, ok := mg.(*v1alpha1.Key)
 	if !ok {
 		return managed.ExternalUpdate{}, errors.New(errNotKey)
 	}
+	// The key may be regenerated, so drop what was observed of its
+	// current token.
+	defer c.client.Invalidate("/key/info", tokenID(meta.GetExternalName(cr), ""))
+
+	payload, err := generatePayload(parameters(cr))
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	// LiteLLM computes a new expiry from the duration whenever it is sent,
+	// so only send it when it changed. Otherwise every update would push the
+	// expiry out.
+	o := cr.Status.AtProvider
+	extend := o.Duration != "" && !durationUpToDate(cr.Spec.ForProvider.Duration, o.Duration)
+	if !extend {
+		delete(payload, "duration")
+	}
+	// A fixed expiry is sent as the duration until then, whenever the key
+	// doesn't expire at it.
+	expire := !expiresAtUpToDate(cr.Spec.ForProvider.ExpiresAtRFC3339, o.Expires)
+	if expire {
+		if err := c.setExpiresIn(payload, cr.Spec.ForProvider); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+	// Only send what changed, so that the update neither writes fields
+	// needlessly nor clobbers changes made to fields that didn't.
+	if c.observed != nil {
+		changedFields(payload, parameters(cr), c.observed, o)
+	}
 
-	fmt.Printf("Updating: %+v", cr)
+	// An advisory budget must not block the key, so clear the max budget
+	// it may have been created or last updated with.
+	if advisory(cr.Spec.ForProvider) && cr.Spec.ForProvider.MaxBudget != 0 && c.observed != nil && c.observed.MaxBudget != nil {
+		payload["max_budget"] = nil
+	}
 
-	return managed.ExternalUpdate{
-		// Optionally return any details that may be required to connect to the
-		// external resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
-	}, nil
+	// LiteLLM replaces the whole model_max_budget map when it is sent, but
+	// ignores it when it is omitted, so explicitly clear it once the last
+	// model has been removed from the spec.
+	if len(cr.Spec.ForProvider.ModelMaxBudget) == 0 && len(cr.Status.AtProvider.ModelMaxBudget) > 0 {
+		payload["model_max_budget"] = map[string]float64{}
+	}
+	// The same goes for the per-model limits.
+	if len(cr.Spec.ForProvider.ModelRPMLimit) == 0 && len(o.ModelRPMLimit) > 0 {
+		payload["model_rpm_limit"] = map[string]int64{}
+	}
+	if len(cr.Spec.ForProvider.ModelTPMLimit) == 0 && len(o.ModelTPMLimit) > 0 {
+		payload["model_tpm_limit"] = map[string]int64{}
+	}
+
+	// LiteLLM replaces the whole metadata object on update, so merge ours
+	// into what the server has to keep the keys it manages itself.
+	if md, ok := payload["metadata"].(map[string]interface{}); ok && c.observed != nil {
+		md = litellm.MergeMetadata(c.observed.Metadata, md)
+		// The guardrails are stored in the metadata too, so don't send
+		// the observed ones back.
+		if g := cr.Spec.ForProvider.Guardrails; g != nil {
+			md["guardrails"] = g
+		}
+		// Nor the per-model limits, which would undo a removed model.
+		for f, l := range map[string]map[string]int64{
+			litellm.MetadataModelRPMLimit: cr.Spec.ForProvider.ModelRPMLimit,
+			litellm.MetadataModelTPMLimit: cr.Spec.ForProvider.ModelTPMLimit,
+		} {
+			if len(l) > 0 {
+				md[f] = l
+			} else {
+				delete(md, f)
+			}
+		}
+		payload["metadata"] = md
+	}
+
+	var rsp struct {
+		Expires string `json:"expires"`
+	}
+	if len(payload) > 0 {
+		payload["key"] = meta.GetExternalName(cr)
+		if err := c.client.Post(ctx, "/key/update", c.withMetadataField(payload), &rsp); err != nil {
+			if alias, ok := payload["key_alias"].(string); ok && alias != o.KeyAlias && aliasRejected(err) {
+				return managed.ExternalUpdate{}, errors.Wrapf(err, errRenameKey, o.KeyAlias, alias)
+			}
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateKey)
+		}
+	}
+	if extend {
+		cr.Status.AtProvider.Duration = cr.Spec.ForProvider.Duration
+	}
+	if extend || expire {
+		if t, err := litellm.ParseTime(rsp.Expires); err == nil {
+			cr.Status.AtProvider.Expires = metav1.Time{Time: t}
+		}
+	}
+
+	if c.observed != nil {
+		if err := c.applySpendAction(ctx, cr, spendAction(cr, c.observed)); err != nil {
+			return managed.ExternalUpdate{}, err
+		}
+	}
+
+	if !c.rotationDue(cr) && !c.regenerationDue(cr) {
+		return managed.ExternalUpdate{}, nil
+	}
+	return c.regenerate(ctx, cr)
+}
+
+// aliasRejected returns true if LiteLLM rejected an update because of the key
+// alias it sets, e.g. because the alias is in use, or LiteLLM doesn't allow
+// the alias of a key to be changed.
+func aliasRejected(err error) bool {
+	var ae *litellm.APIError
+	if !errors.As(err, &ae) || !litellm.IsRejected(err) {
+		return false
+	}
+	return ae.Param == "key_alias" || strings.Contains(strings.ToLower(ae.Body), "alias")
+}
+
+// spendAction returns whether the observed key must be blocked or unblocked
+// because of its spend alert threshold. Only keys that were blocked for
+// exceeding the threshold are unblocked.
+func spendAction(cr *v1alpha1.Key, info *keyInfo) string {
+	t := cr.Spec.ForProvider.SpendAlertThreshold
+	over := t != nil && info.Spend > *t
+	switch {
+	case over && !info.blocked():
+		return actionBlock
+	case !over && info.blocked() && cr.GetCondition(apisv1alpha1.TypeSpendExceeded).Status == corev1.ConditionTrue:
+		return actionUnblock
+	default:
+		return actionNone
+	}
+}
+
+// applySpendAction blocks or unblocks the key and records why.
+func (c *external) applySpendAction(ctx context.Context, cr *v1alpha1.Key, action string) error {
+	payload := map[string]interface{}{"key": meta.GetExternalName(cr)}
+	switch action {
+	case actionBlock:
+		if err := c.client.Post(ctx, "/key/block", payload, nil); err != nil {
+			return errors.Wrap(err, errBlockKey)
+		}
+		cr.Status.AtProvider.Blocked = true
+	case actionUnblock:
+		if err := c.client.Post(ctx, "/key/unblock", payload, nil); err != nil {
+			return errors.Wrap(err, errUnblockKey)
+		}
+		cr.Status.AtProvider.Blocked = false
+	default:
+		return nil
+	}
+	setSpendCondition(cr)
+	return nil
+}
+
+// setSpendCondition sets whether the key is blocked for exceeding its spend
+// alert threshold.
+func setSpendCondition(cr *v1alpha1.Key) {
+	t := cr.Spec.ForProvider.SpendAlertThreshold
+	o := cr.Status.AtProvider
+	if t == nil || o.Spend <= *t || !o.Blocked {
+		cr.SetConditions(apisv1alpha1.SpendBelowThreshold())
+		return
+	}
+	cr.SetConditions(apisv1alpha1.SpendExceeded(fmt.Sprintf("Key spent %.2f USD, exceeding its alert threshold of %.2f USD", o.Spend, *t)))
+}
+
+// rotationDue returns true if the observed key is older than its rotation
+// period. The age of a key is measured from its last rotation, or from its
+// creation if it was never rotated.
+func (c *external) rotationDue(cr *v1alpha1.Key) bool {
+	period := cr.Spec.ForProvider.RotationPeriod
+	if period == nil || period.Duration <= 0 || c.observed == nil {
+		return false
+	}
+	since, err := litellm.ParseTime(c.observed.CreatedAt)
+	if err != nil {
+		since = time.Time{}
+	}
+	if r := cr.Status.AtProvider.LastRotatedAt; r != nil && r.Time.After(since) {
+		since = r.Time
+	}
+	if since.IsZero() {
+		return false
+	}
+	return c.now().Sub(since) >= period.Duration
+}
+
+// setExpiry records the time left until the key expires, and whether it
+// has expired or expires within the expiry warn window.
+func (c *external) setExpiry(cr *v1alpha1.Key) {
+	o := &cr.Status.AtProvider
+	if o.Expires.IsZero() {
+		o.ExpiresIn = ""
+		if cr.GetCondition(apisv1alpha1.TypeExpired).Status == corev1.ConditionTrue {
+			cr.SetConditions(apisv1alpha1.NotExpired())
+		}
+		if cr.GetCondition(apisv1alpha1.TypeExpiringSoon).Status == corev1.ConditionTrue {
+			cr.SetConditions(apisv1alpha1.NotExpiringSoon())
+		}
+		return
+	}
+
+	left := o.Expires.Sub(c.now())
+	if left <= 0 {
+		o.ExpiresIn = "0s"
+		cr.SetConditions(apisv1alpha1.Expired(fmt.Sprintf("Key expired at %s", o.Expires.UTC().Format(time.RFC3339))))
+		cr.SetConditions(apisv1alpha1.NotExpiringSoon())
+		return
+	}
+	o.ExpiresIn = duration.HumanDuration(left)
+	cr.SetConditions(apisv1alpha1.NotExpired())
+	if c.expiryWarnWindow > 0 && left <= c.expiryWarnWindow {
+		cr.SetConditions(apisv1alpha1.ExpiringSoon(fmt.Sprintf("Key expires in %s, at %s", o.ExpiresIn, o.Expires.UTC().Format(time.RFC3339))))
+		return
+	}
+	cr.SetConditions(apisv1alpha1.NotExpiringSoon())
+}
+
+// regenerationDue returns true if the key has expired and must be
+// regenerated with the duration of its Key.
+func (c *external) regenerationDue(cr *v1alpha1.Key) bool {
+	return c.regenerateExpired && cr.Spec.ForProvider.Duration != "" && cr.GetCondition(apisv1alpha1.TypeExpired).Status == corev1.ConditionTrue
+}
+
+// regenerate regenerates the key and records the new key as its external
+// name. LiteLLM keeps the alias and settings of a regenerated key. An
+// expired key gets a new expiry computed from its duration.
+func (c *external) regenerate(ctx context.Context, cr *v1alpha1.Key) (managed.ExternalUpdate, error) {
+	var rsp struct {
+		Key     string `json:"key"`
+		TokenID string `json:"token_id"`
+		Expires string `json:"expires"`
+	}
+	payload := map[string]interface{}{"key": meta.GetExternalName(cr)}
+	expired := c.regenerationDue(cr)
+	if expired {
+		payload["duration"] = cr.Spec.ForProvider.Duration
+	}
+	if err := c.client.Post(ctx, "/key/regenerate", payload, &rsp); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errRegenerate)
+	}
+	cd := c.publishKey(ctx, cr, rsp.Key)
+
+	// The old key no longer works, so record the new one in the status
+	// first; it is persisted even if updating the annotation fails.
+	token := tokenID(rsp.Key, rsp.TokenID)
+	cr.Status.AtProvider.TokenID = token
+	cr.Status.AtProvider.LastRotatedAt = &metav1.Time{Time: c.now()}
+	if expired {
+		cr.Status.AtProvider.Duration = cr.Spec.ForProvider.Duration
+		if t, err := litellm.ParseTime(rsp.Expires); err == nil {
+			cr.Status.AtProvider.Expires = metav1.Time{Time: t}
+			c.setExpiry(cr)
+		}
+	}
+
+	// Only status changes are persisted after an update, so the new
+	// external name must be written explicitly. Updating the object resets
+	// its status to the stored one, so restore ours afterwards.
+	status := cr.Status.DeepCopy()
+	meta.SetExternalName(cr, token)
+	if err := c.kube.Update(ctx, cr); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errPersistKey)
+	}
+	cr.Status = *status
+
+	return managed.ExternalUpdate{ConnectionDetails: cd}, nil
+}
+
+// publishKey writes the supplied key, which LiteLLM only returns when it is
+// generated, to the connection secret of the supplied Key before anything
+// else can fail, retrying a few times. A key that still can't be published is
+// retained in the status of the Key, if enabled, for Observe to publish
+// later. The key is returned as connection details either way, so that the
+// reconciler tries to publish it once more.
+func (c *external) publishKey(ctx context.Context, cr *v1alpha1.Key, key string) managed.ConnectionDetails {
+	cd := managed.ConnectionDetails{"key": []byte(key)}
+	if c.publisher == nil {
+		return cd
+	}
+	err := retry.OnError(retry.DefaultBackoff, func(err error) bool {
+		return !errors.Is(err, context.Canceled)
+	}, func() error {
+		_, err := c.publisher.PublishConnection(ctx, cr, cd)
+		return err
+	})
+	if err == nil {
+		cr.Status.AtProvider.UnpublishedKey = ""
+		return cd
+	}
+	if !c.retainKeys {
+		c.recorder.Event(cr, event.Warning(reasonCannotPublishKey, errors.Wrap(err, errPublishKey)))
+		return cd
+	}
+
+	// Changes to the status made by Create are discarded when the reconciler
+	// persists the external name, so the retained key is persisted first.
+	cr.Status.AtProvider.UnpublishedKey = key
+	if serr := c.kube.Status().Update(ctx, cr); serr != nil {
+		c.recorder.Event(cr, event.Warning(reasonCannotPublishKey, errors.Wrap(serr, errStoreKey)))
+		return cd
+	}
+	c.recorder.Event(cr, event.Warning(reasonCannotPublishKey, errors.Wrap(err, errRetainKey)))
+	return cd
 }
 
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
@@ -251,8 +1009,506 @@ func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	if !ok {
 		return errors.New(errNotKey)
 	}
+	defer c.client.Invalidate("/key/info", tokenID(meta.GetExternalName(cr), ""))
+	if c.softDelete {
+		return c.softDeleteKey(ctx, cr)
+	}
 
-	fmt.Printf("Deleting: %+v", cr)
+	payload := map[string]interface{}{"keys": []string{meta.GetExternalName(cr)}}
+	err := c.client.Post(ctx, "/key/delete", payload, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteKey)
+}
+
+// softDeleteKey blocks the key of the supplied Key and tags it as deleted in
+// its metadata, so that it is retained in LiteLLM for audit but can no longer
+// be used.
+func (c *external) softDeleteKey(ctx context.Context, cr *v1alpha1.Key) error {
+	token := meta.GetExternalName(cr)
+	err := c.client.Post(ctx, "/key/block", map[string]interface{}{"key": token}, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, errBlockKey)
+	}
 
+	// LiteLLM replaces the whole metadata object on update, so keep what the
+	// server has.
+	var observed map[string]interface{}
+	if c.observed != nil {
+		observed = c.observed.Metadata
+	}
+	md := litellm.MergeMetadata(observed, map[string]interface{}{metadataDeletedAt: c.now().UTC().Format(time.RFC3339)})
+	payload := map[string]interface{}{"key": token, "metadata": md}
+	if err := c.client.Post(ctx, "/key/update", c.withMetadataField(payload), nil); err != nil {
+		return errors.Wrap(err, errUpdateKey)
+	}
+	c.recorder.Event(cr, event.Normal(reasonSoftDeleted, "Blocked the key and tagged it as deleted rather than deleting it; it is retained in LiteLLM until deleted there"))
 	return nil
 }
+
+// keyAlias returns the alias of the key of the supplied Key. An explicit
+// alias is used as is; otherwise the alias is the name of the Key with its
+// key name prefix, if any.
+func keyAlias(cr *v1alpha1.Key) string {
+	p := cr.Spec.ForProvider
+	if p.KeyAlias != "" || p.KeyNamePrefix == "" {
+		return p.KeyAlias
+	}
+	return p.KeyNamePrefix + cr.GetName()
+}
+
+// parameters returns the parameters of the supplied Key with its alias
+// resolved, and its resolved models added to its models.
+func parameters(cr *v1alpha1.Key) v1alpha1.KeyParameters {
+	p := cr.Spec.ForProvider
+	p.KeyAlias = keyAlias(cr)
+	p.Models = p.AllModels()
+	return p
+}
+
+// tokenID returns the token id of the supplied key. LiteLLM identifies keys
+// by the SHA-256 hash of the key, and accepts either in its requests. Older
+// LiteLLM versions don't return the token id of a generated key, and anything
+// that isn't a key is assumed to be a token id already.
+func tokenID(key, id string) string {
+	if id != "" {
+		return id
+	}
+	if !strings.HasPrefix(key, keyPrefix) {
+		return key
+	}
+	h := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(h[:])
+}
+
+// generatePayload returns the /key/generate payload for the supplied
+// parameters. Fields that are not set are omitted.
+func generatePayload(p v1alpha1.KeyParameters) (map[string]interface{}, error) { //nolint:gocyclo // Flat field-by-field mapping.
+	md, err := desiredMetadata(p)
+	if err != nil {
+		return nil, err
+	}
+	resetAt, err := budgetResetAt(p)
+	if err != nil {
+		return nil, err
+	}
+
+	payload := map[string]interface{}{}
+	if p.Duration != "" {
+		payload["duration"] = p.Duration
+	}
+	if p.KeyAlias != "" {
+		payload["key_alias"] = p.KeyAlias
+	}
+	if p.Key != "" {
+		payload["key"] = p.Key
+	}
+	if p.TeamID != "" {
+		payload["team_id"] = p.TeamID
+	}
+	if p.UserID != "" {
+		payload["user_id"] = p.UserID
+	}
+	if p.Models != nil {
+		payload["models"] = p.Models
+	}
+	if p.MaxBudget != 0 {
+		// LiteLLM only alerts when the soft budget is exceeded, but blocks
+		// the key when the max budget is.
+		if advisory(p) {
+			payload["soft_budget"] = p.MaxBudget
+		} else {
+			payload["max_budget"] = p.MaxBudget
+		}
+	}
+	if p.BudgetDuration != "" {
+		payload["budget_duration"] = p.BudgetDuration
+	}
+	if md != nil {
+		payload["metadata"] = md
+	}
+	if p.TPMLimit != nil {
+		payload["tpm_limit"] = *p.TPMLimit
+	}
+	if p.RPMLimit != nil {
+		payload["rpm_limit"] = *p.RPMLimit
+	}
+	if len(p.ModelMaxBudget) > 0 {
+		payload["model_max_budget"] = p.ModelMaxBudget
+	}
+	if len(p.ModelRPMLimit) > 0 {
+		payload["model_rpm_limit"] = p.ModelRPMLimit
+	}
+	if len(p.ModelTPMLimit) > 0 {
+		payload["model_tpm_limit"] = p.ModelTPMLimit
+	}
+	if !resetAt.IsZero() {
+		payload["budget_reset_at"] = resetAt.UTC().Format(time.RFC3339)
+	}
+	if p.Guardrails != nil {
+		payload["guardrails"] = p.Guardrails
+	}
+	if op := p.ObjectPermission; op != nil {
+		payload["object_permission"] = litellm.ObjectPermission(op.VectorStores, op.MCPServers, op.MCPAccessGroups)
+	}
+	return payload, nil
+}
+
+// budgetResetAt returns the fixed budget reset time of the supplied
+// parameters, or the zero time if there is none. The API server rejects
+// invalid values too, but only if the CRD validation is in place.
+func budgetResetAt(p v1alpha1.KeyParameters) (time.Time, error) {
+	if p.BudgetResetAtRFC3339 == "" {
+		return time.Time{}, nil
+	}
+	if p.BudgetDuration != "" {
+		return time.Time{}, errors.New(errBudgetConflict)
+	}
+	t, err := time.Parse(time.RFC3339, p.BudgetResetAtRFC3339)
+	return t, errors.Wrap(err, errBudgetResetAt)
+}
+
+// setExpiresIn sets the duration of the supplied payload to the time left
+// until the fixed expiry of the supplied parameters, if any. LiteLLM only
+// computes expiries from durations, so it has no way to take the time itself.
+func (c *external) setExpiresIn(payload map[string]interface{}, p v1alpha1.KeyParameters) error {
+	if p.ExpiresAtRFC3339 == "" {
+		return nil
+	}
+	t, err := time.Parse(time.RFC3339, p.ExpiresAtRFC3339)
+	if err != nil {
+		return errors.Wrap(err, errExpiresAt)
+	}
+	left := t.Sub(c.now()).Round(time.Second)
+	if left <= 0 {
+		return errors.Errorf(errExpiresAtPast, p.ExpiresAtRFC3339)
+	}
+	payload["duration"] = fmt.Sprintf("%ds", int64(left/time.Second))
+	return nil
+}
+
+// withMetadataField returns the supplied payload with its metadata moved to
+// the field LiteLLM expects it under.
+func (c *external) withMetadataField(payload map[string]interface{}) map[string]interface{} {
+	md, ok := payload["metadata"]
+	if !ok || c.metadataField == "" || c.metadataField == apisv1alpha1.MetadataFieldMetadata {
+		return payload
+	}
+	delete(payload, "metadata")
+	payload[c.metadataField] = md
+	return payload
+}
+
+// desiredMetadata returns the metadata_json of the supplied parameters with
+// the string metadata applied on top of it, or nil if neither is set.
+func desiredMetadata(p v1alpha1.KeyParameters) (map[string]interface{}, error) {
+	if p.Metadata == nil && p.MetadataJSON == nil && p.Logging == nil {
+		return nil, nil
+	}
+	md := map[string]interface{}{}
+	if p.MetadataJSON != nil && len(p.MetadataJSON.Raw) > 0 {
+		if err := json.Unmarshal(p.MetadataJSON.Raw, &md); err != nil {
+			return nil, errors.Wrap(err, errMetadata)
+		}
+	}
+	for k, v := range p.Metadata {
+		md[k] = v
+	}
+	if p.Logging != nil {
+		md[metadataLogging] = loggingCallbacks(p.Logging)
+	}
+	return md, nil
+}
+
+// loggingCallbacks returns the logging metadata of the supplied config, i.e.
+// an entry per callback with the type of requests it is called for.
+func loggingCallbacks(l *v1alpha1.LoggingConfig) []interface{} {
+	types := map[string]string{}
+	for _, c := range l.SuccessCallbacks {
+		types[c] = callbackSuccess
+	}
+	for _, c := range l.FailureCallbacks {
+		if types[c] == callbackSuccess {
+			types[c] = callbackSuccessAndFailure
+			continue
+		}
+		types[c] = callbackFailure
+	}
+	names := make([]string, 0, len(types))
+	for c := range types {
+		names = append(names, c)
+	}
+	sort.Strings(names)
+	callbacks := make([]interface{}, 0, len(names))
+	for _, c := range names {
+		callbacks = append(callbacks, map[string]interface{}{"callback_name": c, "callback_type": types[c]})
+	}
+	return callbacks
+}
+
+// loggingUpToDate returns true if the observed logging metadata has the
+// desired callbacks. LiteLLM may add settings such as callback_vars to each
+// callback, so only names and types are compared.
+func loggingUpToDate(desired []interface{}, observed interface{}) bool {
+	o, ok := observed.([]interface{})
+	if !ok || len(o) != len(desired) {
+		return false
+	}
+	want := map[interface{}]interface{}{}
+	for _, d := range desired {
+		c, _ := d.(map[string]interface{})
+		want[c["callback_name"]] = c["callback_type"]
+	}
+	for _, oc := range o {
+		c, ok := oc.(map[string]interface{})
+		if !ok {
+			return false
+		}
+		if t, ok := want[c["callback_name"]]; !ok || t != c["callback_type"] {
+			return false
+		}
+	}
+	return true
+}
+
+// isUpToDate returns true if the observed key matches the supplied
+// parameters.
+func isUpToDate(p v1alpha1.KeyParameters, md map[string]interface{}, info *keyInfo, o v1alpha1.KeyObservation) bool { //nolint:gocyclo // Flat field-by-field comparison.
+	if p.KeyAlias != "" && p.KeyAlias != info.KeyAlias {
+		return false
+	}
+	if p.TeamID != "" && p.TeamID != info.TeamID {
+		return false
+	}
+	if p.Models != nil && !litellm.SameStrings(p.Models, info.Models) {
+		return false
+	}
+	if p.MaxBudget != 0 && !budgetUpToDate(p, info) {
+		return false
+	}
+	if p.BudgetDuration != "" && p.BudgetDuration != info.BudgetDuration {
+		return false
+	}
+	if p.TPMLimit != nil && !sameLimit(p.TPMLimit, info.TPMLimit) {
+		return false
+	}
+	if p.RPMLimit != nil && !sameLimit(p.RPMLimit, info.RPMLimit) {
+		return false
+	}
+	if !sameMetadata(p, md, info) {
+		return false
+	}
+	if p.Guardrails != nil && !litellm.SameStrings(p.Guardrails, litellm.Guardrails(info.Metadata)) {
+		return false
+	}
+	if op := p.ObjectPermission; op != nil && !litellm.SameObjectPermission(litellm.ObjectPermission(op.VectorStores, op.MCPServers, op.MCPAccessGroups), info.ObjectPermission) {
+		return false
+	}
+	if !durationUpToDate(p.Duration, o.Duration) {
+		return false
+	}
+	if !expiresAtUpToDate(p.ExpiresAtRFC3339, o.Expires) {
+		return false
+	}
+	if !budgetResetAtUpToDate(p.BudgetResetAtRFC3339, info.BudgetResetAt) {
+		return false
+	}
+	if !sameModelLimits(p.ModelRPMLimit, o.ModelRPMLimit) || !sameModelLimits(p.ModelTPMLimit, o.ModelTPMLimit) {
+		return false
+	}
+	return sameModelMaxBudget(p.ModelMaxBudget, o.ModelMaxBudget)
+}
+
+// sameLimit returns true if both limits are set to the same value.
+func sameLimit(a, b *int64) bool {
+	return a != nil && b != nil && *a == *b
+}
+
+// advisory returns true if the MaxBudget of the supplied parameters only
+// alerts, rather than blocks the key, when it is exceeded.
+func advisory(p v1alpha1.KeyParameters) bool {
+	return p.BudgetEnforcement == v1alpha1.BudgetEnforcementAdvisory
+}
+
+// budgetUpToDate returns true if the observed key has the desired budget,
+// enforced as desired.
+func budgetUpToDate(p v1alpha1.KeyParameters, info *keyInfo) bool {
+	if advisory(p) {
+		sb := info.softBudget()
+		return info.MaxBudget == nil && sb != nil && p.MaxBudget == *sb
+	}
+	return info.MaxBudget != nil && p.MaxBudget == *info.MaxBudget
+}
+
+// sameMetadata returns true if the observed metadata of the key contains the
+// supplied desired metadata, including its logging callbacks.
+func sameMetadata(p v1alpha1.KeyParameters, md map[string]interface{}, info *keyInfo) bool {
+	if p.Logging != nil {
+		if !loggingUpToDate(loggingCallbacks(p.Logging), info.Metadata[metadataLogging]) {
+			return false
+		}
+		rest := make(map[string]interface{}, len(md))
+		for k, v := range md {
+			if k != metadataLogging {
+				rest[k] = v
+			}
+		}
+		md = rest
+	}
+	return metadataUpToDate(md, info.Metadata)
+}
+
+// changedFields removes the fields of the supplied /key/update payload that
+// already match the observed key, so that an update only writes what
+// changed. Fields that are not in the payload are left as they are.
+func changedFields(payload map[string]interface{}, p v1alpha1.KeyParameters, info *keyInfo, o v1alpha1.KeyObservation) { //nolint:gocyclo // Flat field-by-field comparison.
+	same := map[string]bool{
+		"key_alias":         p.KeyAlias == info.KeyAlias,
+		"team_id":           p.TeamID == info.TeamID,
+		"user_id":           p.UserID == info.UserID,
+		"models":            litellm.SameStrings(p.Models, info.Models),
+		"max_budget":        info.MaxBudget != nil && p.MaxBudget == *info.MaxBudget,
+		"soft_budget":       info.softBudget() != nil && p.MaxBudget == *info.softBudget(),
+		"budget_duration":   p.BudgetDuration == info.BudgetDuration,
+		"tpm_limit":         sameLimit(p.TPMLimit, info.TPMLimit),
+		"rpm_limit":         sameLimit(p.RPMLimit, info.RPMLimit),
+		"model_max_budget":  sameModelMaxBudget(p.ModelMaxBudget, o.ModelMaxBudget),
+		"model_rpm_limit":   sameModelLimits(p.ModelRPMLimit, o.ModelRPMLimit),
+		"model_tpm_limit":   sameModelLimits(p.ModelTPMLimit, o.ModelTPMLimit),
+		"budget_reset_at":   budgetResetAtUpToDate(p.BudgetResetAtRFC3339, info.BudgetResetAt),
+		"guardrails":        litellm.SameStrings(p.Guardrails, litellm.Guardrails(info.Metadata)),
+		"object_permission": true,
+	}
+	if op := p.ObjectPermission; op != nil {
+		same["object_permission"] = litellm.SameObjectPermission(litellm.ObjectPermission(op.VectorStores, op.MCPServers, op.MCPAccessGroups), info.ObjectPermission)
+	}
+	if md, ok := payload["metadata"].(map[string]interface{}); ok {
+		same["metadata"] = sameMetadata(p, md, info)
+	}
+	for k := range payload {
+		if same[k] {
+			delete(payload, k)
+		}
+	}
+}
+
+// budgetResetAtUpToDate returns true if the observed budget reset time is
+// the desired one, if any.
+func budgetResetAtUpToDate(desired, observed string) bool {
+	if desired == "" {
+		return true
+	}
+	d, err := time.Parse(time.RFC3339, desired)
+	if err != nil {
+		return false
+	}
+	o, err := litellm.ParseTime(observed)
+	return err == nil && d.Truncate(time.Second).Equal(o.Truncate(time.Second))
+}
+
+// expiresAtUpToDate returns true if the observed expiry of a key is within a
+// minute of the desired fixed expiry, if any.
+func expiresAtUpToDate(desired string, observed metav1.Time) bool {
+	if desired == "" {
+		return true
+	}
+	d, err := time.Parse(time.RFC3339, desired)
+	if err != nil || observed.IsZero() {
+		return false
+	}
+	diff := d.Sub(observed.Time)
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff < time.Minute
+}
+
+// durationUpToDate returns true unless the desired duration implies an
+// expiry that is materially different from the one computed from the
+// observed duration.
+func durationUpToDate(desired, observed string) bool {
+	if desired == "" || observed == "" || desired == observed {
+		return true
+	}
+	d, derr := parseDuration(desired)
+	o, oerr := parseDuration(observed)
+	if derr != nil || oerr != nil {
+		return false
+	}
+	diff := d - o
+	if diff < 0 {
+		diff = -diff
+	}
+	return diff < time.Minute
+}
+
+// parseDuration parses a LiteLLM duration such as 30s, 30m, 30h, 30d, 4w,
+// or 1mo. A month is 30 days.
+func parseDuration(s string) (time.Duration, error) {
+	units := []struct {
+		suffix string
+		unit   time.Duration
+	}{
+		// mo must be tried before m.
+		{"mo", 30 * 24 * time.Hour},
+		{"s", time.Second},
+		{"m", time.Minute},
+		{"h", time.Hour},
+		{"d", 24 * time.Hour},
+		{"w", 7 * 24 * time.Hour},
+	}
+	for _, u := range units {
+		if n, ok := strings.CutSuffix(s, u.suffix); ok {
+			v, err := strconv.Atoi(n)
+			if err != nil {
+				return 0, errors.Wrapf(err, errParseDuration, s)
+			}
+			return time.Duration(v) * u.unit, nil
+		}
+	}
+	return 0, errors.Errorf(errParseDuration, s)
+}
+
+// metadataUpToDate returns true if every metadata key in the spec has the
+// desired value. LiteLLM adds its own metadata keys (e.g. logging), so keys
+// that are not in the spec are ignored.
+func metadataUpToDate(desired, observed map[string]interface{}) bool {
+	for k, v := range desired {
+		if ov, ok := observed[k]; !ok || !reflect.DeepEqual(v, ov) {
+			return false
+		}
+	}
+	return true
+}
+
+// sameModelMaxBudget returns true if both maps hold the same budget for the
+// same models. A nil map is equal to an empty one.
+func sameModelMaxBudget(a, b map[string]float64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for m, budget := range a {
+		if ob, ok := b[m]; !ok || ob != budget {
+			return false
+		}
+	}
+	return true
+}
+
+// sameModelLimits returns true if both maps hold the same limit for the same
+// models. A nil map is equal to an empty one.
+func sameModelLimits(a, b map[string]int64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for m, limit := range a {
+		if ol, ok := b[m]; !ok || ol != limit {
+			return false
+		}
+	}
+	return true
+}
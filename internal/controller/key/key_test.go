@@ -18,13 +18,35 @@ package key
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	guardrailv1alpha1 "github.com/crossplane/provider-litellm/apis/guardrail/v1alpha1"
+	"github.com/crossplane/provider-litellm/apis/key/v1alpha1"
+	modelv1alpha1 "github.com/crossplane/provider-litellm/apis/model/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -35,40 +57,2492 @@ import (
 // https://github.com/golang/go/wiki/TestComments
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
-func TestObserve(t *testing.T) {
-	type fields struct {
-		service interface{}
+func key(token string, p v1alpha1.KeyParameters, o v1alpha1.KeyObservation) *v1alpha1.Key {
+	cr := &v1alpha1.Key{
+		Spec:   v1alpha1.KeySpec{ForProvider: p},
+		Status: v1alpha1.KeyStatus{AtProvider: o},
 	}
+	meta.SetExternalName(cr, token)
+	return cr
+}
 
-	type args struct {
-		ctx context.Context
-		mg  resource.Managed
-	}
+func named(name string, cr *v1alpha1.Key) *v1alpha1.Key {
+	cr.SetName(name)
+	return cr
+}
+
+func intended(cr *v1alpha1.Key) *v1alpha1.Key {
+	cr.SetAnnotations(map[string]string{annotationCreateIntent: string(cr.GetUID())})
+	return cr
+}
+
+func withUID(uid string, cr *v1alpha1.Key) *v1alpha1.Key {
+	cr.SetUID(types.UID(uid))
+	return cr
+}
 
+func TestObserve(t *testing.T) {
 	type want struct {
 		o   managed.ExternalObservation
+		obs v1alpha1.KeyObservation
 		err error
 	}
 
 	cases := map[string]struct {
-		reason string
-		fields fields
-		args   args
-		want   want
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Key
+		want      want
 	}{
-		// TODO: Add test cases.
+		"NotFound": {
+			reason:    "A 404 from /key/info should report the key as absent.",
+			responses: map[string]fake.Response{},
+			cr:        key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"ServerMetadataIgnored": {
+			reason:    "Metadata keys added by LiteLLM itself should not be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"team": "ml", "logging": [{"callback_name": "langfuse"}], "tpm_limit_type": "best_effort"}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{Metadata: map[string]string{"team": "ml"}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1"},
+			},
+		},
+		"MetadataChanged": {
+			reason:    "A metadata key whose value differs from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"team": "web", "logging": []}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{Metadata: map[string]string{"team": "ml"}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1"},
+			},
+		},
+		"NestedMetadataUpToDate": {
+			reason:    "Nested JSON metadata matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"logging": [{"callback_name": "langfuse", "callback_vars": {"langfuse_host": "https://lf"}}]}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{MetadataJSON: &runtime.RawExtension{Raw: []byte(`{"logging": [{"callback_name": "langfuse", "callback_vars": {"langfuse_host": "https://lf"}}]}`)}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1"},
+			},
+		},
+		"NestedMetadataChanged": {
+			reason:    "Nested JSON metadata that differs from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"logging": [{"callback_name": "langsmith"}]}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{MetadataJSON: &runtime.RawExtension{Raw: []byte(`{"logging": [{"callback_name": "langfuse"}]}`)}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1"},
+			},
+		},
+		"ModelMaxBudgetUpToDate": {
+			reason:    "Per-model budgets matching the spec should be up to date, whichever shape LiteLLM returns them in.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"model_max_budget": {"gpt-4": 10, "gpt-4o": {"budget_limit": 5, "time_period": "1d"}}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{ModelMaxBudget: map[string]float64{"gpt-4": 10, "gpt-4o": 5}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelMaxBudget: map[string]float64{"gpt-4": 10, "gpt-4o": 5}},
+			},
+		},
+		"ModelMaxBudgetAdded": {
+			reason:    "A per-model budget added to the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"model_max_budget": {"gpt-4": 10}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{ModelMaxBudget: map[string]float64{"gpt-4": 10, "gpt-4o": 5}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelMaxBudget: map[string]float64{"gpt-4": 10}},
+			},
+		},
+		"ModelMaxBudgetChanged": {
+			reason:    "A changed per-model budget should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"model_max_budget": {"gpt-4": 10}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{ModelMaxBudget: map[string]float64{"gpt-4": 20}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelMaxBudget: map[string]float64{"gpt-4": 10}},
+			},
+		},
+		"ModelMaxBudgetRemoved": {
+			reason:    "A per-model budget removed from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"model_max_budget": {"gpt-4": 10}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelMaxBudget: map[string]float64{"gpt-4": 10}},
+			},
+		},
+		"ModelLimitsUpToDate": {
+			reason:    "Per-model limits stored in the metadata of the key should be observed.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"model_rpm_limit": {"gpt-4": 10}, "model_tpm_limit": {"gpt-4": 1000}}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{ModelRPMLimit: map[string]int64{"gpt-4": 10}, ModelTPMLimit: map[string]int64{"gpt-4": 1000}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelRPMLimit: map[string]int64{"gpt-4": 10}, ModelTPMLimit: map[string]int64{"gpt-4": 1000}},
+			},
+		},
+		"ModelRPMLimitAdded": {
+			reason:    "A per-model limit added to the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"model_rpm_limit": {"gpt-4": 10}}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{ModelRPMLimit: map[string]int64{"gpt-4": 10, "gpt-4o": 5}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelRPMLimit: map[string]int64{"gpt-4": 10}},
+			},
+		},
+		"ModelTPMLimitChanged": {
+			reason:    "A changed per-model limit should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"model_tpm_limit": {"gpt-4": 1000}}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{ModelTPMLimit: map[string]int64{"gpt-4": 2000}}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelTPMLimit: map[string]int64{"gpt-4": 1000}},
+			},
+		},
+		"ModelRPMLimitRemoved": {
+			reason:    "A per-model limit removed from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"model_rpm_limit": {"gpt-4": 10}}}}`}},
+			cr:        key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.KeyObservation{TokenID: "tok-1", ModelRPMLimit: map[string]int64{"gpt-4": 10}},
+			},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service}
-			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
 			}
 			if diff := cmp.Diff(tc.want.o, got); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
 			}
+			if diff := cmp.Diff(tc.want.obs, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/list":     {Body: `{"keys": []}`},
+		"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1", "expires": null}`},
+	})
+	defer srv.Close()
+
+	cr := key("", v1alpha1.KeyParameters{KeyAlias: "ci", ModelMaxBudget: map[string]float64{"gpt-4": 10}, ModelRPMLimit: map[string]int64{"gpt-4": 5}}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	got, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"key_alias":        "ci",
+		"model_max_budget": map[string]interface{}{"gpt-4": float64(10)},
+		"model_rpm_limit":  map[string]interface{}{"gpt-4": float64(5)},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/generate")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("tok-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+	if diff := cmp.Diff(managed.ConnectionDetails{"key": []byte("sk-1")}, got.ConnectionDetails); diff != "" {
+		t.Errorf("e.Create(...): -want connection details, +got connection details:\n%s", diff)
+	}
+}
+
+func TestAuditFields(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/list":     {Body: `{"keys": []}`},
+		"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+		"/key/info":     {Body: `{"key": "tok-1", "info": {"key_alias": "ci", "created_by": "crossplane", "updated_by": "alice"}}`},
+	})
+	defer srv.Close()
+
+	cr := key("", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client(), actorIdentity: "crossplane"}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+	if diff := cmp.Diff("crossplane", srv.Body("/key/generate")["created_by"]); diff != "" {
+		t.Errorf("e.Create(...): -want created_by, +got created_by:\n%s", diff)
+	}
+
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	got := []string{cr.Status.AtProvider.CreatedBy, cr.Status.AtProvider.UpdatedBy}
+	if diff := cmp.Diff([]string{"crossplane", "alice"}, got); diff != "" {
+		t.Errorf("e.Observe(...): -want audit fields, +got audit fields:\n%s", diff)
+	}
+}
+
+func TestCreateValidateTeam(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		validate bool
+		team     map[string]fake.Response
+		want     error
+		wantPath bool
+	}{
+		"TeamExists": {
+			reason:   "A key of an existing team should be generated.",
+			validate: true,
+			team:     map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {"team_id": "ml"}}`}},
+			wantPath: true,
+		},
+		"TeamMissing": {
+			reason:   "A key of a missing team should not be generated.",
+			validate: true,
+			team:     map[string]fake.Response{"/team/info": {Status: http.StatusNotFound, Body: `{"detail": "team not found"}`}},
+			want:     errors.Errorf(errNoTeam, "ml"),
+		},
+		"Disabled": {
+			reason:   "The team should not be checked unless validation is enabled.",
+			team:     map[string]fake.Response{"/team/info": {Status: http.StatusNotFound}},
+			wantPath: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			responses := map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}}
+			for p, r := range tc.team {
+				responses[p] = r
+			}
+			srv := fake.NewServer(responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client(), validateTeam: tc.validate}
+			_, err := e.Create(context.Background(), key("", v1alpha1.KeyParameters{TeamID: "ml"}, v1alpha1.KeyObservation{}))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.wantPath, slices.Contains(srv.Paths(), "/key/generate")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want generated, +got generated:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreatePending(t *testing.T) {
+	generate := fake.NewServer(map[string]fake.Response{
+		"/key/list":     {Body: `{"keys": []}`},
+		"/key/generate": {Body: `{"key": null, "token_id": "tok-1", "status": "pending"}`},
+	})
+	defer generate.Close()
+	pending := fake.NewServer(map[string]fake.Response{
+		"/key/info": {Body: `{"key": "tok-1", "info": {"key_alias": "ci", "status": "pending"}}`},
+	})
+	defer pending.Close()
+	ready := fake.NewServer(map[string]fake.Response{
+		"/key/info": {Body: `{"key": "tok-1", "info": {"key_alias": "ci", "status": "generated", "key": "sk-1"}}`},
+	})
+	defer ready.Close()
+
+	cr := key("", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{})
+	e := external{client: generate.Client()}
+	created, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+	if created.ConnectionDetails != nil {
+		t.Errorf("e.Create(...): a pending key should not publish connection details, got %v", created.ConnectionDetails)
+	}
+	if diff := cmp.Diff("tok-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+
+	e.client = pending.Client()
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if got.ConnectionDetails != nil || cr.GetCondition(xpv1.TypeReady).Status == corev1.ConditionTrue {
+		t.Errorf("e.Observe(...): a pending key should neither publish connection details nor be ready")
+	}
+
+	e.client = ready.Client()
+	got, err = e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if diff := cmp.Diff(managed.ConnectionDetails{"key": []byte("sk-1")}, got.ConnectionDetails); diff != "" {
+		t.Errorf("e.Observe(...): -want connection details, +got connection details:\n%s", diff)
+	}
+	if cr.GetCondition(xpv1.TypeReady).Status != corev1.ConditionTrue {
+		t.Errorf("e.Observe(...): a generated key should be ready")
+	}
+	if diff := cmp.Diff("generated", cr.Status.AtProvider.Status); diff != "" {
+		t.Errorf("e.Observe(...): -want status, +got status:\n%s", diff)
+	}
+}
+
+func TestCreateAliasPreCheck(t *testing.T) {
+	list := fake.Response{Body: `{"keys": [{"token": "hashed-1", "key_alias": "ci"}], "total_count": 1}`}
+
+	type want struct {
+		c       managed.ExternalCreation
+		extName string
+		paths   []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Key
+		want      want
+	}{
+		"AliasFree": {
+			reason:    "A key should be generated when no key has the alias.",
+			responses: map[string]fake.Response{"/key/list": {Body: `{"keys": []}`}, "/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}},
+			cr:        named("my-key", key("my-key", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{})),
+			want: want{
+				c:       managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{"key": []byte("sk-1")}},
+				extName: "tok-1",
+				paths:   []string{"/key/list", "/key/generate"},
+			},
+		},
+		"AdoptByAlias": {
+			reason:    "An existing key whose alias matches the external name should be adopted.",
+			responses: map[string]fake.Response{"/key/list": list},
+			cr:        key("ci", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}),
+			want: want{
+				extName: "hashed-1",
+				paths:   []string{"/key/list"},
+			},
+		},
+		"AdoptByToken": {
+			reason:    "An existing key whose token matches the external name should be adopted.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "hashed-1", "info": {"key_alias": "ci"}}`}},
+			cr:        key("hashed-1", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}),
+			want: want{
+				extName: "hashed-1",
+				paths:   []string{"/key/info"},
+			},
+		},
+		"AliasInUse": {
+			reason:    "An existing key with the alias that does not match the external name should fail the create.",
+			responses: map[string]fake.Response{"/key/list": list},
+			cr:        key("other-key", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}),
+			want: want{
+				extName: "other-key",
+				paths:   []string{"/key/info", "/key/list"},
+				err:     errors.Errorf(errAliasInUse, "ci"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client(), adoptByAlias: true}
+			got, err := e.Create(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.c, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.extName, meta.GetExternalName(tc.cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateIdempotent(t *testing.T) {
+	type want struct {
+		extName string
+		paths   []string
+		err     error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Key
+		want      want
+	}{
+		"RecordedKeyExists": {
+			reason:    "A key whose recorded token exists should be adopted rather than generated again.",
+			responses: map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {}}`}},
+			cr:        named("ci", key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})),
+			want:      want{extName: "tok-1", paths: []string{"/key/info"}},
+		},
+		"InterruptedCreate": {
+			reason: "A key generated by an earlier create whose token was never recorded should be adopted by its alias.",
+			responses: map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": [{"token": "hashed-1", "key_alias": "ci", "metadata": {"crossplane_create_intent": "uid-1"}}]}`},
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   withUID("uid-1", named("ci-key", key("ci-key", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}))),
+			want: want{extName: "hashed-1", paths: []string{"/key/list"}},
+		},
+		"AliasOfUnownedKey": {
+			reason: "A key with the alias that was not generated for the Key should not be adopted.",
+			responses: map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": [{"token": "hashed-1", "key_alias": "ci"}]}`},
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   withUID("uid-1", named("ci-key", key("ci-key", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}))),
+			want: want{extName: "ci-key", paths: []string{"/key/list"}, err: errors.Errorf(errAliasInUse, "ci")},
+		},
+		"AliasOfOtherKey": {
+			reason: "A key with the alias that was generated for another Key should not be adopted.",
+			responses: map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": [{"token": "hashed-1", "key_alias": "ci", "metadata": {"crossplane_create_intent": "uid-0"}}]}`},
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   withUID("uid-1", named("ci-key", key("ci-key", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}))),
+			want: want{extName: "ci-key", paths: []string{"/key/list"}, err: errors.Errorf(errAliasInUse, "ci")},
+		},
+		"Absent": {
+			reason: "A key should be generated when none exists.",
+			responses: map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": []}`},
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   named("ci-key", key("ci-key", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{})),
+			want: want{extName: "tok-2", paths: []string{"/key/list", "/key/generate"}},
+		},
+		"InterruptedCreateWithoutAlias": {
+			reason: "A key generated by an earlier create of a Key without an alias should be adopted by the UID it was tagged with.",
+			responses: map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": [{"token": "hashed-0", "metadata": {"crossplane_create_intent": "uid-0"}}, {"token": "hashed-1", "metadata": {"crossplane_create_intent": "uid-1"}}], "total_pages": 1}`},
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   intended(withUID("uid-1", named("ci-key", key("ci-key", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})))),
+			want: want{extName: "hashed-1", paths: []string{"/key/list"}},
+		},
+		"InterruptedCreateWithoutAliasAbsent": {
+			reason: "A key should be generated if an earlier create of a Key without an alias never generated one.",
+			responses: map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": [{"token": "hashed-0", "metadata": {"crossplane_create_intent": "uid-0"}}], "total_pages": 1}`},
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   intended(withUID("uid-1", named("ci-key", key("ci-key", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})))),
+			want: want{extName: "tok-2", paths: []string{"/key/list", "/key/generate"}},
+		},
+		"WithoutAlias": {
+			reason: "A Key without an alias that never attempted a create should generate its key without listing keys.",
+			responses: map[string]fake.Response{
+				"/key/generate": {Body: `{"key": "sk-2", "token_id": "tok-2"}`},
+			},
+			cr:   withUID("uid-1", named("ci-key", key("ci-key", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{}))),
+			want: want{extName: "tok-2", paths: []string{"/key/generate"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			kube := &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)}
+			e := external{kube: kube, client: srv.Client()}
+			_, err := e.Create(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.extName, meta.GetExternalName(tc.cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateRecordsIntent(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}})
+	defer srv.Close()
+
+	// The intent must be persisted before the key is generated, or a key
+	// generated by a create that is interrupted can't be found again.
+	var persisted string
+	var requests int
+	kube := &test.MockClient{MockUpdate: func(_ context.Context, obj client.Object, _ ...client.UpdateOption) error {
+		persisted, requests = obj.GetAnnotations()[annotationCreateIntent], len(srv.Paths())
+		return nil
+	}}
+	cr := withUID("uid-1", named("ci-key", key("", v1alpha1.KeyParameters{Metadata: map[string]string{"team": "ml"}}, v1alpha1.KeyObservation{})))
+	e := external{kube: kube, client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	if diff := cmp.Diff("uid-1", persisted); diff != "" {
+		t.Errorf("e.Create(...): -want persisted intent, +got persisted intent:\n%s", diff)
+	}
+	if requests != 0 {
+		t.Errorf("e.Create(...): the intent should be persisted before the key is generated")
+	}
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{"team": "ml", "crossplane_create_intent": "uid-1"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/generate")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestCreateTagsOwner(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/list":     {Body: `{"keys": []}`},
+		"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+	})
+	defer srv.Close()
+
+	// A key with an alias is found by it, so no intent is recorded, but it
+	// is still tagged with the UID of the Key it was generated for.
+	kube := &test.MockClient{MockUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+		t.Errorf("e.Create(...): no intent should be recorded for a Key with an alias")
+		return nil
+	}}
+	cr := withUID("uid-1", named("ci-key", key("", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{})))
+	e := external{kube: kube, client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"key_alias": "ci",
+		"metadata":  map[string]interface{}{"crossplane_create_intent": "uid-1"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/generate")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestCreateIntentError(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}})
+	defer srv.Close()
+
+	errBoom := errors.New("boom")
+	kube := &test.MockClient{MockUpdate: test.NewMockUpdateFn(errBoom)}
+	cr := withUID("uid-1", named("ci-key", key("", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})))
+	e := external{kube: kube, client: srv.Client()}
+	_, err := e.Create(context.Background(), cr)
+	if diff := cmp.Diff(errors.Wrap(errBoom, errIntent), err, test.EquateErrors()); diff != "" {
+		t.Errorf("e.Create(...): -want error, +got error:\n%s", diff)
+	}
+	if len(srv.Paths()) != 0 {
+		t.Errorf("e.Create(...): no key should be generated if the intent can't be recorded")
+	}
+}
+
+func TestTokenID(t *testing.T) {
+	// The SHA-256 hash of sk-1, as LiteLLM computes it.
+	hashed := "0f2c10bf3d128c719c6bfa4ecbae94b7fceebaea6e4438fef38a90e5acc326f3"
+
+	cases := map[string]struct {
+		reason string
+		key    string
+		id     string
+		want   string
+	}{
+		"Returned": {
+			reason: "The token id returned by LiteLLM should be used.",
+			key:    "sk-1",
+			id:     "tok-1",
+			want:   "tok-1",
+		},
+		"Hashed": {
+			reason: "A key without a returned token id should be hashed like LiteLLM does.",
+			key:    "sk-1",
+			want:   hashed,
+		},
+		"AlreadyTokenID": {
+			reason: "Anything that isn't a key should be assumed to be a token id.",
+			key:    hashed,
+			want:   hashed,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			if diff := cmp.Diff(tc.want, tokenID(tc.key, tc.id)); diff != "" {
+				t.Errorf("\n%s\ntokenID(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateExternalNameIsTokenID(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}})
+	defer srv.Close()
+
+	cr := key("", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	got, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	if diff := cmp.Diff("tok-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+	if diff := cmp.Diff(v1alpha1.KeyObservation{TokenID: "tok-1"}, cr.Status.AtProvider); diff != "" {
+		t.Errorf("e.Create(...): -want status, +got status:\n%s", diff)
+	}
+	if diff := cmp.Diff(managed.ConnectionDetails{"key": []byte("sk-1")}, got.ConnectionDetails); diff != "" {
+		t.Errorf("e.Create(...): -want connection details, +got connection details:\n%s", diff)
+	}
+}
+
+func TestStatusOmitsKey(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-secret", "token_id": "tok-1"}`}})
+	defer srv.Close()
+
+	cr := key("", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	got, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	status, err := json.Marshal(cr.Status)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(status), "sk-secret") || strings.Contains(meta.GetExternalName(cr), "sk-secret") {
+		t.Errorf("e.Create(...): the raw key must only be published as a connection detail, got status %s", status)
+	}
+	if diff := cmp.Diff([]byte("sk-secret"), got.ConnectionDetails["key"]); diff != "" {
+		t.Errorf("e.Create(...): -want connection detail, +got connection detail:\n%s", diff)
+	}
+}
+
+// publisher fails to publish connection details the first failures times,
+// and records those it publishes.
+type publisher struct {
+	failures  int
+	attempts  int
+	published []managed.ConnectionDetails
+}
+
+func (p *publisher) PublishConnection(_ context.Context, _ resource.ConnectionSecretOwner, cd managed.ConnectionDetails) (bool, error) {
+	p.attempts++
+	if p.attempts <= p.failures {
+		return false, errors.New("boom")
+	}
+	p.published = append(p.published, cd)
+	return true, nil
+}
+
+func (p *publisher) UnpublishConnection(_ context.Context, _ resource.ConnectionSecretOwner, _ managed.ConnectionDetails) error {
+	return nil
+}
+
+func TestPublishKey(t *testing.T) {
+	type want struct {
+		published   bool
+		unpublished string
+		events      int
+	}
+
+	cases := map[string]struct {
+		reason   string
+		failures int
+		retain   bool
+		want     want
+	}{
+		"Published": {
+			reason: "A generated key should be published by Create.",
+			want:   want{published: true},
+		},
+		"PublishedOnRetry": {
+			reason:   "A key that fails to publish once should be published on retry.",
+			failures: 1,
+			want:     want{published: true},
+		},
+		"Retained": {
+			reason:   "A key that can't be published should be retained in the status if enabled.",
+			failures: 100,
+			retain:   true,
+			want:     want{unpublished: "sk-1", events: 1},
+		},
+		"NotRetained": {
+			reason:   "A key that can't be published should only be reported unless retaining is enabled.",
+			failures: 100,
+			want:     want{events: 1},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": []}`},
+				"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+			})
+			defer srv.Close()
+
+			var persisted string
+			kube := &test.MockClient{MockStatusUpdate: func(_ context.Context, obj client.Object, _ ...client.SubResourceUpdateOption) error {
+				persisted = obj.(*v1alpha1.Key).Status.AtProvider.UnpublishedKey
+				return nil
+			}}
+			p := &publisher{failures: tc.failures}
+			rec := &recorder{}
+			cr := key("", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})
+			e := external{kube: kube, client: srv.Client(), recorder: rec, publisher: p, retainKeys: tc.retain}
+			got, err := e.Create(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Create(...): %v", tc.reason, err)
+			}
+
+			if diff := cmp.Diff([]byte("sk-1"), got.ConnectionDetails["key"]); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want connection detail, +got connection detail:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.published, len(p.published) == 1); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want published, +got published:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.unpublished, cr.Status.AtProvider.UnpublishedKey); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want unpublished key, +got unpublished key:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.unpublished, persisted); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want persisted unpublished key, +got persisted unpublished key:\n%s", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, len(rec.events)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want events, +got events:\n%s", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPublishRetainedKey(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info": {Body: `{"key": "tok-1", "info": {}}`},
+	})
+	defer srv.Close()
+
+	// The connection secret can't be written until the second Observe.
+	p := &publisher{failures: 1}
+	cr := key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{UnpublishedKey: "sk-1"})
+	e := external{client: srv.Client(), recorder: &recorder{}, publisher: p, retainKeys: true}
+	for i := 0; i < 2; i++ {
+		got, err := e.Observe(context.Background(), cr)
+		if err != nil {
+			t.Fatalf("e.Observe(...): %v", err)
+		}
+		if diff := cmp.Diff([]byte("sk-1"), got.ConnectionDetails["key"]); diff != "" {
+			t.Errorf("e.Observe(...): -want connection detail, +got connection detail:\n%s", diff)
+		}
+	}
+
+	if diff := cmp.Diff([]managed.ConnectionDetails{{"key": []byte("sk-1")}}, p.published); diff != "" {
+		t.Errorf("e.Observe(...): -want published, +got published:\n%s", diff)
+	}
+	if cr.Status.AtProvider.UnpublishedKey != "" {
+		t.Errorf("e.Observe(...): a published key should be cleared from the status")
+	}
+}
+
+func TestObserveMigratesKey(t *testing.T) {
+	hashed := "0f2c10bf3d128c719c6bfa4ecbae94b7fceebaea6e4438fef38a90e5acc326f3"
+	srv := fake.NewServer(map[string]fake.Response{"/key/info": {Body: `{"key": "` + hashed + `", "info": {}}`}})
+	defer srv.Close()
+
+	cr := key("sk-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+
+	want := managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ResourceLateInitialized: true}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Errorf("e.Observe(...): -want, +got:\n%s", diff)
+	}
+	if diff := cmp.Diff(hashed, meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Observe(...): -want external name, +got external name:\n%s", diff)
+	}
+	if diff := cmp.Diff(hashed, srv.Requests()[0].Query.Get("key")); diff != "" {
+		t.Errorf("e.Observe(...): -want key query, +got key query:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Key
+		want   map[string]interface{}
+	}{
+		"ModelMaxBudgetAdded": {
+			reason: "Adding a per-model budget should send the full map.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{ModelMaxBudget: map[string]float64{"gpt-4": 10, "gpt-4o": 5}}, v1alpha1.KeyObservation{ModelMaxBudget: map[string]float64{"gpt-4": 10}}),
+			want: map[string]interface{}{
+				"key":              "tok-1",
+				"model_max_budget": map[string]interface{}{"gpt-4": float64(10), "gpt-4o": float64(5)},
+			},
+		},
+		"ModelMaxBudgetChanged": {
+			reason: "Changing a per-model budget should send the new value.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{ModelMaxBudget: map[string]float64{"gpt-4": 20}}, v1alpha1.KeyObservation{ModelMaxBudget: map[string]float64{"gpt-4": 10}}),
+			want: map[string]interface{}{
+				"key":              "tok-1",
+				"model_max_budget": map[string]interface{}{"gpt-4": float64(20)},
+			},
+		},
+		"ModelMaxBudgetRemoved": {
+			reason: "Removing one of several per-model budgets should send the remaining map.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{ModelMaxBudget: map[string]float64{"gpt-4": 10}}, v1alpha1.KeyObservation{ModelMaxBudget: map[string]float64{"gpt-4": 10, "gpt-4o": 5}}),
+			want: map[string]interface{}{
+				"key":              "tok-1",
+				"model_max_budget": map[string]interface{}{"gpt-4": float64(10)},
+			},
+		},
+		"LastModelMaxBudgetRemoved": {
+			reason: "Removing the last per-model budget should explicitly clear the map.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{Duration: "30d"}, v1alpha1.KeyObservation{ModelMaxBudget: map[string]float64{"gpt-4": 10}}),
+			want: map[string]interface{}{
+				"key":              "tok-1",
+				"model_max_budget": map[string]interface{}{},
+			},
+		},
+		"ModelRPMLimitAdded": {
+			reason: "Adding a per-model limit should send the full map.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{ModelRPMLimit: map[string]int64{"gpt-4": 10, "gpt-4o": 5}}, v1alpha1.KeyObservation{ModelRPMLimit: map[string]int64{"gpt-4": 10}}),
+			want: map[string]interface{}{
+				"key":             "tok-1",
+				"model_rpm_limit": map[string]interface{}{"gpt-4": float64(10), "gpt-4o": float64(5)},
+			},
+		},
+		"ModelTPMLimitChanged": {
+			reason: "Changing a per-model limit should send the new value.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{ModelTPMLimit: map[string]int64{"gpt-4": 2000}}, v1alpha1.KeyObservation{ModelTPMLimit: map[string]int64{"gpt-4": 1000}}),
+			want: map[string]interface{}{
+				"key":             "tok-1",
+				"model_tpm_limit": map[string]interface{}{"gpt-4": float64(2000)},
+			},
+		},
+		"ModelRPMLimitRemoved": {
+			reason: "Removing one of several per-model limits should send the remaining map.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{ModelRPMLimit: map[string]int64{"gpt-4": 10}}, v1alpha1.KeyObservation{ModelRPMLimit: map[string]int64{"gpt-4": 10, "gpt-4o": 5}}),
+			want: map[string]interface{}{
+				"key":             "tok-1",
+				"model_rpm_limit": map[string]interface{}{"gpt-4": float64(10)},
+			},
+		},
+		"LastModelTPMLimitRemoved": {
+			reason: "Removing the last per-model limit should explicitly reset the limits.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{Duration: "30d"}, v1alpha1.KeyObservation{ModelTPMLimit: map[string]int64{"gpt-4": 1000}}),
+			want: map[string]interface{}{
+				"key":             "tok-1",
+				"model_tpm_limit": map[string]interface{}{},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/key/update": {Body: `{}`}})
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			if _, err := e.Update(context.Background(), tc.cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Body("/key/update")); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateSendsChangedFields(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info":   {Body: `{"key": "tok-1", "info": {"key_alias": "ci", "team_id": "ml", "models": ["gpt-4o"], "max_budget": 10, "tpm_limit": 1000, "metadata": {"env": "prod"}}}`},
+		"/key/update": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{
+		KeyAlias:  "ci",
+		TeamID:    "ml",
+		Models:    []string{"gpt-4o"},
+		MaxBudget: 20,
+		Metadata:  map[string]string{"env": "prod"},
+	}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{"key": "tok-1", "max_budget": float64(20)}
+	if diff := cmp.Diff(want, srv.Body("/key/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestUpdateMergesMetadata(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info":   {Body: `{"key": "tok-1", "info": {"metadata": {"team": "web", "logging": [{"callback_name": "langfuse"}]}}}`},
+		"/key/update": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{Metadata: map[string]string{"team": "ml"}}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"key": "tok-1",
+		"metadata": map[string]interface{}{
+			"team":    "ml",
+			"logging": []interface{}{map[string]interface{}{"callback_name": "langfuse"}},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestUpdateMergesModelLimits(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info":   {Body: `{"key": "tok-1", "info": {"metadata": {"team": "web", "model_rpm_limit": {"gpt-4": 10, "gpt-4o": 5}}}}`},
+		"/key/update": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{Metadata: map[string]string{"team": "ml"}, ModelRPMLimit: map[string]int64{"gpt-4": 10}}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	// The observed limits must not be merged back into the metadata, or the
+	// removed model would keep its limit.
+	want := map[string]interface{}{
+		"key":             "tok-1",
+		"model_rpm_limit": map[string]interface{}{"gpt-4": float64(10)},
+		"metadata": map[string]interface{}{
+			"team":            "ml",
+			"model_rpm_limit": map[string]interface{}{"gpt-4": float64(10)},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestMetadataFieldName(t *testing.T) {
+	cases := map[string]struct {
+		reason   string
+		field    string
+		info     string
+		upToDate bool
+		want     map[string]interface{}
+	}{
+		"Default": {
+			reason:   "Metadata should be read from and sent under metadata by default.",
+			info:     `{"metadata": {"team": "ml"}, "key_metadata": {"team": "web"}}`,
+			upToDate: true,
+			want:     map[string]interface{}{"metadata": map[string]interface{}{"team": "ml"}},
+		},
+		"KeyMetadata": {
+			reason:   "Metadata should be read from and sent under the configured field.",
+			field:    apisv1alpha1.MetadataFieldKeyMetadata,
+			info:     `{"metadata": {"team": "ml"}, "key_metadata": {"team": "web"}}`,
+			upToDate: false,
+			want:     map[string]interface{}{"key_metadata": map[string]interface{}{"team": "ml"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":     {Body: `{"key": "tok-1", "info": ` + tc.info + `}`},
+				"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+			})
+			defer srv.Close()
+
+			p := v1alpha1.KeyParameters{Metadata: map[string]string{"team": "ml"}}
+			e := external{client: srv.Client(), metadataField: tc.field}
+			o, err := e.Observe(context.Background(), key("tok-1", p, v1alpha1.KeyObservation{}))
+			if err != nil {
+				t.Fatalf("e.Observe(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.upToDate, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if _, err := e.Create(context.Background(), key("", p, v1alpha1.KeyObservation{})); err != nil {
+				t.Fatalf("e.Create(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Body("/key/generate")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateNestedMetadata(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}})
+	defer srv.Close()
+
+	cr := key("", v1alpha1.KeyParameters{
+		Metadata:     map[string]string{"team": "ml"},
+		MetadataJSON: &runtime.RawExtension{Raw: []byte(`{"team": "web", "logging": [{"callback_name": "langfuse", "callback_vars": {"langfuse_host": "https://lf"}}]}`)},
+	}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"team": "ml",
+			"logging": []interface{}{map[string]interface{}{
+				"callback_name": "langfuse",
+				"callback_vars": map[string]interface{}{"langfuse_host": "https://lf"},
+			}},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/generate")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestCreateLogging(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`}})
+	defer srv.Close()
+
+	cr := key("", v1alpha1.KeyParameters{
+		Logging: &v1alpha1.LoggingConfig{SuccessCallbacks: []string{"s3", "langfuse"}, FailureCallbacks: []string{"langfuse"}},
+	}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"logging": []interface{}{
+				map[string]interface{}{"callback_name": "langfuse", "callback_type": "success_and_failure"},
+				map[string]interface{}{"callback_name": "s3", "callback_type": "success"},
+			},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/generate")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestResolveGuardrails(t *testing.T) {
+	type want struct {
+		guardrails []string
+		status     corev1.ConditionStatus
+		err        bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		ready  bool
+		want   want
+	}{
+		"NotReady": {
+			reason: "A reference to a Guardrail that isn't ready should not resolve.",
+			ready:  false,
+			want:   want{status: corev1.ConditionFalse, err: true},
+		},
+		"Ready": {
+			reason: "A reference to a ready Guardrail should resolve to its guardrail name.",
+			ready:  true,
+			want:   want{guardrails: []string{"pii"}, status: corev1.ConditionTrue},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					g := obj.(*guardrailv1alpha1.Guardrail)
+					g.SetName("pii")
+					g.Spec.ForProvider.GuardrailName = "pii"
+					if tc.ready {
+						g.SetConditions(xpv1.Available())
+					}
+					return nil
+				}),
+				MockPatch: test.NewMockPatchFn(nil),
+			}
+			cr := key("tok-1", v1alpha1.KeyParameters{GuardrailRefs: []xpv1.Reference{{Name: "pii"}}}, v1alpha1.KeyObservation{})
+
+			err := litellm.NewReferenceResolver(kube).ResolveReferences(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.guardrails, cr.Spec.ForProvider.Guardrails); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want guardrails, +got guardrails:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, cr.GetCondition(apisv1alpha1.TypeReferenceResolution).Status); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestResolveModels(t *testing.T) {
+	type want struct {
+		resolved []string
+		sent     []string
+		err      bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		models   []string
+		refs     []xpv1.Reference
+		resolved []string
+		ready    bool
+		want     want
+	}{
+		"NotReady": {
+			reason: "A reference to a Model that isn't ready should not resolve, so that the key isn't created.",
+			models: []string{"gpt-4o-mini"},
+			refs:   []xpv1.Reference{{Name: "gpt-4o"}},
+			ready:  false,
+			want:   want{err: true},
+		},
+		"Ready": {
+			reason: "A reference to a ready Model should add its model name to the models of the key.",
+			models: []string{"gpt-4o-mini"},
+			refs:   []xpv1.Reference{{Name: "gpt-4o"}},
+			ready:  true,
+			want:   want{resolved: []string{"gpt-4o"}, sent: []string{"gpt-4o-mini", "gpt-4o"}},
+		},
+		"AlreadyListed": {
+			reason: "A referenced model that is already listed should not be listed twice.",
+			models: []string{"gpt-4o"},
+			refs:   []xpv1.Reference{{Name: "gpt-4o"}},
+			ready:  true,
+			want:   want{resolved: []string{"gpt-4o"}, sent: []string{"gpt-4o"}},
+		},
+		"RefRemoved": {
+			reason:   "The model of a reference that was removed should no longer be granted to the key.",
+			models:   []string{"gpt-4o-mini"},
+			resolved: []string{"gpt-4o"},
+			ready:    true,
+			want:     want{sent: []string{"gpt-4o-mini"}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			kube := &test.MockClient{
+				MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+					m := obj.(*modelv1alpha1.Model)
+					m.SetName("gpt-4o")
+					m.Spec.ForProvider.ModelName = "gpt-4o"
+					if tc.ready {
+						m.SetConditions(xpv1.Available())
+					}
+					return nil
+				}),
+				MockPatch: test.NewMockPatchFn(nil),
+			}
+			cr := key("", v1alpha1.KeyParameters{Models: tc.models, ModelRefs: tc.refs, ResolvedModels: tc.resolved}, v1alpha1.KeyObservation{})
+
+			err := litellm.NewReferenceResolver(kube).ResolveReferences(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err != nil); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if err != nil {
+				return
+			}
+			if diff := cmp.Diff(tc.models, cr.Spec.ForProvider.Models); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): the models of the spec should be left as they are:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.resolved, cr.Spec.ForProvider.ResolvedModels); diff != "" {
+				t.Errorf("\n%s\nResolveReferences(...): -want resolved models, +got resolved models:\n%s\n", tc.reason, diff)
+			}
+
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": []}`},
+				"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+			})
+			defer srv.Close()
+			e := external{client: srv.Client()}
+			if _, err := e.Create(context.Background(), cr); err != nil {
+				t.Fatalf("e.Create(...): %v", err)
+			}
+			var sent []string
+			for _, m := range srv.Body("/key/generate")["models"].([]interface{}) {
+				sent = append(sent, m.(string))
+			}
+			if diff := cmp.Diff(tc.want.sent, sent); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want models, +got models:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.models, cr.Spec.ForProvider.Models); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): the models of the spec should be left as they are:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestGuardrailsUpToDate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"metadata": {"guardrails": ["pii"]}}}`}})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{Guardrails: []string{"pii", "toxicity"}}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if got.ResourceUpToDate {
+		t.Errorf("e.Observe(...): a key missing a desired guardrail should not be up to date")
+	}
+}
+
+func TestKeyNamePrefix(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.KeyParameters
+		want   string
+	}{
+		"NoPrefix": {
+			reason: "A Key without a prefix or alias should not set an alias.",
+			p:      v1alpha1.KeyParameters{},
+			want:   "",
+		},
+		"PrefixApplied": {
+			reason: "The alias of a Key without one should be its name with the prefix.",
+			p:      v1alpha1.KeyParameters{KeyNamePrefix: "team-a-"},
+			want:   "team-a-ci-key",
+		},
+		"AliasOverridesPrefix": {
+			reason: "An explicit alias should be used as is.",
+			p:      v1alpha1.KeyParameters{KeyNamePrefix: "team-a-", KeyAlias: "ci"},
+			want:   "ci",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": []}`},
+				"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+			})
+			defer srv.Close()
+
+			cr := named("ci-key", key("", tc.p, v1alpha1.KeyObservation{}))
+			e := external{client: srv.Client()}
+			if _, err := e.Create(context.Background(), cr); err != nil {
+				t.Fatalf("e.Create(...): %v", err)
+			}
+			got, _ := srv.Body("/key/generate")["key_alias"].(string)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want alias, +got alias:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, cr.Status.AtProvider.KeyAlias); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want observed alias, +got observed alias:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestKeyAliasUpToDate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"key_alias": "ci-key"}}`}})
+	defer srv.Close()
+
+	cr := named("ci-key", key("tok-1", v1alpha1.KeyParameters{KeyNamePrefix: "team-a-"}, v1alpha1.KeyObservation{}))
+	e := external{client: srv.Client()}
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if got.ResourceUpToDate {
+		t.Errorf("e.Observe(...): a key whose alias lacks the prefix should not be up to date")
+	}
+	if diff := cmp.Diff("ci-key", cr.Status.AtProvider.KeyAlias); diff != "" {
+		t.Errorf("e.Observe(...): -want observed alias, +got observed alias:\n%s", diff)
+	}
+}
+
+func TestUpdateKeyAlias(t *testing.T) {
+	type want struct {
+		body map[string]interface{}
+		err  error
+	}
+	rejected := `{"error": {"message": "Key with alias 'web' already exists.", "type": "bad_request_error", "param": "key_alias", "code": "400"}}`
+
+	cases := map[string]struct {
+		reason string
+		update fake.Response
+		want   want
+	}{
+		"Renamed": {
+			reason: "A changed alias should be sent to LiteLLM to rename the key.",
+			update: fake.Response{Body: `{}`},
+			want:   want{body: map[string]interface{}{"key": "tok-1", "key_alias": "web"}},
+		},
+		"RenameRejected": {
+			reason: "A rename LiteLLM refuses should be reported as such.",
+			update: fake.Response{Status: http.StatusBadRequest, Body: rejected},
+			want: want{
+				body: map[string]interface{}{"key": "tok-1", "key_alias": "web"},
+				err: errors.Wrapf(&litellm.APIError{
+					Method:     http.MethodPost,
+					Path:       "/key/update",
+					StatusCode: http.StatusBadRequest,
+					Type:       "bad_request_error",
+					Message:    "Key with alias 'web' already exists.",
+					Param:      "key_alias",
+					Body:       rejected,
+				}, errRenameKey, "ci", "web"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":   {Body: `{"key": "tok-1", "info": {"key_alias": "ci"}}`},
+				"/key/update": tc.update,
+			})
+			defer srv.Close()
+
+			cr := key("tok-1", v1alpha1.KeyParameters{KeyAlias: "web"}, v1alpha1.KeyObservation{})
+			e := external{client: srv.Client()}
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if o.ResourceUpToDate {
+				t.Errorf("\n%s\ne.Observe(...): a key with another alias should not be up to date", tc.reason)
+			}
+			_, err = e.Update(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.body, srv.Body("/key/update")); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObjectPermission(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info":   {Body: `{"key": "tok-1", "info": {"object_permission": {"vector_stores": ["docs"], "mcp_servers": []}}}`},
+		"/key/update": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{ObjectPermission: &v1alpha1.ObjectPermission{VectorStores: []string{"docs"}, MCPServers: []string{"github"}}}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if got.ResourceUpToDate {
+		t.Errorf("e.Observe(...): a key missing a desired MCP server should not be up to date")
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+	want := map[string]interface{}{
+		"vector_stores": []interface{}{"docs"},
+		"mcp_servers":   []interface{}{"github"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/key/update")["object_permission"]); diff != "" {
+		t.Errorf("e.Update(...): -want object_permission, +got object_permission:\n%s", diff)
+	}
+}
+
+func TestObjectPermissionRoundTrip(t *testing.T) {
+	op := &v1alpha1.ObjectPermission{VectorStores: []string{"docs"}, MCPServers: []string{"github", "jira"}}
+
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/list":     {Body: `{"keys": []}`},
+		"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1"}`},
+	})
+	cr := key("", v1alpha1.KeyParameters{ObjectPermission: op}, v1alpha1.KeyObservation{})
+	if _, err := (&external{client: srv.Client()}).Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+	sent, err := json.Marshal(srv.Body("/key/generate")["object_permission"])
+	srv.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// LiteLLM returns what it was sent, along with fields of its own.
+	srv = fake.NewServer(map[string]fake.Response{
+		"/key/info": {Body: fmt.Sprintf(`{"key": "tok-1", "info": {"object_permission": %s}}`, sent)},
+	})
+	defer srv.Close()
+	got, err := (&external{client: srv.Client()}).Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if !got.ResourceUpToDate {
+		t.Errorf("e.Observe(...): a key with the object_permission it was created with should be up to date, sent %s", sent)
+	}
+}
+
+func TestObjectPermissionDrift(t *testing.T) {
+	op := &v1alpha1.ObjectPermission{VectorStores: []string{"docs"}, MCPServers: []string{"github"}}
+
+	cases := map[string]struct {
+		reason   string
+		observed string
+		want     bool
+	}{
+		"Same": {
+			reason:   "The same permissions should be up to date.",
+			observed: `{"vector_stores": ["docs"], "mcp_servers": ["github"]}`,
+			want:     true,
+		},
+		"ServerAdded": {
+			reason:   "Permissions and fields LiteLLM added that the Key doesn't manage should be ignored.",
+			observed: `{"object_permission_id": "op-1", "vector_stores": ["docs"], "mcp_servers": ["github"], "mcp_access_groups": ["internal"]}`,
+			want:     true,
+		},
+		"Removed": {
+			reason:   "A vector store that was removed should be drift.",
+			observed: `{"vector_stores": [], "mcp_servers": ["github"]}`,
+		},
+		"Added": {
+			reason:   "An MCP server that was added to a managed list should be drift.",
+			observed: `{"vector_stores": ["docs"], "mcp_servers": ["github", "jira"]}`,
+		},
+		"Unset": {
+			reason:   "A key without any permissions should be drift.",
+			observed: `null`,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info": {Body: fmt.Sprintf(`{"key": "tok-1", "info": {"object_permission": %s}}`, tc.observed)},
+			})
+			defer srv.Close()
+
+			cr := key("tok-1", v1alpha1.KeyParameters{ObjectPermission: op}, v1alpha1.KeyObservation{})
+			got, err := (&external{client: srv.Client()}).Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("e.Observe(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, got.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestLoggingUpToDate(t *testing.T) {
+	desired := loggingCallbacks(&v1alpha1.LoggingConfig{SuccessCallbacks: []string{"langfuse"}})
+
+	cases := map[string]struct {
+		reason   string
+		observed string
+		want     bool
+	}{
+		"Matching": {
+			reason:   "Callbacks with extra settings added by LiteLLM should be up to date.",
+			observed: `[{"callback_name": "langfuse", "callback_type": "success", "callback_vars": {"langfuse_host": "https://lf"}}]`,
+			want:     true,
+		},
+		"TypeChanged": {
+			reason:   "A callback with a different type should not be up to date.",
+			observed: `[{"callback_name": "langfuse", "callback_type": "failure"}]`,
+			want:     false,
+		},
+		"Extra": {
+			reason:   "An undesired callback should not be up to date.",
+			observed: `[{"callback_name": "langfuse", "callback_type": "success"}, {"callback_name": "s3", "callback_type": "success"}]`,
+			want:     false,
+		},
+		"Missing": {
+			reason:   "Missing logging metadata should not be up to date.",
+			observed: `null`,
+			want:     false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var observed interface{}
+			if err := json.Unmarshal([]byte(tc.observed), &observed); err != nil {
+				t.Fatal(err)
+			}
+			got := loggingUpToDate(desired, observed)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nloggingUpToDate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRotation(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	period := &metav1.Duration{Duration: 30 * 24 * time.Hour}
+	info := fake.Response{Body: `{"key": "tok-1", "info": {"created_at": "2024-04-01T00:00:00"}}`}
+
+	type want struct {
+		upToDate bool
+		paths    []string
+		extName  string
+		u        managed.ExternalUpdate
+		obs      v1alpha1.KeyObservation
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Key
+		want   want
+	}{
+		"Disabled": {
+			reason: "A key without a rotation period should never be rotated.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{}),
+			want: want{
+				upToDate: true,
+				paths:    []string{"/key/info"},
+				extName:  "tok-1",
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-1"},
+			},
+		},
+		"RecentlyRotated": {
+			reason: "A key rotated within its rotation period should not be rotated again.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{RotationPeriod: period}, v1alpha1.KeyObservation{LastRotatedAt: &metav1.Time{Time: now.Add(-24 * time.Hour)}}),
+			want: want{
+				upToDate: true,
+				paths:    []string{"/key/info"},
+				extName:  "tok-1",
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-1", LastRotatedAt: &metav1.Time{Time: now.Add(-24 * time.Hour)}},
+			},
+		},
+		"AgedPastPeriod": {
+			reason: "A key older than its rotation period should be regenerated and the new key published.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{RotationPeriod: period}, v1alpha1.KeyObservation{}),
+			want: want{
+				upToDate: false,
+				paths:    []string{"/key/info", "/key/regenerate"},
+				extName:  "tok-2",
+				u:        managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{"key": []byte("sk-2")}},
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-2", LastRotatedAt: &metav1.Time{Time: now}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":       info,
+				"/key/update":     {Body: `{}`},
+				"/key/regenerate": {Body: `{"key": "sk-2", "token_id": "tok-2", "key_alias": "ci"}`},
+			})
+			defer srv.Close()
+
+			e := external{
+				kube:   &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				client: srv.Client(),
+				now:    func() time.Time { return now },
+			}
+			o, err := e.Observe(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			u, err := e.Update(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.extName, meta.GetExternalName(tc.cr)); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.u, u); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExpiry(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+	window := time.Hour
+
+	type want struct {
+		expiresIn    string
+		expiringSoon corev1.ConditionStatus
+		expired      corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason string
+		left   time.Duration
+		window time.Duration
+		want   want
+	}{
+		"OutsideWindow": {
+			reason: "A key that expires just after the warn window should not be expiring soon.",
+			left:   window + time.Second,
+			window: window,
+			want:   want{expiresIn: "60m", expiringSoon: corev1.ConditionFalse, expired: corev1.ConditionFalse},
+		},
+		"AtWindow": {
+			reason: "A key that expires exactly at the end of the warn window should be expiring soon.",
+			left:   window,
+			window: window,
+			want:   want{expiresIn: "60m", expiringSoon: corev1.ConditionTrue, expired: corev1.ConditionFalse},
+		},
+		"NoWindow": {
+			reason: "A key should never be expiring soon without a warn window.",
+			left:   time.Minute,
+			want:   want{expiresIn: "60s", expiringSoon: corev1.ConditionFalse, expired: corev1.ConditionFalse},
+		},
+		"AtExpiry": {
+			reason: "A key whose expiry is now should be expired.",
+			left:   0,
+			window: window,
+			want:   want{expiresIn: "0s", expiringSoon: corev1.ConditionFalse, expired: corev1.ConditionTrue},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			e := external{expiryWarnWindow: tc.window, now: func() time.Time { return now }}
+			cr := key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{Expires: metav1.Time{Time: now.Add(tc.left)}})
+			e.setExpiry(cr)
+			got := want{
+				expiresIn:    cr.Status.AtProvider.ExpiresIn,
+				expiringSoon: cr.GetCondition(apisv1alpha1.TypeExpiringSoon).Status,
+				expired:      cr.GetCondition(apisv1alpha1.TypeExpired).Status,
+			}
+			if diff := cmp.Diff(tc.want, got, cmp.AllowUnexported(want{})); diff != "" {
+				t.Errorf("\n%s\ne.setExpiry(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestRegenerateExpired(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason     string
+		regenerate bool
+		duration   string
+		want       []string
+	}{
+		"Regenerated": {
+			reason:     "An expired key should be regenerated if the ProviderConfig allows it.",
+			regenerate: true,
+			duration:   "30d",
+			want:       []string{"/key/info", "/key/regenerate"},
+		},
+		"NotAllowed": {
+			reason:   "An expired key should be left expired unless the ProviderConfig allows regenerating it.",
+			duration: "30d",
+			want:     []string{"/key/info"},
+		},
+		"NoDuration": {
+			reason:     "An expired key without a duration should be left expired.",
+			regenerate: true,
+			want:       []string{"/key/info"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":       {Body: `{"key": "tok-1", "info": {"expires": "2024-05-01T00:00:00"}}`},
+				"/key/update":     {Body: `{}`},
+				"/key/regenerate": {Body: `{"key": "sk-2", "token_id": "tok-2", "expires": "2024-07-01T00:00:00"}`},
+			})
+			defer srv.Close()
+
+			e := external{
+				kube:              &test.MockClient{MockUpdate: test.NewMockUpdateFn(nil)},
+				client:            srv.Client(),
+				regenerateExpired: tc.regenerate,
+				now:               func() time.Time { return now },
+			}
+			cr := key("tok-1", v1alpha1.KeyParameters{Duration: tc.duration}, v1alpha1.KeyObservation{Duration: tc.duration})
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(!tc.regenerate || tc.duration == "", o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+			if len(tc.want) < 3 {
+				return
+			}
+			if diff := cmp.Diff(tc.duration, srv.Body("/key/regenerate")["duration"]); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want duration, +got duration:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(corev1.ConditionFalse, cr.GetCondition(apisv1alpha1.TypeExpired).Status); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want expired, +got expired:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/key/delete": {Body: `{"deleted_keys": ["tok-1"]}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"keys": []interface{}{"tok-1"}}
+	if diff := cmp.Diff(want, srv.Body("/key/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+// recorder records the events it is asked to emit.
+type recorder struct {
+	events []event.Event
+}
+
+func (r *recorder) Event(_ runtime.Object, e event.Event) { r.events = append(r.events, e) }
+
+func (r *recorder) WithAnnotations(_ ...string) event.Recorder { return r }
+
+func TestSoftDelete(t *testing.T) {
+	now := time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		paths  []string
+		update map[string]interface{}
+		events int
+	}
+
+	cases := map[string]struct {
+		reason string
+		soft   bool
+		want   want
+	}{
+		"Hard": {
+			reason: "A key should be deleted by default.",
+			want:   want{paths: []string{"/key/info", "/key/delete"}},
+		},
+		"Soft": {
+			reason: "A soft deleted key should be blocked and tagged as deleted, keeping its metadata, rather than deleted.",
+			soft:   true,
+			want: want{
+				paths: []string{"/key/info", "/key/block", "/key/update"},
+				update: map[string]interface{}{
+					"key":      "tok-1",
+					"metadata": map[string]interface{}{"team": "ml", metadataDeletedAt: "2024-06-01T00:00:00Z"},
+				},
+				events: 1,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":   {Body: `{"key": "tok-1", "info": {"metadata": {"team": "ml"}}}`},
+				"/key/delete": {Body: `{"deleted_keys": ["tok-1"]}`},
+				"/key/block":  {Body: `{}`},
+				"/key/update": {Body: `{}`},
+			})
+			defer srv.Close()
+
+			rec := &recorder{}
+			cr := key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})
+			e := external{client: srv.Client(), recorder: rec, softDelete: tc.soft, now: func() time.Time { return now }}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("e.Observe(...): %v", err)
+			}
+			if err := e.Delete(context.Background(), cr); err != nil {
+				t.Fatalf("e.Delete(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.update, srv.Body("/key/update")); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.events, len(rec.events)); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want events, +got events:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveSoftDeleted(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info": {Body: `{"key": "tok-1", "info": {"blocked": true, "metadata": {"crossplane_deleted_at": "2024-06-01T00:00:00Z"}}}`},
+	})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{}, v1alpha1.KeyObservation{})
+	cr.SetDeletionTimestamp(&metav1.Time{Time: time.Now()})
+	e := external{client: srv.Client(), softDelete: true}
+	got, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if got.ResourceExists {
+		t.Errorf("e.Observe(...): a soft deleted key of a deleted Key should not exist")
+	}
+}
+
+func TestSpendAlertThreshold(t *testing.T) {
+	threshold := 10.0
+	raised := 20.0
+
+	type want struct {
+		paths  []string
+		status corev1.ConditionStatus
+	}
+
+	cases := map[string]struct {
+		reason     string
+		threshold  *float64
+		conditions []xpv1.Condition
+		// infos are the /key/info responses of consecutive reconciles.
+		infos []string
+		want  want
+	}{
+		"BelowThreshold": {
+			reason:    "A key whose spend is below its threshold should not be blocked.",
+			threshold: &threshold,
+			infos:     []string{`{"key": "tok-1", "info": {"spend": 5}}`},
+			want:      want{status: corev1.ConditionFalse},
+		},
+		"CrossesThreshold": {
+			reason:    "A key whose spend crosses its threshold should be blocked exactly once.",
+			threshold: &threshold,
+			infos: []string{
+				`{"key": "tok-1", "info": {"spend": 12, "blocked": null}}`,
+				`{"key": "tok-1", "info": {"spend": 13, "blocked": true}}`,
+				`{"key": "tok-1", "info": {"spend": 13, "blocked": true}}`,
+			},
+			want: want{paths: []string{"/key/block"}, status: corev1.ConditionTrue},
+		},
+		"ThresholdRaised": {
+			reason:     "A key blocked for its spend should be unblocked exactly once when its threshold is raised.",
+			threshold:  &raised,
+			conditions: []xpv1.Condition{apisv1alpha1.SpendExceeded("")},
+			infos: []string{
+				`{"key": "tok-1", "info": {"spend": 12, "blocked": true}}`,
+				`{"key": "tok-1", "info": {"spend": 12, "blocked": false}}`,
+			},
+			want: want{paths: []string{"/key/unblock"}, status: corev1.ConditionFalse},
+		},
+		"ThresholdCleared": {
+			reason:     "A key blocked for its spend should be unblocked when its threshold is cleared.",
+			conditions: []xpv1.Condition{apisv1alpha1.SpendExceeded("")},
+			infos:      []string{`{"key": "tok-1", "info": {"spend": 12, "blocked": true}}`},
+			want:       want{paths: []string{"/key/unblock"}, status: corev1.ConditionFalse},
+		},
+		"BlockedManually": {
+			reason:    "A key that was blocked for another reason should not be unblocked.",
+			threshold: &threshold,
+			infos:     []string{`{"key": "tok-1", "info": {"spend": 5, "blocked": true}}`},
+			want:      want{status: corev1.ConditionFalse},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := key("tok-1", v1alpha1.KeyParameters{SpendAlertThreshold: tc.threshold}, v1alpha1.KeyObservation{})
+			cr.SetConditions(tc.conditions...)
+
+			var paths []string
+			for _, info := range tc.infos {
+				srv := fake.NewServer(map[string]fake.Response{
+					"/key/info":    {Body: info},
+					"/key/update":  {Body: `{}`},
+					"/key/block":   {Body: `{}`},
+					"/key/unblock": {Body: `{}`},
+				})
+				e := external{client: srv.Client()}
+				o, err := e.Observe(context.Background(), cr)
+				if err != nil {
+					t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+				}
+				if !o.ResourceUpToDate {
+					if _, err := e.Update(context.Background(), cr); err != nil {
+						t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+					}
+				}
+				for _, p := range srv.Paths() {
+					if p != "/key/info" {
+						paths = append(paths, p)
+					}
+				}
+				srv.Close()
+			}
+
+			if diff := cmp.Diff(tc.want.paths, paths); diff != "" {
+				t.Errorf("\n%s\nreconcile: -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.status, cr.GetCondition(apisv1alpha1.TypeSpendExceeded).Status); diff != "" {
+				t.Errorf("\n%s\nreconcile: -want SpendExceeded status, +got SpendExceeded status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExtendDuration(t *testing.T) {
+	expires := time.Date(2024, 8, 30, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		upToDate bool
+		body     map[string]interface{}
+		obs      v1alpha1.KeyObservation
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Key
+		want   want
+	}{
+		"Unchanged": {
+			reason: "A key whose duration didn't change should be up to date and keep its expiry.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{Duration: "30d"}, v1alpha1.KeyObservation{Duration: "30d"}),
+			want: want{
+				upToDate: true,
+				body:     nil,
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-1", Duration: "30d"},
+			},
+		},
+		"Equivalent": {
+			reason: "A duration implying the same expiry should not push a new expiry.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{Duration: "720h"}, v1alpha1.KeyObservation{Duration: "30d"}),
+			want: want{
+				upToDate: true,
+				body:     nil,
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-1", Duration: "30d"},
+			},
+		},
+		"Extended": {
+			reason: "A longer duration should be sent and the new expiry recorded.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{Duration: "90d"}, v1alpha1.KeyObservation{Duration: "30d"}),
+			want: want{
+				upToDate: false,
+				body:     map[string]interface{}{"key": "tok-1", "duration": "90d"},
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-1", Duration: "90d", Expires: metav1.Time{Time: expires}},
+			},
+		},
+		"NotRecorded": {
+			reason: "A key whose duration was never recorded should be assumed to have been generated with the desired one.",
+			cr:     key("tok-1", v1alpha1.KeyParameters{Duration: "90d"}, v1alpha1.KeyObservation{}),
+			want: want{
+				upToDate: true,
+				body:     nil,
+				obs:      v1alpha1.KeyObservation{TokenID: "tok-1", Duration: "90d"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":   {Body: `{"key": "tok-1", "info": {}}`},
+				"/key/update": {Body: `{"key": "tok-1", "expires": "2024-08-30T00:00:00"}`},
+			})
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			o, err := e.Observe(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if _, err := e.Update(context.Background(), tc.cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.body, srv.Body("/key/update")); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	now := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+	expires := time.Date(2024, 8, 31, 0, 0, 0, 0, time.UTC)
+	p := v1alpha1.KeyParameters{ExpiresAtRFC3339: "2024-08-31T00:00:00Z"}
+
+	type want struct {
+		upToDate bool
+		body     map[string]interface{}
+		expires  metav1.Time
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.Key
+		want   want
+	}{
+		"Unchanged": {
+			reason: "A key that expires at its fixed expiry should be up to date and keep its expiry.",
+			cr:     key("tok-1", p, v1alpha1.KeyObservation{Expires: metav1.Time{Time: expires.Add(time.Second)}}),
+			want:   want{upToDate: true, expires: metav1.Time{Time: expires.Add(time.Second)}},
+		},
+		"Changed": {
+			reason: "A key that expires at another time should be sent the duration until its fixed expiry.",
+			cr:     key("tok-1", p, v1alpha1.KeyObservation{Expires: metav1.Time{Time: expires.Add(-24 * time.Hour)}}),
+			want: want{
+				body:    map[string]interface{}{"key": "tok-1", "duration": "2592000s"},
+				expires: metav1.Time{Time: expires},
+			},
+		},
+		"NeverExpires": {
+			reason: "A key that never expires should be sent the duration until its fixed expiry.",
+			cr:     key("tok-1", p, v1alpha1.KeyObservation{}),
+			want: want{
+				body:    map[string]interface{}{"key": "tok-1", "duration": "2592000s"},
+				expires: metav1.Time{Time: expires},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":   {Body: `{"key": "tok-1", "info": {}}`},
+				"/key/update": {Body: `{"key": "tok-1", "expires": "2024-08-31T00:00:00"}`},
+			})
+			defer srv.Close()
+
+			e := external{client: srv.Client(), now: func() time.Time { return now }}
+			o, err := e.Observe(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if _, err := e.Update(context.Background(), tc.cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.body, srv.Body("/key/update")); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.expires, tc.cr.Status.AtProvider.Expires); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want expiry, +got expiry:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreateExpiresAt(t *testing.T) {
+	now := time.Date(2024, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	type want struct {
+		duration interface{}
+		err      error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		expiresAt string
+		want      want
+	}{
+		"Future": {
+			reason:    "A key with a fixed expiry should be generated with the duration until then.",
+			expiresAt: "2024-08-01T01:00:00Z",
+			want:      want{duration: "3600s"},
+		},
+		"Passed": {
+			reason:    "A fixed expiry that has passed should be returned as an error.",
+			expiresAt: "2024-07-31T00:00:00Z",
+			want:      want{err: errors.Errorf(errExpiresAtPast, "2024-07-31T00:00:00Z")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/list":     {Body: `{"keys": []}`},
+				"/key/generate": {Body: `{"key": "sk-1", "token_id": "tok-1", "expires": "2024-08-01T01:00:00"}`},
+			})
+			defer srv.Close()
+
+			cr := key("", v1alpha1.KeyParameters{ExpiresAtRFC3339: tc.expiresAt}, v1alpha1.KeyObservation{})
+			e := external{client: srv.Client(), now: func() time.Time { return now }}
+			_, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.duration, srv.Body("/key/generate")["duration"]); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want duration, +got duration:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBudgetResetAt(t *testing.T) {
+	_, errParse := time.Parse(time.RFC3339, "2024-09-01")
+
+	type want struct {
+		payload  map[string]interface{}
+		err      error
+		upToDate bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		p        v1alpha1.KeyParameters
+		observed string
+		want     want
+	}{
+		"Unset": {
+			reason:   "A key without a fixed reset time should not send or compare one.",
+			p:        v1alpha1.KeyParameters{},
+			observed: "2024-09-01T00:00:00",
+			want:     want{payload: map[string]interface{}{}, upToDate: true},
+		},
+		"Set": {
+			reason:   "A fixed reset time should be sent in UTC and match the same instant.",
+			p:        v1alpha1.KeyParameters{BudgetResetAtRFC3339: "2024-09-01T02:00:00+02:00"},
+			observed: "2024-09-01T00:00:00",
+			want:     want{payload: map[string]interface{}{"budget_reset_at": "2024-09-01T00:00:00Z"}, upToDate: true},
+		},
+		"Changed": {
+			reason:   "A different fixed reset time should not be up to date.",
+			p:        v1alpha1.KeyParameters{BudgetResetAtRFC3339: "2024-10-01T00:00:00Z"},
+			observed: "2024-09-01T00:00:00",
+			want:     want{payload: map[string]interface{}{"budget_reset_at": "2024-10-01T00:00:00Z"}, upToDate: false},
+		},
+		"Invalid": {
+			reason:   "A reset time that isn't RFC 3339 should be rejected.",
+			p:        v1alpha1.KeyParameters{BudgetResetAtRFC3339: "2024-09-01"},
+			observed: "2024-09-01T00:00:00",
+			want: want{
+				err: errors.Wrap(errParse, errBudgetResetAt),
+			},
+		},
+		"Conflict": {
+			reason: "A fixed reset time can't be combined with a budget duration.",
+			p:      v1alpha1.KeyParameters{BudgetResetAtRFC3339: "2024-09-01T00:00:00Z", BudgetDuration: "30d"},
+			want:   want{err: errors.New(errBudgetConflict)},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			payload, err := generatePayload(tc.p)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ngeneratePayload(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.payload, payload); diff != "" {
+				t.Errorf("\n%s\ngeneratePayload(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if tc.want.err != nil {
+				return
+			}
+			got := budgetResetAtUpToDate(tc.p.BudgetResetAtRFC3339, tc.observed)
+			if diff := cmp.Diff(tc.want.upToDate, got); diff != "" {
+				t.Errorf("\n%s\nbudgetResetAtUpToDate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestBudgetEnforcement(t *testing.T) {
+	type want struct {
+		payload  map[string]interface{}
+		upToDate bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		p        v1alpha1.KeyParameters
+		observed string
+		want     want
+	}{
+		"Default": {
+			reason:   "A budget should be enforced unless the enforcement mode is set.",
+			p:        v1alpha1.KeyParameters{MaxBudget: 10},
+			observed: `{"max_budget": 10}`,
+			want:     want{payload: map[string]interface{}{"max_budget": float64(10)}, upToDate: true},
+		},
+		"Enforce": {
+			reason:   "An enforced budget should be sent as the max budget, which blocks the key.",
+			p:        v1alpha1.KeyParameters{MaxBudget: 10, BudgetEnforcement: v1alpha1.BudgetEnforcementEnforce},
+			observed: `{"soft_budget": 10}`,
+			want:     want{payload: map[string]interface{}{"max_budget": float64(10)}, upToDate: false},
+		},
+		"Advisory": {
+			reason:   "An advisory budget should only be sent as the soft budget.",
+			p:        v1alpha1.KeyParameters{MaxBudget: 10, BudgetEnforcement: v1alpha1.BudgetEnforcementAdvisory},
+			observed: `{"soft_budget": 10}`,
+			want:     want{payload: map[string]interface{}{"soft_budget": float64(10)}, upToDate: true},
+		},
+		"AdvisoryBudgetTable": {
+			reason:   "The soft budget should be read from the budget linked to the key.",
+			p:        v1alpha1.KeyParameters{MaxBudget: 10, BudgetEnforcement: v1alpha1.BudgetEnforcementAdvisory},
+			observed: `{"litellm_budget_table": {"soft_budget": 10}}`,
+			want:     want{payload: map[string]interface{}{"soft_budget": float64(10)}, upToDate: true},
+		},
+		"AdvisoryStillEnforced": {
+			reason:   "An advisory budget should not be up to date while the key still has a max budget.",
+			p:        v1alpha1.KeyParameters{MaxBudget: 10, BudgetEnforcement: v1alpha1.BudgetEnforcementAdvisory},
+			observed: `{"max_budget": 10, "soft_budget": 10}`,
+			want:     want{payload: map[string]interface{}{"soft_budget": float64(10)}, upToDate: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			payload, err := generatePayload(tc.p)
+			if err != nil {
+				t.Fatalf("generatePayload(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.payload, payload); diff != "" {
+				t.Errorf("\n%s\ngeneratePayload(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			info := &keyInfo{}
+			if err := json.Unmarshal([]byte(tc.observed), info); err != nil {
+				t.Fatalf("json.Unmarshal(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, budgetUpToDate(tc.p, info)); diff != "" {
+				t.Errorf("\n%s\nbudgetUpToDate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateAdvisoryBudget(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info":   {Body: `{"key": "tok-1", "info": {"max_budget": 10}}`},
+		"/key/update": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	cr := key("tok-1", v1alpha1.KeyParameters{MaxBudget: 10, BudgetEnforcement: v1alpha1.BudgetEnforcementAdvisory}, v1alpha1.KeyObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	// The max budget the key was enforced with should be cleared.
+	want := map[string]interface{}{"key": "tok-1", "soft_budget": float64(10), "max_budget": nil}
+	if diff := cmp.Diff(want, srv.Body("/key/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestLimits(t *testing.T) {
+	team := `{"team_id": "ml", "team_info": {"team_id": "ml", "tpm_limit": 1000, "rpm_limit": 10}}`
+	limit := func(v int64) *int64 { return &v }
+
+	type want struct {
+		tpm, rpm *int64
+		payload  map[string]interface{}
+		upToDate bool
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.KeyParameters
+		info   string
+		want   want
+	}{
+		"Inherited": {
+			reason: "A key without limits should report the limits of its team.",
+			p:      v1alpha1.KeyParameters{TeamID: "ml"},
+			info:   `{"team_id": "ml"}`,
+			want:   want{tpm: limit(1000), rpm: limit(10), payload: map[string]interface{}{"team_id": "ml"}, upToDate: true},
+		},
+		"Overridden": {
+			reason: "A limit set on the key should take precedence over that of its team.",
+			p:      v1alpha1.KeyParameters{TeamID: "ml", TPMLimit: limit(500)},
+			info:   `{"team_id": "ml", "tpm_limit": 500}`,
+			want:   want{tpm: limit(500), rpm: limit(10), payload: map[string]interface{}{"team_id": "ml", "tpm_limit": int64(500)}, upToDate: true},
+		},
+		"Changed": {
+			reason: "A key whose limit differs from the desired one should not be up to date.",
+			p:      v1alpha1.KeyParameters{TeamID: "ml", RPMLimit: limit(5)},
+			info:   `{"team_id": "ml", "rpm_limit": 20}`,
+			want:   want{tpm: limit(1000), rpm: limit(20), payload: map[string]interface{}{"team_id": "ml", "rpm_limit": int64(5)}, upToDate: false},
+		},
+		"NoTeam": {
+			reason: "A key without a team or limits should report no limits.",
+			p:      v1alpha1.KeyParameters{},
+			info:   `{}`,
+			want:   want{payload: map[string]interface{}{}, upToDate: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/key/info":  {Body: `{"key": "tok-1", "info": ` + tc.info + `}`},
+				"/team/info": {Body: team},
+			})
+			defer srv.Close()
+
+			payload, err := generatePayload(tc.p)
+			if err != nil {
+				t.Fatalf("generatePayload(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.payload, payload); diff != "" {
+				t.Errorf("\n%s\ngeneratePayload(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+
+			cr := key("tok-1", tc.p, v1alpha1.KeyObservation{})
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("e.Observe(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, got.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.tpm, cr.Status.AtProvider.TPMLimit); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want TPM limit, +got TPM limit:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.rpm, cr.Status.AtProvider.RPMLimit); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want RPM limit, +got RPM limit:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveCache(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/info":   {Body: `{"key": "tok-1", "info": {"key_alias": "ci"}}`},
+		"/key/update": {Body: `{}`},
+	})
+	defer srv.Close()
+
+	pc := &apisv1alpha1.ProviderConfig{
+		ObjectMeta: metav1.ObjectMeta{UID: "observe-cache"},
+		Spec:       apisv1alpha1.ProviderConfigSpec{ObserveCacheTTL: &metav1.Duration{Duration: time.Minute}},
+	}
+	e := external{client: litellm.NewClient(&litellm.Config{APIBase: srv.URL, APIKey: "sk-test", ProviderConfig: pc})}
+	infos := func() int {
+		n := 0
+		for _, p := range srv.Paths() {
+			if p == "/key/info" {
+				n++
+			}
+		}
+		return n
+	}
+
+	cr := key("tok-1", v1alpha1.KeyParameters{KeyAlias: "web"}, v1alpha1.KeyObservation{})
+	for i := 0; i < 2; i++ {
+		if _, err := e.Observe(context.Background(), cr); err != nil {
+			t.Fatalf("e.Observe(...): %v", err)
+		}
+	}
+	if diff := cmp.Diff(1, infos()); diff != "" {
+		t.Errorf("e.Observe(...): a key observed again within the TTL should be reused: -want requests, +got requests:\n%s", diff)
+	}
+
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if diff := cmp.Diff(2, infos()); diff != "" {
+		t.Errorf("e.Observe(...): a key observed after it was updated should be fetched again: -want requests, +got requests:\n%s", diff)
+	}
+}
+
+func TestFieldSelection(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		enabled bool
+		want    []string
+	}{
+		"Enabled": {
+			reason:  "With field selection the compared fields of /key/info should be requested.",
+			enabled: true,
+			want:    []string{"key_alias", "models", "metadata", "spend", "blocked", "budget_reset_at"},
+		},
+		"Disabled": {
+			reason: "Without field selection no fields should be requested.",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/key/info": {Body: `{"key": "tok-1", "info": {"key_alias": "ci"}}`}})
+			defer srv.Close()
+
+			e := external{client: litellm.NewClient(&litellm.Config{APIBase: srv.URL, APIKey: "sk-test", FieldSelection: tc.enabled})}
+			o, err := e.Observe(context.Background(), key("tok-1", v1alpha1.KeyParameters{KeyAlias: "ci"}, v1alpha1.KeyObservation{}))
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if !o.ResourceUpToDate {
+				t.Errorf("\n%s\ne.Observe(...): a key matching its spec should be up to date", tc.reason)
+			}
+
+			q := srv.Requests()[0].Query
+			if diff := cmp.Diff("tok-1", q.Get("key")); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want key, +got key:\n%s\n", tc.reason, diff)
+			}
+			var got []string
+			if f := q.Get("fields"); f != "" {
+				got = strings.Split(f, ",")
+			}
+			for _, f := range tc.want {
+				if !slices.Contains(got, f) {
+					t.Errorf("\n%s\ne.Observe(...): field %q not requested in %v", tc.reason, f, got)
+				}
+			}
+			if tc.want == nil && got != nil {
+				t.Errorf("\n%s\ne.Observe(...): want no fields, got %v", tc.reason, got)
+			}
 		})
 	}
 }
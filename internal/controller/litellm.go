@@ -20,20 +20,64 @@ import (
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	ctrl "sigs.k8s.io/controller-runtime"
 
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/controller/alertingconfig"
+	"github.com/crossplane/provider-litellm/internal/controller/budget"
+	"github.com/crossplane/provider-litellm/internal/controller/callbackconfig"
 	"github.com/crossplane/provider-litellm/internal/controller/config"
+	"github.com/crossplane/provider-litellm/internal/controller/credential"
+	"github.com/crossplane/provider-litellm/internal/controller/customer"
+	"github.com/crossplane/provider-litellm/internal/controller/guardrail"
 	"github.com/crossplane/provider-litellm/internal/controller/key"
+	"github.com/crossplane/provider-litellm/internal/controller/mcpserver"
+	"github.com/crossplane/provider-litellm/internal/controller/model"
+	"github.com/crossplane/provider-litellm/internal/controller/modelalias"
+	"github.com/crossplane/provider-litellm/internal/controller/organization"
+	"github.com/crossplane/provider-litellm/internal/controller/organizationmember"
+	"github.com/crossplane/provider-litellm/internal/controller/rawrequest"
+	"github.com/crossplane/provider-litellm/internal/controller/routerconfig"
+	"github.com/crossplane/provider-litellm/internal/controller/spendreport"
+	"github.com/crossplane/provider-litellm/internal/controller/tag"
+	"github.com/crossplane/provider-litellm/internal/controller/team"
+	"github.com/crossplane/provider-litellm/internal/controller/user"
 )
 
 // Setup creates all Litellm controllers with the supplied logger and adds them to
-// the supplied manager.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+// the supplied manager. The managed resource controllers select the
+// ProviderConfig of resources that don't reference one with the supplied
+// selector.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
 	for _, setup := range []func(ctrl.Manager, controller.Options) error{
 		config.Setup,
-		key.Setup,
+		config.SetupHealth,
 	} {
 		if err := setup(mgr, o); err != nil {
 			return err
 		}
 	}
+	for _, setup := range []func(ctrl.Manager, controller.Options, *litellm.ProviderConfigSelector) error{
+		alertingconfig.Setup,
+		budget.Setup,
+		callbackconfig.Setup,
+		credential.Setup,
+		customer.Setup,
+		guardrail.Setup,
+		key.Setup,
+		mcpserver.Setup,
+		model.Setup,
+		modelalias.Setup,
+		organization.Setup,
+		organizationmember.Setup,
+		rawrequest.Setup,
+		routerconfig.Setup,
+		spendreport.Setup,
+		tag.Setup,
+		team.Setup,
+		user.Setup,
+	} {
+		if err := setup(mgr, o, selector); err != nil {
+			return err
+		}
+	}
 	return nil
 }
@@ -0,0 +1,350 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/mcpserver/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotMCPServer = "managed resource is not an MCPServer custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetServer    = "cannot get MCP server"
+	errCreateServer = "cannot create MCP server"
+	errUpdateServer = "cannot update MCP server"
+	errDeleteServer = "cannot delete MCP server"
+	errGetSecret    = "cannot get secret of auth value"
+)
+
+// serverPath is the path of the MCP server endpoints of LiteLLM.
+const serverPath = "/v1/mcp/server"
+
+// Setup adds a controller that reconciles MCPServer managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.MCPServerGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.MCPServerGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.MCPServerKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.MCPServer{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.MCPServerGroupVersionKind)))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(serversForSecret(mgr.GetClient()))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.MCPServerGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// serversForSecret returns a function that maps a Secret to the MCPServers
+// whose auth value it holds, so that rotated tokens are sent to the proxy.
+func serversForSecret(kube client.Client) handler.MapFunc {
+	return func(ctx context.Context, s client.Object) []reconcile.Request {
+		l := &v1alpha1.MCPServerList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, m := range l.Items {
+			ref := m.Spec.ForProvider.AuthValueSecretRef
+			if ref != nil && ref.Name == s.GetName() && ref.Namespace == s.GetNamespace() {
+				reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: m.GetName()}})
+			}
+		}
+		return reqs
+	}
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied MCPServer.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.MCPServer); !ok {
+		return nil, errors.New(errNotMCPServer)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// MCP server to ensure it reflects the managed resource's desired state.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// serverInfo is an MCP server returned by /v1/mcp/server.
+type serverInfo struct {
+	ServerID        string   `json:"server_id"`
+	ServerName      string   `json:"server_name"`
+	Alias           string   `json:"alias"`
+	Description     string   `json:"description"`
+	URL             string   `json:"url"`
+	Transport       string   `json:"transport"`
+	AuthType        string   `json:"auth_type"`
+	MCPAccessGroups []string `json:"mcp_access_groups"`
+	CreatedAt       string   `json:"created_at"`
+	UpdatedAt       string   `json:"updated_at"`
+}
+
+// path returns the /v1/mcp/server path of the supplied server id.
+func path(id string) string {
+	return serverPath + "/" + url.PathEscape(id)
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.MCPServer)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotMCPServer)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	info := &serverInfo{}
+	err := c.client.Get(ctx, path(id), nil, info)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetServer)
+	}
+
+	// LiteLLM doesn't return the auth value, so whether it is up to date is
+	// tracked by the version of its secret instead.
+	_, version, err := c.authValue(ctx, cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	o := &cr.Status.AtProvider
+	o.ServerID = info.ServerID
+	o.URL = info.URL
+	o.TransportType = info.Transport
+	o.CreatedAt = parseTime(info.CreatedAt)
+	o.UpdatedAt = parseTime(info.UpdatedAt)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: version == o.SecretVersion && isUpToDate(cr.Spec.ForProvider, info),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.MCPServer)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotMCPServer)
+	}
+
+	payload, version, err := c.generatePayload(ctx, meta.GetExternalName(cr), cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	rsp := &serverInfo{}
+	if err := c.client.Post(ctx, serverPath, payload, rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateServer)
+	}
+	if rsp.ServerID != "" {
+		meta.SetExternalName(cr, rsp.ServerID)
+	}
+	cr.Status.AtProvider.SecretVersion = version
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.MCPServer)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotMCPServer)
+	}
+
+	payload, version, err := c.generatePayload(ctx, meta.GetExternalName(cr), cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := c.client.Do(ctx, http.MethodPut, serverPath, nil, payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateServer)
+	}
+	cr.Status.AtProvider.SecretVersion = version
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.MCPServer)
+	if !ok {
+		return errors.New(errNotMCPServer)
+	}
+
+	err := c.client.Do(ctx, http.MethodDelete, path(meta.GetExternalName(cr)), nil, nil, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteServer)
+}
+
+// authValue returns the auth value read from the secret referenced by the
+// supplied parameters, and the resource version of the secret. Both are empty
+// if no secret is referenced.
+func (c *external) authValue(ctx context.Context, p v1alpha1.MCPServerParameters) (string, string, error) {
+	ref := p.AuthValueSecretRef
+	if ref == nil {
+		return "", "", nil
+	}
+	s := &corev1.Secret{}
+	if err := c.kube.Get(ctx, types.NamespacedName{Namespace: ref.Namespace, Name: ref.Name}, s); err != nil {
+		return "", "", errors.Wrap(err, errGetSecret)
+	}
+	return strings.TrimSpace(string(s.Data[ref.Key])), s.GetResourceVersion(), nil
+}
+
+// generatePayload returns the POST and PUT /v1/mcp/server payload for the
+// supplied server id and parameters, and the resource version of the secret
+// the auth value was read from.
+func (c *external) generatePayload(ctx context.Context, id string, p v1alpha1.MCPServerParameters) (map[string]interface{}, string, error) {
+	value, version, err := c.authValue(ctx, p)
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload := map[string]interface{}{
+		"server_name": p.ServerName,
+		"url":         p.URL,
+		"transport":   transport(p),
+	}
+	if id != "" {
+		payload["server_id"] = id
+	}
+	if p.Alias != "" {
+		payload["alias"] = p.Alias
+	}
+	if p.Description != "" {
+		payload["description"] = p.Description
+	}
+	if p.AuthType != "" {
+		payload["auth_type"] = p.AuthType
+	}
+	if p.MCPAccessGroups != nil {
+		payload["mcp_access_groups"] = p.MCPAccessGroups
+	}
+	if p.AuthValueSecretRef != nil {
+		payload["credentials"] = map[string]interface{}{"auth_value": value}
+	}
+	return payload, version, nil
+}
+
+// transport returns the transport of the supplied parameters, which defaults
+// to server sent events.
+func transport(p v1alpha1.MCPServerParameters) string {
+	if p.TransportType == "" {
+		return v1alpha1.TransportSSE
+	}
+	return p.TransportType
+}
+
+// parseTime returns the supplied LiteLLM timestamp, or nil if it is empty or
+// can't be parsed.
+func parseTime(s string) *metav1.Time {
+	t, err := litellm.ParseTime(s)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+// isUpToDate returns true if the observed server matches the supplied
+// parameters. Optional fields that aren't set aren't compared.
+func isUpToDate(p v1alpha1.MCPServerParameters, o *serverInfo) bool {
+	switch {
+	case p.ServerName != o.ServerName, p.URL != o.URL, transport(p) != o.Transport:
+		return false
+	case p.Alias != "" && p.Alias != o.Alias:
+		return false
+	case p.Description != "" && p.Description != o.Description:
+		return false
+	case p.AuthType != "" && p.AuthType != o.AuthType:
+		return false
+	case p.MCPAccessGroups != nil && !litellm.SameStrings(p.MCPAccessGroups, o.MCPAccessGroups):
+		return false
+	}
+	return true
+}
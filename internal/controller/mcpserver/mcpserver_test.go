@@ -0,0 +1,285 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package mcpserver
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/mcpserver/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func server(id string, p v1alpha1.MCPServerParameters) *v1alpha1.MCPServer {
+	cr := &v1alpha1.MCPServer{Spec: v1alpha1.MCPServerSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+var authValue = &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "github-mcp", Namespace: "crossplane-system"}, Key: "token"}
+
+func secretClient(version string) *test.MockClient {
+	return &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			s := obj.(*corev1.Secret)
+			s.SetResourceVersion(version)
+			s.Data = map[string][]byte{"token": []byte("ghp-1\n")}
+			return nil
+		}),
+	}
+}
+
+func TestObserve(t *testing.T) {
+	info := fake.Response{Body: `{
+		"server_id": "mcp-1",
+		"server_name": "github",
+		"alias": "gh",
+		"url": "https://mcp.github.com/sse",
+		"transport": "sse",
+		"auth_type": "bearer_token",
+		"mcp_access_groups": ["dev", "ops"],
+		"created_at": "2024-06-01T00:00:00"
+	}`}
+	desired := func() v1alpha1.MCPServerParameters {
+		return v1alpha1.MCPServerParameters{
+			ServerName:         "github",
+			Alias:              "gh",
+			URL:                "https://mcp.github.com/sse",
+			AuthType:           "bearer_token",
+			AuthValueSecretRef: authValue,
+			MCPAccessGroups:    []string{"ops", "dev"},
+		}
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		p         func(p *v1alpha1.MCPServerParameters)
+		version   string
+		want      want
+	}{
+		"NotFound": {
+			reason:    "An MCP server LiteLLM doesn't know should be reported as absent.",
+			responses: map[string]fake.Response{},
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "An MCP server matching the spec should be up to date, even though its auth value isn't returned.",
+			responses: map[string]fake.Response{"GET /v1/mcp/server/mcp-1": info},
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"SecretRotated": {
+			reason:    "An MCP server whose auth value secret changed since it was sent should not be up to date.",
+			responses: map[string]fake.Response{"GET /v1/mcp/server/mcp-1": info},
+			version:   "2",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"URLDrifted": {
+			reason:    "An MCP server at a different URL should not be up to date.",
+			responses: map[string]fake.Response{"GET /v1/mcp/server/mcp-1": info},
+			p:         func(p *v1alpha1.MCPServerParameters) { p.URL = "https://mcp.github.com/mcp" },
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"TransportDrifted": {
+			reason:    "An MCP server using a different transport should not be up to date.",
+			responses: map[string]fake.Response{"GET /v1/mcp/server/mcp-1": info},
+			p:         func(p *v1alpha1.MCPServerParameters) { p.TransportType = v1alpha1.TransportHTTP },
+			version:   "1",
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"UnsetFieldsIgnored": {
+			reason:    "Optional fields that aren't set in the spec should not be compared.",
+			responses: map[string]fake.Response{"GET /v1/mcp/server/mcp-1": info},
+			p: func(p *v1alpha1.MCPServerParameters) {
+				p.Alias = ""
+				p.AuthType = ""
+				p.MCPAccessGroups = nil
+			},
+			version: "1",
+			want:    want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"Error": {
+			reason:    "Errors getting the MCP server should be returned.",
+			responses: map[string]fake.Response{"GET /v1/mcp/server/mcp-1": {Status: http.StatusInternalServerError, Body: "boom"}},
+			version:   "1",
+			want: want{
+				err: errors.Wrap(errors.New("GET /v1/mcp/server/mcp-1 returned unexpected status 500: boom"), errGetServer),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			p := desired()
+			if tc.p != nil {
+				tc.p(&p)
+			}
+			cr := server("mcp-1", p)
+			cr.Status.AtProvider.SecretVersion = "1"
+			e := external{kube: secretClient(tc.version), client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestServersForSecret(t *testing.T) {
+	named := func(name string, ref *xpv1.SecretKeySelector) v1alpha1.MCPServer {
+		s := server("", v1alpha1.MCPServerParameters{AuthValueSecretRef: ref})
+		s.SetName(name)
+		return *s
+	}
+	kube := &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			obj.(*v1alpha1.MCPServerList).Items = []v1alpha1.MCPServer{
+				named("github", authValue),
+				named("jira", &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "jira-mcp", Namespace: "crossplane-system"}, Key: "token"}),
+				named("public", nil),
+			}
+			return nil
+		},
+	}
+
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "github-mcp", Namespace: "crossplane-system"}}
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "github"}}}
+	if diff := cmp.Diff(want, serversForSecret(kube)(context.Background(), s)); diff != "" {
+		t.Errorf("serversForSecret(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"POST /v1/mcp/server": {Body: `{"server_id": "mcp-1", "server_name": "github"}`}})
+	defer srv.Close()
+
+	cr := server("", v1alpha1.MCPServerParameters{
+		ServerName:         "github",
+		Description:        "GitHub tools",
+		URL:                "https://mcp.github.com/mcp",
+		TransportType:      v1alpha1.TransportHTTP,
+		AuthType:           "bearer_token",
+		AuthValueSecretRef: authValue,
+		MCPAccessGroups:    []string{"dev"},
+	})
+	e := external{kube: secretClient("1"), client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"server_name":       "github",
+		"description":       "GitHub tools",
+		"url":               "https://mcp.github.com/mcp",
+		"transport":         "http",
+		"auth_type":         "bearer_token",
+		"mcp_access_groups": []interface{}{"dev"},
+		"credentials":       map[string]interface{}{"auth_value": "ghp-1"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/v1/mcp/server")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("mcp-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+	if diff := cmp.Diff("1", cr.Status.AtProvider.SecretVersion); diff != "" {
+		t.Errorf("e.Create(...): -want secret version, +got secret version:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"PUT /v1/mcp/server": {Body: `{"server_id": "mcp-1"}`}})
+	defer srv.Close()
+
+	cr := server("mcp-1", v1alpha1.MCPServerParameters{
+		ServerName: "github",
+		URL:        "https://mcp.github.com/sse",
+	})
+	e := external{kube: secretClient("2"), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"server_id":   "mcp-1",
+		"server_name": "github",
+		"url":         "https://mcp.github.com/sse",
+		"transport":   "sse",
+	}
+	if diff := cmp.Diff(want, srv.Body("/v1/mcp/server")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+	}{
+		"Deleted": {
+			reason:    "An MCP server should be deleted by its id.",
+			responses: map[string]fake.Response{"DELETE /v1/mcp/server/mcp-1": {Body: `{}`}},
+		},
+		"NotFound": {
+			reason:    "An MCP server that is already gone should be treated as deleted.",
+			responses: map[string]fake.Response{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			if err := e.Delete(context.Background(), server("mcp-1", v1alpha1.MCPServerParameters{})); err != nil {
+				t.Errorf("\n%s\ne.Delete(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff([]string{"/v1/mcp/server/mcp-1"}, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,533 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/url"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/model/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotModel     = "managed resource is not a Model custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetModel    = "cannot get model"
+	errCreateModel = "cannot create model"
+	errUpdateModel = "cannot update model"
+	errDeleteModel = "cannot delete model"
+	errExtraParams = "extraParams must be a JSON object"
+	errHealthCheck = "cannot check health of model"
+	errUnhealthy   = "model is unhealthy"
+)
+
+// Setup adds a controller that reconciles Model managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.ModelGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ModelGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.ModelKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithReferenceResolver(litellm.NewReferenceResolver(mgr.GetClient())),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Model{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.ModelGroupVersionKind)))).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(modelsForSecret(mgr.GetClient()))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.ModelGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// modelsForSecret returns a function that maps a Secret to the Models whose
+// credentials it holds, so that rotated credentials are sent to the proxy.
+func modelsForSecret(kube client.Client) handler.MapFunc {
+	return func(ctx context.Context, s client.Object) []reconcile.Request {
+		l := &v1alpha1.ModelList{}
+		if err := kube.List(ctx, l); err != nil {
+			return nil
+		}
+		var reqs []reconcile.Request
+		for _, m := range l.Items {
+			for _, ref := range secretRefs(m.Spec.ForProvider.LiteLLMParams) {
+				if ref.Name == s.GetName() && ref.Namespace == s.GetNamespace() {
+					reqs = append(reqs, reconcile.Request{NamespacedName: types.NamespacedName{Name: m.GetName()}})
+					break
+				}
+			}
+		}
+		return reqs
+	}
+}
+
+// secretRefs returns the secrets referenced by the supplied params, keyed by
+// the litellm_params they are sent as.
+func secretRefs(p v1alpha1.LiteLLMParams) map[string]xpv1.SecretKeySelector {
+	refs := make(map[string]xpv1.SecretKeySelector, len(p.ExtraSecretParams)+4)
+	for k, ref := range p.ExtraSecretParams {
+		refs[k] = ref
+	}
+	for k, ref := range map[string]*xpv1.SecretKeySelector{
+		"api_key":               p.APIKeySecretRef,
+		"vertex_credentials":    p.VertexCredentialsSecretRef,
+		"aws_access_key_id":     p.AWSAccessKeyIDSecretRef,
+		"aws_secret_access_key": p.AWSSecretAccessKeySecretRef,
+	} {
+		if ref != nil {
+			refs[k] = *ref
+		}
+	}
+	return refs
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Model.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Model); !ok {
+		return nil, errors.New(errNotModel)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// model deployment to ensure it reflects the managed resource's desired
+// state.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// modelInfo is an entry of the data returned by /model/info.
+type modelInfo struct {
+	ModelName     string                 `json:"model_name"`
+	LiteLLMParams map[string]interface{} `json:"litellm_params"`
+	ModelInfo     struct {
+		ID                 string   `json:"id"`
+		DBModel            bool     `json:"db_model"`
+		Mode               string   `json:"mode"`
+		BaseModel          string   `json:"base_model"`
+		InputCostPerToken  *float64 `json:"input_cost_per_token"`
+		OutputCostPerToken *float64 `json:"output_cost_per_token"`
+		AccessGroups       []string `json:"access_groups"`
+	} `json:"model_info"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Model)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotModel)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var rsp struct {
+		Data []modelInfo `json:"data"`
+	}
+	err := c.client.Get(ctx, "/model/info", url.Values{"litellm_model_id": []string{id}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetModel)
+	}
+	// Older LiteLLM versions ignore the litellm_model_id filter.
+	var info *modelInfo
+	for i := range rsp.Data {
+		if rsp.Data[i].ModelInfo.ID == id {
+			info = &rsp.Data[i]
+			break
+		}
+	}
+	if info == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	extra, err := extraParams(cr.Spec.ForProvider.LiteLLMParams)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+
+	_, versions, err := litellm.SecretValues(ctx, c.kube, secretRefs(cr.Spec.ForProvider.LiteLLMParams))
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	keyUpToDate := versions["api_key"] == cr.Status.AtProvider.APIKeySecretVersion
+	delete(versions, "api_key")
+	keyUpToDate = keyUpToDate && litellm.SecretsUpToDate(versions, cr.Status.AtProvider.SecretVersions)
+
+	cr.Status.AtProvider.ModelID = info.ModelInfo.ID
+	cr.Status.AtProvider.DBModel = info.ModelInfo.DBModel
+	cr.SetConditions(xpv1.Available())
+	c.checkHealth(ctx, cr)
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: keyUpToDate && isUpToDate(cr.Spec.ForProvider, extra, info),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Model)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotModel)
+	}
+
+	payload, versions, err := c.generatePayload(ctx, meta.GetExternalName(cr), cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+
+	var rsp struct {
+		ModelID string `json:"model_id"`
+	}
+	if err := c.client.Post(ctx, "/model/new", payload, &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateModel)
+	}
+	if rsp.ModelID != "" {
+		meta.SetExternalName(cr, rsp.ModelID)
+	}
+	recordSecretVersions(&cr.Status.AtProvider, versions)
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Model)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotModel)
+	}
+
+	payload, versions, err := c.generatePayload(ctx, meta.GetExternalName(cr), cr.Spec.ForProvider)
+	if err != nil {
+		return managed.ExternalUpdate{}, err
+	}
+	if err := c.client.Post(ctx, "/model/update", payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateModel)
+	}
+	recordSecretVersions(&cr.Status.AtProvider, versions)
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Model)
+	if !ok {
+		return errors.New(errNotModel)
+	}
+
+	err := c.client.Post(ctx, "/model/delete", map[string]interface{}{"id": meta.GetExternalName(cr)}, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteModel)
+}
+
+// recordSecretVersions records the supplied versions of the secrets whose
+// values were sent to the proxy, keyed by param name.
+func recordSecretVersions(o *v1alpha1.ModelObservation, versions map[string]string) {
+	o.APIKeySecretVersion = versions["api_key"]
+	delete(versions, "api_key")
+	o.SecretVersions = nil
+	if len(versions) > 0 {
+		o.SecretVersions = versions
+	}
+}
+
+// generatePayload returns the /model/new and /model/update payload for the
+// supplied parameters, and the resource versions of the secrets credentials
+// were read from, keyed by param name.
+func (c *external) generatePayload(ctx context.Context, id string, p v1alpha1.ModelParameters) (map[string]interface{}, map[string]string, error) { //nolint:gocyclo // Flat field-by-field mapping.
+	params, err := extraParams(p.LiteLLMParams)
+	if err != nil {
+		return nil, nil, err
+	}
+	lp := p.LiteLLMParams
+	values, versions, err := litellm.SecretValues(ctx, c.kube, secretRefs(lp))
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range values {
+		params[k] = v
+	}
+	params["model"] = lp.Model
+	if lp.APIBase != "" {
+		params["api_base"] = lp.APIBase
+	}
+	if lp.APIVersion != "" {
+		params["api_version"] = lp.APIVersion
+	}
+	if lp.LiteLLMCredentialName != "" {
+		params["litellm_credential_name"] = lp.LiteLLMCredentialName
+	}
+	if lp.Timeout != nil {
+		params["timeout"] = *lp.Timeout
+	}
+	if lp.RPM != nil {
+		params["rpm"] = *lp.RPM
+	}
+	if lp.TPM != nil {
+		params["tpm"] = *lp.TPM
+	}
+	if lp.MaxRetries != nil {
+		params["max_retries"] = *lp.MaxRetries
+	}
+	if lp.CooldownTime != nil {
+		params["cooldown_time"] = *lp.CooldownTime
+	}
+
+	info := map[string]interface{}{}
+	if id != "" {
+		info["id"] = id
+	}
+	if mi := p.ModelInfo; mi != nil {
+		if mi.Mode != "" {
+			info["mode"] = mi.Mode
+		}
+		if mi.BaseModel != "" {
+			info["base_model"] = mi.BaseModel
+		}
+		if mi.InputCostPerToken != nil {
+			info["input_cost_per_token"] = *mi.InputCostPerToken
+		}
+		if mi.OutputCostPerToken != nil {
+			info["output_cost_per_token"] = *mi.OutputCostPerToken
+		}
+		if mi.AccessGroups != nil {
+			info["access_groups"] = mi.AccessGroups
+		}
+	}
+
+	return map[string]interface{}{
+		"model_name":     p.ModelName,
+		"litellm_params": params,
+		"model_info":     info,
+	}, versions, nil
+}
+
+// healthEndpoint is an endpoint reported by /health.
+type healthEndpoint struct {
+	Model   string `json:"model"`
+	APIBase string `json:"api_base"`
+	Error   string `json:"error"`
+}
+
+// checkHealth records whether the proxy can reach the upstream of the
+// supplied model, if health checks are enabled. A failed health check doesn't
+// fail the observation, because it says nothing about the deployment itself.
+func (c *external) checkHealth(ctx context.Context, cr *v1alpha1.Model) {
+	o := &cr.Status.AtProvider
+	if !cr.Spec.ForProvider.HealthCheck {
+		o.Healthy = nil
+		o.HealthError = ""
+		return
+	}
+
+	var rsp struct {
+		HealthyEndpoints   []healthEndpoint `json:"healthy_endpoints"`
+		UnhealthyEndpoints []healthEndpoint `json:"unhealthy_endpoints"`
+	}
+	err := c.client.Get(ctx, "/health", url.Values{"model": []string{cr.Spec.ForProvider.ModelName}}, &rsp)
+	if err != nil {
+		err = errors.Wrap(err, errHealthCheck)
+	}
+	// A model group can have many deployments, so only the endpoints of this
+	// one are considered.
+	if err == nil {
+		for _, ep := range rsp.UnhealthyEndpoints {
+			if isEndpointOf(ep, cr.Spec.ForProvider.LiteLLMParams) {
+				err = errors.New(errUnhealthy)
+				if ep.Error != "" {
+					err = errors.New(ep.Error)
+				}
+				break
+			}
+		}
+	}
+
+	healthy := err == nil
+	o.Healthy = &healthy
+	o.HealthError = ""
+	if err != nil {
+		o.HealthError = err.Error()
+		cr.SetConditions(apisv1alpha1.Unhealthy(o.HealthError))
+		return
+	}
+	cr.SetConditions(apisv1alpha1.Healthy())
+}
+
+// isEndpointOf returns true if the supplied endpoint is the deployment with
+// the supplied params.
+func isEndpointOf(ep healthEndpoint, p v1alpha1.LiteLLMParams) bool {
+	return ep.Model == p.Model && (p.APIBase == "" || ep.APIBase == p.APIBase)
+}
+
+// extraParams returns the extra litellm_params of the supplied parameters.
+func extraParams(p v1alpha1.LiteLLMParams) (map[string]interface{}, error) {
+	params := map[string]interface{}{}
+	if p.ExtraParams == nil || len(p.ExtraParams.Raw) == 0 {
+		return params, nil
+	}
+	if err := json.Unmarshal(p.ExtraParams.Raw, &params); err != nil {
+		return nil, errors.Wrap(err, errExtraParams)
+	}
+	return params, nil
+}
+
+// isUpToDate returns true if the observed deployment matches the supplied
+// parameters. The API key and other credentials are never returned by
+// LiteLLM, so they can't be compared.
+func isUpToDate(p v1alpha1.ModelParameters, extra map[string]interface{}, o *modelInfo) bool { //nolint:gocyclo // Flat field-by-field comparison.
+	if p.ModelName != o.ModelName {
+		return false
+	}
+	lp := p.LiteLLMParams
+	if !sameString(lp.Model, o.LiteLLMParams["model"]) {
+		return false
+	}
+	if lp.APIBase != "" && !sameString(lp.APIBase, o.LiteLLMParams["api_base"]) {
+		return false
+	}
+	if lp.APIVersion != "" && !sameString(lp.APIVersion, o.LiteLLMParams["api_version"]) {
+		return false
+	}
+	if lp.LiteLLMCredentialName != "" && !sameString(lp.LiteLLMCredentialName, o.LiteLLMParams["litellm_credential_name"]) {
+		return false
+	}
+	if lp.Timeout != nil && !sameNumber(*lp.Timeout, o.LiteLLMParams["timeout"]) {
+		return false
+	}
+	if lp.RPM != nil && !sameNumber(*lp.RPM, o.LiteLLMParams["rpm"]) {
+		return false
+	}
+	if lp.TPM != nil && !sameNumber(*lp.TPM, o.LiteLLMParams["tpm"]) {
+		return false
+	}
+	if lp.MaxRetries != nil && !sameNumber(*lp.MaxRetries, o.LiteLLMParams["max_retries"]) {
+		return false
+	}
+	if lp.CooldownTime != nil && !sameNumber(*lp.CooldownTime, o.LiteLLMParams["cooldown_time"]) {
+		return false
+	}
+	for k, v := range extra {
+		if secretParams[k] {
+			continue
+		}
+		if !litellm.SameValue(v, o.LiteLLMParams[k]) {
+			return false
+		}
+	}
+
+	mi := p.ModelInfo
+	if mi == nil {
+		return true
+	}
+	if mi.Mode != "" && mi.Mode != o.ModelInfo.Mode {
+		return false
+	}
+	if mi.BaseModel != "" && mi.BaseModel != o.ModelInfo.BaseModel {
+		return false
+	}
+	if mi.InputCostPerToken != nil && (o.ModelInfo.InputCostPerToken == nil || *mi.InputCostPerToken != *o.ModelInfo.InputCostPerToken) {
+		return false
+	}
+	if mi.OutputCostPerToken != nil && (o.ModelInfo.OutputCostPerToken == nil || *mi.OutputCostPerToken != *o.ModelInfo.OutputCostPerToken) {
+		return false
+	}
+	return mi.AccessGroups == nil || litellm.SameStrings(mi.AccessGroups, o.ModelInfo.AccessGroups)
+}
+
+// sameString returns true if the observed value is the desired string.
+func sameString(want string, got interface{}) bool {
+	s, ok := got.(string)
+	return ok && s == want
+}
+
+// sameNumber returns true if the observed value is the desired number.
+func sameNumber(want int64, got interface{}) bool {
+	return litellm.SameValue(float64(want), got)
+}
+
+// secretParams are the litellm_params LiteLLM redacts or omits from
+// /model/info, so they can't be compared.
+var secretParams = map[string]bool{
+	"api_key":               true,
+	"aws_access_key_id":     true,
+	"aws_secret_access_key": true,
+	"aws_session_token":     true,
+	"vertex_credentials":    true,
+}
@@ -0,0 +1,612 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package model
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/model/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func model(id string, p v1alpha1.ModelParameters) *v1alpha1.Model {
+	cr := &v1alpha1.Model{Spec: v1alpha1.ModelSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	rpm := int64(600)
+	info := fake.Response{Body: `{"data": [{
+		"model_name": "gpt-4o",
+		"litellm_params": {"model": "azure/gpt-4o", "api_base": "https://eu.example.org", "rpm": 600, "region_name": "eu"},
+		"model_info": {"id": "m-1", "db_model": true, "mode": "chat", "access_groups": ["production"]}
+	}]}`}
+	desired := v1alpha1.ModelParameters{
+		ModelName: "gpt-4o",
+		LiteLLMParams: v1alpha1.LiteLLMParams{
+			Model:       "azure/gpt-4o",
+			APIBase:     "https://eu.example.org",
+			RPM:         &rpm,
+			ExtraParams: &runtime.RawExtension{Raw: []byte(`{"region_name": "eu"}`)},
+		},
+		ModelInfo: &v1alpha1.ModelInfo{Mode: "chat", AccessGroups: []string{"production"}},
+	}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Model
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A model missing from the /model/info response should be reported as absent.",
+			responses: map[string]fake.Response{"/model/info": {Body: `{"data": []}`}},
+			cr:        model("m-1", desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A model matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/model/info": info},
+			cr:        model("m-1", desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"Wildcard": {
+			reason: "A wildcard deployment should survive the round trip.",
+			responses: map[string]fake.Response{"/model/info": {Body: `{"data": [{
+				"model_name": "openai/*",
+				"litellm_params": {"model": "openai/*"},
+				"model_info": {"id": "m-1", "access_groups": ["prod-models"]}
+			}]}`}},
+			cr: model("m-1", v1alpha1.ModelParameters{
+				ModelName:     "openai/*",
+				LiteLLMParams: v1alpha1.LiteLLMParams{Model: "openai/*"},
+				ModelInfo:     &v1alpha1.ModelInfo{AccessGroups: []string{"prod-models"}},
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"ParamsDrifted": {
+			reason:    "A model whose litellm_params differ from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/model/info": info},
+			cr: model("m-1", v1alpha1.ModelParameters{
+				ModelName:     "gpt-4o",
+				LiteLLMParams: v1alpha1.LiteLLMParams{Model: "azure/gpt-4o-mini"},
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ExtraParamsDrifted": {
+			reason:    "A model whose extra litellm_params differ from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/model/info": info},
+			cr: model("m-1", v1alpha1.ModelParameters{
+				ModelName: "gpt-4o",
+				LiteLLMParams: v1alpha1.LiteLLMParams{
+					Model:       "azure/gpt-4o",
+					ExtraParams: &runtime.RawExtension{Raw: []byte(`{"region_name": "us"}`)},
+				},
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+// capturedModelInfo is a /model/info entry as returned by a LiteLLM proxy,
+// including the defaults it adds to litellm_params.
+const capturedModelInfo = `{
+	"model_name": "claude-sonnet",
+	"litellm_params": {
+		"aws_region_name": "eu-central-1",
+		"model": "bedrock/anthropic.claude-3-5-sonnet-20240620-v1:0",
+		"rpm": 600.0,
+		"timeout": 30.0,
+		"max_retries": 2,
+		"use_in_pass_through": false,
+		"merge_reasoning_content_in_choices": false,
+		"thinking": {"type": "enabled", "budget_tokens": 1024.0},
+		"tags": ["eu", "prod"]
+	},
+	"model_info": {
+		"id": "8b3a4a8c-6f0e-4a1e-9d84-1b2a4f3c9e10",
+		"db_model": true,
+		"mode": "chat",
+		"base_model": null,
+		"input_cost_per_token": 3e-06,
+		"output_cost_per_token": 1.5e-05,
+		"access_groups": ["production"],
+		"updated_by": "default_user_id",
+		"supports_vision": true
+	}
+}`
+
+func TestIsUpToDate(t *testing.T) {
+	rpm, timeout := int64(600), int64(30)
+	base := func(extra string) v1alpha1.ModelParameters {
+		p := v1alpha1.ModelParameters{
+			ModelName: "claude-sonnet",
+			LiteLLMParams: v1alpha1.LiteLLMParams{
+				Model:           "bedrock/anthropic.claude-3-5-sonnet-20240620-v1:0",
+				APIKeySecretRef: &xpv1.SecretKeySelector{Key: "key"},
+				RPM:             &rpm,
+				Timeout:         &timeout,
+			},
+		}
+		if extra != "" {
+			p.LiteLLMParams.ExtraParams = &runtime.RawExtension{Raw: []byte(extra)}
+		}
+		return p
+	}
+
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.ModelParameters
+		want   bool
+	}{
+		"ServerDefaults": {
+			reason: "Fields added by the proxy that aren't in the spec should be ignored.",
+			p:      base(`{"aws_region_name": "eu-central-1"}`),
+			want:   true,
+		},
+		"Numbers": {
+			reason: "Integers in the spec should equal the floats returned by the proxy.",
+			p:      base(`{"max_retries": 2, "thinking": {"budget_tokens": 1024}}`),
+			want:   true,
+		},
+		"RedactedSecrets": {
+			reason: "Credentials that the proxy doesn't return should be treated as equal.",
+			p:      base(`{"aws_access_key_id": "AKIA", "aws_secret_access_key": "secret"}`),
+			want:   true,
+		},
+		"NestedDrift": {
+			reason: "A nested value that differs from the spec should be drift.",
+			p:      base(`{"thinking": {"type": "disabled"}}`),
+			want:   false,
+		},
+		"ListDrift": {
+			reason: "A list that differs from the spec should be drift.",
+			p:      base(`{"tags": ["eu"]}`),
+			want:   false,
+		},
+		"MissingParam": {
+			reason: "A param in the spec that the proxy doesn't return should be drift.",
+			p:      base(`{"stream_timeout": 10}`),
+			want:   false,
+		},
+		"LoadBalancing": {
+			reason: "Retries returned as floats should equal the integers in the spec.",
+			p: func() v1alpha1.ModelParameters {
+				p := base("")
+				r := int64(2)
+				p.LiteLLMParams.MaxRetries = &r
+				return p
+			}(),
+			want: true,
+		},
+		"CooldownNotSet": {
+			reason: "A cooldown of zero is not the router default, so it should be drift if the deployment has none.",
+			p: func() v1alpha1.ModelParameters {
+				p := base("")
+				c := int64(0)
+				p.LiteLLMParams.CooldownTime = &c
+				return p
+			}(),
+			want: false,
+		},
+		"NumberDrift": {
+			reason: "A number that differs from the spec should be drift.",
+			p: func() v1alpha1.ModelParameters {
+				p := base("")
+				r := int64(1200)
+				p.LiteLLMParams.RPM = &r
+				return p
+			}(),
+			want: false,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			info := &modelInfo{}
+			if err := json.Unmarshal([]byte(capturedModelInfo), info); err != nil {
+				t.Fatalf("json.Unmarshal(...): %v", err)
+			}
+			extra, err := extraParams(tc.p.LiteLLMParams)
+			if err != nil {
+				t.Fatalf("extraParams(...): %v", err)
+			}
+			got := isUpToDate(tc.p, extra, info)
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\nisUpToDate(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveAPIKey(t *testing.T) {
+	ref := &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "azure", Namespace: "crossplane-system"}, Key: "api-key"}
+	info := fake.Response{Body: `{"data": [{"model_name": "gpt-4o", "litellm_params": {"model": "azure/gpt-4o", "api_key": "sk-az*****"}, "model_info": {"id": "m-1"}}]}`}
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			s := obj.(*corev1.Secret)
+			s.SetResourceVersion("2")
+			s.Data = map[string][]byte{"api-key": []byte("sk-azure")}
+			return nil
+		}),
+	}
+
+	cases := map[string]struct {
+		reason  string
+		version string
+		want    managed.ExternalObservation
+	}{
+		"Unchanged": {
+			reason:  "A redacted API key whose secret didn't change since it was sent should be up to date.",
+			version: "2",
+			want:    managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+		},
+		"Rotated": {
+			reason:  "An API key whose secret changed since it was sent should not be up to date.",
+			version: "1",
+			want:    managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/model/info": info})
+			defer srv.Close()
+
+			cr := model("m-1", v1alpha1.ModelParameters{
+				ModelName:     "gpt-4o",
+				LiteLLMParams: v1alpha1.LiteLLMParams{Model: "azure/gpt-4o", APIKeySecretRef: ref},
+			})
+			cr.Status.AtProvider.APIKeySecretVersion = tc.version
+			e := external{kube: kube, client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestModelsForSecret(t *testing.T) {
+	withKey := func(name, secret string) v1alpha1.Model {
+		m := model("", v1alpha1.ModelParameters{LiteLLMParams: v1alpha1.LiteLLMParams{
+			APIKeySecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: secret, Namespace: "crossplane-system"}, Key: "api-key"},
+		}})
+		m.SetName(name)
+		return *m
+	}
+	kube := &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			l := obj.(*v1alpha1.ModelList)
+			bedrock := model("bedrock", v1alpha1.ModelParameters{LiteLLMParams: v1alpha1.LiteLLMParams{
+				AWSAccessKeyIDSecretRef:     &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "aws", Namespace: "crossplane-system"}, Key: "id"},
+				AWSSecretAccessKeySecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "azure", Namespace: "crossplane-system"}, Key: "secret"},
+			}})
+			bedrock.SetName("bedrock")
+			l.Items = []v1alpha1.Model{withKey("gpt-4o-eu", "azure"), withKey("gpt-4o-us", "azure"), withKey("claude", "anthropic"), *model("local", v1alpha1.ModelParameters{}), *bedrock}
+			return nil
+		},
+	}
+
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "azure", Namespace: "crossplane-system"}}
+	want := []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "gpt-4o-eu"}},
+		{NamespacedName: types.NamespacedName{Name: "gpt-4o-us"}},
+		{NamespacedName: types.NamespacedName{Name: "bedrock"}},
+	}
+	if diff := cmp.Diff(want, modelsForSecret(kube)(context.Background(), s)); diff != "" {
+		t.Errorf("modelsForSecret(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/model/new": {Body: `{"model_id": "m-1"}`}})
+	defer srv.Close()
+
+	kube := &test.MockClient{
+		MockGet: test.NewMockGetFn(nil, func(obj client.Object) error {
+			obj.SetResourceVersion("1")
+			obj.(*corev1.Secret).Data = map[string][]byte{"api-key": []byte("sk-azure\n")}
+			return nil
+		}),
+	}
+	cost := 0.000005
+	rpm, tpm, retries, cooldown := int64(600), int64(90000), int64(3), int64(0)
+	cr := model("gpt-4o-eu", v1alpha1.ModelParameters{
+		ModelName: "gpt-4o",
+		LiteLLMParams: v1alpha1.LiteLLMParams{
+			Model:           "azure/gpt-4o",
+			APIVersion:      "2024-06-01",
+			RPM:             &rpm,
+			TPM:             &tpm,
+			MaxRetries:      &retries,
+			CooldownTime:    &cooldown,
+			APIKeySecretRef: &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: "azure", Namespace: "crossplane-system"}, Key: "api-key"},
+			ExtraParams:     &runtime.RawExtension{Raw: []byte(`{"region_name": "eu", "model": "ignored"}`)},
+		},
+		ModelInfo: &v1alpha1.ModelInfo{BaseModel: "azure/gpt-4o", InputCostPerToken: &cost},
+	})
+	e := external{kube: kube, client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"model_name": "gpt-4o",
+		"litellm_params": map[string]interface{}{
+			"model":         "azure/gpt-4o",
+			"api_version":   "2024-06-01",
+			"api_key":       "sk-azure",
+			"region_name":   "eu",
+			"rpm":           float64(600),
+			"tpm":           float64(90000),
+			"max_retries":   float64(3),
+			"cooldown_time": float64(0),
+		},
+		"model_info": map[string]interface{}{
+			"id":                   "gpt-4o-eu",
+			"base_model":           "azure/gpt-4o",
+			"input_cost_per_token": 0.000005,
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/model/new")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("m-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+	if diff := cmp.Diff("1", cr.Status.AtProvider.APIKeySecretVersion); diff != "" {
+		t.Errorf("e.Create(...): -want API key secret version, +got API key secret version:\n%s", diff)
+	}
+}
+
+func TestCreateCredentialName(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/model/new": {Body: `{"model_id": "m-1"}`}})
+	defer srv.Close()
+
+	cr := model("", v1alpha1.ModelParameters{
+		ModelName:     "gpt-4o",
+		LiteLLMParams: v1alpha1.LiteLLMParams{Model: "azure/gpt-4o", LiteLLMCredentialName: "azure-prod"},
+	})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{"model": "azure/gpt-4o", "litellm_credential_name": "azure-prod"}
+	if diff := cmp.Diff(want, srv.Body("/model/new")["litellm_params"]); diff != "" {
+		t.Errorf("e.Create(...): -want litellm_params, +got litellm_params:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/model/delete": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), model("m-1", v1alpha1.ModelParameters{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"id": "m-1"}
+	if diff := cmp.Diff(want, srv.Body("/model/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestObserveHealth(t *testing.T) {
+	info := fake.Response{Body: `{"data": [{
+		"model_name": "gpt-4o",
+		"litellm_params": {"model": "azure/gpt-4o", "api_base": "https://eu.example.org"},
+		"model_info": {"id": "m-1"}
+	}]}`}
+	params := v1alpha1.ModelParameters{
+		ModelName:     "gpt-4o",
+		LiteLLMParams: v1alpha1.LiteLLMParams{Model: "azure/gpt-4o", APIBase: "https://eu.example.org"},
+		HealthCheck:   true,
+	}
+	healthy, unhealthy := true, false
+
+	type want struct {
+		healthy *bool
+		err     string
+		cond    xpv1.Condition
+	}
+
+	cases := map[string]struct {
+		reason string
+		health fake.Response
+		params v1alpha1.ModelParameters
+		want   want
+	}{
+		"Disabled": {
+			reason: "A model without health checks should not be checked.",
+			params: v1alpha1.ModelParameters{ModelName: "gpt-4o", LiteLLMParams: params.LiteLLMParams},
+			want:   want{cond: xpv1.Condition{Type: apisv1alpha1.TypeHealthy, Status: corev1.ConditionUnknown}},
+		},
+		"Healthy": {
+			reason: "A model whose deployment is healthy should be reported as healthy.",
+			health: fake.Response{Body: `{"healthy_endpoints": [{"model": "azure/gpt-4o", "api_base": "https://eu.example.org"}], "unhealthy_endpoints": [{"model": "azure/gpt-4o", "api_base": "https://us.example.org", "error": "timeout"}]}`},
+			params: params,
+			want:   want{healthy: &healthy, cond: apisv1alpha1.Healthy()},
+		},
+		"Unhealthy": {
+			reason: "A model whose deployment is unhealthy should report the error of the health check.",
+			health: fake.Response{Body: `{"healthy_endpoints": [], "unhealthy_endpoints": [{"model": "azure/gpt-4o", "api_base": "https://eu.example.org", "error": "AuthenticationError: invalid api key"}]}`},
+			params: params,
+			want: want{
+				healthy: &unhealthy,
+				err:     "AuthenticationError: invalid api key",
+				cond:    apisv1alpha1.Unhealthy("AuthenticationError: invalid api key"),
+			},
+		},
+		"CheckFailed": {
+			reason: "A health check that fails should report the model as unhealthy rather than fail the observation.",
+			health: fake.Response{Status: http.StatusInternalServerError, Body: "boom"},
+			params: params,
+			want: want{
+				healthy: &unhealthy,
+				err:     errHealthCheck + ": GET /health returned unexpected status 500: boom",
+				cond:    apisv1alpha1.Unhealthy(errHealthCheck + ": GET /health returned unexpected status 500: boom"),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/model/info": info, "/health": tc.health})
+			defer srv.Close()
+
+			cr := model("m-1", tc.params)
+			e := external{client: srv.Client()}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.healthy, cr.Status.AtProvider.Healthy); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want healthy, +got healthy:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.err, cr.Status.AtProvider.HealthError); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.cond, cr.GetCondition(apisv1alpha1.TypeHealthy), test.EquateConditions()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want condition, +got condition:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCredentials(t *testing.T) {
+	ref := func(name, key string) *xpv1.SecretKeySelector {
+		return &xpv1.SecretKeySelector{SecretReference: xpv1.SecretReference{Name: name, Namespace: "crossplane-system"}, Key: key}
+	}
+	secrets := map[string]map[string][]byte{
+		"vertex": {"credentials.json": []byte(`{"type": "service_account"}`)},
+		"aws":    {"id": []byte("AKIA"), "secret": []byte("s3cr3t\n")},
+		"azure":  {"token": []byte("ad-token")},
+	}
+	kube := &test.MockClient{
+		MockGet: func(_ context.Context, key client.ObjectKey, obj client.Object) error {
+			s := obj.(*corev1.Secret)
+			s.SetResourceVersion("7")
+			s.Data = secrets[key.Name]
+			return nil
+		},
+	}
+	info := fake.Response{Body: `{"data": [{"model_name": "claude", "litellm_params": {"model": "bedrock/claude"}, "model_info": {"id": "m-1"}}]}`}
+	srv := fake.NewServer(map[string]fake.Response{"/model/info": info, "/model/update": {Body: `{}`}})
+	defer srv.Close()
+
+	cr := model("m-1", v1alpha1.ModelParameters{
+		ModelName: "claude",
+		LiteLLMParams: v1alpha1.LiteLLMParams{
+			Model:                       "bedrock/claude",
+			VertexCredentialsSecretRef:  ref("vertex", "credentials.json"),
+			AWSAccessKeyIDSecretRef:     ref("aws", "id"),
+			AWSSecretAccessKeySecretRef: ref("aws", "secret"),
+			ExtraSecretParams: map[string]xpv1.SecretKeySelector{
+				"azure_ad_token": *ref("azure", "token"),
+				"model":          *ref("azure", "token"),
+			},
+		},
+	})
+	e := external{kube: kube, client: srv.Client()}
+
+	o, err := e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if o.ResourceUpToDate {
+		t.Errorf("e.Observe(...): credentials that were never sent should not be up to date")
+	}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"model":                 "bedrock/claude",
+		"vertex_credentials":    `{"type": "service_account"}`,
+		"aws_access_key_id":     "AKIA",
+		"aws_secret_access_key": "s3cr3t",
+		"azure_ad_token":        "ad-token",
+	}
+	if diff := cmp.Diff(want, srv.Body("/model/update")["litellm_params"]); diff != "" {
+		t.Errorf("e.Update(...): -want litellm_params, +got litellm_params:\n%s", diff)
+	}
+	wantStatus := v1alpha1.ModelObservation{
+		ModelID: "m-1",
+		SecretVersions: map[string]string{
+			"vertex_credentials":    "7",
+			"aws_access_key_id":     "7",
+			"aws_secret_access_key": "7",
+			"azure_ad_token":        "7",
+			"model":                 "7",
+		},
+	}
+	if diff := cmp.Diff(wantStatus, cr.Status.AtProvider); diff != "" {
+		t.Errorf("e.Update(...): -want status, +got status:\n%s", diff)
+	}
+
+	o, err = e.Observe(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+	if !o.ResourceUpToDate {
+		t.Errorf("e.Observe(...): credentials whose secrets didn't change since they were sent should be up to date")
+	}
+}
@@ -0,0 +1,279 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelalias
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/router/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotModelAlias = "managed resource is not a ModelAlias custom resource"
+	errTrackPCUsage  = "cannot track ProviderConfig usage"
+	errGetConfig     = "cannot get LiteLLM configuration"
+
+	errGetAliases    = "cannot get model group aliases"
+	errUpdateAliases = "cannot update model group aliases"
+	errParseAlias    = "cannot parse model group alias %q"
+)
+
+// aliasLocks serialize the changes to the model_group_alias router setting of
+// each ProviderConfig. LiteLLM replaces the whole setting on update, so the
+// changes of concurrently reconciled ModelAliases would otherwise overwrite
+// each other's aliases.
+var aliasLocks = &locks{locks: map[string]*sync.Mutex{}}
+
+// locks hold a lock per ProviderConfig name.
+type locks struct {
+	mu    sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// Get returns the lock of the supplied ProviderConfig.
+func (l *locks) Get(pc string) *sync.Mutex {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.locks[pc]; !ok {
+		l.locks[pc] = &sync.Mutex{}
+	}
+	return l.locks[pc]
+}
+
+// Setup adds a controller that reconciles ModelAlias managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.ModelAliasGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.ModelAliasGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.ModelAliasKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.ModelAlias{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.ModelAliasGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.ModelAliasGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied ModelAlias.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.ModelAlias); !ok {
+		return nil, errors.New(errNotModelAlias)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	var pc string
+	if ref := mg.GetProviderConfigReference(); ref != nil {
+		pc = ref.Name
+	}
+	return &external{client: c.newClientFn(cfg), mu: aliasLocks.Get(pc)}, nil
+}
+
+// An external keeps an entry of the model_group_alias router setting in line
+// with a ModelAlias. LiteLLM replaces the whole setting on update, so every
+// change reads the current aliases, changes only the one of the ModelAlias
+// and writes them all back, while holding the lock of the ProviderConfig.
+type external struct {
+	client *litellm.Client
+	mu     *sync.Mutex
+}
+
+// alias is an entry of model_group_alias. LiteLLM accepts either the name of
+// the model group or an object that can also hide the alias.
+type alias struct {
+	Model  string `json:"model"`
+	Hidden bool   `json:"hidden,omitempty"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.ModelAlias)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotModelAlias)
+	}
+
+	aliases, err := c.aliases(ctx)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetAliases)
+	}
+	raw, ok := aliases[cr.Spec.ForProvider.Alias]
+	if !ok {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	a, err := parseAlias(raw)
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrapf(err, errParseAlias, cr.Spec.ForProvider.Alias)
+	}
+
+	cr.Status.AtProvider.Model = a.Model
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: a.Model == cr.Spec.ForProvider.Model && a.Hidden == cr.Spec.ForProvider.Hidden,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.ModelAlias)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotModelAlias)
+	}
+
+	if err := c.set(ctx, cr.Spec.ForProvider); err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	meta.SetExternalName(cr, cr.Spec.ForProvider.Alias)
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.ModelAlias)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotModelAlias)
+	}
+
+	return managed.ExternalUpdate{}, c.set(ctx, cr.Spec.ForProvider)
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.ModelAlias)
+	if !ok {
+		return errors.New(errNotModelAlias)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	aliases, err := c.aliases(ctx)
+	if err != nil {
+		return errors.Wrap(err, errGetAliases)
+	}
+	if _, ok := aliases[cr.Spec.ForProvider.Alias]; !ok {
+		return nil
+	}
+	delete(aliases, cr.Spec.ForProvider.Alias)
+	return errors.Wrap(c.update(ctx, aliases), errUpdateAliases)
+}
+
+// aliases returns the current model_group_alias router setting. Entries are
+// kept as they are, so that writing them back doesn't change them.
+func (c *external) aliases(ctx context.Context) (map[string]json.RawMessage, error) {
+	var rsp struct {
+		RouterSettings struct {
+			ModelGroupAlias map[string]json.RawMessage `json:"model_group_alias"`
+		} `json:"router_settings"`
+	}
+	if err := c.client.Get(ctx, "/get/config/callbacks", nil, &rsp); err != nil {
+		return nil, err
+	}
+	if rsp.RouterSettings.ModelGroupAlias == nil {
+		return map[string]json.RawMessage{}, nil
+	}
+	return rsp.RouterSettings.ModelGroupAlias, nil
+}
+
+// set sets the alias of the supplied parameters, keeping all other aliases.
+func (c *external) set(ctx context.Context, p v1alpha1.ModelAliasParameters) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	aliases, err := c.aliases(ctx)
+	if err != nil {
+		return errors.Wrap(err, errGetAliases)
+	}
+	var v interface{} = p.Model
+	if p.Hidden {
+		v = alias{Model: p.Model, Hidden: true}
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return errors.Wrap(err, errUpdateAliases)
+	}
+	aliases[p.Alias] = b
+	return errors.Wrap(c.update(ctx, aliases), errUpdateAliases)
+}
+
+// update replaces the model_group_alias router setting.
+func (c *external) update(ctx context.Context, aliases map[string]json.RawMessage) error {
+	payload := map[string]interface{}{"router_settings": map[string]interface{}{"model_group_alias": aliases}}
+	return c.client.Post(ctx, "/config/update", payload, nil)
+}
+
+// parseAlias parses an entry of model_group_alias.
+func parseAlias(raw json.RawMessage) (alias, error) {
+	var model string
+	if err := json.Unmarshal(raw, &model); err == nil {
+		return alias{Model: model}, nil
+	}
+	a := alias{}
+	err := json.Unmarshal(raw, &a)
+	return a, err
+}
@@ -0,0 +1,203 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package modelalias
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+
+	"github.com/crossplane/provider-litellm/apis/router/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func modelAlias(p v1alpha1.ModelAliasParameters) *v1alpha1.ModelAlias {
+	return &v1alpha1.ModelAlias{Spec: v1alpha1.ModelAliasSpec{ForProvider: p}}
+}
+
+// callbacks is a /get/config/callbacks response with an alias managed by
+// another ModelAlias, and one that is hidden.
+const callbacks = `{"router_settings": {
+	"routing_strategy": "simple-shuffle",
+	"model_group_alias": {"gpt-4": "gpt-4o", "claude": {"model": "claude-sonnet", "hidden": true}}
+}}`
+
+func TestObserve(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.ModelAliasParameters
+		want   managed.ExternalObservation
+	}{
+		"NotFound": {
+			reason: "An alias that isn't configured should not exist.",
+			p:      v1alpha1.ModelAliasParameters{Alias: "gpt-3.5", Model: "gpt-4o-mini"},
+			want:   managed.ExternalObservation{ResourceExists: false},
+		},
+		"UpToDate": {
+			reason: "An alias routing to the desired model should be up to date.",
+			p:      v1alpha1.ModelAliasParameters{Alias: "gpt-4", Model: "gpt-4o"},
+			want:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+		},
+		"HiddenUpToDate": {
+			reason: "A hidden alias routing to the desired model should be up to date.",
+			p:      v1alpha1.ModelAliasParameters{Alias: "claude", Model: "claude-sonnet", Hidden: true},
+			want:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+		},
+		"Drifted": {
+			reason: "An alias routing to another model should not be up to date.",
+			p:      v1alpha1.ModelAliasParameters{Alias: "gpt-4", Model: "gpt-4.1"},
+			want:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/get/config/callbacks": {Body: callbacks}})
+			defer srv.Close()
+
+			e := external{client: srv.Client(), mu: &sync.Mutex{}}
+			got, err := e.Observe(context.Background(), modelAlias(tc.p))
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/get/config/callbacks": {Body: callbacks},
+		"/config/update":        {Body: `{}`},
+	})
+	defer srv.Close()
+
+	e := external{client: srv.Client(), mu: &sync.Mutex{}}
+	if _, err := e.Create(context.Background(), modelAlias(v1alpha1.ModelAliasParameters{Alias: "fast", Model: "gpt-4o-mini"})); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{"router_settings": map[string]interface{}{"model_group_alias": map[string]interface{}{
+		"gpt-4":  "gpt-4o",
+		"claude": map[string]interface{}{"model": "claude-sonnet", "hidden": true},
+		"fast":   "gpt-4o-mini",
+	}}}
+	if diff := cmp.Diff(want, srv.Body("/config/update")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		alias  string
+		want   map[string]interface{}
+	}{
+		"Removed": {
+			reason: "Deleting an alias should keep all other aliases.",
+			alias:  "claude",
+			want: map[string]interface{}{"router_settings": map[string]interface{}{"model_group_alias": map[string]interface{}{
+				"gpt-4": "gpt-4o",
+			}}},
+		},
+		"AlreadyGone": {
+			reason: "Deleting an alias that isn't configured should not update the config.",
+			alias:  "gpt-3.5",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/get/config/callbacks": {Body: callbacks},
+				"/config/update":        {Body: `{}`},
+			})
+			defer srv.Close()
+
+			e := external{client: srv.Client(), mu: &sync.Mutex{}}
+			if err := e.Delete(context.Background(), modelAlias(v1alpha1.ModelAliasParameters{Alias: tc.alias})); err != nil {
+				t.Fatalf("\n%s\ne.Delete(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Body("/config/update")); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestConcurrentUpdates(t *testing.T) {
+	// The server stores the aliases it is sent, and is slow to return them,
+	// so that concurrent changes that aren't serialized overwrite each
+	// other's aliases.
+	var mu sync.Mutex
+	aliases := map[string]interface{}{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/get/config/callbacks":
+			mu.Lock()
+			b, _ := json.Marshal(map[string]interface{}{"router_settings": map[string]interface{}{"model_group_alias": aliases}})
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			_, _ = w.Write(b)
+		case "/config/update":
+			var body struct {
+				RouterSettings struct {
+					ModelGroupAlias map[string]interface{} `json:"model_group_alias"`
+				} `json:"router_settings"`
+			}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			mu.Lock()
+			aliases = body.RouterSettings.ModelGroupAlias
+			mu.Unlock()
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer srv.Close()
+
+	lock := aliasLocks.Get("default")
+	want := map[string]interface{}{}
+	var wg sync.WaitGroup
+	for i := 0; i < 5; i++ {
+		a := fmt.Sprintf("alias-%d", i)
+		want[a] = "gpt-4o"
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			e := external{client: litellm.NewClient(&litellm.Config{APIBase: srv.URL, APIKey: "sk-test"}), mu: lock}
+			if _, err := e.Create(context.Background(), modelAlias(v1alpha1.ModelAliasParameters{Alias: a, Model: "gpt-4o"})); err != nil {
+				t.Errorf("e.Create(...): %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if diff := cmp.Diff(want, aliases); diff != "" {
+		t.Errorf("e.Create(...): -want aliases, +got aliases:\n%s", diff)
+	}
+}
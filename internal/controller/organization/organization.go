@@ -0,0 +1,317 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package organization
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/organization/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotOrganization = "managed resource is not an Organization custom resource"
+	errTrackPCUsage    = "cannot track ProviderConfig usage"
+	errGetConfig       = "cannot get LiteLLM configuration"
+
+	errGetOrganization    = "cannot get organization"
+	errCreateOrganization = "cannot create organization"
+	errUpdateOrganization = "cannot update organization"
+	errDeleteOrganization = "cannot delete organization"
+
+	// connKeyOrganizationID is the connection detail the organization id is
+	// published as, e.g. for compositions that create teams in it.
+	connKeyOrganizationID = "organization_id"
+)
+
+// Setup adds a controller that reconciles Organization managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.OrganizationGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.OrganizationGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.OrganizationKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Organization{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.OrganizationGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.OrganizationGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Organization.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Organization); !ok {
+		return nil, errors.New(errNotOrganization)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// organization to ensure it reflects the managed resource's desired state.
+type external struct {
+	client *litellm.Client
+}
+
+// organizationInfo is returned by /organization/info.
+type organizationInfo struct {
+	OrganizationID    string                 `json:"organization_id"`
+	OrganizationAlias string                 `json:"organization_alias"`
+	BudgetID          string                 `json:"budget_id"`
+	Models            []string               `json:"models"`
+	Metadata          map[string]interface{} `json:"metadata"`
+	Spend             float64                `json:"spend"`
+	Teams             []struct {
+		TeamID string `json:"team_id"`
+	} `json:"teams"`
+	Members []json.RawMessage `json:"members"`
+	Budget  *struct {
+		MaxBudget      *float64 `json:"max_budget"`
+		BudgetDuration string   `json:"budget_duration"`
+		TPMLimit       *int64   `json:"tpm_limit"`
+		RPMLimit       *int64   `json:"rpm_limit"`
+	} `json:"litellm_budget_table"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Organization)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotOrganization)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var raw json.RawMessage
+	err := c.client.Get(ctx, "/organization/info", url.Values{"organization_id": []string{id}}, &raw)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetOrganization)
+	}
+	if litellm.IsNull(raw) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	info := &organizationInfo{}
+	if err := litellm.Decode(ctx, raw, info, litellm.RenamedSpend); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetOrganization)
+	}
+
+	setObservation(&cr.Status.AtProvider, info)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:    true,
+		ResourceUpToDate:  isUpToDate(cr.Spec.ForProvider, info),
+		ConnectionDetails: managed.ConnectionDetails{connKeyOrganizationID: []byte(info.OrganizationID)},
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Organization)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotOrganization)
+	}
+
+	var rsp struct {
+		OrganizationID string `json:"organization_id"`
+	}
+	if err := c.client.Post(ctx, "/organization/new", generatePayload(meta.GetExternalName(cr), cr.Spec.ForProvider), &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateOrganization)
+	}
+	if rsp.OrganizationID != "" {
+		meta.SetExternalName(cr, rsp.OrganizationID)
+	}
+
+	return managed.ExternalCreation{
+		ConnectionDetails: managed.ConnectionDetails{connKeyOrganizationID: []byte(meta.GetExternalName(cr))},
+	}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Organization)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotOrganization)
+	}
+
+	payload := generatePayload(meta.GetExternalName(cr), cr.Spec.ForProvider)
+	if err := c.client.Do(ctx, http.MethodPatch, "/organization/update", nil, payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateOrganization)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Organization)
+	if !ok {
+		return errors.New(errNotOrganization)
+	}
+
+	payload := map[string]interface{}{"organization_ids": []string{meta.GetExternalName(cr)}}
+	err := c.client.Do(ctx, http.MethodDelete, "/organization/delete", nil, payload, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteOrganization)
+}
+
+// setObservation updates the supplied observation with what LiteLLM returned.
+func setObservation(o *v1alpha1.OrganizationObservation, info *organizationInfo) {
+	o.OrganizationID = info.OrganizationID
+	o.BudgetID = info.BudgetID
+	o.Spend = info.Spend
+	o.MaxBudget = nil
+	if info.Budget != nil {
+		o.MaxBudget = info.Budget.MaxBudget
+	}
+	o.TeamIDs = nil
+	for _, t := range info.Teams {
+		o.TeamIDs = append(o.TeamIDs, t.TeamID)
+	}
+	o.MemberCount = len(info.Members)
+}
+
+// generatePayload returns the /organization/new and /organization/update
+// payload for the supplied parameters.
+func generatePayload(id string, p v1alpha1.OrganizationParameters) map[string]interface{} { //nolint:gocyclo // Flat field-by-field mapping.
+	payload := map[string]interface{}{"organization_alias": p.OrganizationAlias}
+	if id != "" {
+		payload["organization_id"] = id
+	}
+	if p.Models != nil {
+		payload["models"] = p.Models
+	}
+	if p.MaxBudget != nil {
+		payload["max_budget"] = *p.MaxBudget
+	}
+	if p.BudgetDuration != "" {
+		payload["budget_duration"] = p.BudgetDuration
+	}
+	if p.TPMLimit != nil {
+		payload["tpm_limit"] = *p.TPMLimit
+	}
+	if p.RPMLimit != nil {
+		payload["rpm_limit"] = *p.RPMLimit
+	}
+	if p.Metadata != nil {
+		payload["metadata"] = p.Metadata
+	}
+	if p.BudgetID != "" {
+		payload["budget_id"] = p.BudgetID
+	}
+	return payload
+}
+
+// isUpToDate returns true if the observed organization matches every field
+// set in the supplied parameters. Fields that are not set are not managed.
+func isUpToDate(p v1alpha1.OrganizationParameters, o *organizationInfo) bool { //nolint:gocyclo // Flat field-by-field comparison.
+	if p.OrganizationAlias != o.OrganizationAlias {
+		return false
+	}
+	if p.Models != nil && !litellm.SameStrings(p.Models, o.Models) {
+		return false
+	}
+	if p.BudgetID != "" && p.BudgetID != o.BudgetID {
+		return false
+	}
+	for k, v := range p.Metadata {
+		if s, ok := o.Metadata[k].(string); !ok || s != v {
+			return false
+		}
+	}
+	// Budgets referenced by id are managed by their Budget, so only compare
+	// the limits of the organization's own budget.
+	if p.BudgetID != "" {
+		return true
+	}
+	b := o.Budget
+	if b == nil {
+		return p.MaxBudget == nil && p.BudgetDuration == "" && p.TPMLimit == nil && p.RPMLimit == nil
+	}
+	if p.MaxBudget != nil && (b.MaxBudget == nil || *p.MaxBudget != *b.MaxBudget) {
+		return false
+	}
+	if p.BudgetDuration != "" && p.BudgetDuration != b.BudgetDuration {
+		return false
+	}
+	if p.TPMLimit != nil && (b.TPMLimit == nil || *p.TPMLimit != *b.TPMLimit) {
+		return false
+	}
+	return p.RPMLimit == nil || (b.RPMLimit != nil && *p.RPMLimit == *b.RPMLimit)
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package organization
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/organization/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func organization(id string, p v1alpha1.OrganizationParameters) *v1alpha1.Organization {
+	cr := &v1alpha1.Organization{Spec: v1alpha1.OrganizationSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	maxBudget := 100.0
+	conn := managed.ConnectionDetails{connKeyOrganizationID: []byte("org-1")}
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Organization
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A 404 from /organization/info should report the organization as absent.",
+			responses: map[string]fake.Response{},
+			cr:        organization("org-1", v1alpha1.OrganizationParameters{OrganizationAlias: "Research"}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "An organization matching the spec should be up to date and publish its id.",
+			responses: map[string]fake.Response{"/organization/info": {Body: `{"organization_id": "org-1", "organization_alias": "Research", "models": ["gpt-4o"], "metadata": {"costCenter": "4711"}, "litellm_budget_table": {"max_budget": 100}}`}},
+			cr: organization("org-1", v1alpha1.OrganizationParameters{
+				OrganizationAlias: "Research",
+				Models:            []string{"gpt-4o"},
+				MaxBudget:         &maxBudget,
+				Metadata:          map[string]string{"costCenter": "4711"},
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true, ConnectionDetails: conn}},
+		},
+		"AliasDrifted": {
+			reason:    "An organization whose alias differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/organization/info": {Body: `{"organization_id": "org-1", "organization_alias": "R&D"}`}},
+			cr:        organization("org-1", v1alpha1.OrganizationParameters{OrganizationAlias: "Research"}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false, ConnectionDetails: conn}},
+		},
+		"BudgetDrifted": {
+			reason:    "An organization without the desired budget should not be up to date.",
+			responses: map[string]fake.Response{"/organization/info": {Body: `{"organization_id": "org-1", "organization_alias": "Research", "litellm_budget_table": null}`}},
+			cr:        organization("org-1", v1alpha1.OrganizationParameters{OrganizationAlias: "Research", MaxBudget: &maxBudget}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false, ConnectionDetails: conn}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveStatus(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/organization/info": {Body: `{
+		"organization_id": "org-1",
+		"organization_alias": "Research",
+		"budget_id": "b-1",
+		"spend": 42.5,
+		"litellm_budget_table": {"max_budget": 100},
+		"teams": [{"team_id": "t-1"}, {"team_id": "t-2"}],
+		"members": [{"user_id": "alice"}, {"user_id": "bob"}, {"user_id": "carol"}]
+	}`}})
+	defer srv.Close()
+
+	cr := organization("org-1", v1alpha1.OrganizationParameters{OrganizationAlias: "Research"})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+
+	maxBudget := 100.0
+	want := v1alpha1.OrganizationObservation{
+		OrganizationID: "org-1",
+		BudgetID:       "b-1",
+		Spend:          42.5,
+		MaxBudget:      &maxBudget,
+		TeamIDs:        []string{"t-1", "t-2"},
+		MemberCount:    3,
+	}
+	if diff := cmp.Diff(want, cr.Status.AtProvider); diff != "" {
+		t.Errorf("e.Observe(...): -want status, +got status:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/organization/new": {Body: `{"organization_id": "org-1"}`}})
+	defer srv.Close()
+
+	cr := organization("", v1alpha1.OrganizationParameters{OrganizationAlias: "Research", BudgetID: "tier-1"})
+	e := external{client: srv.Client()}
+	got, err := e.Create(context.Background(), cr)
+	if err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{"organization_alias": "Research", "budget_id": "tier-1"}
+	if diff := cmp.Diff(want, srv.Body("/organization/new")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("org-1", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+	wantCreation := managed.ExternalCreation{ConnectionDetails: managed.ConnectionDetails{connKeyOrganizationID: []byte("org-1")}}
+	if diff := cmp.Diff(wantCreation, got); diff != "" {
+		t.Errorf("e.Create(...): -want, +got:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"PATCH /organization/update": {Body: `{}`}})
+	defer srv.Close()
+
+	cr := organization("org-1", v1alpha1.OrganizationParameters{OrganizationAlias: "Research", Models: []string{"gpt-4o"}})
+	e := external{client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"organization_id":    "org-1",
+		"organization_alias": "Research",
+		"models":             []interface{}{"gpt-4o"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/organization/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"DELETE /organization/delete": {Body: `[]`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), organization("org-1", v1alpha1.OrganizationParameters{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"organization_ids": []interface{}{"org-1"}}
+	if diff := cmp.Diff(want, srv.Body("/organization/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
@@ -0,0 +1,265 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package organizationmember
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/organization/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotOrganizationMember = "managed resource is not an OrganizationMember custom resource"
+	errTrackPCUsage          = "cannot track ProviderConfig usage"
+	errGetConfig             = "cannot get LiteLLM configuration"
+
+	errGetOrganization = "cannot get organization"
+	errAddMember       = "cannot add organization member"
+	errUpdateMember    = "cannot update organization member"
+	errDeleteMember    = "cannot delete organization member"
+	errNoUserID        = "cannot determine user_id of added organization member"
+)
+
+// Setup adds a controller that reconciles OrganizationMember managed
+// resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.OrganizationMemberGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.OrganizationMemberGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.OrganizationMemberKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithReferenceResolver(managed.NewAPISimpleReferenceResolver(mgr.GetClient())),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.OrganizationMember{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.OrganizationMemberGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.OrganizationMemberGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied OrganizationMember.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.OrganizationMember); !ok {
+		return nil, errors.New(errNotOrganizationMember)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either adds, updates, or removes the member of
+// a LiteLLM organization to ensure it reflects the managed resource's desired
+// state.
+type external struct {
+	client *litellm.Client
+}
+
+// member is an entry of the members returned by /organization/info.
+type member struct {
+	UserID   string `json:"user_id"`
+	UserRole string `json:"user_role"`
+}
+
+// externalName returns the external name of the supplied membership.
+func externalName(orgID, userID string) string {
+	return orgID + ":" + userID
+}
+
+// parseExternalName returns the organization and user id encoded in the
+// supplied external name.
+func parseExternalName(en string) (orgID, userID string, ok bool) {
+	orgID, userID, ok = strings.Cut(en, ":")
+	return orgID, userID, ok && orgID != "" && userID != ""
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.OrganizationMember)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotOrganizationMember)
+	}
+
+	// The external name defaults to the name of the OrganizationMember
+	// until the membership has been created.
+	orgID, userID, ok := parseExternalName(meta.GetExternalName(cr))
+	if !ok {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var rsp struct {
+		Members []member `json:"members"`
+	}
+	err := c.client.Get(ctx, "/organization/info", url.Values{"organization_id": []string{orgID}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetOrganization)
+	}
+
+	var m *member
+	for i := range rsp.Members {
+		if rsp.Members[i].UserID == userID {
+			m = &rsp.Members[i]
+			break
+		}
+	}
+	if m == nil {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	cr.Status.AtProvider.UserID = m.UserID
+	cr.Status.AtProvider.Role = m.UserRole
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: cr.Spec.ForProvider.Role == m.UserRole,
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.OrganizationMember)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotOrganizationMember)
+	}
+
+	p := cr.Spec.ForProvider
+	m := map[string]interface{}{"role": p.Role}
+	if p.UserID != "" {
+		m["user_id"] = p.UserID
+	}
+	if p.UserEmail != "" {
+		m["user_email"] = p.UserEmail
+	}
+	payload := map[string]interface{}{
+		"organization_id": p.OrganizationID,
+		"member":          []interface{}{m},
+	}
+
+	var rsp struct {
+		UpdatedUsers []struct {
+			UserID string `json:"user_id"`
+		} `json:"updated_users"`
+	}
+	if err := c.client.Post(ctx, "/organization/member_add", payload, &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errAddMember)
+	}
+
+	userID := p.UserID
+	if userID == "" && len(rsp.UpdatedUsers) > 0 {
+		userID = rsp.UpdatedUsers[0].UserID
+	}
+	if userID == "" {
+		return managed.ExternalCreation{}, errors.New(errNoUserID)
+	}
+	meta.SetExternalName(cr, externalName(p.OrganizationID, userID))
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.OrganizationMember)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotOrganizationMember)
+	}
+
+	orgID, userID, _ := parseExternalName(meta.GetExternalName(cr))
+	payload := map[string]interface{}{
+		"organization_id": orgID,
+		"user_id":         userID,
+		"role":            cr.Spec.ForProvider.Role,
+	}
+	if err := c.client.Do(ctx, http.MethodPatch, "/organization/member_update", nil, payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateMember)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.OrganizationMember)
+	if !ok {
+		return errors.New(errNotOrganizationMember)
+	}
+
+	orgID, userID, ok := parseExternalName(meta.GetExternalName(cr))
+	if !ok {
+		return nil
+	}
+	payload := map[string]interface{}{"organization_id": orgID, "user_id": userID}
+	err := c.client.Do(ctx, http.MethodDelete, "/organization/member_delete", nil, payload, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteMember)
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package organizationmember
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/organization/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func organizationMember(en string, p v1alpha1.OrganizationMemberParameters) *v1alpha1.OrganizationMember {
+	cr := &v1alpha1.OrganizationMember{Spec: v1alpha1.OrganizationMemberSpec{ForProvider: p}}
+	meta.SetExternalName(cr, en)
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	info := fake.Response{Body: `{"organization_id": "org-1", "members": [{"user_id": "alice", "user_role": "internal_user"}, {"user_id": "bob", "user_role": "org_admin"}]}`}
+
+	type want struct {
+		o   managed.ExternalObservation
+		obs v1alpha1.OrganizationMemberObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.OrganizationMember
+		want      want
+	}{
+		"NotCreated": {
+			reason:    "An external name that doesn't encode a membership should report the member as absent.",
+			responses: map[string]fake.Response{"/organization/info": info},
+			cr:        organizationMember("bob-admin", v1alpha1.OrganizationMemberParameters{Role: "org_admin"}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"OrganizationNotFound": {
+			reason:    "A membership of a missing organization should be reported as absent.",
+			responses: map[string]fake.Response{},
+			cr:        organizationMember("org-1:bob", v1alpha1.OrganizationMemberParameters{Role: "org_admin"}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"MemberNotFound": {
+			reason:    "A user that isn't a member of the organization should be reported as absent.",
+			responses: map[string]fake.Response{"/organization/info": info},
+			cr:        organizationMember("org-1:carol", v1alpha1.OrganizationMemberParameters{Role: "org_admin"}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A member with the desired role should be up to date.",
+			responses: map[string]fake.Response{"/organization/info": info},
+			cr:        organizationMember("org-1:bob", v1alpha1.OrganizationMemberParameters{Role: "org_admin"}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.OrganizationMemberObservation{UserID: "bob", Role: "org_admin"},
+			},
+		},
+		"RoleDrifted": {
+			reason:    "A member with another role should not be up to date.",
+			responses: map[string]fake.Response{"/organization/info": info},
+			cr:        organizationMember("org-1:alice", v1alpha1.OrganizationMemberParameters{Role: "org_admin"}),
+			want: want{
+				o:   managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.OrganizationMemberObservation{UserID: "alice", Role: "internal_user"},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		p       v1alpha1.OrganizationMemberParameters
+		rsp     string
+		body    map[string]interface{}
+		extName string
+	}{
+		"ByUserID": {
+			reason:  "A member added by user id should be named after the organization and user.",
+			p:       v1alpha1.OrganizationMemberParameters{OrganizationID: "org-1", UserID: "bob", Role: "org_admin"},
+			rsp:     `{"organization_id": "org-1", "updated_users": [{"user_id": "bob"}]}`,
+			body:    map[string]interface{}{"organization_id": "org-1", "member": []interface{}{map[string]interface{}{"user_id": "bob", "role": "org_admin"}}},
+			extName: "org-1:bob",
+		},
+		"ByEmail": {
+			reason:  "A member added by email should be named after the user id LiteLLM returns.",
+			p:       v1alpha1.OrganizationMemberParameters{OrganizationID: "org-1", UserEmail: "bob@example.org", Role: "internal_user"},
+			rsp:     `{"organization_id": "org-1", "updated_users": [{"user_id": "u-123"}]}`,
+			body:    map[string]interface{}{"organization_id": "org-1", "member": []interface{}{map[string]interface{}{"user_email": "bob@example.org", "role": "internal_user"}}},
+			extName: "org-1:u-123",
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/organization/member_add": {Body: tc.rsp}})
+			defer srv.Close()
+
+			cr := organizationMember("bob-admin", tc.p)
+			e := external{client: srv.Client()}
+			if _, err := e.Create(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Create(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.body, srv.Body("/organization/member_add")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.extName, meta.GetExternalName(cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"PATCH /organization/member_update": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if _, err := e.Update(context.Background(), organizationMember("org-1:bob", v1alpha1.OrganizationMemberParameters{Role: "org_admin"})); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{"organization_id": "org-1", "user_id": "bob", "role": "org_admin"}
+	if diff := cmp.Diff(want, srv.Body("/organization/member_update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"DELETE /organization/member_delete": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), organizationMember("org-1:bob", v1alpha1.OrganizationMemberParameters{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"organization_id": "org-1", "user_id": "bob"}
+	if diff := cmp.Diff(want, srv.Body("/organization/member_delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
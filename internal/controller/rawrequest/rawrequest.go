@@ -0,0 +1,258 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rawrequest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/util/jsonpath"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/raw/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotRawRequest = "managed resource is not a RawRequest custom resource"
+	errTrackPCUsage  = "cannot track ProviderConfig usage"
+	errGetConfig     = "cannot get LiteLLM configuration"
+
+	errObserve    = "cannot send observe request"
+	errCreate     = "cannot send create request"
+	errDelete     = "cannot send delete request"
+	errIDJSONPath = "cannot parse idJsonPath"
+	errFindID     = "cannot find id in create response"
+	errNoID       = "create response has no id at %s"
+
+	// idPlaceholder is replaced with the external name in request paths.
+	idPlaceholder = "{id}"
+)
+
+// Setup adds a controller that reconciles RawRequest managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.RawRequestGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.RawRequestGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.RawRequestKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		// The external name is the id returned by the create request, so it
+		// must not default to the name of the RawRequest.
+		managed.WithInitializers(selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.RawRequest{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.RawRequestGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.RawRequestGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied RawRequest.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.RawRequest); !ok {
+		return nil, errors.New(errNotRawRequest)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg)}, nil
+}
+
+// An external sends the requests declared by a RawRequest. It knows nothing
+// about the external resource beyond whether it exists.
+type external struct {
+	client *litellm.Client
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.RawRequest)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotRawRequest)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var rsp json.RawMessage
+	err := c.client.Get(ctx, withID(cr.Spec.ForProvider.ObservePath, id), nil, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errObserve)
+	}
+
+	cr.Status.AtProvider.Response = asObject(rsp)
+	cr.SetConditions(xpv1.Available())
+
+	// Changes to the spec are never applied, so the external resource is
+	// always considered up to date.
+	return managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.RawRequest)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotRawRequest)
+	}
+
+	var rsp interface{}
+	if err := c.send(ctx, cr.Spec.ForProvider.Create, "", &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreate)
+	}
+	id, err := extractID(cr.Spec.ForProvider.IDJSONPath, rsp)
+	if err != nil {
+		return managed.ExternalCreation{}, err
+	}
+	meta.SetExternalName(cr, id)
+
+	return managed.ExternalCreation{}, nil
+}
+
+// Update does nothing. LiteLLM endpoints don't share a way to update
+// resources, so RawRequests can only be created and deleted.
+func (c *external) Update(_ context.Context, _ resource.Managed) (managed.ExternalUpdate, error) {
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.RawRequest)
+	if !ok {
+		return errors.New(errNotRawRequest)
+	}
+
+	if cr.Spec.ForProvider.Delete == nil {
+		return nil
+	}
+	err := c.send(ctx, *cr.Spec.ForProvider.Delete, meta.GetExternalName(cr), nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDelete)
+}
+
+// send sends the supplied request for the external resource with the supplied
+// id and decodes the response into out, if it is not nil.
+func (c *external) send(ctx context.Context, r v1alpha1.Request, id string, out interface{}) error {
+	method := r.Method
+	if method == "" {
+		method = http.MethodPost
+	}
+	var body interface{}
+	if r.Body != nil && len(r.Body.Raw) > 0 {
+		body = json.RawMessage(r.Body.Raw)
+	}
+	return c.client.Do(ctx, method, withID(r.Path, id), nil, body, out)
+}
+
+// withID replaces the id placeholder in the supplied path.
+func withID(path, id string) string {
+	return strings.ReplaceAll(path, idPlaceholder, url.QueryEscape(id))
+}
+
+// extractID returns the value at the supplied JSONPath of the supplied
+// response as a string. The braces around the JSONPath are optional.
+func extractID(path string, rsp interface{}) (string, error) {
+	expr := path
+	if !strings.HasPrefix(expr, "{") {
+		expr = "{" + expr + "}"
+	}
+	jp := jsonpath.New("id")
+	if err := jp.Parse(expr); err != nil {
+		return "", errors.Wrap(err, errIDJSONPath)
+	}
+	results, err := jp.FindResults(rsp)
+	if err != nil {
+		return "", errors.Wrap(err, errFindID)
+	}
+	if len(results) == 0 || len(results[0]) == 0 {
+		return "", errors.Errorf(errNoID, path)
+	}
+	id := fmt.Sprint(results[0][0].Interface())
+	if id == "" {
+		return "", errors.Errorf(errNoID, path)
+	}
+	return id, nil
+}
+
+// asObject returns the supplied JSON response as an object. Responses that
+// aren't objects, e.g. lists, are wrapped in an object as its value field.
+func asObject(rsp json.RawMessage) *runtime.RawExtension {
+	if len(rsp) == 0 {
+		return nil
+	}
+	if trimmed := bytes.TrimSpace(rsp); len(trimmed) > 0 && trimmed[0] == '{' {
+		return &runtime.RawExtension{Raw: trimmed}
+	}
+	return &runtime.RawExtension{Raw: []byte(`{"value":` + string(rsp) + `}`)}
+}
@@ -0,0 +1,206 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rawrequest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/raw/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func rawRequest(id string, p v1alpha1.RawRequestParameters) *v1alpha1.RawRequest {
+	cr := &v1alpha1.RawRequest{Spec: v1alpha1.RawRequestSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
+
+var tag = v1alpha1.RawRequestParameters{
+	Create: v1alpha1.Request{
+		Method: "POST",
+		Path:   "/tag/new",
+		Body:   &runtime.RawExtension{Raw: []byte(`{"name": "prod", "models": ["gpt-4o"]}`)},
+	},
+	IDJSONPath:  "{.tag.name}",
+	ObservePath: "/tag/info?names={id}",
+	Delete: &v1alpha1.Request{
+		Path: "/tag/delete",
+		Body: &runtime.RawExtension{Raw: []byte(`{"name": "prod"}`)},
+	},
+}
+
+func TestCreate(t *testing.T) {
+	type want struct {
+		id   string
+		body map[string]interface{}
+		err  error
+	}
+
+	cases := map[string]struct {
+		reason string
+		rsp    string
+		path   string
+		want   want
+	}{
+		"IDExtracted": {
+			reason: "The declared request should be sent and the id extracted from its response.",
+			rsp:    `{"tag": {"name": "prod"}}`,
+			path:   "{.tag.name}",
+			want: want{
+				id:   "prod",
+				body: map[string]interface{}{"name": "prod", "models": []interface{}{"gpt-4o"}},
+			},
+		},
+		"BracesOptional": {
+			reason: "The braces around the JSONPath should be optional.",
+			rsp:    `{"id": 42}`,
+			path:   ".id",
+			want: want{
+				id:   "42",
+				body: map[string]interface{}{"name": "prod", "models": []interface{}{"gpt-4o"}},
+			},
+		},
+		"NoID": {
+			reason: "A response without an id should be an error.",
+			rsp:    `{"tag": {}}`,
+			path:   "{.tag.name}",
+			want: want{
+				body: map[string]interface{}{"name": "prod", "models": []interface{}{"gpt-4o"}},
+				err:  errors.Wrap(errors.New("name is not found"), errFindID),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"POST /tag/new": {Body: tc.rsp}})
+			defer srv.Close()
+
+			p := tag
+			p.IDJSONPath = tc.path
+			cr := rawRequest("", p)
+			e := external{client: srv.Client()}
+			_, err := e.Create(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.body, srv.Body("/tag/new")); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.id, meta.GetExternalName(cr)); diff != "" {
+				t.Errorf("\n%s\ne.Create(...): -want external name, +got external name:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserve(t *testing.T) {
+	type want struct {
+		o        managed.ExternalObservation
+		response string
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		id        string
+		want      want
+	}{
+		"NotCreated": {
+			reason: "A RawRequest without an external name should not exist.",
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"NotFound": {
+			reason: "A RawRequest whose observe path returns 404 should not exist.",
+			id:     "prod",
+			want:   want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"Exists": {
+			reason:    "A RawRequest whose observe path succeeds should exist and record the response.",
+			responses: map[string]fake.Response{"/tag/info": {Body: `{"prod": {"models": ["gpt-4o"]}}`}},
+			id:        "prod",
+			want: want{
+				o:        managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				response: `{"prod": {"models": ["gpt-4o"]}}`,
+			},
+		},
+		"ListResponse": {
+			reason:    "A response that isn't an object should be recorded as the value of an object.",
+			responses: map[string]fake.Response{"/tag/info": {Body: `[{"name": "prod"}]`}},
+			id:        "prod",
+			want: want{
+				o:        managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				response: `{"value":[{"name": "prod"}]}`,
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			cr := rawRequest(tc.id, tag)
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			var response string
+			if r := cr.Status.AtProvider.Response; r != nil {
+				response = string(r.Raw)
+			}
+			if diff := cmp.Diff(tc.want.response, response); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want response, +got response:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"POST /tag/delete": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), rawRequest("prod", tag)); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"name": "prod"}, srv.Body("/tag/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+
+	p := tag
+	p.Delete = nil
+	if err := e.Delete(context.Background(), rawRequest("prod", p)); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{"/tag/delete"}, srv.Paths()); diff != "" {
+		t.Errorf("e.Delete(...): a RawRequest without a delete request should send none: -want, +got:\n%s", diff)
+	}
+}
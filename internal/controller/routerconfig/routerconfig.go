@@ -0,0 +1,280 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routerconfig
+
+import (
+	"context"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/router/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotRouterConfig = "managed resource is not a RouterConfig custom resource"
+	errTrackPCUsage    = "cannot track ProviderConfig usage"
+	errGetConfig       = "cannot get LiteLLM configuration"
+
+	errGetSettings         = "cannot get router settings"
+	errUpdateSettings      = "cannot update router settings"
+	errListRouterConfigs   = "cannot list RouterConfigs"
+	errManagedByOtherOwner = "router settings of ProviderConfig %q are managed by RouterConfig %q"
+)
+
+// Setup adds a controller that reconciles RouterConfig managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.RouterConfigGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.RouterConfigGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.RouterConfigKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.RouterConfig{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.RouterConfigGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.RouterConfigGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied RouterConfig.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.RouterConfig); !ok {
+		return nil, errors.New(errNotRouterConfig)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{kube: c.kube, client: c.newClientFn(cfg)}, nil
+}
+
+// An external keeps the router settings of a LiteLLM proxy in line with a
+// RouterConfig. The router settings always exist, so there is nothing to
+// create or delete.
+type external struct {
+	kube   client.Client
+	client *litellm.Client
+}
+
+// routerSettings are the router_settings of the proxy config.
+type routerSettings struct {
+	Fallbacks       []map[string][]string `json:"fallbacks,omitempty"`
+	NumRetries      *int                  `json:"num_retries,omitempty"`
+	Timeout         *float64              `json:"timeout,omitempty"`
+	RoutingStrategy string                `json:"routing_strategy,omitempty"`
+	AllowedFails    *int                  `json:"allowed_fails,omitempty"`
+	CooldownTime    *float64              `json:"cooldown_time,omitempty"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.RouterConfig)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotRouterConfig)
+	}
+
+	// Deleting a RouterConfig leaves the router settings as they are, so it
+	// is gone as soon as we're asked about it.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	owner, err := litellm.Owner(ctx, c.kube, cr, &v1alpha1.RouterConfigList{})
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errListRouterConfigs)
+	}
+	if owner != "" && owner != cr.GetName() {
+		return managed.ExternalObservation{}, errors.Errorf(errManagedByOtherOwner, cr.GetProviderConfigReference().Name, owner)
+	}
+
+	var rsp struct {
+		RouterSettings routerSettings `json:"router_settings"`
+	}
+	if err := c.client.Get(ctx, "/get/config/callbacks", nil, &rsp); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetSettings)
+	}
+
+	cr.Status.AtProvider = observation(rsp.RouterSettings)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr.Spec.ForProvider, cr.Status.AtProvider),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	_, err := c.Update(ctx, mg)
+	return managed.ExternalCreation{}, err
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.RouterConfig)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotRouterConfig)
+	}
+
+	// LiteLLM merges the supplied router settings into the existing ones.
+	payload := map[string]interface{}{"router_settings": generateSettings(cr.Spec.ForProvider)}
+	if err := c.client.Post(ctx, "/config/update", payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateSettings)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete does nothing. The router settings are left as they are.
+func (c *external) Delete(_ context.Context, mg resource.Managed) error {
+	if _, ok := mg.(*v1alpha1.RouterConfig); !ok {
+		return errors.New(errNotRouterConfig)
+	}
+	return nil
+}
+
+// observation returns the observation of the supplied router settings.
+func observation(s routerSettings) v1alpha1.RouterConfigObservation {
+	o := v1alpha1.RouterConfigObservation{
+		NumRetries:      s.NumRetries,
+		RoutingStrategy: s.RoutingStrategy,
+		AllowedFails:    s.AllowedFails,
+	}
+	if s.Timeout != nil {
+		t := int(*s.Timeout)
+		o.TimeoutSeconds = &t
+	}
+	if s.CooldownTime != nil {
+		t := int(*s.CooldownTime)
+		o.CooldownSeconds = &t
+	}
+	for _, f := range s.Fallbacks {
+		for model, fallbacks := range f {
+			if o.Fallbacks == nil {
+				o.Fallbacks = map[string][]string{}
+			}
+			o.Fallbacks[model] = fallbacks
+		}
+	}
+	return o
+}
+
+// generateSettings returns the router_settings for the supplied parameters.
+// Settings that are not set are omitted.
+func generateSettings(p v1alpha1.RouterConfigParameters) map[string]interface{} {
+	s := map[string]interface{}{}
+	if p.Fallbacks != nil {
+		// LiteLLM expects a list of single entry maps, so sort them to keep
+		// the payload stable.
+		models := make([]string, 0, len(p.Fallbacks))
+		for m := range p.Fallbacks {
+			models = append(models, m)
+		}
+		sort.Strings(models)
+		fallbacks := make([]map[string][]string, 0, len(models))
+		for _, m := range models {
+			fallbacks = append(fallbacks, map[string][]string{m: p.Fallbacks[m]})
+		}
+		s["fallbacks"] = fallbacks
+	}
+	if p.NumRetries != nil {
+		s["num_retries"] = *p.NumRetries
+	}
+	if p.TimeoutSeconds != nil {
+		s["timeout"] = *p.TimeoutSeconds
+	}
+	if p.RoutingStrategy != "" {
+		s["routing_strategy"] = p.RoutingStrategy
+	}
+	if p.AllowedFails != nil {
+		s["allowed_fails"] = *p.AllowedFails
+	}
+	if p.CooldownSeconds != nil {
+		s["cooldown_time"] = *p.CooldownSeconds
+	}
+	return s
+}
+
+// isUpToDate returns true if the observed router settings match every
+// setting in the supplied parameters. Settings that are not set are not
+// managed.
+func isUpToDate(p v1alpha1.RouterConfigParameters, o v1alpha1.RouterConfigObservation) bool {
+	if p.Fallbacks != nil && !reflect.DeepEqual(p.Fallbacks, o.Fallbacks) {
+		return false
+	}
+	if p.NumRetries != nil && (o.NumRetries == nil || *p.NumRetries != *o.NumRetries) {
+		return false
+	}
+	if p.TimeoutSeconds != nil && (o.TimeoutSeconds == nil || *p.TimeoutSeconds != *o.TimeoutSeconds) {
+		return false
+	}
+	if p.AllowedFails != nil && (o.AllowedFails == nil || *p.AllowedFails != *o.AllowedFails) {
+		return false
+	}
+	if p.CooldownSeconds != nil && (o.CooldownSeconds == nil || *p.CooldownSeconds != *o.CooldownSeconds) {
+		return false
+	}
+	return p.RoutingStrategy == "" || p.RoutingStrategy == o.RoutingStrategy
+}
@@ -0,0 +1,200 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package routerconfig
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/router/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+var created = time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)
+
+func routerConfig(name string, age time.Duration, p v1alpha1.RouterConfigParameters) *v1alpha1.RouterConfig {
+	cr := &v1alpha1.RouterConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.Time{Time: created.Add(-age)}},
+		Spec:       v1alpha1.RouterConfigSpec{ForProvider: p},
+	}
+	cr.SetProviderConfigReference(&xpv1.Reference{Name: "default"})
+	return cr
+}
+
+// kube returns a client that lists the supplied RouterConfigs.
+func kube(rcs ...*v1alpha1.RouterConfig) client.Client {
+	return &test.MockClient{
+		MockList: func(_ context.Context, obj client.ObjectList, _ ...client.ListOption) error {
+			l := obj.(*v1alpha1.RouterConfigList)
+			for _, rc := range rcs {
+				l.Items = append(l.Items, *rc)
+			}
+			return nil
+		},
+	}
+}
+
+func TestObserve(t *testing.T) {
+	retries := 3
+	timeout := 60
+	settings := fake.Response{Body: `{"router_settings": {"routing_strategy": "latency-based-routing", "num_retries": 3, "timeout": 60.0, "fallbacks": [{"gpt-4o": ["gpt-4o-mini"]}], "allowed_fails": 3}}`}
+	desired := v1alpha1.RouterConfigParameters{
+		Fallbacks:       map[string][]string{"gpt-4o": {"gpt-4o-mini"}},
+		NumRetries:      &retries,
+		TimeoutSeconds:  &timeout,
+		RoutingStrategy: "latency-based-routing",
+	}
+	deleted := routerConfig("deleted", time.Hour, desired)
+	deleted.SetDeletionTimestamp(&metav1.Time{Time: created})
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.RouterConfig
+		others    []*v1alpha1.RouterConfig
+		want      want
+	}{
+		"UpToDate": {
+			reason:    "Router settings matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("default", 0, desired),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"UnsetSettingsIgnored": {
+			reason:    "Router settings that are not in the spec should not be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("default", 0, v1alpha1.RouterConfigParameters{NumRetries: &retries}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"StrategyDrifted": {
+			reason:    "A routing strategy that differs from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("default", 0, v1alpha1.RouterConfigParameters{RoutingStrategy: "simple-shuffle"}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"FallbacksDrifted": {
+			reason:    "Fallbacks that differ from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("default", 0, v1alpha1.RouterConfigParameters{Fallbacks: map[string][]string{"gpt-4o": {"claude-3-5-sonnet"}}}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"CooldownDrifted": {
+			reason:    "Cooldown settings that differ from the spec should be reported as drift.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("default", 0, v1alpha1.RouterConfigParameters{AllowedFails: &retries, CooldownSeconds: &timeout}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ManagedByOlder": {
+			reason:    "A RouterConfig should not manage router settings that an older RouterConfig of the same ProviderConfig manages.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("newer", 0, desired),
+			others:    []*v1alpha1.RouterConfig{routerConfig("older", time.Hour, desired)},
+			want:      want{err: errors.Errorf(errManagedByOtherOwner, "default", "older")},
+		},
+		"OlderBeingDeleted": {
+			reason:    "A RouterConfig should take over once the older RouterConfig is being deleted.",
+			responses: map[string]fake.Response{"/get/config/callbacks": settings},
+			cr:        routerConfig("newer", 0, desired),
+			others:    []*v1alpha1.RouterConfig{deleted},
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"Deleted": {
+			reason:    "A deleted RouterConfig should be gone without touching the router settings.",
+			responses: map[string]fake.Response{},
+			cr:        deleted,
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{kube: kube(append(tc.others, tc.cr)...), client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/config/update": {Body: `{}`}})
+	defer srv.Close()
+
+	retries, cooldown := 3, 30
+	cr := routerConfig("default", 0, v1alpha1.RouterConfigParameters{
+		Fallbacks:       map[string][]string{"gpt-4o": {"gpt-4o-mini"}, "claude-3-5-sonnet": {"gpt-4o"}},
+		NumRetries:      &retries,
+		RoutingStrategy: "least-busy",
+		AllowedFails:    &retries,
+		CooldownSeconds: &cooldown,
+	})
+	e := external{kube: kube(cr), client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"router_settings": map[string]interface{}{
+			"fallbacks": []interface{}{
+				map[string]interface{}{"claude-3-5-sonnet": []interface{}{"gpt-4o"}},
+				map[string]interface{}{"gpt-4o": []interface{}{"gpt-4o-mini"}},
+			},
+			"num_retries":      float64(3),
+			"routing_strategy": "least-busy",
+			"allowed_fails":    float64(3),
+			"cooldown_time":    float64(30),
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/config/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{})
+	defer srv.Close()
+
+	e := external{kube: kube(), client: srv.Client()}
+	if err := e.Delete(context.Background(), routerConfig("default", 0, v1alpha1.RouterConfigParameters{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+	if diff := cmp.Diff([]string{}, srv.Paths()); diff != "" {
+		t.Errorf("e.Delete(...): -want requests, +got requests:\n%s", diff)
+	}
+}
@@ -0,0 +1,347 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spendreport
+
+import (
+	"context"
+	"net/url"
+	"sort"
+	"time"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/spendreport/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotSpendReport = "managed resource is not a SpendReport custom resource"
+	errTrackPCUsage   = "cannot track ProviderConfig usage"
+	errGetConfig      = "cannot get LiteLLM configuration"
+
+	errGetSpendReport = "cannot get spend report"
+	errObserveOnly    = "cannot restrict SpendReport to the Observe management policy"
+
+	dateFormat    = "2006-01-02"
+	defaultWindow = 24 * time.Hour
+)
+
+// Setup adds a controller that reconciles SpendReport managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.SpendReportGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.SpendReportGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.SpendReportKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient(), pollInterval)),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector, observeOnly(mgr.GetClient())),
+		managed.WithManagementPolicies(),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.SpendReport{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.SpendReportGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.SpendReportGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// observeOnly returns an initializer that defaults a SpendReport without
+// management policies to the Observe policy, since there is nothing to
+// create, update, or delete in LiteLLM. Policies set by the user are kept;
+// the external client treats those operations as no-ops anyway.
+func observeOnly(kube client.Client) managed.InitializerFn {
+	return func(ctx context.Context, mg resource.Managed) error {
+		if len(mg.GetManagementPolicies()) > 0 {
+			return nil
+		}
+		mg.SetManagementPolicies(xpv1.ManagementPolicies{xpv1.ManagementActionObserve})
+		return errors.Wrap(kube.Update(ctx, mg), errObserveOnly)
+	}
+}
+
+// pollInterval refreshes a SpendReport at its refresh interval, if set.
+func pollInterval(mg resource.Managed, interval time.Duration) time.Duration {
+	cr, ok := mg.(*v1alpha1.SpendReport)
+	if !ok || cr.Spec.ForProvider.RefreshInterval == nil || cr.Spec.ForProvider.RefreshInterval.Duration <= 0 {
+		return interval
+	}
+	return cr.Spec.ForProvider.RefreshInterval.Duration
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied SpendReport.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.SpendReport); !ok {
+		return nil, errors.New(errNotSpendReport)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg), now: time.Now}, nil
+}
+
+// An external refreshes a SpendReport from the LiteLLM spend API. It never
+// mutates anything in LiteLLM.
+type external struct {
+	client *litellm.Client
+	now    func() time.Time
+}
+
+// teamSpendDay is a single day of a /global/spend/report response grouped by
+// team.
+type teamSpendDay struct {
+	GroupByDay string `json:"group_by_day"`
+	Teams      []struct {
+		TeamName   string  `json:"team_name"`
+		TotalSpend float64 `json:"total_spend"`
+	} `json:"teams"`
+}
+
+// keySpend is a single entry of a /global/spend/report response grouped by
+// api_key.
+type keySpend struct {
+	APIKey    string  `json:"api_key"`
+	TotalCost float64 `json:"total_cost"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.SpendReport)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotSpendReport)
+	}
+
+	// There is nothing to delete in LiteLLM, so a deleted SpendReport is gone
+	// as soon as we're asked about it.
+	if meta.WasDeleted(cr) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	window := defaultWindow
+	if cr.Spec.ForProvider.Window != nil && cr.Spec.ForProvider.Window.Duration > 0 {
+		window = cr.Spec.ForProvider.Window.Duration
+	}
+	groupBy := cr.Spec.ForProvider.GroupBy
+	if groupBy == "" {
+		groupBy = v1alpha1.GroupByTeam
+	}
+
+	now := c.now().UTC()
+	start, end := now.Add(-window).Format(dateFormat), now.Format(dateFormat)
+	days, err := c.report(ctx, groupBy, start, end)
+	if err != nil {
+		return managed.ExternalObservation{}, err
+	}
+	spend := map[string]float64{}
+	for _, d := range days {
+		for name, s := range d {
+			spend[name] += s
+		}
+	}
+
+	var periods []v1alpha1.SpendPeriod
+	if n := cr.Spec.ForProvider.Periods; n != nil && *n > 0 {
+		if periods, err = c.periods(ctx, groupBy, now, *n); err != nil {
+			return managed.ExternalObservation{}, err
+		}
+	}
+
+	cr.Status.AtProvider = v1alpha1.SpendReportObservation{
+		StartDate:       start,
+		EndDate:         end,
+		Entries:         toEntries(spend),
+		Periods:         periods,
+		LastRefreshTime: &metav1.Time{Time: now},
+	}
+	cr.Status.AtProvider.TotalSpend = total(cr.Status.AtProvider.Entries)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: true,
+	}, nil
+}
+
+// report returns the spend between the supplied dates keyed by day, then by
+// team or key. LiteLLM doesn't break the spend of keys down by day, so it is
+// returned under the end date.
+func (c *external) report(ctx context.Context, groupBy, start, end string) (map[string]map[string]float64, error) {
+	q := url.Values{
+		"start_date": []string{start},
+		"end_date":   []string{end},
+		"group_by":   []string{groupBy},
+	}
+
+	days := map[string]map[string]float64{}
+	add := func(day, name string, s float64) {
+		if days[day] == nil {
+			days[day] = map[string]float64{}
+		}
+		days[day][name] += s
+	}
+	switch groupBy {
+	case v1alpha1.GroupByAPIKey:
+		var rsp []keySpend
+		if err := c.client.Get(ctx, "/global/spend/report", q, &rsp); err != nil {
+			return nil, errors.Wrap(err, errGetSpendReport)
+		}
+		for _, k := range rsp {
+			add(end, k.APIKey, k.TotalCost)
+		}
+	default:
+		var rsp []teamSpendDay
+		if err := c.client.Get(ctx, "/global/spend/report", q, &rsp); err != nil {
+			return nil, errors.Wrap(err, errGetSpendReport)
+		}
+		for _, d := range rsp {
+			// Some LiteLLM versions return the day as a timestamp.
+			day := d.GroupByDay
+			if len(day) > len(dateFormat) {
+				day = day[:len(dateFormat)]
+			}
+			for _, t := range d.Teams {
+				add(day, t.TeamName, t.TotalSpend)
+			}
+		}
+	}
+	return days, nil
+}
+
+// periods returns the spend of the supplied number of days up to now, newest
+// first. The spend of keys is requested day by day, since LiteLLM doesn't
+// break it down by day.
+func (c *external) periods(ctx context.Context, groupBy string, now time.Time, n int) ([]v1alpha1.SpendPeriod, error) {
+	dates := make([]string, n)
+	for i := range dates {
+		dates[i] = now.AddDate(0, 0, -i).Format(dateFormat)
+	}
+
+	days := map[string]map[string]float64{}
+	switch groupBy {
+	case v1alpha1.GroupByAPIKey:
+		for _, date := range dates {
+			d, err := c.report(ctx, groupBy, date, date)
+			if err != nil {
+				return nil, err
+			}
+			days[date] = d[date]
+		}
+	default:
+		d, err := c.report(ctx, groupBy, dates[n-1], dates[0])
+		if err != nil {
+			return nil, err
+		}
+		days = d
+	}
+
+	periods := make([]v1alpha1.SpendPeriod, 0, n)
+	for _, date := range dates {
+		p := v1alpha1.SpendPeriod{Date: date, Entries: toEntries(days[date])}
+		p.TotalSpend = total(p.Entries)
+		periods = append(periods, p)
+	}
+	return periods, nil
+}
+
+// total returns the sum of the spend of the supplied entries.
+func total(entries []v1alpha1.SpendEntry) float64 {
+	var t float64
+	for _, e := range entries {
+		t += e.Spend
+	}
+	return t
+}
+
+// toEntries returns the supplied spend as entries sorted by name, so that the
+// status doesn't churn between refreshes.
+func toEntries(spend map[string]float64) []v1alpha1.SpendEntry {
+	entries := make([]v1alpha1.SpendEntry, 0, len(spend))
+	for name, s := range spend {
+		entries = append(entries, v1alpha1.SpendEntry{Name: name, Spend: s})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name < entries[j].Name })
+	return entries
+}
+
+// Create is a no-op. A SpendReport always exists.
+func (c *external) Create(_ context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	if _, ok := mg.(*v1alpha1.SpendReport); !ok {
+		return managed.ExternalCreation{}, errors.New(errNotSpendReport)
+	}
+	return managed.ExternalCreation{}, nil
+}
+
+// Update is a no-op. A SpendReport is always up to date.
+func (c *external) Update(_ context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	if _, ok := mg.(*v1alpha1.SpendReport); !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotSpendReport)
+	}
+	return managed.ExternalUpdate{}, nil
+}
+
+// Delete is a no-op. A SpendReport has nothing to delete in LiteLLM.
+func (c *external) Delete(_ context.Context, mg resource.Managed) error {
+	if _, ok := mg.(*v1alpha1.SpendReport); !ok {
+		return errors.New(errNotSpendReport)
+	}
+	return nil
+}
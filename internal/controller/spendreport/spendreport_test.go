@@ -0,0 +1,333 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package spendreport
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/spendreport/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+)
+
+func TestObserve(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+
+	type args struct {
+		cr *v1alpha1.SpendReport
+	}
+
+	type want struct {
+		o     managed.ExternalObservation
+		query string
+		obs   v1alpha1.SpendReportObservation
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason  string
+		handler http.HandlerFunc
+		args    args
+		want    want
+	}{
+		"TeamSpend": {
+			reason: "Spend grouped by team should be summed across days.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`[
+					{"group_by_day": "2024-05-01", "teams": [{"team_name": "ml", "total_spend": 1.5}, {"team_name": "web", "total_spend": 0.25}]},
+					{"group_by_day": "2024-05-02", "teams": [{"team_name": "ml", "total_spend": 2}]}
+				]`))
+			},
+			args: args{
+				cr: &v1alpha1.SpendReport{},
+			},
+			want: want{
+				o:     managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				query: "end_date=2024-05-02&group_by=team&start_date=2024-05-01",
+				obs: v1alpha1.SpendReportObservation{
+					StartDate:  "2024-05-01",
+					EndDate:    "2024-05-02",
+					TotalSpend: 3.75,
+					Entries: []v1alpha1.SpendEntry{
+						{Name: "ml", Spend: 3.5},
+						{Name: "web", Spend: 0.25},
+					},
+					LastRefreshTime: &metav1.Time{Time: now},
+				},
+			},
+		},
+		"KeySpend": {
+			reason: "Spend grouped by api_key over a configured window should be reported per key.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`[{"api_key": "88dc28", "total_cost": 0.5}, {"api_key": "12ab34", "total_cost": 1}]`))
+			},
+			args: args{
+				cr: &v1alpha1.SpendReport{
+					Spec: v1alpha1.SpendReportSpec{
+						ForProvider: v1alpha1.SpendReportParameters{
+							GroupBy: v1alpha1.GroupByAPIKey,
+							Window:  &metav1.Duration{Duration: 7 * 24 * time.Hour},
+						},
+					},
+				},
+			},
+			want: want{
+				o:     managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				query: "end_date=2024-05-02&group_by=api_key&start_date=2024-04-25",
+				obs: v1alpha1.SpendReportObservation{
+					StartDate:  "2024-04-25",
+					EndDate:    "2024-05-02",
+					TotalSpend: 1.5,
+					Entries: []v1alpha1.SpendEntry{
+						{Name: "12ab34", Spend: 1},
+						{Name: "88dc28", Spend: 0.5},
+					},
+					LastRefreshTime: &metav1.Time{Time: now},
+				},
+			},
+		},
+		"Deleted": {
+			reason: "A deleted SpendReport should not exist, without calling LiteLLM.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			args: args{
+				cr: &v1alpha1.SpendReport{ObjectMeta: metav1.ObjectMeta{DeletionTimestamp: &metav1.Time{Time: now}}},
+			},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: false},
+			},
+		},
+		"ServerError": {
+			reason: "Errors from the spend endpoint should be returned.",
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			args: args{
+				cr: &v1alpha1.SpendReport{},
+			},
+			want: want{
+				query: "end_date=2024-05-02&group_by=team&start_date=2024-05-01",
+				err:   errors.Wrap(errors.New("GET /global/spend/report returned unexpected status 500: "), errGetSpendReport),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var query string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path != "/global/spend/report" {
+					t.Errorf("unexpected request path %q", r.URL.Path)
+				}
+				query = r.URL.RawQuery
+				tc.handler(w, r)
+			}))
+			defer srv.Close()
+
+			e := external{
+				client: litellm.NewClient(&litellm.Config{APIBase: srv.URL, APIKey: "sk-test"}),
+				now:    func() time.Time { return now },
+			}
+			got, err := e.Observe(context.Background(), tc.args.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.query, query); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want query, +got query:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, tc.args.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObservePeriods(t *testing.T) {
+	now := time.Date(2024, 5, 2, 12, 0, 0, 0, time.UTC)
+	periods := 2
+
+	cases := map[string]struct {
+		reason  string
+		groupBy string
+		handler http.HandlerFunc
+		queries []string
+		want    []v1alpha1.SpendPeriod
+	}{
+		"TeamSpend": {
+			reason:  "Daily spend grouped by team should be split by the days LiteLLM returns, newest first.",
+			groupBy: v1alpha1.GroupByTeam,
+			handler: func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(`[
+					{"group_by_day": "2024-05-01T00:00:00", "teams": [{"team_name": "ml", "total_spend": 1.5}, {"team_name": "web", "total_spend": 0.25}]},
+					{"group_by_day": "2024-05-02T00:00:00", "teams": [{"team_name": "ml", "total_spend": 2}]}
+				]`))
+			},
+			queries: []string{
+				"end_date=2024-05-02&group_by=team&start_date=2024-05-01",
+				"end_date=2024-05-02&group_by=team&start_date=2024-05-01",
+			},
+			want: []v1alpha1.SpendPeriod{
+				{Date: "2024-05-02", TotalSpend: 2, Entries: []v1alpha1.SpendEntry{{Name: "ml", Spend: 2}}},
+				{Date: "2024-05-01", TotalSpend: 1.75, Entries: []v1alpha1.SpendEntry{{Name: "ml", Spend: 1.5}, {Name: "web", Spend: 0.25}}},
+			},
+		},
+		"KeySpend": {
+			reason:  "Daily spend grouped by api_key should be requested day by day.",
+			groupBy: v1alpha1.GroupByAPIKey,
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Query().Get("start_date") == "2024-05-01" && r.URL.Query().Get("end_date") == "2024-05-01" {
+					_, _ = w.Write([]byte(`[]`))
+					return
+				}
+				_, _ = w.Write([]byte(`[{"api_key": "88dc28", "total_cost": 0.5}]`))
+			},
+			queries: []string{
+				"end_date=2024-05-02&group_by=api_key&start_date=2024-05-01",
+				"end_date=2024-05-02&group_by=api_key&start_date=2024-05-02",
+				"end_date=2024-05-01&group_by=api_key&start_date=2024-05-01",
+			},
+			want: []v1alpha1.SpendPeriod{
+				{Date: "2024-05-02", TotalSpend: 0.5, Entries: []v1alpha1.SpendEntry{{Name: "88dc28", Spend: 0.5}}},
+				{Date: "2024-05-01", Entries: []v1alpha1.SpendEntry{}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			var queries []string
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				queries = append(queries, r.URL.RawQuery)
+				tc.handler(w, r)
+			}))
+			defer srv.Close()
+
+			cr := &v1alpha1.SpendReport{Spec: v1alpha1.SpendReportSpec{ForProvider: v1alpha1.SpendReportParameters{
+				GroupBy: tc.groupBy,
+				Periods: &periods,
+			}}}
+			e := external{
+				client: litellm.NewClient(&litellm.Config{APIBase: srv.URL, APIKey: "sk-test"}),
+				now:    func() time.Time { return now },
+			}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("e.Observe(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.queries, queries); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want queries, +got queries:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want, cr.Status.AtProvider.Periods); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want periods, +got periods:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestPollInterval(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		refresh *metav1.Duration
+		want    time.Duration
+	}{
+		"Unset": {
+			reason: "A SpendReport without a refresh interval should be refreshed at the poll interval.",
+			want:   time.Minute,
+		},
+		"Set": {
+			reason:  "A SpendReport with a refresh interval should be refreshed at it.",
+			refresh: &metav1.Duration{Duration: time.Hour},
+			want:    time.Hour,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.SpendReport{Spec: v1alpha1.SpendReportSpec{ForProvider: v1alpha1.SpendReportParameters{RefreshInterval: tc.refresh}}}
+			if diff := cmp.Diff(tc.want, pollInterval(cr, time.Minute)); diff != "" {
+				t.Errorf("\n%s\npollInterval(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveOnly(t *testing.T) {
+	observe := xpv1.ManagementPolicies{xpv1.ManagementActionObserve}
+	all := xpv1.ManagementPolicies{xpv1.ManagementActionAll}
+
+	type want struct {
+		policies xpv1.ManagementPolicies
+		updated  bool
+	}
+
+	cases := map[string]struct {
+		reason   string
+		policies xpv1.ManagementPolicies
+		want     want
+	}{
+		"Unset": {
+			reason: "A SpendReport without policies should default to Observe.",
+			want:   want{policies: observe, updated: true},
+		},
+		"All": {
+			reason:   "A SpendReport whose policies were set should keep them.",
+			policies: all,
+			want:     want{policies: all},
+		},
+		"ObserveOnly": {
+			reason:   "A SpendReport that is already restricted to Observe should not be updated.",
+			policies: observe,
+			want:     want{policies: observe},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			updated := false
+			kube := &test.MockClient{MockUpdate: func(_ context.Context, _ client.Object, _ ...client.UpdateOption) error {
+				updated = true
+				return nil
+			}}
+			cr := &v1alpha1.SpendReport{}
+			cr.SetManagementPolicies(tc.policies)
+			if err := observeOnly(kube).Initialize(context.Background(), cr); err != nil {
+				t.Fatalf("Initialize(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want.policies, cr.GetManagementPolicies()); diff != "" {
+				t.Errorf("\n%s\nInitialize(...): -want policies, +got policies:\n%s\n", tc.reason, diff)
+			}
+			if updated != tc.want.updated {
+				t.Errorf("\n%s\nInitialize(...): want updated %t, got %t", tc.reason, tc.want.updated, updated)
+			}
+		})
+	}
+}
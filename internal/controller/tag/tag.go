@@ -0,0 +1,267 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/tag/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotTag       = "managed resource is not a Tag custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetTag    = "cannot get tag"
+	errCreateTag = "cannot create tag"
+	errUpdateTag = "cannot update tag"
+	errDeleteTag = "cannot delete tag"
+)
+
+// Setup adds a controller that reconciles Tag managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.TagGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.TagGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.TagKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.Tag{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.TagGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.TagGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied Tag.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.Tag); !ok {
+		return nil, errors.New(errNotTag)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg)}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// tag to ensure it reflects the managed resource's desired state.
+type external struct {
+	client *litellm.Client
+}
+
+// tagInfo is a tag as returned by /tag/info.
+type tagInfo struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Models      []string `json:"models"`
+	Spend       float64  `json:"spend"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+// name returns the LiteLLM name of the supplied Tag.
+func name(cr *v1alpha1.Tag) string {
+	if n := cr.Spec.ForProvider.Name; n != "" {
+		return n
+	}
+	return meta.GetExternalName(cr)
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.Tag)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotTag)
+	}
+
+	n := name(cr)
+	if n == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	// /tag/info looks up several tags at once and returns them keyed by name.
+	var rsp map[string]json.RawMessage
+	err := c.client.Post(ctx, "/tag/info", map[string]interface{}{"names": []string{n}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetTag)
+	}
+	raw, ok := rsp[n]
+	if !ok || litellm.IsNull(raw) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	info := tagInfo{}
+	if err := litellm.Decode(ctx, raw, &info, litellm.RenamedSpend); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetTag)
+	}
+	if info.Name == "" {
+		info.Name = n
+	}
+
+	setObservation(&cr.Status.AtProvider, &info)
+	cr.SetConditions(xpv1.Available())
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr.Spec.ForProvider, &info),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.Tag)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotTag)
+	}
+
+	n := name(cr)
+	// A tag that already exists is adopted, and updated on the next
+	// reconcile if it differs.
+	if err := c.client.Post(ctx, "/tag/new", generatePayload(n, cr.Spec.ForProvider), nil); err != nil && !litellm.IsConflict(err) {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateTag)
+	}
+	meta.SetExternalName(cr, n)
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.Tag)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotTag)
+	}
+
+	if err := c.client.Post(ctx, "/tag/update", generatePayload(name(cr), cr.Spec.ForProvider), nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateTag)
+	}
+
+	return managed.ExternalUpdate{}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.Tag)
+	if !ok {
+		return errors.New(errNotTag)
+	}
+
+	err := c.client.Post(ctx, "/tag/delete", map[string]interface{}{"name": name(cr)}, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteTag)
+}
+
+// setObservation updates the supplied observation with what LiteLLM returned.
+func setObservation(o *v1alpha1.TagObservation, info *tagInfo) {
+	o.Name = info.Name
+	o.Description = info.Description
+	o.Models = info.Models
+	o.Spend = info.Spend
+	o.CreatedAt = parseTime(info.CreatedAt)
+	o.UpdatedAt = parseTime(info.UpdatedAt)
+}
+
+// parseTime returns the supplied LiteLLM timestamp, or nil if it is empty or
+// can't be parsed.
+func parseTime(s string) *metav1.Time {
+	t, err := litellm.ParseTime(s)
+	if err != nil {
+		return nil
+	}
+	return &metav1.Time{Time: t}
+}
+
+// generatePayload returns the /tag/new and /tag/update payload for the
+// supplied parameters.
+func generatePayload(name string, p v1alpha1.TagParameters) map[string]interface{} {
+	payload := map[string]interface{}{"name": name}
+	if p.Description != "" {
+		payload["description"] = p.Description
+	}
+	if p.Models != nil {
+		payload["models"] = p.Models
+	}
+	return payload
+}
+
+// isUpToDate returns true if the observed tag matches every field set in the
+// supplied parameters. Fields that are not set are not managed.
+func isUpToDate(p v1alpha1.TagParameters, o *tagInfo) bool {
+	if p.Description != "" && p.Description != o.Description {
+		return false
+	}
+	if p.Models != nil && !litellm.SameStrings(p.Models, o.Models) {
+		return false
+	}
+	return true
+}
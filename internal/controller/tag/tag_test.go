@@ -0,0 +1,232 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tag
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/tag/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func tag(name string, p v1alpha1.TagParameters) *v1alpha1.Tag {
+	cr := &v1alpha1.Tag{Spec: v1alpha1.TagSpec{ForProvider: p}}
+	meta.SetExternalName(cr, name)
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	info := fake.Response{Body: `{"marketing": {
+		"name": "marketing",
+		"description": "Marketing team traffic",
+		"models": ["gpt-4o", "claude-sonnet"],
+		"spend": 12.5,
+		"created_at": "2024-05-01T10:00:00Z"
+	}}`}
+
+	type want struct {
+		o   managed.ExternalObservation
+		obs v1alpha1.TagObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		p         v1alpha1.TagParameters
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A tag LiteLLM doesn't know should be reported as absent.",
+			responses: map[string]fake.Response{},
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A tag matching the spec should be up to date, and its spend reported.",
+			responses: map[string]fake.Response{"/tag/info": info},
+			p:         v1alpha1.TagParameters{Description: "Marketing team traffic", Models: []string{"claude-sonnet", "gpt-4o"}},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true},
+				obs: v1alpha1.TagObservation{
+					Name:        "marketing",
+					Description: "Marketing team traffic",
+					Models:      []string{"gpt-4o", "claude-sonnet"},
+					Spend:       12.5,
+					CreatedAt:   parseTime("2024-05-01T10:00:00Z"),
+				},
+			},
+		},
+		"ModelsDrifted": {
+			reason:    "A tag whose models differ from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/tag/info": info},
+			p:         v1alpha1.TagParameters{Models: []string{"gpt-4o"}},
+			want: want{
+				o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false},
+				obs: v1alpha1.TagObservation{
+					Name:        "marketing",
+					Description: "Marketing team traffic",
+					Models:      []string{"gpt-4o", "claude-sonnet"},
+					Spend:       12.5,
+					CreatedAt:   parseTime("2024-05-01T10:00:00Z"),
+				},
+			},
+		},
+		"Error": {
+			reason:    "Errors getting the tag should be returned.",
+			responses: map[string]fake.Response{"/tag/info": {Status: http.StatusInternalServerError, Body: "boom"}},
+			want: want{
+				err: errors.Wrap(errors.New("POST /tag/info returned unexpected status 500: boom"), errGetTag),
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			cr := tag("marketing", tc.p)
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want observation, +got observation:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/tag/new": {Body: `{"message": "Tag marketing-eu created successfully"}`}})
+	defer srv.Close()
+
+	cr := tag("marketing", v1alpha1.TagParameters{Name: "marketing-eu", Description: "EU marketing", Models: []string{"gpt-4o"}})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"name":        "marketing-eu",
+		"description": "EU marketing",
+		"models":      []interface{}{"gpt-4o"},
+	}
+	if diff := cmp.Diff(want, srv.Body("/tag/new")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("marketing-eu", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+}
+
+func TestCreateConflict(t *testing.T) {
+	cases := map[string]struct {
+		reason  string
+		rsp     fake.Response
+		wantErr bool
+	}{
+		"AlreadyExists": {
+			reason: "A tag that already exists should be adopted.",
+			rsp:    fake.Response{Status: http.StatusBadRequest, Body: `{"error": {"message": "Tag marketing-eu already exists", "type": "bad_request_error", "param": "name"}}`},
+		},
+		"Invalid": {
+			reason:  "Any other bad request should be an error.",
+			rsp:     fake.Response{Status: http.StatusBadRequest, Body: `{"error": "invalid model gpt-5"}`},
+			wantErr: true,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/tag/new": tc.rsp})
+			defer srv.Close()
+
+			cr := tag("marketing", v1alpha1.TagParameters{Name: "marketing-eu"})
+			e := external{client: srv.Client()}
+			_, err := e.Create(context.Background(), cr)
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("\n%s\ne.Create(...): want error %t, got %v", tc.reason, tc.wantErr, err)
+			}
+			if err == nil && meta.GetExternalName(cr) != "marketing-eu" {
+				t.Errorf("\n%s\ne.Create(...): want external name marketing-eu, got %q", tc.reason, meta.GetExternalName(cr))
+			}
+		})
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/tag/update": {Body: `{"message": "Tag marketing updated successfully"}`}})
+	defer srv.Close()
+
+	cr := tag("marketing", v1alpha1.TagParameters{Description: "Marketing team traffic"})
+	e := external{client: srv.Client()}
+	if _, err := e.Update(context.Background(), cr); err != nil {
+		t.Fatalf("e.Update(...): %v", err)
+	}
+
+	want := map[string]interface{}{"name": "marketing", "description": "Marketing team traffic"}
+	if diff := cmp.Diff(want, srv.Body("/tag/update")); diff != "" {
+		t.Errorf("e.Update(...): -want body, +got body:\n%s", diff)
+	}
+}
+
+func TestDelete(t *testing.T) {
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		want      error
+	}{
+		"Deleted": {
+			reason:    "Deleting a tag should succeed.",
+			responses: map[string]fake.Response{"/tag/delete": {Body: `{"message": "Tag marketing deleted successfully"}`}},
+		},
+		"NotFound": {
+			reason:    "Deleting a tag that is already gone should succeed.",
+			responses: map[string]fake.Response{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			err := e.Delete(context.Background(), tag("marketing", v1alpha1.TagParameters{}))
+			if diff := cmp.Diff(tc.want, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(map[string]interface{}{"name": "marketing"}, srv.Body("/tag/delete")); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
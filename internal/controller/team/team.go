@@ -18,43 +18,53 @@ package team
 
 import (
 	"context"
-	"fmt"
+	"encoding/json"
+	"net/url"
+	"strconv"
 
 	"github.com/pkg/errors"
-	"k8s.io/apimachinery/pkg/types"
+	corev1 "k8s.io/api/core/v1"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
 	"github.com/crossplane/crossplane-runtime/pkg/connection"
 	"github.com/crossplane/crossplane-runtime/pkg/controller"
 	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
 	"github.com/crossplane/crossplane-runtime/pkg/resource"
 
 	"github.com/crossplane/provider-litellm/apis/team/v1alpha1"
 	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
 	"github.com/crossplane/provider-litellm/internal/features"
 )
 
 const (
-	errNotTeam    = "managed resource is not a Team custom resource"
+	errNotTeam      = "managed resource is not a Team custom resource"
 	errTrackPCUsage = "cannot track ProviderConfig usage"
-	errGetPC        = "cannot get ProviderConfig"
-	errGetCreds     = "cannot get credentials"
-
-	errNewClient = "cannot create new Service"
-)
-
-// A NoOpService does nothing.
-type NoOpService struct{}
-
-var (
-	newNoOpService = func(_ []byte) (interface{}, error) { return &NoOpService{}, nil }
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetTeam     = "cannot get team"
+	errCreateTeam  = "cannot create team"
+	errUpdateTeam  = "cannot update team"
+	errDeleteTeam  = "cannot delete team"
+	errBlockTeam   = "cannot block team"
+	errUnblockTeam = "cannot unblock team"
+	errListKeys    = "cannot list keys of team"
+	errDeleteKeys  = "cannot delete keys of team"
+	errTeamHasKeys = "team has %d active keys"
+
+	// keyPageSize is the number of keys requested per /key/list page.
+	keyPageSize = 100
 )
 
 // Setup adds a controller that reconciles Team managed resources.
-func Setup(mgr ctrl.Manager, o controller.Options) error {
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
 	name := managed.ControllerName(v1alpha1.TeamGroupKind)
 
 	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
@@ -62,31 +72,36 @@ func Setup(mgr ctrl.Manager, o controller.Options) error {
 		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
 	}
 
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
 	r := managed.NewReconciler(mgr,
 		resource.ManagedKind(v1alpha1.TeamGroupVersionKind),
-		managed.WithExternalConnecter(&connector{
-			kube:         mgr.GetClient(),
-			usage:        resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
-			newServiceFn: newNoOpService}),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.TeamKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithReferenceResolver(litellm.NewReferenceResolver(mgr.GetClient())),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
 		managed.WithLogger(o.Logger.WithValues("controller", name)),
 		managed.WithPollInterval(o.PollInterval),
-		managed.WithRecorder(event.NewAPIRecorder(mgr.GetEventRecorderFor(name))),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
 		managed.WithConnectionPublishers(cps...))
 
 	return ctrl.NewControllerManagedBy(mgr).
 		Named(name).
 		WithOptions(o.ForControllerRuntime()).
-		WithEventFilter(resource.DesiredStateChanged()).
-		For(&v1alpha1.Team{}).
-		Complete(ratelimiter.NewReconciler(name, r, o.GlobalRateLimiter))
+		For(&v1alpha1.Team{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.TeamGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.TeamGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
 }
 
 // A connector is expected to produce an ExternalClient when its Connect method
 // is called.
 type connector struct {
-	kube         client.Client
-	usage        resource.Tracker
-	newServiceFn func(creds []byte) (interface{}, error)
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
 }
 
 // Connect typically produces an ExternalClient by:
@@ -95,8 +110,7 @@ type connector struct {
 // 3. Getting the credentials specified by the ProviderConfig.
 // 4. Using the credentials to form a client.
 func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
-	cr, ok := mg.(*v1alpha1.Team)
-	if !ok {
+	if _, ok := mg.(*v1alpha1.Team); !ok {
 		return nil, errors.New(errNotTeam)
 	}
 
@@ -104,31 +118,47 @@ func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.E
 		return nil, errors.Wrap(err, errTrackPCUsage)
 	}
 
-	pc := &apisv1alpha1.ProviderConfig{}
-	if err := c.kube.Get(ctx, types.NamespacedName{Name: cr.GetProviderConfigReference().Name}, pc); err != nil {
-		return nil, errors.Wrap(err, errGetPC)
-	}
-
-	cd := pc.Spec.Credentials
-	data, err := resource.CommonCredentialExtractor(ctx, cd.Source, c.kube, cd.CommonCredentialSelectors)
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
 	if err != nil {
-		return nil, errors.Wrap(err, errGetCreds)
+		return nil, errors.Wrap(err, errGetConfig)
 	}
 
-	svc, err := c.newServiceFn(data)
-	if err != nil {
-		return nil, errors.Wrap(err, errNewClient)
-	}
-
-	return &external{service: svc}, nil
+	return &external{
+		client:      c.newClientFn(cfg),
+		forceDelete: cfg.ProviderConfig.Spec.ForceDeleteTeams,
+	}, nil
 }
 
 // An ExternalClient observes, then either creates, updates, or deletes an
 // external resource to ensure it reflects the managed resource's desired state.
 type external struct {
-	// A 'client' used to connect to the external resource API. In practice this
-	// would be something like an AWS SDK client.
-	service interface{}
+	client *litellm.Client
+
+	// observed is the team as last observed, if it was observed before an
+	// update.
+	observed *teamInfo
+
+	// forceDelete deletes the keys of a team rather than waiting for them
+	// to be deleted.
+	forceDelete bool
+}
+
+// teamInfo is the team_info object returned by /team/info.
+type teamInfo struct {
+	TeamID           string                     `json:"team_id"`
+	TeamAlias        string                     `json:"team_alias"`
+	Models           []string                   `json:"models"`
+	MaxBudget        *float64                   `json:"max_budget"`
+	BudgetDuration   string                     `json:"budget_duration"`
+	TPMLimit         *int64                     `json:"tpm_limit"`
+	RPMLimit         *int64                     `json:"rpm_limit"`
+	Metadata         map[string]interface{}     `json:"metadata"`
+	Spend            float64                    `json:"spend"`
+	Blocked          bool                       `json:"blocked"`
+	ObjectPermission map[string]json.RawMessage `json:"object_permission"`
+	Members          []struct {
+		UserID string `json:"user_id"`
+	} `json:"members_with_roles"`
 }
 
 func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
@@ -137,23 +167,47 @@ func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.Ex
 		return managed.ExternalObservation{}, errors.New(errNotTeam)
 	}
 
-	// These fmt statements should be removed in the real implementation.
-	fmt.Printf("Observing: %+v", cr)
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var rsp struct {
+		TeamInfo json.RawMessage `json:"team_info"`
+	}
+	err := c.client.Get(ctx, "/team/info", url.Values{"team_id": []string{id}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetTeam)
+	}
+	if litellm.IsNull(rsp.TeamInfo) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	info := &teamInfo{}
+	if err := litellm.Decode(ctx, rsp.TeamInfo, info, litellm.RenamedSpend); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetTeam)
+	}
+
+	// Access groups may come back expanded into their models.
+	if m := cr.Spec.ForProvider.Models; m != nil {
+		same, err := c.client.SameModels(ctx, m, info.Models)
+		if err != nil {
+			return managed.ExternalObservation{}, errors.Wrap(err, errGetTeam)
+		}
+		if same {
+			info.Models = m
+		}
+	}
+
+	setObservation(&cr.Status.AtProvider, info)
+	cr.SetConditions(xpv1.Available())
+	c.observed = info
 
 	return managed.ExternalObservation{
-		// Return false when the external resource does not exist. This lets
-		// the managed resource reconciler know that it needs to call Create to
-		// (re)create the resource, or that it has successfully been deleted.
-		ResourceExists: true,
-
-		// Return false when the external resource exists, but it not up to date
-		// with the desired managed resource state. This lets the managed
-		// resource reconciler know that it needs to call Update.
-		ResourceUpToDate: true,
-
-		// Return any details that may be required to connect to the external
-		// resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr.Spec.ForProvider, info),
 	}, nil
 }
 
@@ -163,13 +217,21 @@ func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalCreation{}, errors.New(errNotTeam)
 	}
 
-	fmt.Printf("Creating: %+v", cr)
+	payload := generatePayload(meta.GetExternalName(cr), cr.Spec.ForProvider)
+	if b := cr.Spec.ForProvider.Blocked; b != nil && *b {
+		payload["blocked"] = true
+	}
+	var rsp struct {
+		TeamID string `json:"team_id"`
+	}
+	if err := c.client.Post(ctx, "/team/new", payload, &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateTeam)
+	}
+	if rsp.TeamID != "" {
+		meta.SetExternalName(cr, rsp.TeamID)
+	}
 
-	return managed.ExternalCreation{
-		// Optionally return any details that may be required to connect to the
-		// external resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
-	}, nil
+	return managed.ExternalCreation{}, nil
 }
 
 func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
@@ -178,22 +240,186 @@ func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.Ext
 		return managed.ExternalUpdate{}, errors.New(errNotTeam)
 	}
 
-	fmt.Printf("Updating: %+v", cr)
+	id := meta.GetExternalName(cr)
+	payload := generatePayload(id, cr.Spec.ForProvider)
+	// LiteLLM replaces the whole metadata object on update, so merge ours
+	// into what the server has to keep the keys that aren't managed.
+	if md := cr.Spec.ForProvider.Metadata; md != nil && c.observed != nil {
+		desired := make(map[string]interface{}, len(md))
+		for k, v := range md {
+			desired[k] = v
+		}
+		merged := litellm.MergeMetadata(c.observed.Metadata, desired)
+		// The guardrails are stored in the metadata too, so don't send
+		// the observed ones back if the spec manages them.
+		if g := cr.Spec.ForProvider.Guardrails; g != nil {
+			merged["guardrails"] = g
+		}
+		payload["metadata"] = merged
+	}
+	if err := c.client.Post(ctx, "/team/update", payload, nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateTeam)
+	}
 
-	return managed.ExternalUpdate{
-		// Optionally return any details that may be required to connect to the
-		// external resource. These will be stored as the connection secret.
-		ConnectionDetails: managed.ConnectionDetails{},
-	}, nil
+	// Like for customers, blocking has dedicated endpoints. They are only
+	// called if the team isn't blocked or unblocked already.
+	b := cr.Spec.ForProvider.Blocked
+	if b == nil || *b == cr.Status.AtProvider.Blocked {
+		return managed.ExternalUpdate{}, nil
+	}
+	payload = map[string]interface{}{"team_id": id}
+	if *b {
+		if err := c.client.Post(ctx, "/team/block", payload, nil); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errBlockTeam)
+		}
+	} else {
+		if err := c.client.Post(ctx, "/team/unblock", payload, nil); err != nil {
+			return managed.ExternalUpdate{}, errors.Wrap(err, errUnblockTeam)
+		}
+	}
+	cr.Status.AtProvider.Blocked = *b
+
+	return managed.ExternalUpdate{}, nil
 }
 
+// Delete deletes the team once it has no keys left. LiteLLM fails to delete
+// teams whose keys still exist, so until they are gone Delete returns an
+// error and is retried. If forced, the keys are deleted first.
 func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
 	cr, ok := mg.(*v1alpha1.Team)
 	if !ok {
 		return errors.New(errNotTeam)
 	}
 
-	fmt.Printf("Deleting: %+v", cr)
+	id := meta.GetExternalName(cr)
+	keys, err := c.listKeys(ctx, id)
+	if err != nil {
+		return errors.Wrap(err, errListKeys)
+	}
+	if len(keys) > 0 && !c.forceDelete {
+		return errors.Errorf(errTeamHasKeys, len(keys))
+	}
+	if len(keys) > 0 {
+		err := c.client.Post(ctx, "/key/delete", map[string]interface{}{"keys": keys}, nil)
+		if err != nil && !litellm.IsNotFound(err) {
+			return errors.Wrap(err, errDeleteKeys)
+		}
+	}
+
+	err = c.client.Post(ctx, "/team/delete", map[string]interface{}{"team_ids": []string{id}}, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteTeam)
+}
+
+// listKeys returns the tokens of all keys of the supplied team.
+func (c *external) listKeys(ctx context.Context, teamID string) ([]string, error) {
+	tokens := []string{}
+	for page := 1; ; page++ {
+		var rsp struct {
+			Keys []struct {
+				Token string `json:"token"`
+			} `json:"keys"`
+			TotalPages int `json:"total_pages"`
+		}
+		q := url.Values{
+			"team_id":            []string{teamID},
+			"return_full_object": []string{"true"},
+			"page":               []string{strconv.Itoa(page)},
+			"size":               []string{strconv.Itoa(keyPageSize)},
+		}
+		if err := c.client.Get(ctx, "/key/list", q, &rsp); err != nil {
+			return nil, err
+		}
+		for _, k := range rsp.Keys {
+			tokens = append(tokens, k.Token)
+		}
+		if page >= rsp.TotalPages {
+			return tokens, nil
+		}
+	}
+}
+
+// setObservation sets the supplied observation from the observed team.
+func setObservation(o *v1alpha1.TeamObservation, info *teamInfo) {
+	o.TeamID = info.TeamID
+	o.SpendUSD = info.Spend
+	o.MemberCount = len(info.Members)
+	o.Blocked = info.Blocked
+}
+
+// generatePayload returns the /team/new and /team/update payload for the
+// supplied parameters.
+func generatePayload(id string, p v1alpha1.TeamParameters) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if id != "" {
+		payload["team_id"] = id
+	}
+	if p.TeamAlias != "" {
+		payload["team_alias"] = p.TeamAlias
+	}
+	if p.Models != nil {
+		payload["models"] = p.Models
+	}
+	if p.MaxBudget != nil {
+		payload["max_budget"] = *p.MaxBudget
+	}
+	if p.BudgetDuration != "" {
+		payload["budget_duration"] = p.BudgetDuration
+	}
+	if p.TPMLimit != nil {
+		payload["tpm_limit"] = *p.TPMLimit
+	}
+	if p.RPMLimit != nil {
+		payload["rpm_limit"] = *p.RPMLimit
+	}
+	if p.Metadata != nil {
+		payload["metadata"] = p.Metadata
+	}
+	if p.Guardrails != nil {
+		payload["guardrails"] = p.Guardrails
+	}
+	if op := p.ObjectPermission; op != nil {
+		payload["object_permission"] = litellm.ObjectPermission(op.VectorStores, op.MCPServers, op.MCPAccessGroups)
+	}
+	return payload
+}
 
-	return nil
+// isUpToDate returns true if the observed team matches every field set in
+// the supplied parameters. Fields that are not set are not managed.
+func isUpToDate(p v1alpha1.TeamParameters, o *teamInfo) bool { //nolint:gocyclo // Flat field-by-field comparison.
+	if p.Blocked != nil && *p.Blocked != o.Blocked {
+		return false
+	}
+	if p.TeamAlias != "" && p.TeamAlias != o.TeamAlias {
+		return false
+	}
+	if p.Models != nil && !litellm.SameStrings(p.Models, o.Models) {
+		return false
+	}
+	if p.MaxBudget != nil && (o.MaxBudget == nil || *p.MaxBudget != *o.MaxBudget) {
+		return false
+	}
+	if p.BudgetDuration != "" && p.BudgetDuration != o.BudgetDuration {
+		return false
+	}
+	if p.TPMLimit != nil && (o.TPMLimit == nil || *p.TPMLimit != *o.TPMLimit) {
+		return false
+	}
+	if p.RPMLimit != nil && (o.RPMLimit == nil || *p.RPMLimit != *o.RPMLimit) {
+		return false
+	}
+	if p.Guardrails != nil && !litellm.SameStrings(p.Guardrails, litellm.Guardrails(o.Metadata)) {
+		return false
+	}
+	if op := p.ObjectPermission; op != nil && !litellm.SameObjectPermission(litellm.ObjectPermission(op.VectorStores, op.MCPServers, op.MCPAccessGroups), o.ObjectPermission) {
+		return false
+	}
+	for k, v := range p.Metadata {
+		if s, ok := o.Metadata[k].(string); !ok || s != v {
+			return false
+		}
+	}
+	return true
 }
@@ -18,13 +18,18 @@ package team
 
 import (
 	"context"
+	"fmt"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
 
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
 	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
-	"github.com/crossplane/crossplane-runtime/pkg/resource"
 	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/team/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
 )
 
 // Unlike many Kubernetes projects Crossplane does not use third party testing
@@ -35,15 +40,15 @@ import (
 // https://github.com/golang/go/wiki/TestComments
 // https://github.com/crossplane/crossplane/blob/master/CONTRIBUTING.md#contributing-code
 
-func TestObserve(t *testing.T) {
-	type fields struct {
-		service interface{}
-	}
+func team(id string, p v1alpha1.TeamParameters) *v1alpha1.Team {
+	cr := &v1alpha1.Team{Spec: v1alpha1.TeamSpec{ForProvider: p}}
+	meta.SetExternalName(cr, id)
+	return cr
+}
 
-	type args struct {
-		ctx context.Context
-		mg  resource.Managed
-	}
+func TestObserve(t *testing.T) {
+	budget := 100.0
+	tpm := int64(1000)
 
 	type want struct {
 		o   managed.ExternalObservation
@@ -51,18 +56,80 @@ func TestObserve(t *testing.T) {
 	}
 
 	cases := map[string]struct {
-		reason string
-		fields fields
-		args   args
-		want   want
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.Team
+		want      want
 	}{
-		// TODO: Add test cases.
+		"NotFound": {
+			reason:    "A 404 from /team/info should report the team as absent.",
+			responses: map[string]fake.Response{},
+			cr:        team("ml", v1alpha1.TeamParameters{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A team matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "team_alias": "ML", "models": ["b", "a"], "max_budget": 100, "tpm_limit": 1000, "metadata": {"owner": "jo", "extra": 1}}}`}},
+			cr: team("ml", v1alpha1.TeamParameters{
+				TeamAlias: "ML",
+				Models:    []string{"a", "b"},
+				MaxBudget: &budget,
+				TPMLimit:  &tpm,
+				Metadata:  map[string]string{"owner": "jo"},
+			}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"Drifted": {
+			reason:    "A team whose TPM limit differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "tpm_limit": 10}}`}},
+			cr:        team("ml", v1alpha1.TeamParameters{TPMLimit: &tpm}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"GuardrailsDrifted": {
+			reason:    "A team whose metadata lists different guardrails than the spec should not be up to date.",
+			responses: map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "metadata": {"guardrails": ["pii"]}}}`}},
+			cr:        team("ml", v1alpha1.TeamParameters{Guardrails: []string{"pii", "toxicity"}}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ObjectPermissionDrifted": {
+			reason:    "A team missing a desired MCP server should not be up to date.",
+			responses: map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "object_permission": {"mcp_servers": ["github"], "vector_stores": null}}}`}},
+			cr:        team("ml", v1alpha1.TeamParameters{ObjectPermission: &v1alpha1.ObjectPermission{MCPServers: []string{"github", "jira"}}}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"ObjectPermissionUpToDate": {
+			reason:    "Lists of the object permission that are not in the spec should not be managed.",
+			responses: map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "object_permission": {"mcp_servers": ["jira", "github"], "vector_stores": ["docs"]}}}`}},
+			cr:        team("ml", v1alpha1.TeamParameters{ObjectPermission: &v1alpha1.ObjectPermission{MCPServers: []string{"github", "jira"}}}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"AccessGroupExpanded": {
+			reason: "A team whose access group was expanded into its models should be up to date.",
+			responses: map[string]fake.Response{
+				"/team/info":  {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "models": ["gpt-4o", "claude-sonnet"]}}`},
+				"/model/info": {Body: `{"data": [{"model_name": "gpt-4o", "model_info": {"access_groups": ["prod-models"]}}, {"model_name": "claude-sonnet", "model_info": {"access_groups": ["prod-models"]}}]}`},
+			},
+			cr:   team("ml", v1alpha1.TeamParameters{Models: []string{"prod-models"}}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"ModelsDrifted": {
+			reason: "A team missing a model of its access group should not be up to date.",
+			responses: map[string]fake.Response{
+				"/team/info":  {Body: `{"team_id": "ml", "team_info": {"team_id": "ml", "models": ["gpt-4o"]}}`},
+				"/model/info": {Body: `{"data": [{"model_name": "gpt-4o", "model_info": {"access_groups": ["prod-models"]}}, {"model_name": "claude-sonnet", "model_info": {"access_groups": ["prod-models"]}}]}`},
+			},
+			cr:   team("ml", v1alpha1.TeamParameters{Models: []string{"prod-models"}}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
 	}
 
 	for name, tc := range cases {
 		t.Run(name, func(t *testing.T) {
-			e := external{service: tc.fields.service}
-			got, err := e.Observe(tc.args.ctx, tc.args.mg)
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
 			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
 				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
 			}
@@ -72,3 +139,256 @@ func TestObserve(t *testing.T) {
 		})
 	}
 }
+
+func TestObserveStatus(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/team/info": {Body: `{"team_id": "ml", "team_info": {
+		"team_id": "ml",
+		"team_alias": "ML",
+		"spend": 12.75,
+		"blocked": true,
+		"members_with_roles": [{"user_id": "alice", "role": "admin"}, {"user_id": "bob", "role": "user"}]
+	}}`}})
+	defer srv.Close()
+
+	cr := team("ml", v1alpha1.TeamParameters{TeamAlias: "ML"})
+	e := external{client: srv.Client()}
+	if _, err := e.Observe(context.Background(), cr); err != nil {
+		t.Fatalf("e.Observe(...): %v", err)
+	}
+
+	want := v1alpha1.TeamObservation{
+		TeamID:      "ml",
+		SpendUSD:    12.75,
+		MemberCount: 2,
+		Blocked:     true,
+	}
+	if diff := cmp.Diff(want, cr.Status.AtProvider); diff != "" {
+		t.Errorf("e.Observe(...): -want status, +got status:\n%s", diff)
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/team/new": {Body: `{"team_id": "ml"}`}})
+	defer srv.Close()
+
+	cr := team("ml", v1alpha1.TeamParameters{TeamAlias: "ML", Models: []string{"gpt-4o"}, Guardrails: []string{"pii"}, ObjectPermission: &v1alpha1.ObjectPermission{VectorStores: []string{"docs"}}})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"team_id":    "ml",
+		"team_alias": "ML",
+		"models":     []interface{}{"gpt-4o"},
+		"guardrails": []interface{}{"pii"},
+		"object_permission": map[string]interface{}{
+			"vector_stores": []interface{}{"docs"},
+		},
+	}
+	if diff := cmp.Diff(want, srv.Body("/team/new")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("ml", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+}
+
+func TestBlock(t *testing.T) {
+	yes, no := true, false
+
+	type want struct {
+		upToDate bool
+		paths    []string
+		blocked  bool
+	}
+
+	cases := map[string]struct {
+		reason  string
+		blocked bool
+		desired *bool
+		want    want
+	}{
+		"Block": {
+			reason:  "An unblocked team that should be blocked should be blocked.",
+			blocked: false,
+			desired: &yes,
+			want:    want{upToDate: false, paths: []string{"/team/info", "/team/update", "/team/block"}, blocked: true},
+		},
+		"Unblock": {
+			reason:  "A blocked team that should be unblocked should be unblocked.",
+			blocked: true,
+			desired: &no,
+			want:    want{upToDate: false, paths: []string{"/team/info", "/team/update", "/team/unblock"}, blocked: false},
+		},
+		"AlreadyBlocked": {
+			reason:  "A blocked team that should be blocked should not be blocked again.",
+			blocked: true,
+			desired: &yes,
+			want:    want{upToDate: true, paths: []string{"/team/info", "/team/update"}, blocked: true},
+		},
+		"Unmanaged": {
+			reason:  "A blocked team whose blocked state isn't managed should be left alone.",
+			blocked: true,
+			want:    want{upToDate: true, paths: []string{"/team/info", "/team/update"}, blocked: true},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/team/info":    {Body: fmt.Sprintf(`{"team_id": "ml", "team_info": {"team_id": "ml", "blocked": %t}}`, tc.blocked)},
+				"/team/update":  {Body: `{}`},
+				"/team/block":   {Body: `{}`},
+				"/team/unblock": {Body: `{}`},
+			})
+			defer srv.Close()
+
+			cr := team("ml", v1alpha1.TeamParameters{Blocked: tc.desired})
+			e := external{client: srv.Client()}
+			o, err := e.Observe(context.Background(), cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.upToDate, o.ResourceUpToDate); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want up to date, +got up to date:\n%s\n", tc.reason, diff)
+			}
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want requests, +got requests:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.blocked, cr.Status.AtProvider.Blocked); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want blocked, +got blocked:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestUpdateMetadata(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		info   string
+		p      v1alpha1.TeamParameters
+		want   map[string]interface{}
+	}{
+		"ServerMetadataKept": {
+			reason: "Metadata set on the team outside the spec should survive an update.",
+			info:   `{"team_id": "ml", "team_info": {"team_id": "ml", "metadata": {"owner": "web", "cost_center": "42"}}}`,
+			p:      v1alpha1.TeamParameters{Metadata: map[string]string{"owner": "ml"}},
+			want: map[string]interface{}{
+				"team_id":  "ml",
+				"metadata": map[string]interface{}{"owner": "ml", "cost_center": "42"},
+			},
+		},
+		"GuardrailsKept": {
+			reason: "The guardrails of a team whose guardrails aren't managed should survive an update of its metadata.",
+			info:   `{"team_id": "ml", "team_info": {"team_id": "ml", "metadata": {"owner": "web", "guardrails": ["pii"]}}}`,
+			p:      v1alpha1.TeamParameters{Metadata: map[string]string{"owner": "ml"}},
+			want: map[string]interface{}{
+				"team_id":  "ml",
+				"metadata": map[string]interface{}{"owner": "ml", "guardrails": []interface{}{"pii"}},
+			},
+		},
+		"GuardrailsManaged": {
+			reason: "The observed guardrails should not be sent back in the metadata if the spec manages them.",
+			info:   `{"team_id": "ml", "team_info": {"team_id": "ml", "metadata": {"owner": "web", "guardrails": ["pii"]}}}`,
+			p:      v1alpha1.TeamParameters{Metadata: map[string]string{"owner": "ml"}, Guardrails: []string{"toxicity"}},
+			want: map[string]interface{}{
+				"team_id":    "ml",
+				"guardrails": []interface{}{"toxicity"},
+				"metadata":   map[string]interface{}{"owner": "ml", "guardrails": []interface{}{"toxicity"}},
+			},
+		},
+		"Unmanaged": {
+			reason: "A team whose metadata isn't managed should not have its metadata sent.",
+			info:   `{"team_id": "ml", "team_info": {"team_id": "ml", "metadata": {"owner": "web"}}}`,
+			p:      v1alpha1.TeamParameters{TeamAlias: "ML"},
+			want:   map[string]interface{}{"team_id": "ml", "team_alias": "ML"},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/team/info":   {Body: tc.info},
+				"/team/update": {Body: `{}`},
+			})
+			defer srv.Close()
+
+			cr := team("ml", tc.p)
+			e := external{client: srv.Client()}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if _, err := e.Update(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want, srv.Body("/team/update")); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	keys := fake.Response{Body: `{"keys": [{"token": "hashed-1"}, {"token": "hashed-2"}], "total_count": 2, "current_page": 1, "total_pages": 1}`}
+
+	type want struct {
+		paths []string
+		keys  map[string]interface{}
+		err   error
+	}
+
+	cases := map[string]struct {
+		reason      string
+		forceDelete bool
+		responses   map[string]fake.Response
+		want        want
+	}{
+		"NoKeys": {
+			reason:    "A team without keys should be deleted.",
+			responses: map[string]fake.Response{"/key/list": {Body: `{"keys": [], "total_pages": 0}`}},
+			want:      want{paths: []string{"/key/list", "/team/delete"}},
+		},
+		"Blocked": {
+			reason:    "A team with keys should not be deleted until its keys are gone.",
+			responses: map[string]fake.Response{"/key/list": keys},
+			want: want{
+				paths: []string{"/key/list"},
+				err:   errors.Errorf(errTeamHasKeys, 2),
+			},
+		},
+		"Forced": {
+			reason:      "A team with keys should be deleted along with its keys when forced.",
+			forceDelete: true,
+			responses:   map[string]fake.Response{"/key/list": keys},
+			want: want{
+				paths: []string{"/key/list", "/key/delete", "/team/delete"},
+				keys:  map[string]interface{}{"keys": []interface{}{"hashed-1", "hashed-2"}},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			tc.responses["/key/delete"] = fake.Response{Body: `{}`}
+			tc.responses["/team/delete"] = fake.Response{Body: `{}`}
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client(), forceDelete: tc.forceDelete}
+			err := e.Delete(context.Background(), team("ml", v1alpha1.TeamParameters{}))
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.keys, srv.Body("/key/delete")); diff != "" {
+				t.Errorf("\n%s\ne.Delete(...): -want body, +got body:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
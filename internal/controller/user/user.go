@@ -0,0 +1,363 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/connection"
+	"github.com/crossplane/crossplane-runtime/pkg/controller"
+	"github.com/crossplane/crossplane-runtime/pkg/event"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/ratelimiter"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/resource"
+
+	"github.com/crossplane/provider-litellm/apis/user/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+	"github.com/crossplane/provider-litellm/internal/features"
+)
+
+const (
+	errNotUser      = "managed resource is not a User custom resource"
+	errTrackPCUsage = "cannot track ProviderConfig usage"
+	errGetConfig    = "cannot get LiteLLM configuration"
+
+	errGetUser    = "cannot get user"
+	errCreateUser = "cannot create user"
+	errUpdateUser = "cannot update user"
+	errDeleteUser = "cannot delete user"
+	errInvite     = "cannot create invitation"
+
+	// Connection detail keys.
+	keyInvitationURL     = "invitation_url"
+	keyInvitationExpires = "invitation_expires"
+)
+
+// Setup adds a controller that reconciles User managed resources.
+func Setup(mgr ctrl.Manager, o controller.Options, selector *litellm.ProviderConfigSelector) error {
+	name := managed.ControllerName(v1alpha1.UserGroupKind)
+
+	cps := []managed.ConnectionPublisher{managed.NewAPISecretPublisher(mgr.GetClient(), mgr.GetScheme())}
+	if o.Features.Enabled(features.EnableAlphaExternalSecretStores) {
+		cps = append(cps, connection.NewDetailsManager(mgr.GetClient(), apisv1alpha1.StoreConfigGroupVersionKind))
+	}
+
+	recorder := event.NewAPIRecorder(mgr.GetEventRecorderFor(name))
+
+	r := managed.NewReconciler(mgr,
+		resource.ManagedKind(v1alpha1.UserGroupVersionKind),
+		managed.WithExternalConnecter(litellm.NewRejectionConnecter(litellm.NewSyncTimeConnecter(litellm.NewMetricsConnecter(v1alpha1.UserKind, &connector{
+			kube:        mgr.GetClient(),
+			usage:       resource.NewProviderConfigUsageTracker(mgr.GetClient(), &apisv1alpha1.ProviderConfigUsage{}),
+			newClientFn: litellm.NewClient})))),
+		managed.WithInitializers(managed.NewNameAsExternalName(mgr.GetClient()), selector),
+		managed.WithLogger(o.Logger.WithValues("controller", name)),
+		managed.WithPollInterval(o.PollInterval),
+		managed.WithPollIntervalHook(litellm.PollIntervalHook(mgr.GetClient())),
+		managed.WithRecorder(recorder),
+		managed.WithConnectionPublishers(cps...))
+
+	return ctrl.NewControllerManagedBy(mgr).
+		Named(name).
+		WithOptions(o.ForControllerRuntime()).
+		For(&v1alpha1.User{}, builder.WithPredicates(resource.DesiredStateChanged())).
+		Watches(&corev1.Secret{}, handler.EnqueueRequestsFromMapFunc(litellm.ManagedForSecret(mgr.GetClient(), resource.ManagedKind(v1alpha1.UserGroupVersionKind)))).
+		Complete(ratelimiter.NewReconciler(name, litellm.NewBreakerReconciler(mgr.GetClient(), recorder, resource.ManagedKind(v1alpha1.UserGroupVersionKind), litellm.NewRejectionReconciler(r)), o.GlobalRateLimiter))
+}
+
+// A connector is expected to produce an ExternalClient when its Connect method
+// is called.
+type connector struct {
+	kube        client.Client
+	usage       resource.Tracker
+	newClientFn func(cfg *litellm.Config) *litellm.Client
+}
+
+// Connect produces an ExternalClient for the ProviderConfig referenced by the
+// supplied User.
+func (c *connector) Connect(ctx context.Context, mg resource.Managed) (managed.ExternalClient, error) {
+	if _, ok := mg.(*v1alpha1.User); !ok {
+		return nil, errors.New(errNotUser)
+	}
+
+	if err := c.usage.Track(ctx, mg); err != nil {
+		return nil, errors.Wrap(err, errTrackPCUsage)
+	}
+
+	cfg, err := litellm.GetConfig(ctx, c.kube, mg)
+	if err != nil {
+		return nil, errors.Wrap(err, errGetConfig)
+	}
+
+	return &external{client: c.newClientFn(cfg), apiBase: cfg.APIBase}, nil
+}
+
+// An external observes, then either creates, updates, or deletes a LiteLLM
+// user to ensure it reflects the managed resource's desired state.
+type external struct {
+	client  *litellm.Client
+	apiBase string
+}
+
+// userInfo is the user_info object returned by /user/info.
+type userInfo struct {
+	UserID         string                 `json:"user_id"`
+	UserEmail      string                 `json:"user_email"`
+	UserAlias      string                 `json:"user_alias"`
+	SSOUserID      string                 `json:"sso_user_id"`
+	UserRole       string                 `json:"user_role"`
+	Models         []string               `json:"models"`
+	MaxBudget      *float64               `json:"max_budget"`
+	BudgetDuration string                 `json:"budget_duration"`
+	Metadata       map[string]interface{} `json:"metadata"`
+	Spend          float64                `json:"spend"`
+	Teams          []string               `json:"teams"`
+	CreatedAt      string                 `json:"created_at"`
+}
+
+// invitation is returned by /invitation/new.
+type invitation struct {
+	ID        string `json:"id"`
+	ExpiresAt string `json:"expires_at"`
+}
+
+func (c *external) Observe(ctx context.Context, mg resource.Managed) (managed.ExternalObservation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalObservation{}, errors.New(errNotUser)
+	}
+
+	id := meta.GetExternalName(cr)
+	if id == "" {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+
+	var rsp struct {
+		UserInfo json.RawMessage   `json:"user_info"`
+		Keys     []json.RawMessage `json:"keys"`
+	}
+	err := c.client.Get(ctx, "/user/info", url.Values{"user_id": []string{id}}, &rsp)
+	if litellm.IsNotFound(err) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	if err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetUser)
+	}
+	// Older LiteLLM versions answer with an empty user_info rather than a 404.
+	if litellm.IsNull(rsp.UserInfo) {
+		return managed.ExternalObservation{ResourceExists: false}, nil
+	}
+	info := &userInfo{}
+	if err := litellm.Decode(ctx, rsp.UserInfo, info, litellm.RenamedSpend); err != nil {
+		return managed.ExternalObservation{}, errors.Wrap(err, errGetUser)
+	}
+
+	setObservation(&cr.Status.AtProvider, info, len(rsp.Keys))
+	cr.SetConditions(xpv1.Available(), budgetCondition(cr.Status.AtProvider))
+
+	return managed.ExternalObservation{
+		ResourceExists:   true,
+		ResourceUpToDate: isUpToDate(cr.Spec.ForProvider, info) && !invitationPending(cr),
+	}, nil
+}
+
+func (c *external) Create(ctx context.Context, mg resource.Managed) (managed.ExternalCreation, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalCreation{}, errors.New(errNotUser)
+	}
+
+	payload := generatePayload(meta.GetExternalName(cr), cr.Spec.ForProvider)
+	// Keys are managed through Key resources, so don't let LiteLLM mint one
+	// we would never track.
+	payload["auto_create_key"] = false
+
+	var rsp struct {
+		UserID string `json:"user_id"`
+	}
+	if err := c.client.Post(ctx, "/user/new", payload, &rsp); err != nil {
+		return managed.ExternalCreation{}, errors.Wrap(err, errCreateUser)
+	}
+	if rsp.UserID != "" {
+		meta.SetExternalName(cr, rsp.UserID)
+	}
+
+	return managed.ExternalCreation{}, nil
+}
+
+func (c *external) Update(ctx context.Context, mg resource.Managed) (managed.ExternalUpdate, error) {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return managed.ExternalUpdate{}, errors.New(errNotUser)
+	}
+
+	id := meta.GetExternalName(cr)
+	if err := c.client.Post(ctx, "/user/update", generatePayload(id, cr.Spec.ForProvider), nil); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errUpdateUser)
+	}
+
+	if !invitationPending(cr) {
+		return managed.ExternalUpdate{}, nil
+	}
+
+	inv := invitation{}
+	if err := c.client.Post(ctx, "/invitation/new", map[string]interface{}{"user_id": id}, &inv); err != nil {
+		return managed.ExternalUpdate{}, errors.Wrap(err, errInvite)
+	}
+
+	cr.Status.AtProvider.InvitationID = inv.ID
+	cr.Status.AtProvider.InvitationSerial = cr.Spec.ForProvider.InvitationSerial
+	cr.Status.AtProvider.InvitationExpiresAt = nil
+	if t, err := litellm.ParseTime(inv.ExpiresAt); err == nil {
+		cr.Status.AtProvider.InvitationExpiresAt = &metav1.Time{Time: t}
+	}
+
+	return managed.ExternalUpdate{
+		ConnectionDetails: managed.ConnectionDetails{
+			keyInvitationURL:     []byte(c.apiBase + "/ui?invitation_id=" + url.QueryEscape(inv.ID)),
+			keyInvitationExpires: []byte(inv.ExpiresAt),
+		},
+	}, nil
+}
+
+func (c *external) Delete(ctx context.Context, mg resource.Managed) error {
+	cr, ok := mg.(*v1alpha1.User)
+	if !ok {
+		return errors.New(errNotUser)
+	}
+
+	payload := map[string]interface{}{"user_ids": []string{meta.GetExternalName(cr)}}
+	err := c.client.Post(ctx, "/user/delete", payload, nil)
+	if litellm.IsNotFound(err) {
+		return nil
+	}
+	return errors.Wrap(err, errDeleteUser)
+}
+
+// setObservation updates the supplied observation with what LiteLLM returned.
+// The invitation fields are left untouched; they are only known to Update.
+func setObservation(o *v1alpha1.UserObservation, info *userInfo, keys int) {
+	o.UserID = info.UserID
+	o.UserRole = info.UserRole
+	o.Spend = info.Spend
+	o.MaxBudget = info.MaxBudget
+	o.KeyCount = keys
+	o.Teams = info.Teams
+	o.CreatedAt = nil
+	if t, err := litellm.ParseTime(info.CreatedAt); err == nil {
+		o.CreatedAt = &metav1.Time{Time: t}
+	}
+}
+
+// budgetCondition returns whether the observed user has reached its budget.
+func budgetCondition(o v1alpha1.UserObservation) xpv1.Condition {
+	if o.MaxBudget != nil && o.Spend >= *o.MaxBudget {
+		return apisv1alpha1.BudgetExceeded(fmt.Sprintf("spend of %.2f USD has reached the max budget of %.2f USD", o.Spend, *o.MaxBudget))
+	}
+	return apisv1alpha1.WithinBudget()
+}
+
+// invitationPending returns true if an invitation has been requested but not
+// yet created for the current invitation serial.
+func invitationPending(cr *v1alpha1.User) bool {
+	if !cr.Spec.ForProvider.SendInvite {
+		return false
+	}
+	return cr.Status.AtProvider.InvitationID == "" || cr.Status.AtProvider.InvitationSerial != cr.Spec.ForProvider.InvitationSerial
+}
+
+// generatePayload returns the /user/new and /user/update payload for the
+// supplied parameters.
+func generatePayload(id string, p v1alpha1.UserParameters) map[string]interface{} {
+	payload := map[string]interface{}{}
+	if id != "" {
+		payload["user_id"] = id
+	}
+	if p.UserEmail != "" {
+		payload["user_email"] = p.UserEmail
+	}
+	if p.UserAlias != "" {
+		payload["user_alias"] = p.UserAlias
+	}
+	if p.SSOUserID != "" {
+		payload["sso_user_id"] = p.SSOUserID
+	}
+	if p.UserRole != "" {
+		payload["user_role"] = p.UserRole
+	}
+	if p.Models != nil {
+		payload["models"] = p.Models
+	}
+	if p.MaxBudget != nil {
+		payload["max_budget"] = *p.MaxBudget
+	}
+	if p.BudgetDuration != "" {
+		payload["budget_duration"] = p.BudgetDuration
+	}
+	if p.Metadata != nil {
+		payload["metadata"] = p.Metadata
+	}
+	return payload
+}
+
+// isUpToDate returns true if the observed user matches every field set in
+// the supplied parameters. Fields that are not set are not managed.
+func isUpToDate(p v1alpha1.UserParameters, o *userInfo) bool { //nolint:gocyclo // Flat field-by-field comparison.
+	// The proxy may store emails lowercased.
+	if p.UserEmail != "" && !strings.EqualFold(p.UserEmail, o.UserEmail) {
+		return false
+	}
+	if p.UserAlias != "" && p.UserAlias != o.UserAlias {
+		return false
+	}
+	if p.SSOUserID != "" && p.SSOUserID != o.SSOUserID {
+		return false
+	}
+	if p.UserRole != "" && p.UserRole != o.UserRole {
+		return false
+	}
+	if p.Models != nil && !litellm.SameStrings(p.Models, o.Models) {
+		return false
+	}
+	if p.MaxBudget != nil && (o.MaxBudget == nil || *p.MaxBudget != *o.MaxBudget) {
+		return false
+	}
+	if p.BudgetDuration != "" && p.BudgetDuration != o.BudgetDuration {
+		return false
+	}
+	for k, v := range p.Metadata {
+		if s, ok := o.Metadata[k].(string); !ok || s != v {
+			return false
+		}
+	}
+	return true
+}
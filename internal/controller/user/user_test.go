@@ -0,0 +1,311 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package user
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+	"github.com/crossplane/crossplane-runtime/pkg/reconciler/managed"
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/user/v1alpha1"
+	apisv1alpha1 "github.com/crossplane/provider-litellm/apis/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func user(name string, p v1alpha1.UserParameters, o v1alpha1.UserObservation) *v1alpha1.User {
+	cr := &v1alpha1.User{
+		Spec:   v1alpha1.UserSpec{ForProvider: p},
+		Status: v1alpha1.UserStatus{AtProvider: o},
+	}
+	meta.SetExternalName(cr, name)
+	return cr
+}
+
+func TestObserve(t *testing.T) {
+	budget := 10.0
+
+	type want struct {
+		o   managed.ExternalObservation
+		err error
+	}
+
+	cases := map[string]struct {
+		reason    string
+		responses map[string]fake.Response
+		cr        *v1alpha1.User
+		want      want
+	}{
+		"NotFound": {
+			reason:    "A 404 from /user/info should report the user as absent.",
+			responses: map[string]fake.Response{},
+			cr:        user("alice", v1alpha1.UserParameters{}, v1alpha1.UserObservation{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"EmptyUserInfo": {
+			reason:    "An empty user_info should report the user as absent.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_id": "alice", "user_info": null}`}},
+			cr:        user("alice", v1alpha1.UserParameters{}, v1alpha1.UserObservation{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: false}},
+		},
+		"UpToDate": {
+			reason:    "A user matching the spec should be up to date.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice", "user_email": "alice@example.org", "max_budget": 10, "models": ["b", "a"], "metadata": {"team": "ml", "other": {"x": 1}}}}`}},
+			cr: user("alice", v1alpha1.UserParameters{
+				UserEmail: "alice@example.org",
+				MaxBudget: &budget,
+				Models:    []string{"a", "b"},
+				Metadata:  map[string]string{"team": "ml"},
+			}, v1alpha1.UserObservation{}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"EmailCaseNormalized": {
+			reason:    "An email the proxy stored lowercased should still be up to date.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice", "user_email": "alice@example.org", "sso_user_id": "okta|123", "user_alias": "Alice"}}`}},
+			cr: user("alice", v1alpha1.UserParameters{
+				UserEmail: "Alice@Example.org",
+				SSOUserID: "okta|123",
+				UserAlias: "Alice",
+			}, v1alpha1.UserObservation{}),
+			want: want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+		"SSOUserIDDrifted": {
+			reason:    "A user whose sso_user_id differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice", "sso_user_id": "okta|999"}}`}},
+			cr:        user("alice", v1alpha1.UserParameters{SSOUserID: "okta|123"}, v1alpha1.UserObservation{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"Drifted": {
+			reason:    "A user whose budget differs from the spec should not be up to date.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice", "max_budget": 5}}`}},
+			cr:        user("alice", v1alpha1.UserParameters{MaxBudget: &budget}, v1alpha1.UserObservation{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"InvitationPending": {
+			reason:    "A user that should be invited but has no invitation should not be up to date.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice"}}`}},
+			cr:        user("alice", v1alpha1.UserParameters{SendInvite: true}, v1alpha1.UserObservation{}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"InvitationSerialBumped": {
+			reason:    "Bumping the invitation serial should request a new invitation.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice"}}`}},
+			cr:        user("alice", v1alpha1.UserParameters{SendInvite: true, InvitationSerial: 2}, v1alpha1.UserObservation{InvitationID: "inv-1", InvitationSerial: 1}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: false}},
+		},
+		"InvitationSent": {
+			reason:    "A user with an invitation for the current serial should be up to date.",
+			responses: map[string]fake.Response{"/user/info": {Body: `{"user_info": {"user_id": "alice"}}`}},
+			cr:        user("alice", v1alpha1.UserParameters{SendInvite: true, InvitationSerial: 1}, v1alpha1.UserObservation{InvitationID: "inv-1", InvitationSerial: 1}),
+			want:      want{o: managed.ExternalObservation{ResourceExists: true, ResourceUpToDate: true}},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(tc.responses)
+			defer srv.Close()
+
+			e := external{client: srv.Client()}
+			got, err := e.Observe(context.Background(), tc.cr)
+			if diff := cmp.Diff(tc.want.err, err, test.EquateErrors()); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want error, +got error:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.o, got); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestObserveStatus(t *testing.T) {
+	budget := 10.0
+	created := time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC)
+
+	cases := map[string]struct {
+		reason string
+		body   string
+		obs    v1alpha1.UserObservation
+		budget corev1.ConditionStatus
+	}{
+		"WithinBudget": {
+			reason: "Spend, budget, keys, teams and creation time should be reported from /user/info.",
+			body:   `{"user_info": {"user_id": "alice", "user_role": "internal_user", "spend": 2.5, "max_budget": 10, "teams": ["ml"], "created_at": "2024-04-01T09:30:00"}, "keys": [{"token": "a"}, {"token": "b"}]}`,
+			obs: v1alpha1.UserObservation{
+				UserID:    "alice",
+				UserRole:  "internal_user",
+				Spend:     2.5,
+				MaxBudget: &budget,
+				KeyCount:  2,
+				Teams:     []string{"ml"},
+				CreatedAt: &metav1.Time{Time: created},
+			},
+			budget: corev1.ConditionFalse,
+		},
+		"BudgetExceeded": {
+			reason: "A user whose spend has reached its budget should have the BudgetExceeded condition.",
+			body:   `{"user_info": {"user_id": "alice", "spend": 10, "max_budget": 10}, "keys": []}`,
+			obs: v1alpha1.UserObservation{
+				UserID:    "alice",
+				Spend:     10,
+				MaxBudget: &budget,
+			},
+			budget: corev1.ConditionTrue,
+		},
+		"NoBudget": {
+			reason: "A user without a budget can't exceed it.",
+			body:   `{"user_info": {"user_id": "alice", "spend": 100}}`,
+			obs: v1alpha1.UserObservation{
+				UserID: "alice",
+				Spend:  100,
+			},
+			budget: corev1.ConditionFalse,
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{"/user/info": {Body: tc.body}})
+			defer srv.Close()
+
+			cr := user("alice", v1alpha1.UserParameters{}, v1alpha1.UserObservation{})
+			e := external{client: srv.Client()}
+			if _, err := e.Observe(context.Background(), cr); err != nil {
+				t.Fatalf("\n%s\ne.Observe(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.obs, cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.budget, cr.GetCondition(apisv1alpha1.TypeBudgetExceeded).Status); diff != "" {
+				t.Errorf("\n%s\ne.Observe(...): -want BudgetExceeded, +got BudgetExceeded:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestCreate(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/user/new": {Body: `{"user_id": "alice", "key": "sk-unused"}`}})
+	defer srv.Close()
+
+	cr := user("alice", v1alpha1.UserParameters{UserEmail: "alice@example.org", UserRole: "internal_user", SSOUserID: "okta|123", UserAlias: "Alice"}, v1alpha1.UserObservation{})
+	e := external{client: srv.Client()}
+	if _, err := e.Create(context.Background(), cr); err != nil {
+		t.Fatalf("e.Create(...): %v", err)
+	}
+
+	want := map[string]interface{}{
+		"user_id":         "alice",
+		"user_email":      "alice@example.org",
+		"user_role":       "internal_user",
+		"sso_user_id":     "okta|123",
+		"user_alias":      "Alice",
+		"auto_create_key": false,
+	}
+	if diff := cmp.Diff(want, srv.Body("/user/new")); diff != "" {
+		t.Errorf("e.Create(...): -want body, +got body:\n%s", diff)
+	}
+	if diff := cmp.Diff("alice", meta.GetExternalName(cr)); diff != "" {
+		t.Errorf("e.Create(...): -want external name, +got external name:\n%s", diff)
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	expires := time.Date(2024, 5, 9, 12, 0, 0, 0, time.UTC)
+
+	type want struct {
+		paths []string
+		u     managed.ExternalUpdate
+		obs   v1alpha1.UserObservation
+	}
+
+	cases := map[string]struct {
+		reason string
+		cr     *v1alpha1.User
+		want   want
+	}{
+		"NoInvitation": {
+			reason: "Updating a user without an invitation request should only update the user.",
+			cr:     user("alice", v1alpha1.UserParameters{UserRole: "internal_user"}, v1alpha1.UserObservation{}),
+			want: want{
+				paths: []string{"/user/update"},
+			},
+		},
+		"SendInvitation": {
+			reason: "A pending invitation should be created and its link published.",
+			cr:     user("alice", v1alpha1.UserParameters{SendInvite: true, InvitationSerial: 3}, v1alpha1.UserObservation{}),
+			want: want{
+				paths: []string{"/user/update", "/invitation/new"},
+				u: managed.ExternalUpdate{ConnectionDetails: managed.ConnectionDetails{
+					keyInvitationURL:     []byte("BASE/ui?invitation_id=inv-2"),
+					keyInvitationExpires: []byte("2024-05-09T12:00:00"),
+				}},
+				obs: v1alpha1.UserObservation{
+					InvitationID:        "inv-2",
+					InvitationSerial:    3,
+					InvitationExpiresAt: &metav1.Time{Time: expires},
+				},
+			},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			srv := fake.NewServer(map[string]fake.Response{
+				"/user/update":    {Body: `{}`},
+				"/invitation/new": {Body: `{"id": "inv-2", "user_id": "alice", "expires_at": "2024-05-09T12:00:00"}`},
+			})
+			defer srv.Close()
+
+			e := external{client: srv.Client(), apiBase: "BASE"}
+			got, err := e.Update(context.Background(), tc.cr)
+			if err != nil {
+				t.Fatalf("\n%s\ne.Update(...): %v", tc.reason, err)
+			}
+			if diff := cmp.Diff(tc.want.paths, srv.Paths()); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want paths, +got paths:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.u, got); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if diff := cmp.Diff(tc.want.obs, tc.cr.Status.AtProvider); diff != "" {
+				t.Errorf("\n%s\ne.Update(...): -want status, +got status:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}
+
+func TestDelete(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{"/user/delete": {Body: `{}`}})
+	defer srv.Close()
+
+	e := external{client: srv.Client()}
+	if err := e.Delete(context.Background(), user("alice", v1alpha1.UserParameters{}, v1alpha1.UserObservation{})); err != nil {
+		t.Fatalf("e.Delete(...): %v", err)
+	}
+
+	want := map[string]interface{}{"user_ids": []interface{}{"alice"}}
+	if diff := cmp.Diff(want, srv.Body("/user/delete")); diff != "" {
+		t.Errorf("e.Delete(...): -want body, +got body:\n%s", diff)
+	}
+}
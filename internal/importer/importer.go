@@ -0,0 +1,190 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package importer generates the manifests of managed resources that adopt
+// existing LiteLLM objects.
+package importer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+
+	xpv1 "github.com/crossplane/crossplane-runtime/apis/common/v1"
+	"github.com/crossplane/crossplane-runtime/pkg/meta"
+
+	"github.com/crossplane/provider-litellm/apis/key/v1alpha1"
+	"github.com/crossplane/provider-litellm/internal/clients/litellm"
+)
+
+const (
+	errListKeys    = "cannot list keys of team"
+	errMarshalKey  = "cannot marshal Key"
+	errWriteKey    = "cannot write Key"
+	errNoTeam      = "a team is required"
+	keyPageSize    = 100
+	tokenNameChars = 12
+)
+
+// invalidNameChars are the characters a Kubernetes object name can't have.
+var invalidNameChars = regexp.MustCompile(`[^a-z0-9-]+`)
+
+// key is a key returned by /key/list.
+type key struct {
+	Token          string                 `json:"token"`
+	KeyAlias       string                 `json:"key_alias"`
+	TeamID         string                 `json:"team_id"`
+	UserID         string                 `json:"user_id"`
+	Models         []string               `json:"models"`
+	MaxBudget      *float64               `json:"max_budget"`
+	BudgetDuration string                 `json:"budget_duration"`
+	TPMLimit       *int64                 `json:"tpm_limit"`
+	RPMLimit       *int64                 `json:"rpm_limit"`
+	Metadata       map[string]interface{} `json:"metadata"`
+}
+
+// TeamKeys returns a Key that adopts each key of the supplied team, using
+// the supplied ProviderConfig. The Keys orphan their keys when deleted, so
+// that adopting keys can't delete them. Nothing is changed in LiteLLM.
+func TeamKeys(ctx context.Context, c *litellm.Client, teamID, providerConfig string) ([]v1alpha1.Key, error) {
+	if teamID == "" {
+		return nil, errors.New(errNoTeam)
+	}
+	var keys []key
+	for page := 1; ; page++ {
+		var rsp struct {
+			Keys       []key `json:"keys"`
+			TotalPages int   `json:"total_pages"`
+		}
+		q := url.Values{
+			"team_id":            []string{teamID},
+			"return_full_object": []string{"true"},
+			"page":               []string{strconv.Itoa(page)},
+			"size":               []string{strconv.Itoa(keyPageSize)},
+		}
+		if err := c.Get(ctx, "/key/list", q, &rsp); err != nil {
+			return nil, errors.Wrap(err, errListKeys)
+		}
+		keys = append(keys, rsp.Keys...)
+		if page >= rsp.TotalPages {
+			break
+		}
+	}
+
+	names := map[string]int{}
+	crs := make([]v1alpha1.Key, 0, len(keys))
+	for _, k := range keys {
+		cr := v1alpha1.Key{
+			TypeMeta: metav1.TypeMeta{APIVersion: v1alpha1.KeyGroupVersionKind.GroupVersion().String(), Kind: v1alpha1.KeyKind},
+			Spec: v1alpha1.KeySpec{
+				ResourceSpec: xpv1.ResourceSpec{
+					ProviderConfigReference: &xpv1.Reference{Name: providerConfig},
+					DeletionPolicy:          xpv1.DeletionOrphan,
+				},
+				ForProvider: parameters(k),
+			},
+		}
+		name := objectName(k)
+		names[name]++
+		if n := names[name]; n > 1 {
+			name = fmt.Sprintf("%s-%d", name, n)
+		}
+		cr.SetName(name)
+		meta.SetExternalName(&cr, k.Token)
+		crs = append(crs, cr)
+	}
+	return crs, nil
+}
+
+// parameters returns the parameters of a Key that match the supplied key, so
+// that adopting it doesn't change it.
+func parameters(k key) v1alpha1.KeyParameters {
+	p := v1alpha1.KeyParameters{
+		KeyAlias:       k.KeyAlias,
+		TeamID:         k.TeamID,
+		UserID:         k.UserID,
+		Models:         k.Models,
+		BudgetDuration: k.BudgetDuration,
+		TPMLimit:       k.TPMLimit,
+		RPMLimit:       k.RPMLimit,
+	}
+	if k.MaxBudget != nil {
+		p.MaxBudget = *k.MaxBudget
+	}
+	// Only string metadata can be set on a Key. LiteLLM manages the rest,
+	// e.g. logging, itself.
+	for name, v := range k.Metadata {
+		if s, ok := v.(string); ok {
+			if p.Metadata == nil {
+				p.Metadata = map[string]string{}
+			}
+			p.Metadata[name] = s
+		}
+	}
+	return p
+}
+
+// objectName returns the name of the Key of the supplied key: its alias, if
+// that is a valid name once lower cased, otherwise the start of its token.
+func objectName(k key) string {
+	name := strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(k.KeyAlias), "-"), "-")
+	if len(validation.IsDNS1123Label(name)) == 0 {
+		return name
+	}
+	token := k.Token
+	if len(token) > tokenNameChars {
+		token = token[:tokenNameChars]
+	}
+	return "key-" + strings.Trim(invalidNameChars.ReplaceAllString(strings.ToLower(token), "-"), "-")
+}
+
+// Write writes the supplied Keys to the supplied writer as a stream of YAML
+// documents.
+func Write(w io.Writer, keys []v1alpha1.Key) error {
+	for i := range keys {
+		b, err := json.Marshal(&keys[i])
+		if err != nil {
+			return errors.Wrap(err, errMarshalKey)
+		}
+		// Drop what the API server sets, so the manifests are applied as is.
+		obj := map[string]interface{}{}
+		if err := json.Unmarshal(b, &obj); err != nil {
+			return errors.Wrap(err, errMarshalKey)
+		}
+		delete(obj, "status")
+		if m, ok := obj["metadata"].(map[string]interface{}); ok {
+			delete(m, "creationTimestamp")
+		}
+		y, err := yaml.Marshal(obj)
+		if err != nil {
+			return errors.Wrap(err, errMarshalKey)
+		}
+		if _, err := fmt.Fprintf(w, "---\n%s", y); err != nil {
+			return errors.Wrap(err, errWriteKey)
+		}
+	}
+	return nil
+}
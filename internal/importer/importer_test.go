@@ -0,0 +1,108 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package importer
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	"github.com/crossplane/provider-litellm/internal/clients/litellm/fake"
+)
+
+func TestTeamKeys(t *testing.T) {
+	srv := fake.NewServer(map[string]fake.Response{
+		"/key/list?page=1&return_full_object=true&size=100&team_id=ml": {Body: `{"keys": [
+			{"token": "a1b2c3d4e5f6a7b8", "key_alias": "CI Runner", "team_id": "ml", "models": ["gpt-4o"], "max_budget": 100, "budget_duration": "30d", "tpm_limit": 1000, "metadata": {"env": "prod", "logging": [{"callback_name": "langfuse"}]}}
+		], "total_pages": 2}`},
+		"/key/list?page=2&return_full_object=true&size=100&team_id=ml": {Body: `{"keys": [
+			{"token": "f0e1d2c3b4a5f6e7", "team_id": "ml"},
+			{"token": "0123456789abcdef", "key_alias": "ci-runner", "team_id": "ml"}
+		], "total_pages": 2}`},
+	})
+	defer srv.Close()
+
+	keys, err := TeamKeys(context.Background(), srv.Client(), "ml", "prod")
+	if err != nil {
+		t.Fatalf("TeamKeys(...): %v", err)
+	}
+	var got bytes.Buffer
+	if err := Write(&got, keys); err != nil {
+		t.Fatalf("Write(...): %v", err)
+	}
+
+	want := `---
+apiVersion: key.litellm.crossplane.io/v1alpha1
+kind: Key
+metadata:
+  annotations:
+    crossplane.io/external-name: a1b2c3d4e5f6a7b8
+  name: ci-runner
+spec:
+  deletionPolicy: Orphan
+  forProvider:
+    budget_duration: 30d
+    key_alias: CI Runner
+    max_budget: 100
+    metadata:
+      env: prod
+    models:
+    - gpt-4o
+    team_id: ml
+    tpm_limit: 1000
+  providerConfigRef:
+    name: prod
+---
+apiVersion: key.litellm.crossplane.io/v1alpha1
+kind: Key
+metadata:
+  annotations:
+    crossplane.io/external-name: f0e1d2c3b4a5f6e7
+  name: key-f0e1d2c3b4a5
+spec:
+  deletionPolicy: Orphan
+  forProvider:
+    team_id: ml
+  providerConfigRef:
+    name: prod
+---
+apiVersion: key.litellm.crossplane.io/v1alpha1
+kind: Key
+metadata:
+  annotations:
+    crossplane.io/external-name: 0123456789abcdef
+  name: ci-runner-2
+spec:
+  deletionPolicy: Orphan
+  forProvider:
+    key_alias: ci-runner
+    team_id: ml
+  providerConfigRef:
+    name: prod
+`
+	if diff := cmp.Diff(want, got.String()); diff != "" {
+		t.Errorf("Write(TeamKeys(...)): -want, +got:\n%s", diff)
+	}
+	for _, r := range srv.Requests() {
+		if r.Method != http.MethodGet {
+			t.Errorf("TeamKeys(...): want only GET requests, got %s %s", r.Method, r.Path)
+		}
+	}
+}
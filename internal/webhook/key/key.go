@@ -0,0 +1,133 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package key defaults and validates Keys before they are admitted.
+package key
+
+import (
+	"context"
+	"strings"
+
+	"github.com/pkg/errors"
+	kerrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	"github.com/crossplane/crossplane-runtime/pkg/webhook"
+
+	"github.com/crossplane/provider-litellm/apis/key/v1alpha1"
+)
+
+const (
+	errNotKey = "managed resource is not a Key custom resource"
+)
+
+// +kubebuilder:webhook:verbs=create;update,path=/mutate-key-litellm-crossplane-io-v1alpha1-key,mutating=true,failurePolicy=fail,groups=key.litellm.crossplane.io,resources=keys,versions=v1alpha1,name=keys.key.litellm.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+// +kubebuilder:webhook:verbs=create;update,path=/validate-key-litellm-crossplane-io-v1alpha1-key,mutating=false,failurePolicy=fail,groups=key.litellm.crossplane.io,resources=keys,versions=v1alpha1,name=keys.key.litellm.crossplane.io,sideEffects=None,admissionReviewVersions=v1
+
+// Setup adds the Key defaulting and validating webhooks to the supplied
+// manager.
+func Setup(mgr ctrl.Manager) error {
+	m := webhook.NewMutator(webhook.WithMutationFns(func(_ context.Context, obj runtime.Object) error {
+		return normalize(obj)
+	}))
+	v := webhook.NewValidator(
+		webhook.WithValidateCreationFns(func(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+			return nil, validate(obj)
+		}),
+		webhook.WithValidateUpdateFns(func(_ context.Context, _, obj runtime.Object) (admission.Warnings, error) {
+			return nil, validate(obj)
+		}),
+	)
+	return ctrl.NewWebhookManagedBy(mgr).For(&v1alpha1.Key{}).WithDefaulter(m).WithValidator(v).Complete()
+}
+
+// normalize puts the fields of the supplied Key that LiteLLM accepts in
+// several formats into a canonical one, so that formatting differences
+// don't show up as drift.
+func normalize(obj runtime.Object) error {
+	cr, ok := obj.(*v1alpha1.Key)
+	if !ok {
+		return errors.New(errNotKey)
+	}
+	p := &cr.Spec.ForProvider
+	p.Models = normalizeModels(p.Models)
+	p.Duration = normalizeDuration(p.Duration)
+	return nil
+}
+
+// normalizeModels returns the supplied models without surrounding
+// whitespace, empty entries or duplicates, in their original order.
+func normalizeModels(models []string) []string {
+	if models == nil {
+		return nil
+	}
+	out := make([]string, 0, len(models))
+	seen := make(map[string]bool, len(models))
+	for _, m := range models {
+		m = strings.TrimSpace(m)
+		if m == "" || seen[m] {
+			continue
+		}
+		seen[m] = true
+		out = append(out, m)
+	}
+	return out
+}
+
+// normalizeDuration returns the supplied duration without whitespace and
+// with a lower case unit, e.g. "30 D" becomes "30d".
+func normalizeDuration(d string) string {
+	return strings.ToLower(strings.Join(strings.Fields(d), ""))
+}
+
+// validate returns an error if the supplied Key has fields that LiteLLM
+// would reject or silently ignore in combination.
+func validate(obj runtime.Object) error {
+	cr, ok := obj.(*v1alpha1.Key)
+	if !ok {
+		return errors.New(errNotKey)
+	}
+	errs := validateParameters(cr.Spec.ForProvider, field.NewPath("spec", "forProvider"))
+	if len(errs) == 0 {
+		return nil
+	}
+	return kerrors.NewInvalid(v1alpha1.KeyGroupVersionKind.GroupKind(), cr.GetName(), errs)
+}
+
+// validateParameters returns the conflicting fields of the supplied
+// parameters. Rules the CRD validates, e.g. that budget_duration and
+// budget_reset_at are mutually exclusive, are not repeated here.
+func validateParameters(p v1alpha1.KeyParameters, path *field.Path) field.ErrorList {
+	var errs field.ErrorList
+	if p.Duration != "" && p.ExpiresAtRFC3339 != "" {
+		errs = append(errs, field.Forbidden(path.Child("expires_at"), "a fixed expiry can't be combined with duration, which computes the expiry"))
+	}
+	if p.SpendAlertThreshold != nil && p.MaxBudget != 0 && p.BudgetEnforcement != v1alpha1.BudgetEnforcementAdvisory && *p.SpendAlertThreshold > p.MaxBudget {
+		errs = append(errs, field.Invalid(path.Child("spend_alert_threshold"), *p.SpendAlertThreshold, "must not exceed max_budget, which stops the key from spending first"))
+	}
+	for m, b := range p.ModelMaxBudget {
+		if p.MaxBudget != 0 && b > p.MaxBudget {
+			errs = append(errs, field.Invalid(path.Child("model_max_budget").Key(m), b, "must not exceed max_budget"))
+		}
+	}
+	if p.Key != "" && p.RotationPeriod != nil && p.RotationPeriod.Duration > 0 {
+		errs = append(errs, field.Forbidden(path.Child("rotation_period"), "a key with a fixed value can't be rotated"))
+	}
+	return errs
+}
@@ -0,0 +1,173 @@
+/*
+Copyright 2024 The Crossplane Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package key
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/pkg/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+
+	"github.com/crossplane/crossplane-runtime/pkg/test"
+
+	"github.com/crossplane/provider-litellm/apis/key/v1alpha1"
+)
+
+func TestValidate(t *testing.T) {
+	threshold := 20.0
+	path := field.NewPath("spec", "forProvider")
+
+	cases := map[string]struct {
+		reason string
+		obj    runtime.Object
+		want   field.ErrorList
+	}{
+		"Valid": {
+			reason: "A Key without conflicting fields should be admitted.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				MaxBudget:           100,
+				BudgetDuration:      "30d",
+				SpendAlertThreshold: &threshold,
+				ModelMaxBudget:      map[string]float64{"gpt-4": 50},
+				RotationPeriod:      &metav1.Duration{Duration: time.Hour},
+			}}},
+		},
+		"FixedExpiry": {
+			reason: "A Key with a fixed expiry and no duration should be admitted.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				ExpiresAtRFC3339: "2030-01-01T00:00:00Z",
+			}}},
+		},
+		"DurationAndExpiresAt": {
+			reason: "A fixed expiry should be rejected alongside a duration.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				Duration:         "30d",
+				ExpiresAtRFC3339: "2030-01-01T00:00:00Z",
+			}}},
+			want: field.ErrorList{field.Forbidden(path.Child("expires_at"), "")},
+		},
+		"ThresholdAboveMaxBudget": {
+			reason: "A spend alert threshold above the max budget should be rejected.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				MaxBudget:           10,
+				SpendAlertThreshold: &threshold,
+			}}},
+			want: field.ErrorList{field.Invalid(path.Child("spend_alert_threshold"), threshold, "")},
+		},
+		"ThresholdAboveAdvisoryBudget": {
+			reason: "A spend alert threshold above an advisory budget, which doesn't block the key, should be accepted.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				MaxBudget:           10,
+				BudgetEnforcement:   v1alpha1.BudgetEnforcementAdvisory,
+				SpendAlertThreshold: &threshold,
+			}}},
+		},
+		"ModelMaxBudgetAboveMaxBudget": {
+			reason: "A model budget above the max budget should be rejected.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				MaxBudget:      10,
+				ModelMaxBudget: map[string]float64{"gpt-4": 50},
+			}}},
+			want: field.ErrorList{field.Invalid(path.Child("model_max_budget").Key("gpt-4"), 50.0, "")},
+		},
+		"RotatedFixedKey": {
+			reason: "A key with a fixed value should not be rotated.",
+			obj: &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: v1alpha1.KeyParameters{
+				Key:            "sk-fixed",
+				RotationPeriod: &metav1.Duration{Duration: time.Hour},
+			}}},
+			want: field.ErrorList{field.Forbidden(path.Child("rotation_period"), "")},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			got := validateParameters(tc.obj.(*v1alpha1.Key).Spec.ForProvider, path)
+			if diff := cmp.Diff(tc.want, got, cmp.Transformer("IgnoreDetail", func(e *field.Error) field.Error {
+				c := *e
+				c.Detail = ""
+				return c
+			})); diff != "" {
+				t.Errorf("\n%s\nvalidateParameters(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+			if err := validate(tc.obj); (err != nil) != (len(tc.want) > 0) {
+				t.Errorf("\n%s\nvalidate(...): got error %v", tc.reason, err)
+			}
+		})
+	}
+}
+
+func TestValidateNotKey(t *testing.T) {
+	err := validate(&v1alpha1.KeyList{})
+	if diff := cmp.Diff(errors.New(errNotKey), err, test.EquateErrors()); diff != "" {
+		t.Errorf("validate(...): -want error, +got error:\n%s", diff)
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	cases := map[string]struct {
+		reason string
+		p      v1alpha1.KeyParameters
+		want   v1alpha1.KeyParameters
+	}{
+		"Messy": {
+			reason: "Models should be trimmed and deduplicated, and the duration compacted.",
+			p: v1alpha1.KeyParameters{
+				Models:   []string{" gpt-4o", "claude-sonnet ", "gpt-4o", " "},
+				Duration: " 30 D ",
+			},
+			want: v1alpha1.KeyParameters{
+				Models:   []string{"gpt-4o", "claude-sonnet"},
+				Duration: "30d",
+			},
+		},
+		"Clean": {
+			reason: "A canonical spec should be left untouched.",
+			p: v1alpha1.KeyParameters{
+				Models:   []string{"gpt-4o", "claude-sonnet"},
+				Duration: "1h",
+				KeyAlias: "ci",
+			},
+			want: v1alpha1.KeyParameters{
+				Models:   []string{"gpt-4o", "claude-sonnet"},
+				Duration: "1h",
+				KeyAlias: "ci",
+			},
+		},
+		"Unset": {
+			reason: "Unset models should stay unset rather than become an empty list.",
+			p:      v1alpha1.KeyParameters{},
+			want:   v1alpha1.KeyParameters{},
+		},
+	}
+
+	for name, tc := range cases {
+		t.Run(name, func(t *testing.T) {
+			cr := &v1alpha1.Key{Spec: v1alpha1.KeySpec{ForProvider: tc.p}}
+			if err := normalize(cr); err != nil {
+				t.Fatalf("normalize(...): %v", err)
+			}
+			if diff := cmp.Diff(tc.want, cr.Spec.ForProvider); diff != "" {
+				t.Errorf("\n%s\nnormalize(...): -want, +got:\n%s\n", tc.reason, diff)
+			}
+		})
+	}
+}